@@ -0,0 +1,2 @@
+-- Drop user_settings table
+DROP TABLE IF EXISTS `user_settings`;
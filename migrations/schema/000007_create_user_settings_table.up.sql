@@ -0,0 +1,8 @@
+-- Create user_settings table
+CREATE TABLE IF NOT EXISTS `user_settings` (
+  `user_id` VARCHAR(255) NOT NULL COMMENT 'Slack user ID',
+  `lang` VARCHAR(16) NOT NULL COMMENT 'Preferred response language',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Record creation time',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Record update time',
+  PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
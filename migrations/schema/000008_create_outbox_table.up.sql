@@ -0,0 +1,19 @@
+-- Create outbox table
+CREATE TABLE IF NOT EXISTS `outbox` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Outbox message ID',
+  `mention_id` CHAR(26) NOT NULL COMMENT 'Reference to slack_mentions.id',
+  `correlation_id` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Correlation ID for logs and queue messages',
+  `queue_key` VARCHAR(255) NOT NULL COMMENT 'Destination queue key',
+  `payload` JSON NOT NULL COMMENT 'Queue message body',
+  `status` VARCHAR(50) NOT NULL DEFAULT 'pending' COMMENT 'Status of the message (pending, processing, sent)',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT 'Number of send attempts',
+  `next_attempt_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Time when the message can be sent next',
+  `locked_until` DATETIME NULL DEFAULT NULL COMMENT 'Lease expiry while a relay is sending the message',
+  `last_error` TEXT NULL COMMENT 'Last send error',
+  `sent_at` DATETIME NULL DEFAULT NULL COMMENT 'Time when the message was sent',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Record creation time',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Record update time',
+  PRIMARY KEY (`id`),
+  INDEX `idx_outbox_status_next_attempt_at` (`status`, `next_attempt_at`),
+  INDEX `idx_outbox_mention_id` (`mention_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
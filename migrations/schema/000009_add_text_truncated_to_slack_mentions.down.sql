@@ -0,0 +1,2 @@
+-- Drop text_truncated column from slack_mentions table
+ALTER TABLE `slack_mentions` DROP COLUMN `text_truncated`;
@@ -0,0 +1,3 @@
+-- Add text_truncated column to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `text_truncated` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Whether the text was truncated to the maximum length' AFTER `text`;
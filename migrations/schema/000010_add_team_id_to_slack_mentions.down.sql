@@ -0,0 +1,4 @@
+-- Drop team_id column from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP INDEX `idx_slack_mentions_team_id`,
+  DROP COLUMN `team_id`;
@@ -0,0 +1,4 @@
+-- Add team_id column to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `team_id` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Slack workspace (team) ID' AFTER `type`,
+  ADD INDEX `idx_slack_mentions_team_id` (`team_id`);
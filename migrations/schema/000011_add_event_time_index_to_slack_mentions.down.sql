@@ -0,0 +1,3 @@
+-- Drop event_time index from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP INDEX `idx_slack_mentions_event_time`;
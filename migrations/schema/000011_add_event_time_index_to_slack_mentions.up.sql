@@ -0,0 +1,3 @@
+-- Add event_time index to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD INDEX `idx_slack_mentions_event_time` (`event_time`);
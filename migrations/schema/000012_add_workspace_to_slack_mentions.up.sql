@@ -0,0 +1,3 @@
+-- Add workspace column to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `workspace` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Configured workspace name' AFTER `team_id`;
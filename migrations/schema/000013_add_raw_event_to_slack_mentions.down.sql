@@ -0,0 +1,3 @@
+-- Drop raw_event column from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP COLUMN `raw_event`;
@@ -0,0 +1,3 @@
+-- Add raw_event column to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `raw_event` MEDIUMTEXT NULL COMMENT 'Raw Slack event JSON for debugging and replay' AFTER `text_truncated`;
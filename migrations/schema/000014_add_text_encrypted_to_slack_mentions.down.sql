@@ -0,0 +1,3 @@
+-- Drop text_encrypted column from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP COLUMN `text_encrypted`;
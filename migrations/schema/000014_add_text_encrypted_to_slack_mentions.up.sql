@@ -0,0 +1,3 @@
+-- Add text_encrypted column to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `text_encrypted` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Whether text is encrypted with AES-GCM (nonce + ciphertext, base64)' AFTER `text`;
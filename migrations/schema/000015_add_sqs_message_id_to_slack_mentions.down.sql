@@ -0,0 +1,3 @@
+-- Drop sqs_message_id column from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP COLUMN `sqs_message_id`;
@@ -0,0 +1,3 @@
+-- Add sqs_message_id column to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `sqs_message_id` VARCHAR(100) NULL COMMENT 'MessageId returned by SQS when the mention was enqueued' AFTER `raw_event`;
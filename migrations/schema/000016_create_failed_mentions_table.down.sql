@@ -0,0 +1,2 @@
+-- Drop failed_mentions table
+DROP TABLE IF EXISTS `failed_mentions`;
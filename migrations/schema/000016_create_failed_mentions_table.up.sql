@@ -0,0 +1,17 @@
+-- Create failed_mentions table
+CREATE TABLE IF NOT EXISTS `failed_mentions` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Dead-letter ID',
+  `mention_id` CHAR(26) NOT NULL COMMENT 'Reference to slack_mentions.id',
+  `correlation_id` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Correlation ID for logs and queue messages',
+  `queue_key` VARCHAR(255) NOT NULL COMMENT 'Destination queue key',
+  `payload` JSON NOT NULL COMMENT 'Queue message body',
+  `reason` TEXT NOT NULL COMMENT 'Last enqueue error',
+  `attempts` INT NOT NULL DEFAULT 1 COMMENT 'Number of failed send attempts',
+  `failed_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Time of the last failure',
+  `replayed_at` DATETIME NULL DEFAULT NULL COMMENT 'Time when the message was replayed',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Record creation time',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Record update time',
+  PRIMARY KEY (`id`),
+  INDEX `idx_failed_mentions_replayed_at` (`replayed_at`),
+  INDEX `idx_failed_mentions_mention_id` (`mention_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,4 @@
+-- Drop user_name and user_real_name columns from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP COLUMN `user_real_name`,
+  DROP COLUMN `user_name`;
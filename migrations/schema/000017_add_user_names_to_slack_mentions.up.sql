@@ -0,0 +1,4 @@
+-- Add user_name and user_real_name columns to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `user_name` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Slack display name of the user (user ID if unavailable)' AFTER `user_id`,
+  ADD COLUMN `user_real_name` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Slack real name of the user (user ID if unavailable)' AFTER `user_name`;
@@ -0,0 +1,3 @@
+-- Drop user_id and event_time index from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP INDEX `idx_slack_mentions_user_id_event_time`;
@@ -0,0 +1,3 @@
+-- Add user_id and event_time index to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD INDEX `idx_slack_mentions_user_id_event_time` (`user_id`, `event_time`);
@@ -0,0 +1,6 @@
+-- Drop conversation_id from slack_mentions table and conversations table
+ALTER TABLE `slack_mentions`
+  DROP INDEX `idx_slack_mentions_conversation_id`,
+  DROP COLUMN `conversation_id`;
+
+DROP TABLE IF EXISTS `conversations`;
@@ -0,0 +1,17 @@
+-- Create conversations table and add conversation_id to slack_mentions table
+CREATE TABLE IF NOT EXISTS `conversations` (
+  `id` CHAR(26) NOT NULL COMMENT 'ULID primary key',
+  `channel_id` VARCHAR(255) NOT NULL COMMENT 'Slack channel ID',
+  `thread_ts` VARCHAR(255) NOT NULL COMMENT 'Slack thread root timestamp',
+  `started_by` VARCHAR(255) NOT NULL COMMENT 'Slack user ID who started the conversation',
+  `last_activity` DATETIME NOT NULL COMMENT 'Time of the last message in the conversation',
+  `message_count` INT NOT NULL DEFAULT 1 COMMENT 'Number of messages in the conversation',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Record creation time',
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Record update time',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_conversations_channel_id_thread_ts` (`channel_id`, `thread_ts`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `conversation_id` CHAR(26) NULL DEFAULT NULL COMMENT 'Reference to conversations.id' AFTER `sqs_message_id`,
+  ADD INDEX `idx_slack_mentions_conversation_id` (`conversation_id`);
@@ -0,0 +1,3 @@
+-- Drop status column from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP COLUMN `status`;
@@ -0,0 +1,3 @@
+-- Add status column to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `status` VARCHAR(32) NOT NULL DEFAULT 'received' COMMENT 'Processing status (received, timed_out)' AFTER `conversation_id`;
@@ -0,0 +1,4 @@
+-- Drop status_detail column from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP COLUMN `status_detail`,
+  MODIFY COLUMN `status` VARCHAR(32) NOT NULL DEFAULT 'received' COMMENT 'Processing status (received, timed_out)';
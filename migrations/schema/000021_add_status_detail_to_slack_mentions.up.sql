@@ -0,0 +1,4 @@
+-- Add status_detail column to slack_mentions table and document the new statuses
+ALTER TABLE `slack_mentions`
+  MODIFY COLUMN `status` VARCHAR(32) NOT NULL DEFAULT 'received' COMMENT 'Processing status (received, queued, answered, failed, timed_out)',
+  ADD COLUMN `status_detail` TEXT NULL COMMENT 'Reason of the last status change (e.g. publish error)' AFTER `status`;
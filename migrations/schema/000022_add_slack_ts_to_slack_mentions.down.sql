@@ -0,0 +1,4 @@
+-- Drop Slack ts columns from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP COLUMN `thread_ts`,
+  DROP COLUMN `ts`;
@@ -0,0 +1,4 @@
+-- Add the original Slack ts strings to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `ts` VARCHAR(32) NULL COMMENT 'Slack message ts as received (e.g. 1712345678.000200)' AFTER `timestamp`,
+  ADD COLUMN `thread_ts` VARCHAR(32) NULL COMMENT 'Slack thread_ts as received (NULL outside threads)' AFTER `ts`;
@@ -0,0 +1,4 @@
+-- Drop channel response settings from slack_mentions table
+ALTER TABLE `slack_mentions`
+  DROP COLUMN `persona`,
+  DROP COLUMN `language`;
@@ -0,0 +1,4 @@
+-- Add channel response settings to slack_mentions table
+ALTER TABLE `slack_mentions`
+  ADD COLUMN `language` VARCHAR(32) NULL COMMENT 'Response language configured for the channel (channel_settings)' AFTER `conversation_id`,
+  ADD COLUMN `persona` VARCHAR(255) NULL COMMENT 'Persona configured for the channel (channel_settings)' AFTER `language`;
@@ -0,0 +1,2 @@
+-- Drop conversation_messages table
+DROP TABLE IF EXISTS `conversation_messages`;
@@ -0,0 +1,15 @@
+-- Create conversation_messages table
+CREATE TABLE IF NOT EXISTS `conversation_messages` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Conversation message ID',
+  `channel_id` VARCHAR(255) NOT NULL COMMENT 'Slack channel ID',
+  `thread_ts` VARCHAR(255) NOT NULL COMMENT 'Slack thread root timestamp',
+  `ts` VARCHAR(255) NOT NULL COMMENT 'Slack message timestamp',
+  `role` VARCHAR(16) NOT NULL COMMENT 'user or assistant',
+  `user_id` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Slack user ID who posted the message (empty for the bot)',
+  `text` TEXT NOT NULL COMMENT 'Message text',
+  `text_encrypted` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Whether text is encrypted with AES-GCM (nonce + ciphertext, base64)',
+  `posted_at` DATETIME NOT NULL COMMENT 'Time when the message was posted',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Record creation time',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_conversation_messages_channel_id_thread_ts_ts` (`channel_id`, `thread_ts`, `ts`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
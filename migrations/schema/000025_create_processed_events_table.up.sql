@@ -0,0 +1,7 @@
+-- Create processed_events table
+CREATE TABLE IF NOT EXISTS `processed_events` (
+  `event_key` VARCHAR(255) NOT NULL COMMENT 'Slack event_id (or client_msg_id) with the team ID',
+  `processed_at` DATETIME NOT NULL COMMENT 'Time when the event was first processed',
+  PRIMARY KEY (`event_key`),
+  INDEX `idx_processed_events_processed_at` (`processed_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
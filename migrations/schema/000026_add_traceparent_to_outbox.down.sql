@@ -0,0 +1,3 @@
+-- Drop traceparent column from outbox table
+ALTER TABLE `outbox`
+  DROP COLUMN `traceparent`;
@@ -0,0 +1,3 @@
+-- Add traceparent column to outbox table
+ALTER TABLE `outbox`
+  ADD COLUMN `traceparent` VARCHAR(55) NOT NULL DEFAULT '' COMMENT 'W3C traceparent of the span that received the mention' AFTER `correlation_id`;
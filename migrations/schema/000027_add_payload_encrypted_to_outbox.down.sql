@@ -0,0 +1,3 @@
+-- Drop payload_encrypted column from outbox table
+ALTER TABLE `outbox`
+  DROP COLUMN `payload_encrypted`;
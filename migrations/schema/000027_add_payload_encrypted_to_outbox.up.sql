@@ -0,0 +1,3 @@
+-- Add payload_encrypted column to outbox table
+ALTER TABLE `outbox`
+  ADD COLUMN `payload_encrypted` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Whether payload is encrypted with AES-GCM (JSON string of nonce + ciphertext, base64)' AFTER `payload`;
@@ -0,0 +1,3 @@
+-- Drop payload_encrypted column from failed_mentions table
+ALTER TABLE `failed_mentions`
+  DROP COLUMN `payload_encrypted`;
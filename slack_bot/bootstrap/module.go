@@ -2,11 +2,63 @@ package bootstrap
 
 import (
 	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
 	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/modules"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
 	"go.uber.org/fx"
 )
 
+// CommandModule はBotの常駐に必要なすべてのモジュール
 var CommandModule = fx.Options(
-	config.Module,
+	modules.DatabaseModule,
 	modules.RepositoryModule,
+	modules.MentionStoreModule,
+	modules.RetentionModule,
+	modules.StorageModule,
+	modules.TracingModule,
+	modules.HTTPServerModule,
+	modules.MetricsModule,
+	modules.MaintenanceModule,
+	modules.QueueModule,
+	modules.OutboxModule,
+	modules.ConversationModule,
+	modules.EventDedupModule,
+	modules.DigestModule,
+	modules.MentionReplayModule,
 )
+
+// ReplayModule はキューに送信できなかったメッセージ・保存したメンションの再送に必要なモジュール
+var ReplayModule = fx.Options(
+	modules.DatabaseModule,
+	modules.RepositoryModule,
+	modules.MentionStoreModule,
+	fx.Provide(
+		metrics.NewRegistry,
+		metrics.NewMetrics,
+	),
+	modules.QueueModule,
+	fx.Provide(usecase.NewDeadLetterReplayer),
+	modules.MentionReplayModule,
+)
+
+// WorkerModule はキューのメンションにAIで回答してSlackに投稿するワーカーに必要なモジュール
+// 回答したメンションの状態の更新と回答の会話への保存のため、データベースが有効な場合はメンション・会話の保存先も使用する
+var WorkerModule = fx.Options(
+	modules.DatabaseModule,
+	modules.RepositoryModule,
+	modules.MentionStoreModule,
+	modules.ConversationModule,
+	modules.TracingModule,
+	modules.WorkerModule,
+)
+
+// MigrateModule はデータベースのマイグレーションに必要なモジュール
+var MigrateModule = fx.Options(
+	modules.MigrateDatabaseModule,
+)
+
+// NewApp は設定の読み込みとロガーに opts のモジュールを加えたアプリケーションを作成する
+// サブコマンドごとに必要なモジュールだけを指定する
+func NewApp(opts ...fx.Option) *fx.App {
+	return fx.New(append([]fx.Option{config.Module, modules.LoggerModule}, opts...)...)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// adminCommandPrefix は管理コマンドとして扱うメンションの最初の単語
+const adminCommandPrefix = "admin"
+
+// 管理コマンドを処理するメソッド
+// メンションが管理コマンド（@bot admin <command>）の場合は処理して true を返す
+// 管理者（admin.user_ids）以外のユーザーには権限がないことを返信する
+func (app *SlackBotApp) handleAdminCommand(ctx context.Context, evt *slackevents.AppMentionEvent) bool {
+	if len(app.AppConfig.Admin.UserIDs) == 0 {
+		return false
+	}
+	args := adminCommandArgs(evt.Text)
+	if args == nil {
+		return false
+	}
+
+	if !slices.Contains(app.AppConfig.Admin.UserIDs, evt.User) {
+		logger.Printf(ctx, "管理者以外のユーザーによる管理コマンドを拒否しました: user=%s", evt.User)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.denied"))
+		return true
+	}
+
+	var command string
+	if len(args) > 0 {
+		command = args[0]
+	}
+	switch command {
+	case "pause":
+		if !app.Maintenance.Pause() {
+			app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.already_paused"))
+			return true
+		}
+		logger.Printf(ctx, "管理コマンドによりメンションの処理を一時停止しました: user=%s", evt.User)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.paused"))
+	case "resume":
+		if !app.Maintenance.Resume() {
+			app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.not_paused"))
+			return true
+		}
+		logger.Printf(ctx, "管理コマンドによりメンションの処理を再開しました: user=%s", evt.User)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.resumed"))
+	case "status":
+		app.replyInThread(ctx, evt, app.adminStatus(ctx, evt.User))
+	case "export":
+		app.handleAdminExport(ctx, evt, args[1:])
+	case "dead_letters":
+		app.handleAdminDeadLetters(ctx, evt)
+	case "replay":
+		app.handleAdminReplay(ctx, evt, args[1:])
+	default:
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.unknown", command))
+	}
+	return true
+}
+
+// メンションのテキストが管理コマンドの場合は admin に続く引数を返し、それ以外の場合は nil を返す
+// Botへのメンション（<@U...>）は取り除く
+func adminCommandArgs(text string) []string {
+	var fields []string
+	for _, field := range strings.Fields(text) {
+		if strings.HasPrefix(field, "<@") {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 || !strings.EqualFold(fields[0], adminCommandPrefix) {
+		return nil
+	}
+	return append([]string{}, fields[1:]...)
+}
+
+// 管理コマンドの status で返信する処理の状態を作成するメソッド
+// 取得できなかった項目は "-" と表示する
+func (app *SlackBotApp) adminStatus(ctx context.Context, userID string) string {
+	state := app.t(ctx, userID, "admin.state_running")
+	if app.Maintenance.Paused() {
+		state = app.t(ctx, userID, "admin.state_paused")
+	}
+
+	connection := app.t(ctx, userID, "admin.disconnected")
+	switch {
+	case app.Workspace.IsWebhook():
+		connection = app.t(ctx, userID, "admin.webhook")
+	case app.connected.Load():
+		connection = app.t(ctx, userID, "admin.connected")
+	}
+
+	queueBacklog := "-"
+	if count, err := app.Publisher.Backlog(ctx, app.queueKey(config.QueueKeyMention)); err != nil {
+		logger.Errorf(ctx, "キューのメッセージ数の取得エラー: %v", err)
+	} else {
+		queueBacklog = strconv.Itoa(count)
+	}
+
+	outboxBacklog := "-"
+	if app.OutboxRepository != nil {
+		if count, err := app.OutboxRepository.CountPending(ctx); err != nil {
+			logger.Errorf(ctx, "アウトボックスの件数取得エラー: %v", err)
+		} else {
+			outboxBacklog = strconv.Itoa(count)
+		}
+	}
+
+	recentMentions := "-"
+	if app.MentionQuery != nil {
+		if count, err := app.MentionQuery.CountSince(ctx, time.Now().Add(-24*time.Hour)); err != nil {
+			logger.Errorf(ctx, "メンション数の取得エラー: %v", err)
+		} else {
+			recentMentions = strconv.Itoa(count)
+		}
+	}
+
+	return app.t(ctx, userID, "admin.status", state, connection, queueBacklog, outboxBacklog, recentMentions)
+}
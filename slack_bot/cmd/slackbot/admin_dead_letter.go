@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack/slackevents"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+)
+
+const (
+	// deadLetterListLimit は管理コマンドの dead_letters で表示するデッドレターの件数
+	deadLetterListLimit = 10
+	// deadLetterReasonLength は dead_letters で表示する失敗の理由の最大文字数
+	deadLetterReasonLength = 200
+	// replayDefaultLimit は管理コマンドの replay で件数を指定しない場合に再送する件数
+	replayDefaultLimit = 10
+	// replayMaxLimit は管理コマンドの replay で一度に再送できる件数の上限
+	replayMaxLimit = 100
+)
+
+// 管理コマンドの dead_letters を処理するメソッド
+// 再送していないデッドレターを古い順に表示する
+func (app *SlackBotApp) handleAdminDeadLetters(ctx context.Context, evt *slackevents.AppMentionEvent) {
+	if app.DeadLetters == nil {
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.dead_letter_unavailable"))
+		return
+	}
+	failed, err := app.DeadLetters.FindPending(ctx, deadLetterListLimit)
+	if err != nil {
+		logger.Errorf(ctx, "デッドレターの取得エラー: %v", err)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.dead_letters_failed"))
+		return
+	}
+	if len(failed) == 0 {
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.dead_letters_empty"))
+		return
+	}
+
+	loc := app.workspaceLocation(ctx)
+	lines := make([]string, 0, len(failed))
+	for _, m := range failed {
+		lines = append(lines, fmt.Sprintf("• #%d %s `%s` %s: %s",
+			m.ID, m.FailedAt.In(loc).Format("2006-01-02 15:04"), m.QueueKey, m.MentionID, truncateRunes(m.Reason, deadLetterReasonLength)))
+	}
+	app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.dead_letters", deadLetterListLimit, strings.Join(lines, "\n")))
+}
+
+// 管理コマンドの replay を処理するメソッド
+// 再送していないデッドレターを古い順に指定した件数（省略した場合は replayDefaultLimit 件）まで元のキューに再送する
+func (app *SlackBotApp) handleAdminReplay(ctx context.Context, evt *slackevents.AppMentionEvent, args []string) {
+	if app.DeadLetters == nil {
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.dead_letter_unavailable"))
+		return
+	}
+	limit, err := parseReplayArgs(args)
+	if err != nil {
+		logger.Printf(ctx, "管理コマンドの replay の引数が不正です: user=%s: %v", evt.User, err)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.replay_usage", replayMaxLimit, replayDefaultLimit))
+		return
+	}
+
+	replayer := usecase.NewDeadLetterReplayer(app.DeadLetters, app.Publisher)
+	replayed, failed, err := replayer.Replay(ctx, limit)
+	if err != nil {
+		logger.Errorf(ctx, "デッドレターの再送エラー: %v", err)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.replay_failed", replayed, failed))
+		return
+	}
+	logger.Printf(ctx, "管理コマンドによりデッドレターを再送しました: user=%s replayed=%d failed=%d", evt.User, replayed, failed)
+	app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.replay_done", replayed, failed))
+}
+
+// 管理コマンドの replay の引数から再送する件数を返す
+func parseReplayArgs(args []string) (int, error) {
+	switch len(args) {
+	case 0:
+		return replayDefaultLimit, nil
+	case 1:
+		limit, err := strconv.Atoi(args[0])
+		if err != nil || limit < 1 || limit > replayMaxLimit {
+			return 0, fmt.Errorf("件数は1〜%dで指定してください: %q", replayMaxLimit, args[0])
+		}
+		return limit, nil
+	default:
+		return 0, fmt.Errorf("引数が多すぎます: %v", args)
+	}
+}
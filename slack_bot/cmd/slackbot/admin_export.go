@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+)
+
+const (
+	// exportDateLayout は管理コマンドの export で指定する日付の形式
+	exportDateLayout = "2006-01-02"
+	// exportDefaultDays は期間を指定しない場合にエクスポートする日数（今日を含む）
+	exportDefaultDays = 7
+)
+
+// exportRequest は管理コマンドの export の引数
+// from は開始日の0時、to は終了日の翌日の0時（この時刻を含まない）
+type exportRequest struct {
+	format usecase.ExportFormat
+	from   time.Time
+	to     time.Time
+}
+
+// 管理コマンドの export を処理するメソッド
+// 期間内のメンションの履歴をCSVまたはJSONのファイルとして、コマンドを実行したスレッドにアップロードする
+func (app *SlackBotApp) handleAdminExport(ctx context.Context, evt *slackevents.AppMentionEvent, args []string) {
+	if app.MentionQuery == nil {
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.export_unavailable"))
+		return
+	}
+	loc := app.workspaceLocation(ctx)
+	req, err := parseExportArgs(args, time.Now().In(loc))
+	if err != nil {
+		logger.Printf(ctx, "管理コマンドの export の引数が不正です: user=%s: %v", evt.User, err)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.export_usage"))
+		return
+	}
+
+	cfg := app.AppConfig.Admin.Export
+	exporter := usecase.NewMentionExporter(app.MentionQuery, cfg.MaxRows, cfg.MaxTextLength)
+	var buf bytes.Buffer
+	count, err := exporter.Export(ctx, &buf, req.format, req.from, req.to, loc)
+	if errors.Is(err, usecase.ErrExportTooManyRows) {
+		logger.Printf(ctx, "エクスポートする件数が上限を超えています: user=%s max_rows=%d", evt.User, cfg.MaxRows)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.export_too_many", cfg.MaxRows))
+		return
+	}
+	if err != nil {
+		logger.Errorf(ctx, "メンションのエクスポートエラー: %v", err)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.export_failed"))
+		return
+	}
+
+	lastDay := req.to.AddDate(0, 0, -1).Format(exportDateLayout)
+	filename := fmt.Sprintf("mentions_%s_%s.%s", req.from.Format(exportDateLayout), lastDay, req.format)
+	_, err = app.SlackClient.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Channel:         evt.Channel,
+		ThreadTimestamp: threadTimeStamp(evt),
+		Filename:        filename,
+		Title:           filename,
+		FileSize:        buf.Len(),
+		Reader:          &buf,
+		InitialComment:  app.t(ctx, evt.User, "admin.export_done", req.from.Format(exportDateLayout), lastDay, count),
+	})
+	if err != nil {
+		logger.Errorf(ctx, "エクスポートしたファイルのアップロードエラー: %v", err)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "admin.export_failed"))
+		return
+	}
+	logger.Printf(ctx, "管理コマンドによりメンションの履歴をエクスポートしました: user=%s file=%s count=%d", evt.User, filename, count)
+}
+
+// ワークスペースのタイムゾーンを返すメソッド
+// 読み込めない場合はログに出力してサーバーのタイムゾーンを返す
+func (app *SlackBotApp) workspaceLocation(ctx context.Context) *time.Location {
+	loc, err := time.LoadLocation(app.Workspace.OfficeHours.Timezone)
+	if err != nil {
+		logger.Errorf(ctx, "タイムゾーンの読み込みエラー（サーバーのタイムゾーンを使用します）: %v", err)
+		return time.Local
+	}
+	return loc
+}
+
+// 管理コマンドの export の引数（[from] [to] [csv|json]）を解析する
+// 日付は YYYY-MM-DD 形式で now のタイムゾーンの日付として扱い、形式は順不同で指定できる
+// 期間を省略した場合は今日までの7日間、to を省略した場合は今日までとする
+func parseExportArgs(args []string, now time.Time) (exportRequest, error) {
+	req := exportRequest{format: usecase.ExportFormatCSV}
+	var dates []time.Time
+	for _, arg := range args {
+		if format, ok := usecase.ParseExportFormat(strings.ToLower(arg)); ok {
+			req.format = format
+			continue
+		}
+		date, err := time.ParseInLocation(exportDateLayout, arg, now.Location())
+		if err != nil {
+			return exportRequest{}, fmt.Errorf("不正な引数です: %s", arg)
+		}
+		dates = append(dates, date)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch len(dates) {
+	case 0:
+		req.from, req.to = today.AddDate(0, 0, 1-exportDefaultDays), today.AddDate(0, 0, 1)
+	case 1:
+		req.from, req.to = dates[0], today.AddDate(0, 0, 1)
+	case 2:
+		req.from, req.to = dates[0], dates[1].AddDate(0, 0, 1)
+	default:
+		return exportRequest{}, errors.New("日付は開始日と終了日の2つまで指定できます")
+	}
+	if !req.from.Before(req.to) {
+		return exportRequest{}, errors.New("開始日には終了日以前の日付を指定してください")
+	}
+	return req, nil
+}
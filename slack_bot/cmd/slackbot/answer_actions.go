@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"slices"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/blockkit"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// 回答に付けるボタンと確認ダイアログの表示文言を返すメソッド
+// 回答はチャンネルの全員に表示されるため、デフォルトの言語を使用する
+func (app *SlackBotApp) answerLabels() blockkit.AnswerLabels {
+	lang := app.Translator.DefaultLang()
+	return blockkit.AnswerLabels{
+		Regenerate:    app.Translator.T(lang, "answer.regenerate"),
+		ShowSources:   app.Translator.T(lang, "answer.show_sources"),
+		Delete:        app.Translator.T(lang, "answer.delete"),
+		ConfirmTitle:  app.Translator.T(lang, "answer.delete_confirm_title"),
+		ConfirmText:   app.Translator.T(lang, "answer.delete_confirm_text"),
+		ConfirmDelete: app.Translator.T(lang, "answer.delete"),
+		Cancel:        app.Translator.T(lang, "answer.cancel"),
+	}
+}
+
+// 「再生成」が押されたときに、保存したメンションをキューに再送して回答を作り直すメソッド
+// 回答はAIワーカーが同じスレッドに新しく投稿する
+func (app *SlackBotApp) handleRegenerateAnswer(callback slack.InteractionCallback, action *slack.BlockAction) {
+	target, ok := app.answerActionTarget(action)
+	if !ok {
+		return
+	}
+	ctx := logger.WithCorrelationID(context.Background(), target.MentionID)
+	userID := callback.User.ID
+
+	id, err := ulid.ParseStrict(target.MentionID)
+	if app.MentionQuery == nil || err != nil {
+		app.replyAnswerAction(ctx, callback, slack.MsgOptionText(app.t(ctx, userID, "answer.regenerate_unavailable"), false))
+		return
+	}
+	if !app.canOperateAnswer(ctx, userID, id) {
+		app.replyAnswerAction(ctx, callback, slack.MsgOptionText(app.t(ctx, userID, "answer.not_owner"), false))
+		return
+	}
+	messageID, err := app.MentionReplayer.Replay(ctx, id)
+	if err != nil {
+		logger.Errorf(ctx, "回答の再生成エラー: %v", err)
+		app.replyAnswerAction(ctx, callback, slack.MsgOptionText(app.t(ctx, userID, "answer.regenerate_failed"), false))
+		return
+	}
+	logger.Printf(ctx, "回答の再生成のためメンションを再送しました: user=%s message_id=%s", userID, messageID)
+	app.replyAnswerAction(ctx, callback, slack.MsgOptionText(app.t(ctx, userID, "answer.regenerate_requested"), false))
+}
+
+// 「根拠を表示」が押されたときに、回答の根拠の資料を押したユーザーにだけ表示するメソッド
+func (app *SlackBotApp) handleShowSources(callback slack.InteractionCallback, action *slack.BlockAction) {
+	target, ok := app.answerActionTarget(action)
+	if !ok {
+		return
+	}
+	ctx := logger.WithCorrelationID(context.Background(), target.MentionID)
+	userID := callback.User.ID
+
+	sources := target.AnswerSources()
+	if len(sources) == 0 {
+		app.replyAnswerAction(ctx, callback, slack.MsgOptionText(app.t(ctx, userID, "answer.no_sources"), false))
+		return
+	}
+	title := app.t(ctx, userID, "answer.sources_title")
+	app.replyAnswerAction(ctx, callback,
+		slack.MsgOptionText(title, false),
+		slack.MsgOptionBlocks(blockkit.SourcesBlocks(title, sources)...),
+	)
+}
+
+// 「削除」が押されたときに回答のメッセージを削除するメソッド
+func (app *SlackBotApp) handleDeleteAnswer(callback slack.InteractionCallback, action *slack.BlockAction) {
+	target, ok := app.answerActionTarget(action)
+	if !ok {
+		return
+	}
+	ctx := logger.WithCorrelationID(context.Background(), target.MentionID)
+	userID := callback.User.ID
+
+	id, _ := ulid.ParseStrict(target.MentionID)
+	if !app.canOperateAnswer(ctx, userID, id) {
+		app.replyAnswerAction(ctx, callback, slack.MsgOptionText(app.t(ctx, userID, "answer.not_owner"), false))
+		return
+	}
+	if _, _, err := app.SlackClient.DeleteMessageContext(ctx, callback.Channel.ID, callback.Message.Timestamp); err != nil {
+		logger.Errorf(ctx, "回答の削除エラー: %v", err)
+		app.replyAnswerAction(ctx, callback, slack.MsgOptionText(app.t(ctx, userID, "answer.delete_failed"), false))
+		return
+	}
+	logger.Printf(ctx, "回答を削除しました: channel=%s ts=%s user=%s", callback.Channel.ID, callback.Message.Timestamp, userID)
+}
+
+// 回答のボタンの value から操作の対象を取り出すメソッド（回答のボタンが無効な場合は処理しない）
+func (app *SlackBotApp) answerActionTarget(action *slack.BlockAction) (blockkit.AnswerActionValue, bool) {
+	if !app.AppConfig.AnswerActions.Enabled {
+		return blockkit.AnswerActionValue{}, false
+	}
+	target, err := blockkit.ParseAnswerActionValue(action.Value)
+	if err != nil {
+		app.Logger.Error("回答のボタンの値の解析エラー", "error", err)
+		return blockkit.AnswerActionValue{}, false
+	}
+	return target, true
+}
+
+// ユーザーが回答を再生成・削除できるかを返すメソッド
+// 管理者 (admin.user_ids) と、保存したメンションの質問したユーザーのみ操作できる
+func (app *SlackBotApp) canOperateAnswer(ctx context.Context, userID string, mentionID ulid.ULID) bool {
+	if slices.Contains(app.AppConfig.Admin.UserIDs, userID) {
+		return true
+	}
+	if app.MentionQuery == nil || mentionID == (ulid.ULID{}) {
+		return false
+	}
+	mention, err := app.MentionQuery.FindByID(ctx, slackmodel.MentionID(mentionID))
+	if err != nil {
+		logger.Errorf(ctx, "回答のメンションの取得エラー: %v", err)
+		return false
+	}
+	return mention.UserID == userID
+}
+
+// 回答のボタンを押したユーザーにだけ見えるメッセージを回答のスレッドに返信するメソッド
+func (app *SlackBotApp) replyAnswerAction(ctx context.Context, callback slack.InteractionCallback, options ...slack.MsgOption) {
+	options = append(options, slack.MsgOptionTS(callback.Message.ThreadTimestamp))
+	if _, err := app.SlackClient.PostEphemeralContext(ctx, callback.Channel.ID, callback.User.ID, options...); err != nil {
+		logger.Errorf(ctx, "返信エラー: %v", err)
+	}
+}
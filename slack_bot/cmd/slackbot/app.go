@@ -0,0 +1,1077 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/i18n"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/cache"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+// tracerName はBotが作成するスパンの計装名
+const tracerName = "github.com/takeuchi-shogo/ai-slack-bot/slack_bot"
+
+// correlationMetadataEventType はBotが投稿するメッセージに付与するメタデータのイベント種別
+const correlationMetadataEventType = "ai_slack_bot_request"
+
+// detachedTimeout は処理の期限を過ぎた後にエラーの返信や記録を行う期限
+const detachedTimeout = 5 * time.Second
+
+type SlackBotApp struct {
+	SlackClient *slack.Client
+	// SocketModeClient はWebhookでイベントを受信する場合 nil
+	SocketModeClient *socketmode.Client
+	AppConfig        *config.AppConfig
+	// Workspace は接続しているワークスペースの設定
+	Workspace    config.SlackBotConfig
+	AccessPolicy *slackmodel.AccessPolicy
+	FileStore    di.FileStore
+	// OfficeHours は受付時間の制限が無効な場合 nil
+	OfficeHours *slackmodel.OfficeHours
+	// AutoReplyRules は自動返信が無効な場合 nil
+	AutoReplyRules *slackmodel.AutoReplyRules
+	// MentionQuery, MentionCommand, UserSettingRepository はデータベースが無効な場合 nil
+	MentionQuery          di.SlackMentionQuery
+	MentionCommand        di.SlackMentionCommand
+	UserSettingRepository di.UserSettingRepository
+	Tracer                trace.Tracer
+	Metrics               *metrics.Metrics
+	Publisher             di.QueuePublisher
+	// MentionOutbox はアウトボックスが無効な場合 nil
+	MentionOutbox *usecase.MentionOutbox
+	// DeadLetters はデッドレターの保存が無効な場合 nil
+	DeadLetters di.FailedMentionRepository
+	// Conversations は会話の追跡が無効な場合 nil
+	Conversations *usecase.ConversationTracker
+	// EventDedup はイベントの重複の排除が無効な場合 nil（すべてのワークスペースで共有する）
+	EventDedup *usecase.EventDeduplicator
+	// Maintenance は管理コマンドで切り替えるメンションの処理の一時停止状態（すべてのワークスペースで共有する）
+	Maintenance *usecase.Maintenance
+	// OutboxRepository はアウトボックスが無効な場合 nil
+	OutboxRepository di.OutboxRepository
+	// Translator はユーザーに返信するメッセージを言語ごとに取得する
+	Translator *i18n.Translator
+	// Enrichment はキューに送信する前にメッセージに情報を付加する
+	Enrichment *usecase.EnrichmentPipeline
+	// Commands はスラッシュコマンドを処理に振り分ける
+	Commands *CommandRouter
+	// MentionReplayer は回答の再生成でメンションをキューに再送する（データベースが無効な場合は使用しない）
+	MentionReplayer *usecase.MentionReplayer
+	// BotUserID は起動時に auth.test で取得したBot自身のユーザーID
+	BotUserID string
+	// TeamID は起動時に auth.test で取得したワークスペースのID
+	TeamID string
+	// Logger はワークスペース名を付与したロガー（イベントの処理以外のログに使用する）
+	Logger *slog.Logger
+
+	reactionDedup *cache.TTLSet
+	// threadDedup はスレッド内のメンションの集約が無効な場合 nil
+	threadDedup *cache.Debouncer
+	localeCache *cache.TTLCache[string]
+	userNames   *cache.TTLCache[userNames]
+	// infoLimiter はユーザー・チャンネルの情報を取得するAPIの呼び出し頻度を制限する
+	infoLimiter *cache.TokenBucket
+	// rateLimiter はレート制限が無効な場合 nil
+	rateLimiter *cache.RateLimiter
+	// threadLimiter はスレッドごとの応答数の制限が無効な場合 nil
+	threadLimiter  *cache.RateLimiter
+	threadNotified *cache.TTLSet
+	// workers はイベントの処理を実行するワーカー（すべてのワークスペースで共有する）
+	workers *workerPool
+	// startupGate は起動待ち時間の間に受信したイベントの処理を保留する（startup_delay が 0 の場合は nil）
+	startupGate *startupGate
+	// progress は途中経過の表示が無効な場合 nil
+	progress ProgressNotifier
+	// connected はSocket Modeで接続しているかどうか（管理コマンドの status で表示する）
+	connected atomic.Bool
+	// now は受付時間の判定に使用する現在時刻
+	now func() time.Time
+}
+
+// SlackBotApps は接続するワークスペースごとのアプリケーション
+type SlackBotApps []*SlackBotApp
+
+// ForTeam はワークスペースのIDに対応するアプリケーションを返す
+// teamID が空で、ワークスペースが1つの場合はそのアプリケーションを返す
+func (apps SlackBotApps) ForTeam(teamID string) (*SlackBotApp, bool) {
+	if teamID == "" && len(apps) == 1 {
+		return apps[0], true
+	}
+	for _, app := range apps {
+		if app.TeamID == teamID {
+			return app, true
+		}
+	}
+	return nil, false
+}
+
+// NewSlackBotApps はワークスペースごとにSlackクライアントとSocketModeクライアントを作成する
+// イベントの処理はすべてのワークスペースで共通で、リポジトリやキューなどの依存先も共有する
+func NewSlackBotApps(
+	lc fx.Lifecycle,
+	cfg *config.AppConfig,
+	fileStore di.FileStore,
+	mentionQuery di.SlackMentionQuery,
+	mentionCommand di.SlackMentionCommand,
+	userSettingRepository di.UserSettingRepository,
+	tracerProvider trace.TracerProvider,
+	m *metrics.Metrics,
+	publisher di.QueuePublisher,
+	mentionOutbox *usecase.MentionOutbox,
+	failedMentionRepository di.FailedMentionRepository,
+	conversations *usecase.ConversationTracker,
+	eventDedup *usecase.EventDeduplicator,
+	maintenance *usecase.Maintenance,
+	outboxRepository di.OutboxRepository,
+	connectionHealth *usecase.ConnectionHealth,
+	commands *CommandRouter,
+	mentionReplayer *usecase.MentionReplayer,
+	shutdowner fx.Shutdowner,
+	l *slog.Logger,
+) (SlackBotApps, error) {
+	var autoReplyRules *slackmodel.AutoReplyRules
+	if cfg.AutoReply.Enabled {
+		specs := make([]slackmodel.AutoReplyRuleSpec, 0, len(cfg.AutoReply.Rules))
+		for _, rule := range cfg.AutoReply.Rules {
+			specs = append(specs, slackmodel.AutoReplyRuleSpec{Name: rule.Name, Pattern: rule.Pattern, Reply: rule.Reply})
+		}
+		var err error
+		autoReplyRules, err = slackmodel.NewAutoReplyRules(specs)
+		if err != nil {
+			return nil, fmt.Errorf("自動返信 (auto_reply.rules) の設定が不正です: %w", err)
+		}
+	}
+
+	translator, err := i18n.NewTranslator(cfg.I18n.DefaultLang)
+	if err != nil {
+		return nil, fmt.Errorf("返信の言語 (i18n.default_lang) の設定が不正です: %w", err)
+	}
+
+	// キューへの接続はワークスペースに関係なく1回だけ確認する
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := publisher.Check(ctx); err != nil {
+				return fmt.Errorf("ElasticMQのキューに接続できません (elasticmq.endpoint=%s): %w", cfg.ElasticMQ.Endpoint, err)
+			}
+			return nil
+		},
+	})
+
+	// イベントの処理はワークスペースに関係なく同じワーカーで実行する
+	// フックは追加と逆の順番で停止するため、すべてのワークスペースの接続を止めた後に処理中のイベントを待つ
+	// キューへの送信などのフックはこれより前に追加されているため、処理中のイベントが終わった後に停止する
+	workers := newWorkerPool(l, cfg.EventWorkers.Size, cfg.EventWorkers.QueueSize)
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			l.Info("処理中のイベントの完了を待ちます", "drain_timeout", cfg.EventWorkers.DrainTimeout)
+			ctx, cancel := context.WithTimeout(ctx, cfg.EventWorkers.DrainTimeout)
+			defer cancel()
+			if err := workers.Stop(ctx); err != nil {
+				return fmt.Errorf("処理中のイベントの完了を待てませんでした: %w", err)
+			}
+			return nil
+		},
+	})
+
+	var apps SlackBotApps
+	for _, workspace := range cfg.SlackWorkspaces() {
+		var officeHours *slackmodel.OfficeHours
+		if workspace.OfficeHours.Enabled {
+			var err error
+			officeHours, err = slackmodel.ParseOfficeHours(workspace.OfficeHours.Timezone, workspace.OfficeHours.Schedule)
+			if err != nil {
+				return nil, fmt.Errorf("受付時間 (office_hours) の設定が不正です (workspace=%s): %w", workspace.Name, err)
+			}
+		}
+
+		// Slackクライアントを作成
+		api := NewSlackClient(l, workspace)
+
+		// SocketModeクライアントを作成（Webhookで受信する場合は使用しない）
+		var socketClient *socketmode.Client
+		if !workspace.IsWebhook() {
+			socketClient = NewSocketModeClient(l, api, workspace)
+		}
+
+		app := &SlackBotApp{
+			SlackClient:      api,
+			SocketModeClient: socketClient,
+			AppConfig:        cfg,
+			Workspace:        workspace,
+			AccessPolicy: slackmodel.NewAccessPolicy(
+				cfg.AccessControl.AllowedChannels,
+				cfg.AccessControl.DeniedChannels,
+				cfg.AccessControl.AllowedUsers,
+				cfg.AccessControl.DeniedUsers,
+				cfg.AccessControl.AllowDirectMessages,
+			),
+			FileStore:             fileStore,
+			OfficeHours:           officeHours,
+			AutoReplyRules:        autoReplyRules,
+			MentionQuery:          mentionQuery,
+			MentionCommand:        mentionCommand,
+			UserSettingRepository: userSettingRepository,
+			Translator:            translator,
+			Tracer:                tracerProvider.Tracer(tracerName),
+			Metrics:               m,
+			Publisher:             publisher,
+			MentionOutbox:         mentionOutbox,
+			Conversations:         conversations,
+			EventDedup:            eventDedup,
+			Maintenance:           maintenance,
+			Commands:              commands,
+			MentionReplayer:       mentionReplayer,
+			Logger:                l.With("workspace", workspace.Name),
+			reactionDedup:         cache.NewTTLSet(cfg.Reaction.DedupTTL, 0),
+			localeCache:           cache.NewTTLCache[string](cfg.Enrichment.LocaleCacheTTL, 0),
+			userNames:             cache.NewTTLCache[userNames](cfg.Enrichment.UserCache.TTL, cfg.Enrichment.UserCache.Size),
+			infoLimiter:           cache.NewTokenBucket(cfg.Enrichment.InfoAPI.Interval(), cfg.Enrichment.InfoAPI.Burst),
+			workers:               workers,
+			now:                   time.Now,
+		}
+		if cfg.RateLimit.Enabled {
+			app.rateLimiter = cache.NewRateLimiter(cfg.RateLimit.MaxRequests, cfg.RateLimit.Window)
+		}
+		if cfg.LoopGuard.Enabled {
+			app.threadLimiter = cache.NewRateLimiter(cfg.LoopGuard.MaxThreadResponses, cfg.LoopGuard.Window)
+			app.threadNotified = cache.NewTTLSet(cfg.LoopGuard.Window, 0)
+		}
+		if cfg.DeadLetter.Enabled {
+			app.DeadLetters = failedMentionRepository
+		}
+		if cfg.Outbox.Enabled {
+			app.OutboxRepository = outboxRepository
+		}
+		if cfg.Progress.Enabled {
+			app.progress = newSlackProgressNotifier(api, cfg.PostMessage, cfg.Progress.TTL)
+		}
+		if cfg.ThreadDedup.Enabled {
+			app.threadDedup = cache.NewDebouncer(cfg.ThreadDedup.Window)
+		}
+		enrichment, err := usecase.NewEnrichmentPipeline(cfg.Enrichment.EnabledSteps(), app.enrichers()...)
+		if err != nil {
+			return nil, fmt.Errorf("付加処理 (enrichment.steps) の設定が不正です: %w", err)
+		}
+		app.Enrichment = enrichment
+
+		app.registerLifecycle(lc, connectionHealth, shutdowner)
+		apps = append(apps, app)
+	}
+
+	return apps, nil
+}
+
+// ワークスペースごとにSocketModeクライアントを起動・停止するライフサイクルフックを追加するメソッド
+func (app *SlackBotApp) registerLifecycle(lc fx.Lifecycle, health *usecase.ConnectionHealth, shutdowner fx.Shutdowner) {
+	// Webhookの場合はイベントをHTTPサーバー（startWebhookServer）で受信するため、接続の確認のみ行う
+	if app.Workspace.IsWebhook() {
+		lc.Append(fx.Hook{
+			OnStart: app.selfCheck,
+		})
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	// 停止時はイベントの受信と接続の終了を待ってから、ワーカーの処理の完了を待つ
+	var running sync.WaitGroup
+
+	// 起動待ち時間の間もSocket Modeの接続とイベントへのACKは行い、イベントの処理は待ち時間の経過後に受信順に行う
+	// 待ち時間中に保留するイベントは event_workers.queue_size 件までとし、超えた分はログに出力して破棄する
+	if delay := app.Workspace.StartupDelay; delay > 0 {
+		app.startupGate = newStartupGate(app.AppConfig.EventWorkers.QueueSize)
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			app.Logger.Info("起動待ち時間の経過後にイベントの処理を開始します", "startup_delay", delay)
+			select {
+			case <-time.After(delay):
+				app.startupGate.Open()
+				app.Logger.Info("起動待ち時間が経過したためイベントの処理を開始しました")
+			case <-runCtx.Done():
+				if n := app.startupGate.Discard(); n > 0 {
+					app.Logger.Warn("起動待ち時間中に停止したため保留していたイベントを破棄しました", "count", n)
+				}
+			}
+		}()
+	}
+
+	// イベントハンドラを設定
+	running.Add(1)
+	go func() {
+		defer running.Done()
+		app.handleEvents(runCtx)
+	}()
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := app.selfCheck(ctx); err != nil {
+				return err
+			}
+
+			app.Logger.Info("Socket Modeのクライアントを起動します", "team", app.TeamID)
+			// 非同期でSocketModeクライアントを起動
+			// 接続が終了した場合は再接続し、回復しないエラーが続いた場合のみアプリケーションを停止する
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				app.newSocketModeSupervisor(health, shutdowner).Run(runCtx)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			app.Logger.Info("Socket Modeのクライアントを停止します", "team", app.TeamID)
+			cancel()
+			done := make(chan struct{})
+			go func() {
+				running.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("Socket Modeの接続の終了を待てませんでした (workspace=%s): %w", app.Workspace.Name, ctx.Err())
+			}
+		},
+	})
+}
+
+// authTestTimeout は起動時の auth.test の待ち時間の上限
+// Slackに接続できない場合も起動処理全体の期限を待たずに原因がわかるエラーで停止する
+const authTestTimeout = 10 * time.Second
+
+// 起動時にワークスペースへの接続を確認するメソッド
+// 設定の誤りをSocket Modeの接続エラーとして後から気付くことがないよう、失敗した場合は起動を中止する
+func (app *SlackBotApp) selfCheck(ctx context.Context) error {
+	// Bot自身のユーザーIDとワークスペースのIDを取得する
+	// ユーザーIDは自分のメッセージやリアクションを無視するため、ワークスペースのIDはメンションの記録と回答の投稿先の特定に使用する
+	authCtx, cancel := context.WithTimeout(ctx, authTestTimeout)
+	defer cancel()
+	auth, err := app.SlackClient.AuthTestContext(authCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("Slackの認証が%s以内に完了しませんでした。ネットワークの接続を確認してください (workspace=%s): %w", authTestTimeout, app.Workspace.Name, err)
+	}
+	if err != nil {
+		return fmt.Errorf("Slackの認証に失敗しました。Bot Token (bot_token) を確認してください (workspace=%s): %w", app.Workspace.Name, err)
+	}
+	app.BotUserID = auth.UserID
+	app.TeamID = auth.TeamID
+	app.Logger.Info("Slackの認証に成功しました", "bot_user_id", auth.UserID, "team", auth.Team, "team_id", auth.TeamID)
+
+	if !app.Workspace.IsWebhook() && !strings.HasPrefix(app.Workspace.AppToken, "xapp-") {
+		return fmt.Errorf("App Token (app_token) が xapp- で始まっていません。App-Level Token を設定してください (workspace=%s)", app.Workspace.Name)
+	}
+	return nil
+}
+
+// イベント処理を行うメソッド
+// Socket Modeで受信したイベントにACKを返し、Webhookと共通の処理に渡す
+// ctx が終了した後はイベントを受信しない（ACKを返していないイベントはSlackが再送する）
+func (app *SlackBotApp) handleEvents(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-app.SocketModeClient.Events:
+			if !ok {
+				return
+			}
+			app.handleSocketModeEvent(evt)
+		}
+	}
+}
+
+// Socket Modeで受信したイベントを1件処理するメソッド
+// 処理中にパニックが発生しても受信を続けられるよう、イベントごとに復帰する
+func (app *SlackBotApp) handleSocketModeEvent(evt socketmode.Event) {
+	defer app.recoverEvent(string(evt.Type), evt.Data)
+
+	switch evt.Type {
+	case socketmode.EventTypeConnecting:
+		app.connected.Store(false)
+		app.Logger.Info("Slackに接続しています")
+	case socketmode.EventTypeConnectionError:
+		app.connected.Store(false)
+		app.Logger.Warn("Slackへの接続エラー", "error", evt.Data)
+	case socketmode.EventTypeConnected:
+		app.connected.Store(true)
+		app.Logger.Info("Slackに接続しました")
+	case socketmode.EventTypeEventsAPI:
+		// 処理できないイベントにはACKを返さず、Slackの再送に任せる
+		eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+		if !ok {
+			app.Logger.Error("Socket Modeのイベントの型が不正です", "type", evt.Type, "data", evt.Data)
+			return
+		}
+		// イベントを確認してACK（応答）を返す
+		// 処理はワーカーで行うため、処理に時間がかかってもACKは遅れない
+		app.SocketModeClient.Ack(*evt.Request)
+		app.handleEventsAPIEvent(eventsAPIEvent, innerEventJSON(evt.Request))
+	case socketmode.EventTypeInteractive:
+		callback, ok := evt.Data.(slack.InteractionCallback)
+		if !ok {
+			app.Logger.Error("Socket Modeのイベントの型が不正です", "type", evt.Type, "data", evt.Data)
+			return
+		}
+		// モーダルの入力エラーはAckのペイロードで返す（モーダルを閉じずにエラーを表示する）
+		if res := app.interactionResponse(callback); res != nil {
+			app.SocketModeClient.Ack(*evt.Request, res)
+		} else {
+			app.SocketModeClient.Ack(*evt.Request)
+			app.handleInteractionCallback(callback)
+		}
+	case socketmode.EventTypeSlashCommand:
+		cmd, ok := evt.Data.(slack.SlashCommand)
+		if !ok {
+			app.Logger.Error("Socket Modeのイベントの型が不正です", "type", evt.Type, "data", evt.Data)
+			return
+		}
+		// 3秒以内にACKを返す必要があるため、処理はワーカーで行い結果は response_url またはスレッドに返信する
+		app.SocketModeClient.Ack(*evt.Request)
+		app.Metrics.EventsReceived.WithLabelValues(app.Workspace.Name, slashCommandEventType).Inc()
+		app.dispatch(slashCommandEventType, cmd, func() { app.handleSlashCommand(cmd) })
+	}
+}
+
+// Events APIのイベントを種類ごとの処理に振り分けるメソッド
+// Socket Mode・Webhookのどちらで受信したイベントもこのメソッドで処理する
+// rawEvent は内部イベントのJSON（取得できない場合は nil）
+func (app *SlackBotApp) handleEventsAPIEvent(eventsAPIEvent slackevents.EventsAPIEvent, rawEvent json.RawMessage) {
+	if eventsAPIEvent.Type != slackevents.CallbackEvent {
+		return
+	}
+	innerEvent := eventsAPIEvent.InnerEvent
+	// イベントのIDと種類をログに付与し、それぞれの処理のログから受信したイベントを追えるようにする
+	ctx := logger.With(context.Background(), "workspace", app.Workspace.Name, "event_id", eventID(eventsAPIEvent), "event_type", innerEvent.Type)
+	if !app.claimEvent(ctx, eventsAPIEvent) {
+		return
+	}
+
+	app.Metrics.EventsReceived.WithLabelValues(app.Workspace.Name, innerEvent.Type).Inc()
+
+	// イベントの受信から処理の完了までをスパンとして記録する
+	// スパンはキューのメッセージ属性を通じてワーカーの処理に引き継ぐ
+	ctx, span := app.Tracer.Start(ctx, "slack.event",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("slack.event_type", innerEvent.Type),
+			attribute.String("slack.event_id", eventID(eventsAPIEvent)),
+			attribute.String("slack.team", eventsAPIEvent.TeamID),
+		),
+	)
+
+	var handle func()
+	switch ev := innerEvent.Data.(type) {
+	case *slackevents.AppMentionEvent:
+		handle = func() { app.handleAppMention(ctx, ev, rawEvent, slackmodel.MessageSourceMention) }
+	case *slackevents.MessageEvent:
+		switch {
+		case ev.SubType == slack.MsgSubTypeMessageDeleted:
+			handle = func() { app.handleMessageDeleted(ctx, ev) }
+		case ev.ChannelType == directMessageChannelType:
+			handle = func() { app.handleDirectMessage(ctx, ev, rawEvent) }
+		default:
+			handle = func() { app.handleChannelMessage(ctx, ev, rawEvent) }
+		}
+	case *slackevents.ReactionAddedEvent:
+		handle = func() {
+			app.handleReactionAdded(ctx, ev)
+			app.handleReactionFeedback(ctx, queuemodel.ReactionActionAdded, ev)
+		}
+	case *slackevents.ReactionRemovedEvent:
+		// reaction_added と reaction_removed は同じ形式のため、同じメソッドで処理する
+		handle = func() {
+			app.handleReactionFeedback(ctx, queuemodel.ReactionActionRemoved, (*slackevents.ReactionAddedEvent)(ev))
+		}
+	case *slackevents.AppHomeOpenedEvent:
+		handle = func() { app.handleAppHomeOpened(ctx, ev) }
+	}
+	if handle == nil {
+		span.End()
+		return
+	}
+	task := func() {
+		defer span.End()
+		handle()
+	}
+	if !app.dispatch(innerEvent.Type, innerEvent.Data, task) {
+		span.SetStatus(codes.Error, "停止処理中のためイベントを破棄しました")
+		span.End()
+	}
+}
+
+// ボタンの操作などのインタラクションを処理に渡すメソッド
+// Socket Mode・Webhookのどちらで受信したインタラクションもこのメソッドで処理する
+func (app *SlackBotApp) handleInteractionCallback(callback slack.InteractionCallback) {
+	app.Metrics.EventsReceived.WithLabelValues(app.Workspace.Name, string(callback.Type)).Inc()
+	app.dispatch(string(callback.Type), callback, func() { app.handleInteraction(callback) })
+}
+
+// イベントの処理をワーカーに渡すメソッド
+// payload は処理中にパニックが発生した場合にログに出力し、返信先を特定するためのイベント
+// 停止処理の開始後に受信したイベントは処理せずに破棄し、false を返す
+// 起動待ち時間中はワーカーに渡さずに保留し、保留できる件数を超えた場合は破棄して false を返す
+func (app *SlackBotApp) dispatch(eventType string, payload any, fn func()) bool {
+	task := func() {
+		defer app.recoverEvent(eventType, payload)
+		fn()
+	}
+	submit := func() bool {
+		if !app.workers.Submit(eventType, task) {
+			app.Logger.Warn("停止処理中のためイベントを破棄しました", "event_type", eventType)
+			return false
+		}
+		return true
+	}
+	if app.startupGate == nil {
+		return submit()
+	}
+	if !app.startupGate.Submit(submit) {
+		app.Logger.Warn("起動待ち時間中に保留できるイベント数 (event_workers.queue_size) を超えたためイベントを破棄しました", "event_type", eventType)
+		return false
+	}
+	return true
+}
+
+// Events APIのペイロードから内部イベントのJSONを取り出す
+// slackevents の型に含まれないフィールド（files など）を参照するために使用する
+func innerEventJSON(req *socketmode.Request) json.RawMessage {
+	if req == nil {
+		return nil
+	}
+	return eventJSON(req.Payload)
+}
+
+// Events APIのペイロード（Socket Modeの Payload またはWebhookのリクエストボディ）から内部イベントのJSONを取り出す
+func eventJSON(payload json.RawMessage) json.RawMessage {
+	var envelope struct {
+		Event json.RawMessage `json:"event"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil
+	}
+	return envelope.Event
+}
+
+// メンション処理メソッド
+// source はメッセージの種類（ダイレクトメッセージの場合は MessageSourceDirectMessage、それ以外は MessageSourceMention）
+func (app *SlackBotApp) handleAppMention(ctx context.Context, evt *slackevents.AppMentionEvent, rawEvent json.RawMessage, source slackmodel.MessageSource) {
+	ctx = logger.With(ctx, "channel", evt.Channel, "user", evt.User, "ts", evt.TimeStamp)
+
+	// Botの投稿や編集されたメッセージによるメンションは、Bot同士の応答が繰り返される原因になるため処理しない
+	if reason, ok := app.isSelfTriggered(evt, rawEvent); ok {
+		logger.Printf(ctx, "Botによるメンションのため無視しました (%s): channel=%s ts=%s", reason, evt.Channel, evt.TimeStamp)
+		return
+	}
+
+	// メンションのIDを相関IDとしてログ・キューのメッセージ・Slackへの返信に引き継ぐ
+	mentionID, err := app.newMentionID(source, evt.Channel, evt.TimeStamp)
+	if err != nil {
+		logger.Errorf(ctx, "メンションIDの発行エラー: %v", err)
+		return
+	}
+	ctx, cancel := app.withEventTimeout(logger.WithCorrelationID(ctx, mentionID.String()))
+	defer cancel()
+	// 受信したメンションは相関IDを付けて出力し、相関IDでDBへの保存・キューへの送信までを追えるようにする
+	logger.Printf(ctx, "メンションを受信しました: channel=%s user=%s ts=%s thread_ts=%s text=%q", evt.Channel, evt.User, evt.TimeStamp, evt.ThreadTimeStamp, evt.Text)
+
+	// サンプリングされなかったメンションのスパンは記録されない
+	ctx, span := app.Tracer.Start(ctx, "slack.app_mention",
+		trace.WithAttributes(
+			attribute.String("slack.channel", evt.Channel),
+			attribute.String("slack.user", evt.User),
+			attribute.String("slack.ts", evt.TimeStamp),
+			attribute.String("correlation_id", mentionID.String()),
+		),
+	)
+	defer span.End()
+
+	// 管理コマンド（@bot admin pause など）はキューに送信せずにその場で処理する
+	if app.handleAdminCommand(ctx, evt) {
+		return
+	}
+
+	// 管理コマンドで一時停止している場合はキューに送信せずに返信する
+	if app.Maintenance.Paused() {
+		logger.Printf(ctx, "メンテナンス中のため処理しませんでした: channel=%s user=%s", evt.Channel, evt.User)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "maintenance.paused"))
+		return
+	}
+
+	// 利用が許可されていないチャンネル・ユーザーの場合はキューに送信しない
+	if reason, denied := app.AccessPolicy.DenyReason(slackmodel.ChannelID(evt.Channel), slackmodel.UserID(evt.User)); denied {
+		logger.Printf(ctx, "アクセス制御により拒否しました: channel=%s user=%s reason=%s", evt.Channel, evt.User, reason)
+		app.Metrics.AccessDenied.WithLabelValues(app.Workspace.Name, string(reason)).Inc()
+		if app.AppConfig.AccessControl.NotifyDenied {
+			app.replyInThread(ctx, evt, app.t(ctx, evt.User, "access.denied"))
+		}
+		return
+	}
+
+	// 受付時間外の場合はキューに送信せずに返信する
+	if !app.isOpen() {
+		logger.Printf(ctx, "受付時間外のため処理しませんでした: channel=%s user=%s", evt.Channel, evt.User)
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "office_hours.closed"))
+		return
+	}
+
+	// 短時間に多数のメンションをしたユーザーにはキューに送信せず本人にだけ通知する
+	if ok, retryAfter := app.allowMention(evt.Channel, evt.User); !ok {
+		logger.Printf(ctx, "レート制限によりメンションを処理しませんでした: channel=%s user=%s", evt.Channel, evt.User)
+		app.replyEphemeral(ctx, evt, app.t(ctx, evt.User, "rate_limited", int(math.Ceil(retryAfter.Seconds()))))
+		return
+	}
+
+	// 同じスレッドで応答が続いている場合は、Bot同士の応答の繰り返しとみなして処理を止める
+	if !app.allowThread(ctx, evt) {
+		logger.Printf(ctx, "スレッドの応答数が上限に達したためメンションを処理しませんでした: channel=%s thread_ts=%s", evt.Channel, threadTimeStamp(evt))
+		return
+	}
+
+	// 挨拶やヘルプなどの定型的なメッセージにはAIに送信せずに直接返信する
+	if app.AutoReplyRules != nil {
+		if name, reply, ok := app.AutoReplyRules.Match(slackmodel.Text(evt.Text)); ok {
+			logger.Printf(ctx, "自動返信の規則 (%s) に一致したためキューに送信せずに返信しました: channel=%s user=%s", name, evt.Channel, evt.User)
+			app.Metrics.AutoReplies.WithLabelValues(app.Workspace.Name, name).Inc()
+			app.replyInThread(ctx, evt, reply)
+			return
+		}
+	}
+
+	// 同じスレッドで短時間に続けてメンションされた場合は、最後のメンションだけをキューに送信する
+	if app.threadDedup != nil && evt.ThreadTimeStamp != "" {
+		process := func() {
+			// 集約を待つ間に期限を過ぎないよう、処理を開始した時点から期限を設定し直す
+			ctx, cancel := app.withEventTimeout(context.WithoutCancel(ctx))
+			defer cancel()
+			app.processMention(ctx, evt, rawEvent, source, mentionID)
+		}
+		if !app.threadDedup.Do(evt.Channel+":"+evt.ThreadTimeStamp, process) {
+			logger.Printf(ctx, "同じスレッドの送信待ちのメンションをこのメンションに置き換えました: channel=%s thread_ts=%s", evt.Channel, evt.ThreadTimeStamp)
+		}
+		return
+	}
+
+	app.processMention(ctx, evt, rawEvent, source, mentionID)
+}
+
+// メンションの会話履歴・添付ファイルを取得してキューに送信するメソッド
+func (app *SlackBotApp) processMention(ctx context.Context, evt *slackevents.AppMentionEvent, rawEvent json.RawMessage, source slackmodel.MessageSource, mentionID slackmodel.MentionID) {
+	ctx, span := app.Tracer.Start(ctx, "slack.process_mention")
+	defer span.End()
+
+	// スレッド内のメンションの場合は会話履歴も一緒に送信する
+	threadContext := app.threadContextOrNil(ctx, evt)
+
+	// 添付ファイルの情報を取得する（取得に失敗した場合はファイルなしとして扱う）
+	var attachments []slackmodel.Attachment
+	files, err := app.mentionFiles(ctx, evt, rawEvent)
+	if err != nil {
+		logger.Errorf(ctx, "添付ファイルの取得エラー: %v", err)
+	} else if len(files) > 0 {
+		var rejected []rejectedFile
+		attachments, rejected = app.processAttachments(ctx, evt, files)
+		if len(rejected) > 0 {
+			app.replyRejectedFiles(ctx, evt, rejected)
+		}
+	}
+
+	timestamp, eventTime := mentionTimes(ctx, evt.TimeStamp, evt.EventTimeStamp)
+	mention, err := slackmodel.NewMention(
+		mentionID,
+		source,
+		slackmodel.UserID(evt.User),
+		slackmodel.ChannelID(evt.Channel),
+		slackmodel.Text(evt.Text),
+		timestamp,
+		eventTime,
+		attachments,
+		app.mentionTextOption(),
+		slackmodel.WithTeamID(slackmodel.TeamID(app.TeamID)),
+		slackmodel.WithWorkspace(slackmodel.Workspace(app.Workspace.Name)),
+		slackmodel.WithRawEvent(mentionRawEvent(ctx, evt, rawEvent)),
+		slackmodel.WithSlackTS(evt.TimeStamp, evt.ThreadTimeStamp),
+		app.channelSettingsOption(evt.Channel),
+		app.userNamesOption(ctx, evt.User),
+	)
+	if err != nil {
+		logger.Errorf(ctx, "メンションの検証エラー: %v", err)
+		// ユーザーが直せるエラーの場合のみ返信し、それ以外（イベントの不備など）は返信しない
+		switch {
+		case errors.Is(err, slackmodel.ErrTextTooLong):
+			app.replyInThread(ctx, evt, app.t(ctx, evt.User, "mention.too_long", app.AppConfig.Mention.MaxTextLength))
+		case errors.Is(err, slackmodel.ErrTextRequired):
+			app.replyInThread(ctx, evt, app.t(ctx, evt.User, "mention.empty"))
+		}
+		return
+	}
+
+	app.assignConversation(ctx, mention, threadTimeStamp(evt))
+
+	// App Homeで回答の言語が設定されている場合は一緒に送信する
+	msg := queuemodel.NewMentionMessage(mention, threadContext)
+	app.applyBroadcastMention(msg)
+	msg.Lang = string(app.userLang(ctx, evt.User))
+	app.Enrichment.Enrich(ctx, msg)
+
+	// メンションを保存してElasticMQにメッセージを送信
+	err = app.enqueueMention(ctx, mention, msg)
+	if err != nil {
+		// 処理の期限を過ぎた場合も返信できるようにする
+		ctx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		logger.Errorf(ctx, "ElasticMQへの送信エラー: %v", err)
+		app.Metrics.EnqueueFailures.WithLabelValues(app.Workspace.Name).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "enqueue failed")
+
+		// エラーが発生した場合のみSlackに返信
+		app.replyEnqueueError(ctx, evt, err)
+		return
+	}
+
+	// キューに正常に送信できた場合は返信しない（Pythonが処理する）
+	app.Metrics.MentionsEnqueued.WithLabelValues(app.Workspace.Name).Inc()
+	recordConversationMessage(ctx, app.Conversations, evt.Channel, threadTimeStamp(evt), evt.TimeStamp, slackmodel.ConversationRoleUser, evt.User, msg.Text)
+	logger.Printf(ctx, "メッセージをキューに送信しました。処理はPythonに委譲します。")
+}
+
+// 調査・再実行用に保存するイベントのJSONを返す
+// Slackから受信したJSONがない場合（再試行ボタンなど）はイベントをJSONに変換し、変換できない場合は空にする
+func mentionRawEvent(ctx context.Context, evt *slackevents.AppMentionEvent, rawEvent json.RawMessage) slackmodel.RawEvent {
+	if len(rawEvent) > 0 {
+		return slackmodel.RawEvent(rawEvent)
+	}
+	b, err := json.Marshal(evt)
+	if err != nil {
+		logger.Errorf(ctx, "イベントのJSON変換エラー（保存せずに続行します）: %v", err)
+		return ""
+	}
+	return slackmodel.RawEvent(b)
+}
+
+// メンションのIDを発行するメソッド
+// mention.deterministic_id が有効な場合はイベントのチャンネルとタイムスタンプから導出する
+func (app *SlackBotApp) newMentionID(source slackmodel.MessageSource, channel, ts string) (slackmodel.MentionID, error) {
+	if app.AppConfig.Mention.DeterministicID {
+		if timestamp, err := slackmodel.ParseSlackTimestamp(ts); err == nil && !timestamp.IsZero() {
+			return slackmodel.NewMentionIDFromEvent(source, slackmodel.ChannelID(channel), slackmodel.Timestamp(timestamp))
+		}
+	}
+	return slackmodel.NewMentionID()
+}
+
+// リアクションによる依頼のメンションのIDを発行するメソッド
+// mention.deterministic_id が有効な場合はリアクションされたメッセージ、リアクションしたユーザーとリアクションの時刻から導出する
+func (app *SlackBotApp) newReactionMentionID(channel, ts, user, eventTS string) (slackmodel.MentionID, error) {
+	if app.AppConfig.Mention.DeterministicID {
+		timestamp, err := slackmodel.ParseSlackTimestamp(ts)
+		reactedAt, eventErr := slackmodel.ParseSlackTimestamp(eventTS)
+		if err == nil && eventErr == nil && !timestamp.IsZero() && !reactedAt.IsZero() {
+			return slackmodel.NewReactionMentionIDFromEvent(slackmodel.ChannelID(channel), slackmodel.Timestamp(timestamp), slackmodel.UserID(user), slackmodel.Timestamp(reactedAt))
+		}
+	}
+	return slackmodel.NewMentionID()
+}
+
+// メンションをスレッドの会話に紐付けるメソッド
+// 会話の取得・保存に失敗した場合は会話なしとしてキューへの送信を継続する
+func (app *SlackBotApp) assignConversation(ctx context.Context, mention *slackmodel.Mention, threadTS string) {
+	if app.Conversations == nil {
+		return
+	}
+	id, err := app.Conversations.Resolve(ctx, mention, slackmodel.ThreadTS(threadTS))
+	if err != nil {
+		logger.Errorf(ctx, "会話の紐付けエラー（会話なしで続行します）: %v", err)
+		return
+	}
+	mention.ConversationID = id
+}
+
+// メンションのテキストの最大文字数の設定を返す
+func (app *SlackBotApp) mentionTextOption() slackmodel.MentionOption {
+	return slackmodel.WithMaxTextLength(app.AppConfig.Mention.MaxTextLength, app.AppConfig.Mention.TruncateText)
+}
+
+// チャンネルの回答の言語・ペルソナ（channel_settings）をメンションに設定するオプションを返すメソッド
+func (app *SlackBotApp) channelSettingsOption(channelID string) slackmodel.MentionOption {
+	setting := app.AppConfig.ChannelSettings.For(channelID)
+	return slackmodel.WithChannelSettings(slackmodel.ChannelSettings{Language: setting.Language, Persona: setting.Persona})
+}
+
+// 全体メンション（@here など）が含まれている場合に broadcast_mention を付与し、
+// 設定に応じてテキストから取り除くメソッド
+func (app *SlackBotApp) applyBroadcastMention(msg *queuemodel.MentionMessage) {
+	if !slackmodel.HasBroadcastMention(slackmodel.Text(msg.Text)) {
+		return
+	}
+	msg.BroadcastMention = true
+	if app.AppConfig.Broadcast.Strip {
+		msg.Text = string(slackmodel.StripBroadcastMentions(slackmodel.Text(msg.Text)))
+	}
+}
+
+// メンションを保存してキューに送信するメソッド
+// 送信先はメンションの種類（mention, reaction など）ごとに設定されたキューになる
+// アウトボックスが有効な場合はメンションと送信待ちのメッセージを同じトランザクションで保存し、送信はバックグラウンドで行う
+func (app *SlackBotApp) enqueueMention(ctx context.Context, mention *slackmodel.Mention, msg *queuemodel.MentionMessage) error {
+	queueKey := app.queueKey(msg.Type)
+	msg.CorrelationID = logger.CorrelationID(ctx)
+	msg.StatusUpdates = app.AppConfig.Progress.Enabled
+
+	if app.MentionOutbox == nil {
+		saved := app.saveMention(ctx, mention)
+		err := withHistoryTruncation(ctx, msg, func() error {
+			messageID, err := app.sendToElasticMQ(ctx, queueKey, msg)
+			if err != nil {
+				return err
+			}
+			if saved {
+				app.updateMentionStatus(ctx, mention, slackmodel.MentionStatusQueued, "")
+			}
+			app.saveSQSMessageID(ctx, mention, messageID)
+			return nil
+		})
+		if err != nil {
+			ctx, cancel := detachedContext(ctx)
+			defer cancel()
+			if errors.Is(err, context.DeadlineExceeded) {
+				app.recordTimeout(ctx, mention, saved)
+			} else if saved {
+				app.updateMentionStatus(ctx, mention, slackmodel.MentionStatusFailed, err.Error())
+			}
+			app.saveDeadLetter(ctx, mention, queueKey, msg, err)
+		}
+		return err
+	}
+
+	err := withHistoryTruncation(ctx, msg, func() error {
+		messageBody, err := msg.Marshal()
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		err = app.MentionOutbox.HandleMention(ctx, mention, queueKey, messageBody, msg.CorrelationID)
+		app.Metrics.DBWriteDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
+			return err
+		}
+		logger.Printf(ctx, "メッセージをアウトボックスに保存しました")
+		return nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.Printf(ctx, "処理の期限 (%s) を過ぎたためアウトボックスへの保存を中断しました（メンションは保存されていません）", app.AppConfig.EventWorkers.Timeout)
+	}
+	return err
+}
+
+// キューへの送信中に処理の期限を過ぎたことを記録するメソッド
+// 保存済みのメンションは状態を timed_out に更新する
+func (app *SlackBotApp) recordTimeout(ctx context.Context, mention *slackmodel.Mention, saved bool) {
+	logger.Printf(ctx, "処理の期限 (%s) を過ぎたためキューへの送信を中断しました: 保存=%t", app.AppConfig.EventWorkers.Timeout, saved)
+	if !saved {
+		return
+	}
+	app.updateMentionStatus(ctx, mention, slackmodel.MentionStatusTimedOut, fmt.Sprintf("event_workers.timeout (%s) exceeded", app.AppConfig.EventWorkers.Timeout))
+}
+
+// 受付時間内かどうかを返すメソッド（受付時間の制限が無効な場合は常に true）
+func (app *SlackBotApp) isOpen() bool {
+	return app.OfficeHours == nil || app.OfficeHours.IsOpen(app.now())
+}
+
+// 保存したメンションの処理の状態を更新するメソッド
+// 状態の記録に失敗してもメンションの処理は続けるため、エラーはログに出力するのみ
+func (app *SlackBotApp) updateMentionStatus(ctx context.Context, mention *slackmodel.Mention, status slackmodel.MentionStatus, detail string) {
+	if app.MentionCommand == nil {
+		return
+	}
+	if err := app.MentionCommand.UpdateStatus(ctx, mention.ID, string(status), detail); err != nil {
+		logger.Errorf(ctx, "メンションの状態の更新エラー (%s): %v", status, err)
+		return
+	}
+	mention.Status = status
+}
+
+// イベント1件の処理の期限（event_workers.timeout）を設定したコンテキストを返すメソッド
+func (app *SlackBotApp) withEventTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, app.AppConfig.EventWorkers.Timeout)
+}
+
+// 処理の期限を過ぎた後もエラーの返信や記録を行えるよう、期限を外して新たに短い期限を設定したコンテキストを返す
+// 相関IDなどのコンテキストの値は引き継ぐ
+func detachedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), detachedTimeout)
+}
+
+// メッセージの種類に対応するキューのキーを返すメソッド
+// ワークスペースに queue_name が設定されている場合はワークスペース用のキーを使用する
+func (app *SlackBotApp) queueKey(msgType string) string {
+	return app.AppConfig.QueueKey(app.Workspace.Name, msgType)
+}
+
+// メッセージがキューに送信できるサイズを超えた場合に、会話履歴を減らして send を再試行する
+func withHistoryTruncation(ctx context.Context, msg *queuemodel.MentionMessage, send func() error) error {
+	for {
+		err := send()
+		var tooLarge *queuemodel.MessageTooLargeError
+		if !errors.As(err, &tooLarge) || !msg.TruncateHistory() {
+			return err
+		}
+		logger.Printf(ctx, "メッセージが大きすぎるため (%dバイト) 会話履歴を%d件に減らして再試行します", tooLarge.Size, len(msg.History))
+	}
+}
+
+// ElasticMQの queueKey に対応するキューにメッセージを送信するメソッド
+// コンテキストに相関IDが設定されている場合はメッセージ属性に含める
+// 一時的な送信エラーは elasticmq.retry の設定に従って再試行し、再試行しても送信できなかった場合にエラーを返す
+func (app *SlackBotApp) sendToElasticMQ(ctx context.Context, queueKey string, msg *queuemodel.MentionMessage) (string, error) {
+	messageID, err := retryPublish(ctx, app.AppConfig.ElasticMQ.Retry, queueKey, func() (string, error) {
+		return app.Publisher.PublishWithID(ctx, queueKey, msg)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	logger.Printf(ctx, "メッセージを%sのキュー (%s) に送信しました: message_id=%s", app.AppConfig.ElasticMQ.Endpoint, queueKey, messageID)
+	return messageID, nil
+}
+
+// キューに送信できなかったメッセージをデッドレターとして保存するメソッド
+// 再送しても送信できないメッセージ（サイズの超過）は保存しない
+func (app *SlackBotApp) saveDeadLetter(ctx context.Context, mention *slackmodel.Mention, queueKey string, msg *queuemodel.MentionMessage, sendErr error) {
+	var tooLarge *queuemodel.MessageTooLargeError
+	if app.DeadLetters == nil || errors.As(sendErr, &tooLarge) {
+		return
+	}
+	body, err := msg.Marshal()
+	if err != nil {
+		logger.Errorf(ctx, "デッドレターのエンコードエラー: %v", err)
+		return
+	}
+	failed := entity.NewFailedMention(ulid.ULID(mention.ID), msg.CorrelationID, queueKey, body, sendErr.Error())
+	if err := app.DeadLetters.Create(ctx, failed); err != nil {
+		logger.Errorf(ctx, "デッドレターの保存エラー: %v", err)
+		return
+	}
+	logger.Printf(ctx, "キューに送信できなかったメッセージをデッドレターとして保存しました (id=%d)", failed.ID)
+}
+
+// キューのメッセージIDをメンションに記録するメソッド
+// 送信は完了しているため、記録に失敗してもエラーはログに出力するのみ
+func (app *SlackBotApp) saveSQSMessageID(ctx context.Context, mention *slackmodel.Mention, messageID string) {
+	if !app.AppConfig.Mention.StoreSQSMessageID || app.MentionCommand == nil || messageID == "" {
+		return
+	}
+	if err := app.MentionCommand.UpdateSQSMessageID(ctx, mention.ID, messageID); err != nil {
+		logger.Errorf(ctx, "SQSのメッセージIDの記録エラー: %v", err)
+	}
+}
+
+// ユーザーの言語で返信のメッセージを取得するメソッド
+// 言語はApp Homeでユーザーが設定したもの、未設定の場合は i18n.default_lang を使用する
+func (app *SlackBotApp) t(ctx context.Context, userID, key string, args ...any) string {
+	return app.Translator.T(string(app.userLang(ctx, userID)), key, args...)
+}
+
+// メンションしたユーザー宛てにスレッドで返信するメソッド
+func (app *SlackBotApp) replyInThread(ctx context.Context, evt *slackevents.AppMentionEvent, text string) {
+	app.postThreadReply(ctx, evt.Channel, threadTimeStamp(evt), evt.User, text)
+}
+
+// メンションしたユーザーにだけ見えるメッセージをスレッドで返信するメソッド
+func (app *SlackBotApp) replyEphemeral(ctx context.Context, evt *slackevents.AppMentionEvent, text string) {
+	_, err := app.SlackClient.PostEphemeralContext(ctx, evt.Channel, evt.User,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionTS(threadTimeStamp(evt)),
+	)
+	if err != nil {
+		logger.Errorf(ctx, "返信エラー: %v", err)
+	}
+}
+
+// ユーザーごとのレート制限を確認するメソッド（メンションとリアクションによる依頼で共通）
+// 制限を超えている場合は false と次に受け付け可能になるまでの時間を返す
+func (app *SlackBotApp) allowMention(channelID, userID string) (bool, time.Duration) {
+	if app.rateLimiter == nil || slices.Contains(app.AppConfig.RateLimit.ExemptChannels, channelID) {
+		return true, 0
+	}
+	return app.rateLimiter.Allow(userID)
+}
+
+// 指定したユーザー宛てにスレッドで返信するメソッド
+func (app *SlackBotApp) postThreadReply(ctx context.Context, channelID, threadTS, userID, text string) {
+	_, _, err := app.postMessage(ctx, channelID,
+		slack.MsgOptionText(fmt.Sprintf("<@%s> %s", userID, text), false),
+		slack.MsgOptionTS(threadTS),
+		correlationMetadata(ctx),
+	)
+	if err != nil {
+		logger.Errorf(ctx, "返信エラー: %v", err)
+	}
+}
+
+// 相関IDをメッセージのメタデータとして付与するオプションを返す
+// 本文には表示されず、問い合わせ時にメッセージからログやキューのメッセージを辿るために使用する
+func correlationMetadata(ctx context.Context) slack.MsgOption {
+	return slack.MsgOptionMetadata(slack.SlackMetadata{
+		EventType: correlationMetadataEventType,
+		EventPayload: map[string]interface{}{
+			"correlation_id": logger.CorrelationID(ctx),
+		},
+	})
+}
+
+// メンションをデータベースに保存し、保存できたかどうかを返すメソッド
+// 保存に失敗してもキューへの送信は継続するため、エラーはログに出力するのみ
+func (app *SlackBotApp) saveMention(ctx context.Context, mention *slackmodel.Mention) bool {
+	if app.MentionCommand == nil {
+		return false
+	}
+	e, err := entity.NewSlackMention(mention)
+	if err == nil {
+		start := time.Now()
+		err = app.MentionCommand.Create(ctx, e)
+		app.Metrics.DBWriteDuration.Observe(time.Since(start).Seconds())
+	}
+	if err != nil {
+		logger.Errorf(ctx, "メンションの保存エラー: %v", err)
+		return false
+	}
+	return true
+}
+
+// 返信先のスレッドタイムスタンプを返す
+// スレッド外のメンションの場合はメンション自体をスレッドの起点にする
+func threadTimeStamp(evt *slackevents.AppMentionEvent) string {
+	if evt.ThreadTimeStamp != "" {
+		return evt.ThreadTimeStamp
+	}
+	return evt.TimeStamp
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/bootstrap"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/modules"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+// サブコマンドごとのアプリケーションで、コンストラクタを実行せずに依存関係をすべて解決できることを確認する
+func TestAppGraphValidates(t *testing.T) {
+	// すべてのサブコマンドでメンションの読み取りと書き込みを分けて注入できる
+	mentionStore := fx.Invoke(func(di.SlackMentionQuery, di.SlackMentionCommand) {})
+	tests := []struct {
+		name string
+		opts []fx.Option
+	}{
+		{
+			name: "serve",
+			opts: []fx.Option{bootstrap.CommandModule, serveModule, mentionStore, fx.Populate(new(*config.AppConfig), new(*slog.Logger))},
+		},
+		{
+			name: "replay",
+			opts: []fx.Option{bootstrap.ReplayModule, mentionStore, fx.Populate(new(*config.AppConfig), new(*usecase.DeadLetterReplayer), new(*usecase.MentionReplayer))},
+		},
+		{
+			name: "worker",
+			opts: []fx.Option{bootstrap.WorkerModule, mentionStore, fx.Populate(
+				new(*config.AppConfig),
+				new(*usecase.MentionAnswerer),
+				new(modules.WorkerConsumers),
+				new(di.SlackMentionCommand),
+				new(*usecase.ConversationTracker),
+				new(trace.TracerProvider),
+				new(*slog.Logger),
+			)},
+		},
+		{
+			name: "migrate",
+			opts: []fx.Option{bootstrap.MigrateModule, fx.Populate(new(*bun.DB))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]fx.Option{config.Module, modules.LoggerModule, fx.NopLogger}, tt.opts...)
+			if err := fx.ValidateApp(opts...); err != nil {
+				t.Errorf("fx.ValidateApp() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+const (
+	// homeMentionPreviewLength はApp Homeに表示する質問の最大文字数
+	homeMentionPreviewLength = 100
+
+	homeLangBlockID  = "home_lang"
+	homeLangActionID = "home_lang_select"
+)
+
+// 言語の表示名
+var languageLabels = map[slackmodel.Language]string{
+	slackmodel.LanguageJa: "日本語",
+	slackmodel.LanguageEn: "English",
+}
+
+// homeStats はApp Homeに表示するユーザーの利用状況
+type homeStats struct {
+	TotalCount int
+	Recent     []*slackmodel.Mention
+	Lang       slackmodel.Language
+}
+
+// App Homeが開かれたときにHomeタブを再描画するメソッド
+// 開かれるたびに最新の質問を取得して表示する
+func (app *SlackBotApp) handleAppHomeOpened(ctx context.Context, evt *slackevents.AppHomeOpenedEvent) {
+	if !app.AppConfig.AppHome.Enabled || evt.Tab != "home" {
+		return
+	}
+	app.publishHome(logger.With(ctx, "user", evt.User), evt.User)
+}
+
+// インタラクティブなコールバックを処理するメソッド
+func (app *SlackBotApp) handleInteraction(callback slack.InteractionCallback) {
+	switch callback.Type {
+	case slack.InteractionTypeBlockActions:
+		app.handleBlockActions(callback)
+	case slack.InteractionTypeMessageAction:
+		app.handleMessageShortcut(callback)
+	case slack.InteractionTypeViewSubmission:
+		app.handleShortcutSubmission(callback)
+	}
+}
+
+// Homeタブで選択された言語を保存して再描画するメソッド
+func (app *SlackBotApp) handleLangSelected(userID string, lang slackmodel.Language) {
+	ctx := logger.With(context.Background(), "workspace", app.Workspace.Name, "user", userID)
+
+	if app.UserSettingRepository == nil {
+		logger.Warnf(ctx, "データベースが無効なため言語の設定を保存できません: user=%s", userID)
+		return
+	}
+
+	setting, err := slackmodel.NewUserSetting(slackmodel.UserID(userID), lang)
+	if err != nil {
+		logger.Errorf(ctx, "ユーザー設定の検証エラー: %v", err)
+		return
+	}
+	e, err := entity.NewUserSetting(setting)
+	if err == nil {
+		err = app.UserSettingRepository.Upsert(ctx, e)
+	}
+	if err != nil {
+		logger.Errorf(ctx, "ユーザー設定の保存エラー: %v", err)
+		return
+	}
+
+	app.publishHome(ctx, userID)
+}
+
+// ユーザーのHomeタブを作成して公開するメソッド
+func (app *SlackBotApp) publishHome(ctx context.Context, userID string) {
+	if !app.AppConfig.AppHome.Enabled {
+		return
+	}
+	stats, err := app.loadHomeStats(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, "App Homeの情報取得エラー: %v", err)
+		return
+	}
+
+	// PublishViewContext はslack-goのバージョンによってシグネチャが異なるため PublishView を使用する
+	if _, err := app.SlackClient.PublishView(userID, buildHomeView(stats), ""); err != nil {
+		logger.Errorf(ctx, "App Homeの公開エラー: %v", err)
+	}
+}
+
+// データベースが無効な場合は質問数と最近の質問を表示しない
+func (app *SlackBotApp) loadHomeStats(ctx context.Context, userID string) (*homeStats, error) {
+	if app.MentionQuery == nil {
+		return &homeStats{}, nil
+	}
+	count, err := app.MentionQuery.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("質問数の取得エラー: %w", err)
+	}
+	mentions, err := app.MentionQuery.FindLatestByUser(ctx, userID, app.AppConfig.AppHome.RecentLimit)
+	if err != nil {
+		return nil, fmt.Errorf("最近の質問の取得エラー: %w", err)
+	}
+
+	recent := make([]*slackmodel.Mention, 0, len(mentions))
+	for _, m := range mentions {
+		recent = append(recent, m.ToModel())
+	}
+	return &homeStats{
+		TotalCount: count,
+		Recent:     recent,
+		Lang:       app.userLang(ctx, userID),
+	}, nil
+}
+
+// ユーザーが設定した言語を返す
+// 未設定または取得に失敗した場合（データベースが無効な場合を含む）は空文字を返す
+func (app *SlackBotApp) userLang(ctx context.Context, userID string) slackmodel.Language {
+	if app.UserSettingRepository == nil {
+		return ""
+	}
+	setting, err := app.UserSettingRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, "ユーザー設定の取得エラー: %v", err)
+		}
+		return ""
+	}
+	return setting.ToModel().Lang
+}
+
+// Homeタブのビューを作成する
+func buildHomeView(stats *homeStats) slack.HomeTabViewRequest {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "AI Slack Bot", false, false)),
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*これまでの質問数:* %d件", stats.TotalCount), false, false),
+			nil, nil,
+		),
+		slack.NewDividerBlock(),
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "最近の質問", false, false)),
+	}
+	if len(stats.Recent) == 0 {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, "まだ質問はありません。チャンネルでBotにメンションすると、ここに最近の質問が表示されます。", false, false),
+			nil, nil,
+		))
+	}
+	for _, m := range stats.Recent {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, recentMentionText(m), false, false),
+			nil, nil,
+		))
+	}
+
+	blocks = append(blocks,
+		slack.NewDividerBlock(),
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "設定", false, false)),
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, "*回答の言語*", false, false),
+			nil,
+			slack.NewAccessory(langSelectElement(stats.Lang)),
+			slack.SectionBlockOptionBlockID(homeLangBlockID),
+		),
+	)
+
+	return slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+}
+
+// 最近の質問1件分の表示テキストを作成する
+// 日時はSlackの日付書式を使い、閲覧するユーザーのタイムゾーンで表示させる
+func recentMentionText(m *slackmodel.Mention) string {
+	eventTime := time.Time(m.EventTime)
+	text := strings.ReplaceAll(string(m.Text), "\n", " ")
+	if utf8.RuneCountInString(text) > homeMentionPreviewLength {
+		text = string([]rune(text)[:homeMentionPreviewLength]) + "…"
+	}
+	return fmt.Sprintf("<!date^%d^{date_short} {time}|%s> <#%s>\n%s",
+		eventTime.Unix(), eventTime.Format("2006-01-02 15:04"), m.ChannelID, text)
+}
+
+// 言語を選択するセレクトメニューを作成する
+func langSelectElement(current slackmodel.Language) *slack.SelectBlockElement {
+	options := make([]*slack.OptionBlockObject, 0, len(slackmodel.SupportedLanguages))
+	var initial *slack.OptionBlockObject
+	for _, lang := range slackmodel.SupportedLanguages {
+		option := slack.NewOptionBlockObject(
+			string(lang),
+			slack.NewTextBlockObject(slack.PlainTextType, languageLabels[lang], false, false),
+			nil,
+		)
+		options = append(options, option)
+		if lang == current {
+			initial = option
+		}
+	}
+
+	element := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "言語を選択", false, false),
+		homeLangActionID,
+		options...,
+	)
+	element.InitialOption = initial
+	return element
+}
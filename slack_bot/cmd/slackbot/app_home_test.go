@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+)
+
+func TestBuildHomeView(t *testing.T) {
+	tests := []struct {
+		name   string
+		stats  *homeStats
+		golden string
+	}{
+		{
+			name:   "質問がない場合は案内を表示し言語は未選択にする",
+			stats:  &homeStats{},
+			golden: "home_view_empty.golden.json",
+		},
+		{
+			name: "最近の質問と設定済みの言語を表示する",
+			stats: &homeStats{
+				TotalCount: 12,
+				Recent: []*slackmodel.Mention{
+					{
+						ChannelID: "C001",
+						Text:      "デプロイの手順を\n教えてください",
+						EventTime: slackmodel.EventTime(time.Date(2024, 4, 5, 10, 30, 0, 0, time.UTC)),
+					},
+					{
+						ChannelID: "C002",
+						Text:      slackmodel.Text(strings.Repeat("長", homeMentionPreviewLength+1)),
+						EventTime: slackmodel.EventTime(time.Date(2024, 4, 4, 9, 0, 0, 0, time.UTC)),
+					},
+				},
+				Lang: slackmodel.LanguageEn,
+			},
+			golden: "home_view_recent.golden.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.MarshalIndent(buildHomeView(tt.stats), "", "  ")
+			if err != nil {
+				t.Fatalf("ビューのJSON変換エラー: %v", err)
+			}
+			assertGolden(t, tt.golden, append(got, '\n'))
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/slack-go/slack/slackevents"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/cache"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/repository"
+)
+
+// testMentionEvent はテストで受信するメンションのイベント
+func testMentionEvent(text string) (*slackevents.AppMentionEvent, json.RawMessage) {
+	evt := &slackevents.AppMentionEvent{
+		Type:           "app_mention",
+		User:           "U001",
+		Text:           text,
+		TimeStamp:      "1712311200.000100",
+		Channel:        "C001",
+		EventTimeStamp: "1712311200.000100",
+	}
+	// 添付ファイルの取得でSlackに問い合わせないよう、受信したJSONとしてファイルのないイベントを渡す
+	return evt, json.RawMessage(`{"type":"app_mention"}`)
+}
+
+// キューへの送信が応答しない場合も、処理の期限 (event_workers.timeout) で送信を諦めて期限切れを返信する
+func TestHandleAppMentionTimesOutBlockingPublisher(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	cfg := testAppConfig()
+	cfg.EventWorkers.Timeout = timeout
+	publisher := &fakePublisher{block: true}
+	app, api := newTestApp(t, cfg, publisher)
+	mentions := repository.NewInMemorySlackMentionRepository()
+	app.MentionQuery = mentions
+	app.MentionCommand = mentions
+
+	evt, raw := testMentionEvent("<@UBOT> 質問です")
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		defer close(done)
+		app.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout + 5*time.Second):
+		t.Fatal("処理の期限を過ぎても handleAppMention() が終了しませんでした")
+	}
+	if elapsed := time.Since(start); elapsed < timeout {
+		t.Errorf("handleAppMention() の処理時間 = %v, want %v 以上（期限まで送信を待つ）", elapsed, timeout)
+	}
+
+	replies := api.callsTo("chat.postMessage")
+	if len(replies) != 1 {
+		t.Fatalf("chat.postMessage の呼び出し = %d回, want 1回", len(replies))
+	}
+	want := app.Translator.T("ja", "error.timeout")
+	if got := replies[0].Form.Get("text"); !strings.Contains(got, want) {
+		t.Errorf("返信 = %q, want %q を含む", got, want)
+	}
+	if got := replies[0].Form.Get("thread_ts"); got != evt.TimeStamp {
+		t.Errorf("返信の thread_ts = %q, want %q", got, evt.TimeStamp)
+	}
+
+	saved, err := mentions.ListByUser(context.Background(), evt.User, 10)
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(saved) != 1 {
+		t.Fatalf("保存されたメンション = %d件, want 1件", len(saved))
+	}
+	if got := slackmodel.MentionStatus(saved[0].Status); got != slackmodel.MentionStatusTimedOut {
+		t.Errorf("メンションの状態 = %q, want %q", got, slackmodel.MentionStatusTimedOut)
+	}
+}
+
+// mention.store_sqs_message_id が有効な場合は、キューへの送信後にSQSのメッセージIDをメンションに記録する
+func TestHandleAppMentionStoresSQSMessageID(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		want    string
+	}{
+		{name: "有効な場合は送信したメッセージのIDを記録する", enabled: true, want: "message-1"},
+		{name: "無効な場合は記録しない", enabled: false, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testAppConfig()
+			cfg.Mention.StoreSQSMessageID = tt.enabled
+			publisher := &fakePublisher{}
+			app, _ := newTestApp(t, cfg, publisher)
+			mentions := repository.NewInMemorySlackMentionRepository()
+			app.MentionQuery = mentions
+			app.MentionCommand = mentions
+
+			evt, raw := testMentionEvent("<@UBOT> 質問です")
+			app.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+
+			if got := len(publisher.messages()); got != 1 {
+				t.Fatalf("キューへの送信 = %d件, want 1件", got)
+			}
+			saved, err := mentions.ListByUser(context.Background(), evt.User, 10)
+			if err != nil {
+				t.Fatalf("ListByUser() error = %v", err)
+			}
+			if len(saved) != 1 {
+				t.Fatalf("保存されたメンション = %d件, want 1件", len(saved))
+			}
+			if saved[0].SQSMessageID != tt.want {
+				t.Errorf("SQSMessageID = %q, want %q", saved[0].SQSMessageID, tt.want)
+			}
+			if got := slackmodel.MentionStatus(saved[0].Status); got != slackmodel.MentionStatusQueued {
+				t.Errorf("メンションの状態 = %q, want %q", got, slackmodel.MentionStatusQueued)
+			}
+		})
+	}
+}
+
+// 受付時間外のメンションはキューに送信せずにスレッドで返信する
+func TestHandleAppMentionOfficeHours(t *testing.T) {
+	officeHours, err := slackmodel.ParseOfficeHours("Asia/Tokyo", map[string][]string{"monday": {"09:00-18:00"}})
+	if err != nil {
+		t.Fatalf("ParseOfficeHours() error = %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		wantPublished int
+		wantReply     bool
+	}{
+		{name: "受付時間内はキューに送信する", now: time.Date(2024, 4, 1, 9, 0, 0, 0, tokyo), wantPublished: 1},
+		{name: "受付時間外は送信せずに返信する", now: time.Date(2024, 4, 1, 18, 0, 0, 0, tokyo), wantReply: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakePublisher{}
+			app, api := newTestApp(t, testAppConfig(), publisher)
+			app.OfficeHours = officeHours
+			app.now = func() time.Time { return tt.now }
+
+			evt, raw := testMentionEvent("<@UBOT> 質問です")
+			app.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+
+			if got := len(publisher.messages()); got != tt.wantPublished {
+				t.Errorf("キューへの送信 = %d件, want %d件", got, tt.wantPublished)
+			}
+			replies := api.callsTo("chat.postMessage")
+			if !tt.wantReply {
+				if len(replies) != 0 {
+					t.Errorf("chat.postMessage の呼び出し = %d回, want 0回", len(replies))
+				}
+				return
+			}
+			if len(replies) != 1 {
+				t.Fatalf("chat.postMessage の呼び出し = %d回, want 1回", len(replies))
+			}
+			want := app.Translator.T("ja", "office_hours.closed")
+			if got := replies[0].Form.Get("text"); !strings.Contains(got, want) {
+				t.Errorf("返信 = %q, want %q を含む", got, want)
+			}
+		})
+	}
+}
+
+// 自動返信の規則に一致したメンションはキューに送信せず、スレッドでメンションして直接返信する
+func TestHandleAppMentionAutoReply(t *testing.T) {
+	rules, err := slackmodel.NewAutoReplyRules([]slackmodel.AutoReplyRuleSpec{
+		{Name: "greeting", Pattern: `(?i)^(こんにちは|hello)[!！。]*$`, Reply: "こんにちは！質問があればメンションで送ってください。"},
+	})
+	if err != nil {
+		t.Fatalf("NewAutoReplyRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		text          string
+		wantPublished int
+		wantReply     string
+	}{
+		{name: "挨拶には直接返信する", text: "<@UBOT> こんにちは！", wantReply: "<@U001> こんにちは！質問があればメンションで送ってください。"},
+		{name: "規則に一致しないメンションはキューに送信する", text: "<@UBOT> こんにちは、質問です", wantPublished: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakePublisher{}
+			app, api := newTestApp(t, testAppConfig(), publisher)
+			app.AutoReplyRules = rules
+
+			evt, raw := testMentionEvent(tt.text)
+			app.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+
+			if got := len(publisher.messages()); got != tt.wantPublished {
+				t.Errorf("キューへの送信 = %d件, want %d件", got, tt.wantPublished)
+			}
+			replies := api.callsTo("chat.postMessage")
+			if tt.wantReply == "" {
+				if len(replies) != 0 {
+					t.Errorf("chat.postMessage の呼び出し = %d回, want 0回", len(replies))
+				}
+				return
+			}
+			if len(replies) != 1 {
+				t.Fatalf("chat.postMessage の呼び出し = %d回, want 1回", len(replies))
+			}
+			if got := replies[0].Form.Get("text"); got != tt.wantReply {
+				t.Errorf("返信 = %q, want %q", got, tt.wantReply)
+			}
+			if got := replies[0].Form.Get("thread_ts"); got != evt.TimeStamp {
+				t.Errorf("返信の thread_ts = %q, want %q", got, evt.TimeStamp)
+			}
+			if got := testutil.ToFloat64(app.Metrics.AutoReplies.WithLabelValues("default", "greeting")); got != 1 {
+				t.Errorf("自動返信の回数 = %v, want 1", got)
+			}
+		})
+	}
+}
+
+// 同じスレッドで集約の待ち時間内に続けてメンションされた場合は、最後のメンションだけをキューに送信する
+func TestHandleAppMentionThreadDedup(t *testing.T) {
+	publisher := &fakePublisher{}
+	app, _ := newTestApp(t, testAppConfig(), publisher)
+	app.threadDedup = cache.NewDebouncer(50 * time.Millisecond)
+
+	for i, text := range []string{"<@UBOT> 1つ目", "<@UBOT> 2つ目", "<@UBOT> 3つ目"} {
+		evt, raw := testMentionEvent(text)
+		evt.ThreadTimeStamp = "1712311100.000100"
+		evt.TimeStamp = fmt.Sprintf("17123112%02d.000100", i)
+		app.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+	}
+	// 別のスレッドのメンションは集約しない
+	evt, raw := testMentionEvent("<@UBOT> 別のスレッド")
+	evt.ThreadTimeStamp = "1712311000.000100"
+	app.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(publisher.messages()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 集約の待ち時間を過ぎても追加で送信されないことを確認する
+	time.Sleep(100 * time.Millisecond)
+
+	var texts []string
+	for _, msg := range publisher.messages() {
+		texts = append(texts, msg.Text)
+	}
+	slices.Sort(texts)
+	if want := []string{"<@UBOT> 3つ目", "<@UBOT> 別のスレッド"}; !slices.Equal(texts, want) {
+		t.Errorf("キューに送信したメンション = %q, want %q", texts, want)
+	}
+}
+
+// 全体メンションを含むメッセージには broadcast_mention を付け、broadcast.strip が有効な場合はテキストから取り除く
+func TestApplyBroadcastMention(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		strip    bool
+		wantFlag bool
+		wantText string
+	}{
+		{name: "全体メンションがない場合は変更しない", text: "<@UBOT> 質問です", wantText: "<@UBOT> 質問です"},
+		{name: "取り除かない設定ではフラグだけを付ける", text: "<@UBOT> <!here> 質問です", wantFlag: true, wantText: "<@UBOT> <!here> 質問です"},
+		{name: "取り除く設定ではテキストから取り除く", text: "<@UBOT> <!channel> 質問です", strip: true, wantFlag: true, wantText: "<@UBOT> 質問です"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakePublisher{}
+			cfg := testAppConfig()
+			cfg.Broadcast.Strip = tt.strip
+			app, _ := newTestApp(t, cfg, publisher)
+
+			evt, raw := testMentionEvent(tt.text)
+			app.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+
+			messages := publisher.messages()
+			if len(messages) != 1 {
+				t.Fatalf("キューへの送信 = %d件, want 1件", len(messages))
+			}
+			if messages[0].BroadcastMention != tt.wantFlag {
+				t.Errorf("BroadcastMention = %t, want %t", messages[0].BroadcastMention, tt.wantFlag)
+			}
+			if messages[0].Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", messages[0].Text, tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// 回答の下書きの承認・却下ボタンの action_id
+const (
+	approveAnswerActionID = "approve_answer"
+	rejectAnswerActionID  = "reject_answer"
+)
+
+// draftMaxLength はセクションブロックに表示できる下書きの最大文字数（Slackの上限）
+const draftMaxLength = 3000
+
+// approvalTarget は承認・却下ボタンの value に埋め込む、下書きの回答先
+// AIワーカーは操作のメッセージの value からどのメンションへの回答かを特定する
+type approvalTarget struct {
+	CorrelationID string `json:"correlation_id"`
+	MentionID     string `json:"mention_id,omitempty"`
+	Channel       string `json:"channel"`
+	ThreadTS      string `json:"thread_ts,omitempty"`
+}
+
+// 回答の下書きを承認・却下ボタン付きでモデレーター用のチャンネルに投稿するメソッド
+// 下書きは元のスレッドには投稿せず、承認された場合はAIワーカーが回答 (answer) として送信し直す
+func (app *SlackBotApp) postDraft(ctx context.Context, res responseMessage) error {
+	cfg := app.AppConfig.Approval
+	if !cfg.Enabled {
+		logger.Warnf(ctx, "回答の承認が無効なため下書きを破棄しました")
+		return nil
+	}
+	if res.Channel == "" || res.Text == "" {
+		logger.Warnf(ctx, "下書きのメッセージに channel または text がありません（破棄します）")
+		return nil
+	}
+
+	value, err := json.Marshal(approvalTarget{
+		CorrelationID: res.CorrelationID,
+		MentionID:     res.MentionID,
+		Channel:       res.Channel,
+		ThreadTS:      res.ThreadTS,
+	})
+	if err != nil {
+		return fmt.Errorf("下書きの回答先のエンコードエラー: %w", err)
+	}
+
+	lang := app.Translator.DefaultLang()
+	text := func(key string) *slack.TextBlockObject {
+		return slack.NewTextBlockObject(slack.PlainTextType, app.Translator.T(lang, key), false, false)
+	}
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, app.Translator.T(lang, "approval.draft", res.Channel), false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, truncateRunes(res.Text, draftMaxLength), false, false), nil, nil),
+		slack.NewActionBlock("",
+			slack.NewButtonBlockElement(approveAnswerActionID, string(value), text("approval.approve")).WithStyle(slack.StylePrimary),
+			slack.NewButtonBlockElement(rejectAnswerActionID, string(value), text("approval.reject")).WithStyle(slack.StyleDanger),
+		),
+	}
+	_, _, err = app.postMessage(ctx, cfg.ChannelID,
+		slack.MsgOptionText(res.Text, false),
+		slack.MsgOptionBlocks(blocks...),
+		correlationMetadata(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("下書きの投稿エラー: %w", err)
+	}
+	logger.Printf(ctx, "回答の下書きを投稿しました: channel=%s thread_ts=%s", res.Channel, res.ThreadTS)
+	return nil
+}
+
+// 回答の下書きの承認・却下ボタンが押されたときに、操作をAIワーカーに送信するメソッド
+// 送信できた場合は下書きのボタンを操作の結果に置き換え、重複して操作されないようにする
+func (app *SlackBotApp) handleAnswerReview(callback slack.InteractionCallback, action *slack.BlockAction) {
+	cfg := app.AppConfig.Approval
+	if !cfg.Enabled {
+		return
+	}
+	ctx := logger.With(context.Background(), "workspace", app.Workspace.Name, "channel", callback.Channel.ID, "user", callback.User.ID)
+	userID := callback.User.ID
+
+	var target approvalTarget
+	if err := json.Unmarshal([]byte(action.Value), &target); err != nil {
+		logger.Errorf(ctx, "承認の対象の解析エラー: %v", err)
+		return
+	}
+	ctx = logger.WithCorrelationID(ctx, target.CorrelationID)
+
+	if len(cfg.ModeratorIDs) > 0 && !slices.Contains(cfg.ModeratorIDs, userID) {
+		app.postReviewEphemeral(ctx, callback, app.t(ctx, userID, "approval.not_moderator"))
+		return
+	}
+
+	actionTime, err := slackmodel.ParseSlackTimestamp(action.ActionTs)
+	if err != nil || actionTime.IsZero() {
+		actionTime = time.Now()
+	}
+	msg := queuemodel.NewInteractionMessage(action.ActionID, action.Value, userID, callback.Channel.ID, callback.Message.Timestamp, actionTime)
+	msg.TeamID = app.TeamID
+	msg.Workspace = app.Workspace.Name
+	msg.ThreadTS = callback.Message.ThreadTimestamp
+	msg.MessageText = callback.Message.Text
+	msg.CorrelationID = target.CorrelationID
+
+	if err := app.Publisher.PublishTo(ctx, app.queueKey(config.QueueKeyInteraction), msg); err != nil {
+		logger.Errorf(ctx, "操作の送信エラー: action_id=%s channel=%s ts=%s: %v", action.ActionID, callback.Channel.ID, callback.Message.Timestamp, err)
+		app.Metrics.EnqueueFailures.WithLabelValues(app.Workspace.Name).Inc()
+		app.postReviewEphemeral(ctx, callback, app.t(ctx, userID, "approval.send_failed"))
+		return
+	}
+	logger.Printf(ctx, "操作をキューに送信しました: action_id=%s channel=%s ts=%s user=%s", action.ActionID, callback.Channel.ID, callback.Message.Timestamp, userID)
+
+	// Block Kitのボタンの操作はAckの内容でメッセージを更新できないため、chat.update で置き換える
+	resultKey := "approval.approved"
+	if action.ActionID == rejectAnswerActionID {
+		resultKey = "approval.rejected"
+	}
+	var blocks []slack.Block
+	for _, block := range callback.Message.Blocks.BlockSet {
+		if block.BlockType() != slack.MBTAction {
+			blocks = append(blocks, block)
+		}
+	}
+	blocks = append(blocks, slack.NewContextBlock("",
+		slack.NewTextBlockObject(slack.MarkdownType, app.t(ctx, userID, resultKey, userID), false, false),
+	))
+	_, _, _, err = app.SlackClient.UpdateMessageContext(ctx, callback.Channel.ID, callback.Message.Timestamp,
+		slack.MsgOptionText(callback.Message.Text, false),
+		slack.MsgOptionBlocks(blocks...),
+	)
+	if err != nil {
+		logger.Errorf(ctx, "下書きの更新エラー: %v", err)
+	}
+}
+
+// ボタンを押したユーザーにだけ見えるメッセージを返信するメソッド
+func (app *SlackBotApp) postReviewEphemeral(ctx context.Context, callback slack.InteractionCallback, text string) {
+	_, err := app.SlackClient.PostEphemeralContext(ctx, callback.Channel.ID, callback.User.ID,
+		slack.MsgOptionText(text, false),
+	)
+	if err != nil {
+		logger.Errorf(ctx, "返信エラー: %v", err)
+	}
+}
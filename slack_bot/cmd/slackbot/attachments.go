@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// 受け付けなかった添付ファイルとその理由
+type rejectedFile struct {
+	Name   string
+	Reason string
+}
+
+// メンションに添付されたファイルを取得するメソッド
+// イベントのペイロードに含まれていない場合はメッセージを取得し直す
+func (app *SlackBotApp) mentionFiles(ctx context.Context, evt *slackevents.AppMentionEvent, rawEvent json.RawMessage) ([]slack.File, error) {
+	if rawEvent != nil {
+		var event struct {
+			Files []slack.File `json:"files"`
+		}
+		if err := json.Unmarshal(rawEvent, &event); err == nil {
+			return event.Files, nil
+		}
+	}
+
+	msg, err := app.fetchMessage(ctx, evt.Channel, evt.TimeStamp, evt.ThreadTimeStamp)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Files, nil
+}
+
+// 添付ファイルを上限・形式で検証し、設定されていればストレージに転送するメソッド
+func (app *SlackBotApp) processAttachments(ctx context.Context, evt *slackevents.AppMentionEvent, files []slack.File) ([]slackmodel.Attachment, []rejectedFile) {
+	cfg := app.AppConfig.Attachments
+
+	lang := string(app.userLang(ctx, evt.User))
+	var attachments []slackmodel.Attachment
+	var rejected []rejectedFile
+	for _, f := range files {
+		if len(attachments) >= cfg.MaxFiles {
+			rejected = append(rejected, rejectedFile{Name: f.Name, Reason: app.Translator.T(lang, "attachments.too_many", cfg.MaxFiles)})
+			continue
+		}
+		if f.Size > cfg.MaxSize {
+			rejected = append(rejected, rejectedFile{Name: f.Name, Reason: app.Translator.T(lang, "attachments.too_large", formatBytes(cfg.MaxSize))})
+			continue
+		}
+		if len(cfg.AllowedMimetypes) > 0 && !slices.Contains(cfg.AllowedMimetypes, f.Mimetype) {
+			rejected = append(rejected, rejectedFile{Name: f.Name, Reason: app.Translator.T(lang, "attachments.unsupported_type", f.Mimetype)})
+			continue
+		}
+
+		attachment, err := slackmodel.NewAttachment(slackmodel.FileID(f.ID), f.Name, f.Mimetype, f.URLPrivate, f.Size)
+		if err != nil {
+			logger.Errorf(ctx, "添付ファイルの検証エラー (file=%s): %v", f.ID, err)
+			rejected = append(rejected, rejectedFile{Name: f.Name, Reason: app.Translator.T(lang, "attachments.unreadable")})
+			continue
+		}
+
+		if app.FileStore != nil {
+			key, err := app.uploadAttachment(ctx, evt, f)
+			if err != nil {
+				// 転送に失敗した場合はSlackのURLのまま送信する
+				logger.Errorf(ctx, "添付ファイルの転送エラー (file=%s): %v", f.ID, err)
+			} else {
+				attachment.ObjectKey = key
+				attachment.URLPrivate = ""
+			}
+		}
+
+		attachments = append(attachments, *attachment)
+	}
+
+	return attachments, rejected
+}
+
+// Botトークンでファイルをダウンロードし、ストレージに保存したオブジェクトキーを返すメソッド
+func (app *SlackBotApp) uploadAttachment(ctx context.Context, evt *slackevents.AppMentionEvent, f slack.File) (string, error) {
+	downloadURL := f.URLPrivateDownload
+	if downloadURL == "" {
+		downloadURL = f.URLPrivate
+	}
+
+	var buf bytes.Buffer
+	if err := app.SlackClient.GetFileContext(ctx, downloadURL, &buf); err != nil {
+		return "", fmt.Errorf("ファイルのダウンロードエラー: %w", err)
+	}
+
+	key := path.Join(app.AppConfig.Attachments.Upload.Prefix, evt.Channel, evt.TimeStamp, f.ID+"-"+path.Base(f.Name))
+	if err := app.FileStore.Put(ctx, key, f.Mimetype, buf.Bytes()); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// 受け付けなかった添付ファイルについてスレッドに返信するメソッド
+func (app *SlackBotApp) replyRejectedFiles(ctx context.Context, evt *slackevents.AppMentionEvent, rejected []rejectedFile) {
+	var sb strings.Builder
+	sb.WriteString(app.t(ctx, evt.User, "attachments.rejected") + "\n")
+	for _, r := range rejected {
+		fmt.Fprintf(&sb, "• %s: %s\n", r.Name, r.Reason)
+	}
+	app.replyInThread(ctx, evt, sb.String())
+}
+
+func formatBytes(n int) string {
+	const unit = 1024
+	switch {
+	case n >= unit*unit:
+		return fmt.Sprintf("%dMB", n/(unit*unit))
+	case n >= unit:
+		return fmt.Sprintf("%dKB", n/unit)
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/blockkit"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+)
+
+// blockActionHandler はブロックのアクション（ボタンやメニューの操作）の処理
+type blockActionHandler func(app *SlackBotApp, callback slack.InteractionCallback, action *slack.BlockAction)
+
+// blockActionHandlers は action_id ごとのアクションの処理
+// ボタンなどを追加する場合は、action_id と処理をここに登録する
+var blockActionHandlers = map[string]blockActionHandler{
+	homeLangActionID: func(app *SlackBotApp, callback slack.InteractionCallback, action *slack.BlockAction) {
+		app.handleLangSelected(callback.User.ID, slackmodel.Language(action.SelectedOption.Value))
+	},
+	retryMentionActionID: func(app *SlackBotApp, callback slack.InteractionCallback, action *slack.BlockAction) {
+		app.handleRetryMention(callback, action.Value)
+	},
+	approveAnswerActionID:             (*SlackBotApp).handleAnswerReview,
+	rejectAnswerActionID:              (*SlackBotApp).handleAnswerReview,
+	blockkit.RegenerateAnswerActionID: (*SlackBotApp).handleRegenerateAnswer,
+	blockkit.ShowSourcesActionID:      (*SlackBotApp).handleShowSources,
+	blockkit.DeleteAnswerActionID:     (*SlackBotApp).handleDeleteAnswer,
+}
+
+// ブロックのアクションを action_id に対応する処理に振り分けるメソッド
+func (app *SlackBotApp) handleBlockActions(callback slack.InteractionCallback) {
+	for _, action := range callback.ActionCallback.BlockActions {
+		handler, ok := blockActionHandlers[action.ActionID]
+		if !ok {
+			app.Logger.Warn("不明なアクションを無視しました", "action_id", action.ActionID, "block_id", action.BlockID, "user", callback.User.ID)
+			continue
+		}
+		handler(app, callback, action)
+	}
+}
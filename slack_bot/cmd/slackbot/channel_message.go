@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/slack-go/slack/slackevents"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+)
+
+// 通常の投稿として処理するメッセージのサブタイプ（編集・削除・Botの投稿などは処理しない）
+var channelMessageSubtypes = []string{"", "file_share", "thread_broadcast"}
+
+// チャンネルのメッセージ処理メソッド
+// channel_messages が有効な場合に、Botに宛てたメッセージをメンションと同じように処理する
+func (app *SlackBotApp) handleChannelMessage(ctx context.Context, evt *slackevents.MessageEvent, rawEvent json.RawMessage) {
+	cfg := app.AppConfig.ChannelMessages
+	if !cfg.Enabled || evt.ChannelType == directMessageChannelType {
+		return
+	}
+	if len(cfg.Channels) > 0 && !slices.Contains(cfg.Channels, evt.Channel) {
+		return
+	}
+	if !slices.Contains(channelMessageSubtypes, evt.SubType) || evt.BotID != "" {
+		return
+	}
+	// Botへのメンションを含むメッセージは app_mention イベントで処理する
+	if app.BotUserID != "" && strings.Contains(evt.Text, "<@"+app.BotUserID) {
+		return
+	}
+
+	text, ok := app.addressedText(evt.Text)
+	if !ok {
+		return
+	}
+	app.handleAppMention(ctx, &slackevents.AppMentionEvent{
+		Type:            "app_mention",
+		User:            evt.User,
+		Text:            text,
+		TimeStamp:       evt.TimeStamp,
+		ThreadTimeStamp: evt.ThreadTimeStamp,
+		Channel:         evt.Channel,
+		EventTimeStamp:  evt.EventTimeStamp,
+	}, rawEvent, slackmodel.MessageSourceMention)
+}
+
+// メッセージがBotに宛てたものであれば、プレフィックスを取り除いたテキストを返す
+// addressed_only が無効な場合はプレフィックスがなくてもBotに宛てたものとして扱う
+func (app *SlackBotApp) addressedText(text string) (string, bool) {
+	cfg := app.AppConfig.ChannelMessages
+	trimmed := strings.TrimSpace(text)
+	for _, prefix := range cfg.Prefixes {
+		if rest, ok := strings.CutPrefix(trimmed, prefix); ok {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return text, !cfg.AddressedOnly
+}
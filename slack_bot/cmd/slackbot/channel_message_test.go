@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+)
+
+func TestAddressedText(t *testing.T) {
+	tests := []struct {
+		name          string
+		addressedOnly bool
+		prefixes      []string
+		text          string
+		want          string
+		wantOK        bool
+	}{
+		{name: "プレフィックスを取り除く", addressedOnly: true, prefixes: []string{"bot:", "!ai"}, text: "bot: デプロイの手順は？", want: "デプロイの手順は？", wantOK: true},
+		{name: "2つ目のプレフィックス", addressedOnly: true, prefixes: []string{"bot:", "!ai"}, text: "!ai 質問です", want: "質問です", wantOK: true},
+		{name: "前後の空白は無視する", addressedOnly: true, prefixes: []string{"bot:"}, text: "  bot:質問です  ", want: "質問です", wantOK: true},
+		{name: "プレフィックスが途中にある場合はBot宛てではない", addressedOnly: true, prefixes: []string{"bot:"}, text: "質問です bot: 教えて", wantOK: false},
+		{name: "プレフィックスがない場合はBot宛てではない", addressedOnly: true, prefixes: []string{"bot:"}, text: "今日のランチどうする？", wantOK: false},
+		{name: "大文字・小文字は区別する", addressedOnly: true, prefixes: []string{"bot:"}, text: "BOT: 質問です", wantOK: false},
+		{name: "addressed_only が無効な場合はプレフィックスがなくても処理する", prefixes: []string{"bot:"}, text: "今日のランチどうする？", want: "今日のランチどうする？", wantOK: true},
+		{name: "addressed_only が無効でもプレフィックスは取り除く", prefixes: []string{"bot:"}, text: "bot: 質問です", want: "質問です", wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &SlackBotApp{AppConfig: &config.AppConfig{ChannelMessages: config.ChannelMessagesConfig{
+				Enabled:       true,
+				AddressedOnly: tt.addressedOnly,
+				Prefixes:      tt.prefixes,
+			}}}
+			got, ok := app.addressedText(tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("addressedText(%q) ok = %v, want %v", tt.text, ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("addressedText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// Botに宛てたチャンネルのメッセージだけをメンションとしてキューに送信する
+func TestHandleChannelMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		evt      slackevents.MessageEvent
+		wantText string
+	}{
+		{name: "プレフィックスのあるメッセージ", evt: slackevents.MessageEvent{Text: "bot: 質問です"}, wantText: "質問です"},
+		{name: "スレッド内のメッセージ", evt: slackevents.MessageEvent{Text: "bot: 続きです", ThreadTimeStamp: "1712311100.000100"}, wantText: "続きです"},
+		{name: "ファイル付きのメッセージ", evt: slackevents.MessageEvent{Text: "bot: このファイルを要約して", SubType: "file_share"}, wantText: "このファイルを要約して"},
+		{name: "プレフィックスのないメッセージ", evt: slackevents.MessageEvent{Text: "今日のランチどうする？"}},
+		{name: "Botへのメンションは app_mention で処理する", evt: slackevents.MessageEvent{Text: "bot: <@UBOT> 質問です"}},
+		{name: "Botの投稿", evt: slackevents.MessageEvent{Text: "bot: 質問です", BotID: "B001"}},
+		{name: "編集されたメッセージ", evt: slackevents.MessageEvent{Text: "bot: 質問です", SubType: "message_changed"}},
+		{name: "対象外のチャンネル", evt: slackevents.MessageEvent{Text: "bot: 質問です", Channel: "C999"}},
+		{name: "ダイレクトメッセージ", evt: slackevents.MessageEvent{Text: "bot: 質問です", ChannelType: directMessageChannelType}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testAppConfig()
+			cfg.ChannelMessages = config.ChannelMessagesConfig{
+				Enabled:       true,
+				Channels:      []string{"C001"},
+				AddressedOnly: true,
+				Prefixes:      []string{"bot:"},
+			}
+			publisher := &fakePublisher{}
+			app, _ := newTestApp(t, cfg, publisher)
+
+			evt := tt.evt
+			evt.Type = "message"
+			evt.User = "U001"
+			evt.TimeStamp = "1712311200.000100"
+			evt.EventTimeStamp = "1712311200.000100"
+			if evt.Channel == "" {
+				evt.Channel = "C001"
+			}
+			if evt.ChannelType == "" {
+				evt.ChannelType = "channel"
+			}
+			app.handleChannelMessage(context.Background(), &evt, json.RawMessage(`{"type":"message"}`))
+
+			published := publisher.messages()
+			if tt.wantText == "" {
+				if len(published) != 0 {
+					t.Errorf("キューへの送信 = %d件, want 0件", len(published))
+				}
+				return
+			}
+			if len(published) != 1 {
+				t.Fatalf("キューへの送信 = %d件, want 1件", len(published))
+			}
+			if published[0].Text != tt.wantText {
+				t.Errorf("送信したテキスト = %q, want %q", published[0].Text, tt.wantText)
+			}
+		})
+	}
+}
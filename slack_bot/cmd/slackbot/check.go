@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/slack-go/slack/socketmode"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/bootstrap"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"go.uber.org/fx"
+)
+
+// runCheck はすべてのワークスペースにSocket Modeで接続できるかを確認する
+// 接続できた時点で切断して終了するため、イベントは処理しない
+// Webhookで受信するワークスペースは Bot Token の認証のみを確認する
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 30*time.Second, "ワークスペースごとの接続を待つ時間")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var cfg *config.AppConfig
+	var l *slog.Logger
+	app := bootstrap.NewApp(fx.NopLogger, fx.Populate(&cfg, &l))
+	if err := app.Err(); err != nil {
+		return err
+	}
+
+	for _, workspace := range cfg.SlackWorkspaces() {
+		check := checkConnection
+		if workspace.IsWebhook() {
+			check = checkAuth
+		}
+		if err := check(l, workspace, *timeout); err != nil {
+			return fmt.Errorf("workspace=%s: %w", workspace.Name, err)
+		}
+		fmt.Printf("Connected (workspace=%s)\n", workspace.Name)
+	}
+	return nil
+}
+
+// checkConnection はSocket Modeで接続し、接続が完了するまで待つ
+func checkConnection(l *slog.Logger, workspace config.SlackBotConfig, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client := NewSocketModeClient(l, NewSlackClient(l, workspace), workspace)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- client.RunContext(ctx)
+	}()
+
+	for {
+		select {
+		case evt := <-client.Events:
+			switch evt.Type {
+			case socketmode.EventTypeConnected:
+				return nil
+			case socketmode.EventTypeInvalidAuth:
+				return fmt.Errorf("App Token (app_token) の認証に失敗しました")
+			case socketmode.EventTypeConnectionError:
+				return fmt.Errorf("接続エラー: %v", evt.Data)
+			}
+		case err := <-runErr:
+			if err == nil {
+				err = ctx.Err()
+			}
+			return fmt.Errorf("接続が終了しました: %w", err)
+		case <-ctx.Done():
+			return fmt.Errorf("%s以内に接続できませんでした", timeout)
+		}
+	}
+}
+
+// checkAuth は Bot Token で auth.test を呼び出して認証できるかを確認する
+func checkAuth(l *slog.Logger, workspace config.SlackBotConfig, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if _, err := NewSlackClient(l, workspace).AuthTestContext(ctx); err != nil {
+		return fmt.Errorf("Bot Token (bot_token) の認証に失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/modules"
+	"github.com/uptrace/bun"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// database.enabled が false の場合はデータベースに接続せず、保存せずにキューへの送信だけを行う
+func TestHandleAppMentionWithoutDatabase(t *testing.T) {
+	cfg := testAppConfig()
+	cfg.Database = config.DatabaseConfig{Enabled: false}
+	// 有効でも保存先がない場合は記録しない
+	cfg.Mention.StoreSQSMessageID = true
+
+	var (
+		db             *bun.DB
+		mentionQuery   di.SlackMentionQuery
+		mentionCommand di.SlackMentionCommand
+		userSettings   di.UserSettingRepository
+		outbox         di.OutboxRepository
+		failedMentions di.FailedMentionRepository
+		conversations  di.ConversationRepository
+		processed      di.ProcessedEventRepository
+	)
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(cfg),
+		modules.DatabaseModule,
+		modules.RepositoryModule,
+		modules.MentionStoreModule,
+		fx.Populate(&db, &mentionQuery, &mentionCommand, &userSettings, &outbox, &failedMentions, &conversations, &processed),
+	)
+	app.RequireStart()
+	t.Cleanup(app.RequireStop)
+
+	if db != nil {
+		t.Errorf("*bun.DB = %v, want nil", db)
+	}
+	for name, got := range map[string]any{
+		"SlackMentionQuery":        mentionQuery,
+		"SlackMentionCommand":      mentionCommand,
+		"UserSettingRepository":    userSettings,
+		"OutboxRepository":         outbox,
+		"FailedMentionRepository":  failedMentions,
+		"ConversationRepository":   conversations,
+		"ProcessedEventRepository": processed,
+	} {
+		if got != nil {
+			t.Errorf("%s = %v, want nil", name, got)
+		}
+	}
+
+	publisher := &fakePublisher{}
+	bot, api := newTestApp(t, cfg, publisher)
+	bot.MentionQuery = mentionQuery
+	bot.MentionCommand = mentionCommand
+	bot.UserSettingRepository = userSettings
+
+	evt, raw := testMentionEvent("<@UBOT> 質問です")
+	evt.ThreadTimeStamp = "1712311100.000100"
+	// 保存先がないため、呼び出した場合は nil のリポジトリの呼び出しで panic する
+	bot.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+
+	published := publisher.messages()
+	if len(published) != 1 {
+		t.Fatalf("キューへの送信 = %d件, want 1件", len(published))
+	}
+	if published[0].Text != evt.Text {
+		t.Errorf("送信したテキスト = %q, want %q", published[0].Text, evt.Text)
+	}
+	if replies := api.callsTo("chat.postMessage"); len(replies) != 0 {
+		t.Errorf("chat.postMessage の呼び出し = %d回, want 0回（エラーの返信なし）", len(replies))
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/robfig/cron/v3"
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+const (
+	// digestMaxChannels はまとめに表示するチャンネルの最大数
+	digestMaxChannels = 10
+	// digestTimeout はまとめの集計と投稿のタイムアウト
+	digestTimeout = time.Minute
+)
+
+// 利用状況のまとめを設定された日時に管理者用チャンネルへ投稿するスケジューラーを起動する
+// 投稿先のチャンネルが設定されていない場合は起動しない
+func startDigest(lc fx.Lifecycle, cfg *config.AppConfig, apps SlackBotApps, digest *usecase.ActivityDigest, m *metrics.Metrics) error {
+	digestCfg := cfg.Digest
+	if digestCfg.AdminChannelID == "" {
+		return nil
+	}
+
+	// キューへの送信失敗数はプロセス起動時からの累計のため、前回のまとめとの差分を表示する
+	var lastFailures float64
+	c := cron.New()
+	_, err := c.AddFunc(digestCfg.Schedule, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), digestTimeout)
+		defer cancel()
+
+		app, ok := apps.ForTeam(digestCfg.TeamID)
+		if !ok {
+			logger.Errorf(ctx, "利用状況のまとめの投稿先のワークスペースが見つかりません: team_id=%s", digestCfg.TeamID)
+			return
+		}
+		stats, err := digest.Collect(ctx, time.Now())
+		if err != nil {
+			logger.Errorf(ctx, "利用状況のまとめの集計エラー: %v", err)
+			return
+		}
+		failures := counterValue(m.EnqueueFailures)
+		stats.EnqueueFailures = int(failures - lastFailures)
+		lastFailures = failures
+
+		_, _, err = app.postMessage(ctx, digestCfg.AdminChannelID,
+			slack.MsgOptionText(fmt.Sprintf("AI Slack Bot 利用状況（過去24時間）: メンション%d件", stats.Total), false),
+			slack.MsgOptionBlocks(buildDigestBlocks(stats)...),
+		)
+		if err != nil {
+			logger.Errorf(ctx, "利用状況のまとめの投稿エラー: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("利用状況のまとめの実行日時 (digest.schedule) が不正です: %w", err)
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			c.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			// 実行中のまとめがあれば終了を待つ
+			select {
+			case <-c.Stop().Done():
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+	return nil
+}
+
+// 利用状況のまとめのメッセージを作成する
+func buildDigestBlocks(stats *usecase.DigestStats) []slack.Block {
+	summary := fmt.Sprintf("*期間:* %s 〜 %s\n*メンション数:* %d件\n*キューへの送信失敗:* %d件（前回のまとめ以降）",
+		slackDate(stats.From), slackDate(stats.To), stats.Total, stats.EnqueueFailures)
+
+	var channels strings.Builder
+	channels.WriteString("*チャンネル別*\n")
+	if len(stats.Channels) == 0 {
+		channels.WriteString("メンションはありませんでした。")
+	}
+	for i, c := range stats.Channels {
+		if i == digestMaxChannels {
+			fmt.Fprintf(&channels, "他%dチャンネル\n", len(stats.Channels)-digestMaxChannels)
+			break
+		}
+		fmt.Fprintf(&channels, "• <#%s> %d件\n", c.TargetID, c.Count)
+	}
+
+	var users strings.Builder
+	users.WriteString("*利用の多いユーザー*\n")
+	if len(stats.TopUsers) == 0 {
+		users.WriteString("メンションはありませんでした。")
+	}
+	for i, u := range stats.TopUsers {
+		fmt.Fprintf(&users, "%d. <@%s> %d件\n", i+1, u.TargetID, u.Count)
+	}
+
+	return []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "AI Slack Bot 利用状況（過去24時間）", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, summary, false, false), nil, nil),
+		slack.NewDividerBlock(),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, channels.String(), false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, users.String(), false, false), nil, nil),
+	}
+}
+
+// 閲覧するユーザーのタイムゾーンで表示されるSlackの日付書式
+func slackDate(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short} {time}|%s>", t.Unix(), t.Format("2006-01-02 15:04"))
+}
+
+// カウンターの現在の値をすべてのラベルについて合計して返す
+func counterValue(c prometheus.Collector) float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+
+	var total float64
+	for m := range ch {
+		var metric dto.Metric
+		if err := m.Write(&metric); err != nil {
+			continue
+		}
+		total += metric.GetCounter().GetValue()
+	}
+	return total
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/repository"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+)
+
+func TestBuildDigestBlocks(t *testing.T) {
+	to := time.Date(2024, 4, 5, 9, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+
+	// 表示するチャンネル数の上限を超えた分は件数だけを表示する
+	channels := make([]*entity.MentionCount, 0, digestMaxChannels+2)
+	for i := range digestMaxChannels + 2 {
+		channels = append(channels, &entity.MentionCount{TargetID: fmt.Sprintf("C%03d", i+1), Count: 20 - i})
+	}
+	total := 0
+	for _, c := range channels {
+		total += c.Count
+	}
+
+	tests := []struct {
+		name   string
+		stats  *usecase.DigestStats
+		golden string
+	}{
+		{
+			name:   "メンションがない場合",
+			stats:  &usecase.DigestStats{From: from, To: to},
+			golden: "digest_empty.golden.json",
+		},
+		{
+			name: "チャンネル別の件数と利用の多いユーザーを表示する",
+			stats: &usecase.DigestStats{
+				From:     from,
+				To:       to,
+				Total:    total,
+				Channels: channels,
+				TopUsers: []*entity.MentionCount{
+					{TargetID: "U001", Count: 30},
+					{TargetID: "U002", Count: 12},
+					{TargetID: "U003", Count: 12},
+				},
+				EnqueueFailures: 2,
+			},
+			golden: "digest_activity.golden.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.MarshalIndent(buildDigestBlocks(tt.stats), "", "  ")
+			if err != nil {
+				t.Fatalf("ブロックのJSON変換エラー: %v", err)
+			}
+			assertGolden(t, tt.golden, append(got, '\n'))
+		})
+	}
+}
+
+// 保存したメンションから集計した利用状況のまとめ（期間外と削除済みのメンションは含めない）
+func TestBuildDigestBlocksFromCollect(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 4, 5, 9, 0, 0, 0, time.UTC)
+	mentions := repository.NewInMemorySlackMentionRepository()
+	for _, m := range []struct {
+		user, channel string
+		ago           time.Duration
+		deleted       bool
+	}{
+		{user: "U001", channel: "C001", ago: time.Hour},
+		{user: "U001", channel: "C001", ago: 2 * time.Hour},
+		{user: "U002", channel: "C001", ago: 3 * time.Hour},
+		{user: "U002", channel: "C002", ago: 23 * time.Hour},
+		{user: "U003", channel: "C002", ago: 25 * time.Hour},
+		{user: "U003", channel: "C002", ago: time.Hour, deleted: true},
+	} {
+		eventTime := now.Add(-m.ago)
+		mention := &entity.SlackMention{
+			ID:        dbtypes.ULID(ulid.MustNew(ulid.Timestamp(eventTime), ulid.DefaultEntropy())),
+			UserID:    m.user,
+			ChannelID: m.channel,
+			EventTime: eventTime,
+			CreatedAt: eventTime,
+		}
+		if m.deleted {
+			mention.DeletedAt = now
+		}
+		if err := mentions.Create(ctx, mention); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	stats, err := usecase.NewActivityDigest(mentions).Collect(ctx, now)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	got, err := json.MarshalIndent(buildDigestBlocks(stats), "", "  ")
+	if err != nil {
+		t.Fatalf("ブロックのJSON変換エラー: %v", err)
+	}
+	assertGolden(t, "digest_collected.golden.json", append(got, '\n'))
+}
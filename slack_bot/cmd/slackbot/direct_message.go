@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/slack-go/slack/slackevents"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+)
+
+// directMessageChannelType はBotとのダイレクトメッセージの message イベントの channel_type
+const directMessageChannelType = "im"
+
+// ダイレクトメッセージの処理メソッド
+// direct_messages が有効な場合に、Botへのダイレクトメッセージをメンションと同じ流れで処理する
+// メンションと区別できるよう、メッセージの種類は MessageSourceDirectMessage として保存・送信する
+func (app *SlackBotApp) handleDirectMessage(ctx context.Context, evt *slackevents.MessageEvent, rawEvent json.RawMessage) {
+	if !app.AppConfig.DirectMessages.Enabled || evt.ChannelType != directMessageChannelType {
+		return
+	}
+	// 編集・削除やBot自身の投稿は処理しない
+	if !slices.Contains(channelMessageSubtypes, evt.SubType) || evt.BotID != "" {
+		return
+	}
+	if app.BotUserID != "" && evt.User == app.BotUserID {
+		return
+	}
+
+	app.handleAppMention(ctx, &slackevents.AppMentionEvent{
+		Type:            "app_mention",
+		User:            evt.User,
+		Text:            evt.Text,
+		TimeStamp:       evt.TimeStamp,
+		ThreadTimeStamp: evt.ThreadTimeStamp,
+		Channel:         evt.Channel,
+		EventTimeStamp:  evt.EventTimeStamp,
+	}, rawEvent, slackmodel.MessageSourceDirectMessage)
+}
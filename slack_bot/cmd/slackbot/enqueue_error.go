@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+const retryMentionActionID = "retry_mention"
+
+// retryTarget は再試行ボタンに埋め込む、再試行するメンションの位置
+type retryTarget struct {
+	Channel  string `json:"channel"`
+	TS       string `json:"ts"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+	User     string `json:"user"`
+}
+
+// キューへの送信エラーの種類に応じたユーザー向けのメッセージのキーと、再試行で解決する可能性があるかを返す
+func enqueueErrorKey(err error) (string, bool) {
+	var tooLarge *queuemodel.MessageTooLargeError
+	switch {
+	case errors.As(err, &tooLarge):
+		return "error.message_too_large", false
+	case errors.Is(err, queuemodel.ErrThrottled):
+		return "error.queue_throttled", true
+	case errors.Is(err, context.DeadlineExceeded):
+		return "error.timeout", true
+	default:
+		return "error.queue_send", true
+	}
+}
+
+// キューへの送信エラーをスレッドで返信するメソッド
+// 再試行で解決する可能性がある場合は再試行ボタンを付け、問い合わせ用に相関IDを表示する
+func (app *SlackBotApp) replyEnqueueError(ctx context.Context, evt *slackevents.AppMentionEvent, err error) {
+	lang := string(app.userLang(ctx, evt.User))
+	key, retryable := enqueueErrorKey(err)
+	text := fmt.Sprintf("<@%s> %s", evt.User, app.Translator.T(lang, key))
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+		slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, app.Translator.T(lang, "error.inquiry_id", logger.CorrelationID(ctx)), false, false),
+		),
+	}
+	if retryable {
+		value, err := json.Marshal(retryTarget{
+			Channel:  evt.Channel,
+			TS:       evt.TimeStamp,
+			ThreadTS: evt.ThreadTimeStamp,
+			User:     evt.User,
+		})
+		if err == nil {
+			blocks = append(blocks, slack.NewActionBlock("",
+				slack.NewButtonBlockElement(retryMentionActionID, string(value),
+					slack.NewTextBlockObject(slack.PlainTextType, app.Translator.T(lang, "retry.button"), false, false)),
+			))
+		}
+	}
+
+	_, _, err = app.postMessage(ctx, evt.Channel,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionTS(threadTimeStamp(evt)),
+		correlationMetadata(ctx),
+	)
+	if err != nil {
+		logger.Errorf(ctx, "返信エラー: %v", err)
+	}
+}
+
+// 再試行ボタンが押されたときに元のメンションを取得し直して処理するメソッド
+// 重複して再試行されないよう、ボタンの付いたエラーメッセージは削除する
+func (app *SlackBotApp) handleRetryMention(callback slack.InteractionCallback, value string) {
+	ctx := logger.With(context.Background(), "workspace", app.Workspace.Name, "channel", callback.Channel.ID, "user", callback.User.ID)
+
+	var target retryTarget
+	if err := json.Unmarshal([]byte(value), &target); err != nil {
+		logger.Errorf(ctx, "再試行の対象の解析エラー: %v", err)
+		return
+	}
+	if callback.User.ID != target.User {
+		_, err := app.SlackClient.PostEphemeralContext(ctx, callback.Channel.ID, callback.User.ID,
+			slack.MsgOptionText(app.t(ctx, callback.User.ID, "retry.not_owner"), false),
+			slack.MsgOptionTS(callback.Message.ThreadTimestamp),
+		)
+		if err != nil {
+			logger.Errorf(ctx, "返信エラー: %v", err)
+		}
+		return
+	}
+
+	msg, err := app.fetchMessage(ctx, target.Channel, target.TS, target.ThreadTS)
+	if err != nil {
+		logger.Errorf(ctx, "再試行するメッセージの取得エラー: %v", err)
+		return
+	}
+	if _, _, err := app.SlackClient.DeleteMessageContext(ctx, callback.Channel.ID, callback.Message.Timestamp); err != nil {
+		logger.Errorf(ctx, "エラーメッセージの削除エラー: %v", err)
+	}
+
+	// 再試行するメッセージの種類はチャンネルから判断する（ダイレクトメッセージ以外はメンションとして扱う）
+	source := slackmodel.MessageSourceMention
+	if slackmodel.ChannelID(target.Channel).IsDirectMessage() {
+		source = slackmodel.MessageSourceDirectMessage
+	}
+	app.handleAppMention(ctx, &slackevents.AppMentionEvent{
+		Type:            "app_mention",
+		User:            msg.User,
+		Text:            msg.Text,
+		TimeStamp:       msg.Timestamp,
+		ThreadTimeStamp: msg.ThreadTimestamp,
+		Channel:         target.Channel,
+		EventTimeStamp:  msg.Timestamp,
+	}, nil, source)
+}
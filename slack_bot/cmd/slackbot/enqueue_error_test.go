@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+func TestEnqueueErrorKey(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantKey       string
+		wantRetryable bool
+	}{
+		{name: "サイズの上限を超えた場合は再試行しても解決しない", err: fmt.Errorf("送信エラー: %w", &queuemodel.MessageTooLargeError{Size: 300000}), wantKey: "error.message_too_large"},
+		{name: "スロットリング", err: fmt.Errorf("送信エラー: %w", queuemodel.ErrThrottled), wantKey: "error.queue_throttled", wantRetryable: true},
+		{name: "処理の期限切れ", err: fmt.Errorf("送信エラー: %w", context.DeadlineExceeded), wantKey: "error.timeout", wantRetryable: true},
+		{name: "その他のエラー", err: errors.New("接続エラー"), wantKey: "error.queue_send", wantRetryable: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, retryable := enqueueErrorKey(tt.err)
+			if key != tt.wantKey {
+				t.Errorf("enqueueErrorKey() key = %q, want %q", key, tt.wantKey)
+			}
+			if retryable != tt.wantRetryable {
+				t.Errorf("enqueueErrorKey() retryable = %v, want %v", retryable, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+// 送信エラーの返信は、エラーの説明・問い合わせIDと、再試行で解決する可能性がある場合のみ再試行ボタンを含む
+func TestReplyEnqueueError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantKey   string
+		wantRetry bool
+	}{
+		{name: "再試行できるエラーは再試行ボタンを付ける", err: queuemodel.ErrThrottled, wantKey: "error.queue_throttled", wantRetry: true},
+		{name: "再試行できないエラーは再試行ボタンを付けない", err: &queuemodel.MessageTooLargeError{Size: 300000}, wantKey: "error.message_too_large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, api := newTestApp(t, testAppConfig(), &fakePublisher{})
+			evt, _ := testMentionEvent("<@UBOT> 質問です")
+			evt.ThreadTimeStamp = "1712311100.000100"
+			ctx := logger.WithCorrelationID(context.Background(), "corr-001")
+
+			app.replyEnqueueError(ctx, evt, tt.err)
+
+			replies := api.callsTo("chat.postMessage")
+			if len(replies) != 1 {
+				t.Fatalf("chat.postMessage の呼び出し = %d回, want 1回", len(replies))
+			}
+			if got := replies[0].Form.Get("thread_ts"); got != evt.ThreadTimeStamp {
+				t.Errorf("返信の thread_ts = %q, want %q", got, evt.ThreadTimeStamp)
+			}
+			var blocks slack.Blocks
+			if err := json.Unmarshal([]byte(replies[0].Form.Get("blocks")), &blocks); err != nil {
+				t.Fatalf("blocks の解析エラー: %v", err)
+			}
+			wantBlocks := 2
+			if tt.wantRetry {
+				wantBlocks = 3
+			}
+			if len(blocks.BlockSet) != wantBlocks {
+				t.Fatalf("ブロック = %d個, want %d個", len(blocks.BlockSet), wantBlocks)
+			}
+
+			section, ok := blocks.BlockSet[0].(*slack.SectionBlock)
+			if !ok {
+				t.Fatalf("1つ目のブロック = %T, want *slack.SectionBlock", blocks.BlockSet[0])
+			}
+			wantText := "<@U001> " + app.Translator.T("ja", tt.wantKey)
+			if section.Text.Text != wantText {
+				t.Errorf("エラーの説明 = %q, want %q", section.Text.Text, wantText)
+			}
+
+			contextBlock, ok := blocks.BlockSet[1].(*slack.ContextBlock)
+			if !ok {
+				t.Fatalf("2つ目のブロック = %T, want *slack.ContextBlock", blocks.BlockSet[1])
+			}
+			inquiry, ok := contextBlock.ContextElements.Elements[0].(*slack.TextBlockObject)
+			if !ok || !strings.Contains(inquiry.Text, "corr-001") {
+				t.Errorf("問い合わせID = %v, want corr-001 を含む", contextBlock.ContextElements.Elements[0])
+			}
+
+			if !tt.wantRetry {
+				return
+			}
+			actions, ok := blocks.BlockSet[2].(*slack.ActionBlock)
+			if !ok {
+				t.Fatalf("3つ目のブロック = %T, want *slack.ActionBlock", blocks.BlockSet[2])
+			}
+			button, ok := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+			if !ok {
+				t.Fatalf("再試行ボタン = %T, want *slack.ButtonBlockElement", actions.Elements.ElementSet[0])
+			}
+			if button.ActionID != retryMentionActionID {
+				t.Errorf("ボタンの action_id = %q, want %q", button.ActionID, retryMentionActionID)
+			}
+			var target retryTarget
+			if err := json.Unmarshal([]byte(button.Value), &target); err != nil {
+				t.Fatalf("ボタンの値の解析エラー: %v", err)
+			}
+			want := retryTarget{Channel: "C001", TS: evt.TimeStamp, ThreadTS: evt.ThreadTimeStamp, User: "U001"}
+			if target != want {
+				t.Errorf("再試行の対象 = %+v, want %+v", target, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+)
+
+// 設定ファイルの enrichment.steps で指定する付加処理の名前
+const (
+	enricherUserName    = "user_name"
+	enricherChannelName = "channel_name"
+	enricherPermalink   = "permalink"
+	enricherLocale      = "locale"
+)
+
+// 設定で有効にできる付加処理の一覧
+func (app *SlackBotApp) enrichers() []usecase.Enricher {
+	return []usecase.Enricher{
+		usecase.NewEnricher(enricherUserName, app.enrichUserName),
+		usecase.NewEnricher(enricherChannelName, app.enrichChannelName),
+		usecase.NewEnricher(enricherPermalink, app.enrichPermalink),
+		usecase.NewEnricher(enricherLocale, app.enrichLocale),
+	}
+}
+
+// 依頼したユーザーの表示名を付加する
+// メッセージの作成時に設定済みの場合は何もしない
+func (app *SlackBotApp) enrichUserName(ctx context.Context, msg *queuemodel.MentionMessage) error {
+	if msg.UserName != "" {
+		return nil
+	}
+	names := app.resolveUserNames(ctx, msg.User)
+	msg.UserName = names.name
+	msg.UserRealName = names.realName
+	return nil
+}
+
+// チャンネル名を付加する
+func (app *SlackBotApp) enrichChannelName(ctx context.Context, msg *queuemodel.MentionMessage) error {
+	channel, err := app.channelInfo(ctx, msg.Channel)
+	if err != nil {
+		return err
+	}
+	msg.ChannelName = channel.Name
+	return nil
+}
+
+// 元のメッセージへのリンクを付加する
+func (app *SlackBotApp) enrichPermalink(ctx context.Context, msg *queuemodel.MentionMessage) error {
+	permalink, err := app.SlackClient.GetPermalinkContext(ctx, &slack.PermalinkParameters{Channel: msg.Channel, Ts: msg.TS})
+	if err != nil {
+		return err
+	}
+	msg.Permalink = permalink
+	return nil
+}
+
+// 依頼したユーザーの言語を付加する
+// App Homeでユーザーが設定した言語、Slackの言語設定、i18n.default_lang の順に使用する
+func (app *SlackBotApp) enrichLocale(ctx context.Context, msg *queuemodel.MentionMessage) error {
+	if lang := app.userLang(ctx, msg.User); lang != "" {
+		msg.Locale = string(lang)
+		return nil
+	}
+	locale, err := app.slackLocale(ctx, msg.User)
+	if locale == "" {
+		locale = app.Translator.DefaultLang()
+	}
+	msg.Locale = locale
+	return err
+}
+
+// ユーザーのSlackの言語設定（例: ja-JP）を返す
+// ユーザーごとに locale_cache_ttl の間キャッシュする
+func (app *SlackBotApp) slackLocale(ctx context.Context, userID string) (string, error) {
+	if locale, ok := app.localeCache.Get(userID); ok {
+		return locale, nil
+	}
+	user, err := app.userInfo(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	app.localeCache.Set(userID, user.Locale)
+	return user.Locale, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// fakeUserSettingRepository は langs に設定したユーザーの言語を返す
+type fakeUserSettingRepository struct {
+	di.UserSettingRepository
+	langs map[string]string
+}
+
+func (r *fakeUserSettingRepository) FindByUserID(ctx context.Context, userID string) (*entity.UserSetting, error) {
+	lang, ok := r.langs[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.UserSetting{UserID: userID, Lang: lang}, nil
+}
+
+func TestEnrichLocale(t *testing.T) {
+	tests := []struct {
+		name          string
+		settingLang   string
+		slackLocale   string
+		slackFails    bool
+		want          string
+		wantErr       bool
+		wantUsersInfo int
+	}{
+		{name: "App Homeで設定した言語を優先する", settingLang: "en", slackLocale: "ja-JP", want: "en"},
+		{name: "言語を設定していない場合はSlackの言語設定", slackLocale: "en-US", want: "en-US", wantUsersInfo: 1},
+		{name: "Slackの言語設定がない場合はデフォルトの言語", want: "ja", wantUsersInfo: 1},
+		{name: "Slackの言語設定を取得できない場合もデフォルトの言語を設定する", slackFails: true, want: "ja", wantErr: true, wantUsersInfo: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, api := newTestApp(t, testAppConfig(), &fakePublisher{})
+			api.userLocale = tt.slackLocale
+			api.fail["users.info"] = tt.slackFails
+			settings := &fakeUserSettingRepository{langs: map[string]string{}}
+			if tt.settingLang != "" {
+				settings.langs["U001"] = tt.settingLang
+			}
+			app.UserSettingRepository = settings
+
+			msg := &queuemodel.MentionMessage{User: "U001"}
+			err := app.enrichLocale(context.Background(), msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("enrichLocale() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if msg.Locale != tt.want {
+				t.Errorf("Locale = %q, want %q", msg.Locale, tt.want)
+			}
+			if got := len(api.callsTo("users.info")); got != tt.wantUsersInfo {
+				t.Errorf("users.info の呼び出し = %d回, want %d回", got, tt.wantUsersInfo)
+			}
+		})
+	}
+}
+
+// Slackの言語設定はユーザーごとにキャッシュし、2回目以降は users.info を呼び出さない
+func TestEnrichLocaleCachesSlackLocale(t *testing.T) {
+	app, api := newTestApp(t, testAppConfig(), &fakePublisher{})
+	api.userLocale = "en-US"
+
+	for range 2 {
+		msg := &queuemodel.MentionMessage{User: "U001"}
+		if err := app.enrichLocale(context.Background(), msg); err != nil {
+			t.Fatalf("enrichLocale() error = %v", err)
+		}
+		if msg.Locale != "en-US" {
+			t.Errorf("Locale = %q, want %q", msg.Locale, "en-US")
+		}
+	}
+	if got := len(api.callsTo("users.info")); got != 1 {
+		t.Errorf("users.info の呼び出し = %d回, want 1回", got)
+	}
+}
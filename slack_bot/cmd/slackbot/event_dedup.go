@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+
+	"github.com/slack-go/slack/slackevents"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// Slackが再送したイベントを処理しないよう、初めて受信したイベントの場合のみ true を返すメソッド
+// event_dedup が無効な場合や、イベントを識別するキーがない場合は常に処理する
+func (app *SlackBotApp) claimEvent(ctx context.Context, eventsAPIEvent slackevents.EventsAPIEvent) bool {
+	if app.EventDedup == nil {
+		return true
+	}
+	key := eventDedupKey(eventsAPIEvent)
+	if key == "" {
+		return true
+	}
+	ctx, cancel := app.withEventTimeout(ctx)
+	defer cancel()
+	if app.EventDedup.Claim(ctx, key) {
+		return true
+	}
+	logger.Printf(ctx, "処理済みのイベントのため無視しました: type=%s key=%s", eventsAPIEvent.InnerEvent.Type, key)
+	app.Metrics.EventsDeduplicated.WithLabelValues(app.Workspace.Name, eventsAPIEvent.InnerEvent.Type).Inc()
+	return false
+}
+
+// イベントの重複を判定するキーを返す
+// Slackは再送しても同じ event_id を送信するため event_id を使用し、取得できない場合はメッセージの client_msg_id を使用する
+// 複数のワークスペースで同じ値にならないよう、ワークスペースのIDを付ける
+func eventDedupKey(eventsAPIEvent slackevents.EventsAPIEvent) string {
+	if id := eventID(eventsAPIEvent); id != "" {
+		return eventsAPIEvent.TeamID + ":event:" + id
+	}
+	if msg, ok := eventsAPIEvent.InnerEvent.Data.(*slackevents.MessageEvent); ok && msg.ClientMsgID != "" {
+		return eventsAPIEvent.TeamID + ":" + eventsAPIEvent.InnerEvent.Type + ":" + msg.ClientMsgID
+	}
+	return ""
+}
+
+// Events APIのイベントの event_id を返す（取得できない場合は空文字）
+func eventID(eventsAPIEvent slackevents.EventsAPIEvent) string {
+	if callback, ok := eventsAPIEvent.Data.(*slackevents.EventsAPICallbackEvent); ok {
+		return callback.EventID
+	}
+	return ""
+}
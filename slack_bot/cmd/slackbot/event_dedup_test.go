@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestEventDedupKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		event slackevents.EventsAPIEvent
+		want  string
+	}{
+		{
+			name: "event_id をワークスペースのIDと組み合わせる",
+			event: slackevents.EventsAPIEvent{
+				TeamID:     "T001",
+				Data:       &slackevents.EventsAPICallbackEvent{EventID: "Ev001"},
+				InnerEvent: slackevents.EventsAPIInnerEvent{Type: "app_mention", Data: &slackevents.AppMentionEvent{}},
+			},
+			want: "T001:event:Ev001",
+		},
+		{
+			name: "event_id がない場合はメッセージの client_msg_id を使用する",
+			event: slackevents.EventsAPIEvent{
+				TeamID:     "T001",
+				InnerEvent: slackevents.EventsAPIInnerEvent{Type: "message", Data: &slackevents.MessageEvent{ClientMsgID: "msg-001"}},
+			},
+			want: "T001:message:msg-001",
+		},
+		{
+			name: "event_id を client_msg_id より優先する",
+			event: slackevents.EventsAPIEvent{
+				TeamID:     "T001",
+				Data:       &slackevents.EventsAPICallbackEvent{EventID: "Ev001"},
+				InnerEvent: slackevents.EventsAPIInnerEvent{Type: "message", Data: &slackevents.MessageEvent{ClientMsgID: "msg-001"}},
+			},
+			want: "T001:event:Ev001",
+		},
+		{
+			name: "識別できないイベントは空文字",
+			event: slackevents.EventsAPIEvent{
+				TeamID:     "T001",
+				InnerEvent: slackevents.EventsAPIInnerEvent{Type: "app_mention", Data: &slackevents.AppMentionEvent{}},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eventDedupKey(tt.event); got != tt.want {
+				t.Errorf("eventDedupKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// go test ./cmd/slackbot -update で testdata の golden ファイルを現在の出力で更新する
+var updateGolden = flag.Bool("update", false, "testdata の golden ファイルを更新する")
+
+// assertGolden は got が testdata/name の内容と一致することを確認する
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("golden ファイルの更新エラー: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden ファイルの読み込みエラー: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s と一致しません (-update で更新できます)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// ユーザーの情報を取得する
+// 呼び出し頻度を enrichment.info_api の設定に制限し、Slackのレート制限に達した場合は待ってから再試行する
+func (app *SlackBotApp) userInfo(ctx context.Context, userID string) (*slack.User, error) {
+	var user *slack.User
+	err := app.callInfoAPI(ctx, "users.info", func() error {
+		var err error
+		user, err = app.SlackClient.GetUserInfoContext(ctx, userID)
+		return err
+	})
+	return user, err
+}
+
+// チャンネルの情報を取得する
+func (app *SlackBotApp) channelInfo(ctx context.Context, channelID string) (*slack.Channel, error) {
+	var channel *slack.Channel
+	err := app.callInfoAPI(ctx, "conversations.info", func() error {
+		var err error
+		channel, err = app.SlackClient.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channelID})
+		return err
+	})
+	return channel, err
+}
+
+// callInfoAPI は infoLimiter のトークンを待ってから call を呼び出す
+// レート制限のエラー (slack.RateLimitedError) の場合は Retry-After の時間待って max_retries 回まで再試行する
+func (app *SlackBotApp) callInfoAPI(ctx context.Context, method string, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := app.infoLimiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		err := call()
+		var rateLimited *slack.RateLimitedError
+		if !errors.As(err, &rateLimited) || attempt >= app.AppConfig.Enrichment.InfoAPI.MaxRetries {
+			return err
+		}
+
+		logger.Printf(ctx, "%s のレート制限に達したため %s 後に再試行します (%d/%d)", method, rateLimited.RetryAfter, attempt+1, app.AppConfig.Enrichment.InfoAPI.MaxRetries)
+		timer := time.NewTimer(rateLimited.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+// Bot同士の応答の繰り返しの原因になるため処理しないメッセージのサブタイプ
+var ignoredMessageSubtypes = []string{"bot_message", "message_changed"}
+
+// Botの投稿、Bot自身の投稿、編集されたメッセージによるメンションかどうかと、その理由を返す
+func (app *SlackBotApp) isSelfTriggered(evt *slackevents.AppMentionEvent, rawEvent json.RawMessage) (string, bool) {
+	switch {
+	case evt.BotID != "":
+		return "bot_id=" + evt.BotID, true
+	case app.BotUserID != "" && evt.User == app.BotUserID:
+		return "self", true
+	case evt.Edited != nil:
+		return "edited", true
+	}
+	if subtype := eventSubtype(rawEvent); slices.Contains(ignoredMessageSubtypes, subtype) {
+		return "subtype=" + subtype, true
+	}
+	return "", false
+}
+
+// イベントのJSONから subtype を取り出す（slackevents.AppMentionEvent には含まれない）
+func eventSubtype(rawEvent json.RawMessage) string {
+	if rawEvent == nil {
+		return ""
+	}
+	var event struct {
+		Subtype string `json:"subtype"`
+	}
+	if err := json.Unmarshal(rawEvent, &event); err != nil {
+		return ""
+	}
+	return event.Subtype
+}
+
+// スレッドごとの応答数を記録し、上限を超えている場合は false を返すメソッド
+// 上限を超えたことは window の間に一度だけスレッドで通知する
+func (app *SlackBotApp) allowThread(ctx context.Context, evt *slackevents.AppMentionEvent) bool {
+	if app.threadLimiter == nil {
+		return true
+	}
+	key := evt.Channel + ":" + threadTimeStamp(evt)
+	if ok, _ := app.threadLimiter.Allow(key); ok {
+		return true
+	}
+	if app.threadNotified.Add(key) {
+		app.replyInThread(ctx, evt, app.t(ctx, evt.User, "loop_guard.paused"))
+	}
+	return false
+}
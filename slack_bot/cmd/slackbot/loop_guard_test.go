@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/cache"
+)
+
+// Botの投稿・Bot自身の投稿・編集によるメンションはキューに送信しない
+func TestHandleAppMentionIgnoresBotEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(evt *slackevents.AppMentionEvent) json.RawMessage
+		wantPublished int
+	}{
+		{
+			name:          "ユーザーのメンションは送信する",
+			modify:        func(evt *slackevents.AppMentionEvent) json.RawMessage { return nil },
+			wantPublished: 1,
+		},
+		{
+			name: "bot_id のあるメンションは送信しない",
+			modify: func(evt *slackevents.AppMentionEvent) json.RawMessage {
+				evt.BotID = "B001"
+				return nil
+			},
+		},
+		{
+			name: "Bot自身のユーザーによるメンションは送信しない",
+			modify: func(evt *slackevents.AppMentionEvent) json.RawMessage {
+				evt.User = "UBOT"
+				return nil
+			},
+		},
+		{
+			name: "編集されたメッセージのメンションは送信しない",
+			modify: func(evt *slackevents.AppMentionEvent) json.RawMessage {
+				evt.Edited = &slackevents.Edited{User: evt.User, TimeStamp: "1712311300.000100"}
+				return nil
+			},
+		},
+		{
+			name: "subtype が bot_message のメンションは送信しない",
+			modify: func(evt *slackevents.AppMentionEvent) json.RawMessage {
+				return json.RawMessage(`{"type":"app_mention","subtype":"bot_message"}`)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakePublisher{}
+			app, api := newTestApp(t, testAppConfig(), publisher)
+			evt, raw := testMentionEvent("<@UBOT> 質問です")
+			if r := tt.modify(evt); r != nil {
+				raw = r
+			}
+
+			app.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+
+			if got := len(publisher.messages()); got != tt.wantPublished {
+				t.Errorf("キューへの送信 = %d件, want %d件", got, tt.wantPublished)
+			}
+			if got := len(api.callsTo("chat.postMessage")); got != 0 {
+				t.Errorf("chat.postMessage の呼び出し = %d回, want 0回", got)
+			}
+		})
+	}
+}
+
+// 同じスレッドの応答数が上限に達した後は送信せず、通知は1回だけ返信する
+func TestHandleAppMentionThreadCircuitBreaker(t *testing.T) {
+	publisher := &fakePublisher{}
+	app, api := newTestApp(t, testAppConfig(), publisher)
+	app.threadLimiter = cache.NewRateLimiter(2, time.Minute)
+	app.threadNotified = cache.NewTTLSet(time.Minute, 0)
+
+	for i := range 4 {
+		evt, raw := testMentionEvent("<@UBOT> 続きの質問です")
+		evt.ThreadTimeStamp = "1712311100.000100"
+		evt.TimeStamp = fmt.Sprintf("17123112%02d.000100", i)
+		app.handleAppMention(context.Background(), evt, raw, slackmodel.MessageSourceMention)
+	}
+
+	if got := len(publisher.messages()); got != 2 {
+		t.Errorf("キューへの送信 = %d件, want 2件", got)
+	}
+	replies := api.callsTo("chat.postMessage")
+	if len(replies) != 1 {
+		t.Fatalf("chat.postMessage の呼び出し = %d回, want 1回", len(replies))
+	}
+	want := app.Translator.T("ja", "loop_guard.paused")
+	if got := replies[0].Form.Get("text"); !strings.Contains(got, want) {
+		t.Errorf("返信 = %q, want %q を含む", got, want)
+	}
+}
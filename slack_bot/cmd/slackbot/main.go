@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command はサブコマンドの定義
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{name: "serve", description: "Slackに接続してメンションを処理する", run: runServe},
+	{name: "check", description: "Slackに接続できるかを確認して終了する", run: runCheck},
+	{name: "migrate", description: "データベースのマイグレーションを実行する", run: runMigrate},
+	{name: "replay", description: "キューに送信できなかったメッセージ、または指定したメンションを再送する", run: runReplay},
+	{name: "worker", description: "キューのメンションにAIで回答し、元のスレッドに投稿する", run: runWorker},
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	name, args := flag.Arg(0), flag.Args()[1:]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(args); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "不明なコマンドです: %s\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "使い方: %s [--config path] <command> [flags]\n\nコマンド:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-8s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(out, "\nオプション:")
+	flag.PrintDefaults()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildBinary はテスト用に cmd/slackbot のバイナリをビルドする
+func buildBinary(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("バイナリのビルドは -short では実行しない")
+	}
+	bin := filepath.Join(t.TempDir(), "slackbot")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("ビルドエラー: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// runBinary はバイナリを実行して出力と終了コードを返す
+func runBinary(t *testing.T, bin string, args ...string) (string, int) {
+	t.Helper()
+	out, err := exec.Command(bin, args...).CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(out), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("%v の実行エラー: %v", args, err)
+	}
+	return string(out), 0
+}
+
+func TestBinarySmoke(t *testing.T) {
+	bin := buildBinary(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     []string
+	}{
+		{name: "--help はコマンドの一覧を表示する", args: []string{"--help"}, wantCode: 0, want: []string{"serve", "check", "migrate", "replay", "worker", "-config"}},
+		{name: "コマンドを指定しない場合は使い方を表示する", args: nil, wantCode: 2, want: []string{"使い方:"}},
+		{name: "不明なコマンド", args: []string{"unknown"}, wantCode: 2, want: []string{"不明なコマンドです: unknown"}},
+		{name: "check --help", args: []string{"check", "--help"}, wantCode: 0, want: []string{"-timeout"}},
+		{name: "serve --help", args: []string{"serve", "--help"}, wantCode: 0},
+		{name: "migrate --help", args: []string{"migrate", "--help"}, wantCode: 0, want: []string{"-dir"}},
+		{name: "replay --help", args: []string{"replay", "--help"}, wantCode: 0, want: []string{"-limit"}},
+		{name: "worker --help", args: []string{"worker", "--help"}, wantCode: 0},
+		{name: "check の不正なオプションは設定を読み込む前にエラーにする", args: []string{"check", "-timeout", "soon"}, wantCode: 2, want: []string{"-timeout"}},
+		// オプションを解析できた後は設定ファイルの読み込みで失敗する
+		{name: "check のオプションを解析して設定を読み込む", args: []string{"--config", "testdata/not_found.yml", "check", "-timeout", "1s"}, wantCode: 1, want: []string{"check:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := runBinary(t, bin, tt.args...)
+			if code != tt.wantCode {
+				t.Errorf("%v の終了コード = %d, want %d\n%s", tt.args, code, tt.wantCode, out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("%v の出力に %q が含まれていません\n%s", tt.args, want, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// 指定したタイムスタンプのメッセージを1件取得するメソッド
+// スレッド内のメッセージは conversations.history では取得できないため conversations.replies を使用する
+func (app *SlackBotApp) fetchMessage(ctx context.Context, channelID, ts, threadTS string) (*slack.Message, error) {
+	var messages []slack.Message
+	if threadTS != "" && threadTS != ts {
+		replies, _, _, err := app.SlackClient.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+			ChannelID: channelID,
+			Timestamp: threadTS,
+			Latest:    ts,
+			Oldest:    ts,
+			Inclusive: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		messages = replies
+	} else {
+		history, err := app.SlackClient.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID: channelID,
+			Latest:    ts,
+			Oldest:    ts,
+			Inclusive: true,
+			Limit:     1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		messages = history.Messages
+	}
+
+	for i := range messages {
+		if messages[i].Timestamp == ts {
+			return &messages[i], nil
+		}
+	}
+
+	// スレッドの返信は conversations.history に含まれないため、返信自身を起点に取得し直す
+	if threadTS == "" {
+		replies, _, _, err := app.SlackClient.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+			ChannelID: channelID,
+			Timestamp: ts,
+			Latest:    ts,
+			Oldest:    ts,
+			Inclusive: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range replies {
+			if replies[i].Timestamp == ts {
+				return &replies[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("メッセージが見つかりません: channel=%s ts=%s", channelID, ts)
+}
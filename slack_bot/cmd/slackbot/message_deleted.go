@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/slack-go/slack/slackevents"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// 削除されたメッセージから導出するメンションの種類
+// リアクションによる依頼はリアクションしたユーザー・時刻もIDに含むため、チャンネルとタイムスタンプで検索して削除する
+var deletedMessageSources = []slackmodel.MessageSource{slackmodel.MessageSourceMention, slackmodel.MessageSourceDirectMessage}
+
+// メッセージ削除の処理メソッド
+// deleted_messages が有効な場合に、削除されたメッセージのメンションを論理削除する
+func (app *SlackBotApp) handleMessageDeleted(ctx context.Context, evt *slackevents.MessageEvent) {
+	if !app.AppConfig.DeletedMessages.Enabled || app.MentionCommand == nil || evt.DeletedTimeStamp == "" {
+		return
+	}
+	ctx = logger.With(ctx, "channel", evt.Channel, "ts", evt.DeletedTimeStamp)
+	timestamp, err := slackmodel.ParseSlackTimestamp(evt.DeletedTimeStamp)
+	if err != nil {
+		logger.Errorf(ctx, "タイムスタンプの変換エラー: %v", err)
+		return
+	}
+
+	for _, source := range deletedMessageSources {
+		id, err := slackmodel.NewMentionIDFromEvent(source, slackmodel.ChannelID(evt.Channel), slackmodel.Timestamp(timestamp))
+		if err != nil {
+			logger.Errorf(ctx, "メンションIDの導出エラー: %v", err)
+			return
+		}
+		err = app.MentionCommand.Delete(ctx, id)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			logger.Debugf(ctx, "削除されたメッセージのメンションは保存されていません: type=%s channel=%s ts=%s", source, evt.Channel, evt.DeletedTimeStamp)
+		case err != nil:
+			logger.Errorf(ctx, "メンションの削除エラー (id=%s): %v", id, err)
+		default:
+			logger.Printf(ctx, "削除されたメッセージのメンションを論理削除しました: id=%s channel=%s ts=%s", id, evt.Channel, evt.DeletedTimeStamp)
+		}
+	}
+
+	deleted, err := app.MentionCommand.DeleteByTS(ctx, string(slackmodel.MessageSourceReaction), evt.Channel, evt.DeletedTimeStamp)
+	switch {
+	case err != nil:
+		logger.Errorf(ctx, "リアクションによる依頼の削除エラー: %v", err)
+	case deleted > 0:
+		logger.Printf(ctx, "削除されたメッセージへのリアクションによる依頼を論理削除しました: count=%d channel=%s ts=%s", deleted, evt.Channel, evt.DeletedTimeStamp)
+	}
+}
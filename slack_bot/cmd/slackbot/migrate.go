@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/bootstrap"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.uber.org/fx"
+)
+
+// runMigrate は migrations/schema のSQLファイルでデータベースのマイグレーションを実行する
+// 引数に down を指定した場合は最後に適用したマイグレーションのグループを取り消す
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := fs.String("dir", "../migrations/schema", "マイグレーションのSQLファイルがあるディレクトリ")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	direction := "up"
+	if fs.NArg() > 0 {
+		direction = fs.Arg(0)
+	}
+	if direction != "up" && direction != "down" {
+		return fmt.Errorf("up または down を指定してください: %s", direction)
+	}
+
+	var db *bun.DB
+	app := bootstrap.NewApp(fx.NopLogger, bootstrap.MigrateModule, fx.Populate(&db))
+	if err := app.Err(); err != nil {
+		return err
+	}
+	if db == nil {
+		return fmt.Errorf("データベースが無効です (database.enabled)")
+	}
+	ctx := context.Background()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	defer app.Stop(ctx)
+
+	group, err := migrateDB(ctx, db, os.DirFS(*dir), direction)
+	if err != nil {
+		return fmt.Errorf("%w (dir=%s)", err, *dir)
+	}
+	if group.IsZero() {
+		fmt.Println("適用するマイグレーションはありません")
+		return nil
+	}
+	fmt.Printf("マイグレーションを実行しました (%s): %s\n", direction, group)
+	return nil
+}
+
+// migrateDB は fsys のSQLファイルで db のマイグレーションを実行し、実行したマイグレーションのグループを返す
+// direction が up の場合は未適用のマイグレーションをすべて適用し、down の場合は最後に適用したグループを取り消す
+func migrateDB(ctx context.Context, db *bun.DB, fsys fs.FS, direction string) (*migrate.MigrationGroup, error) {
+	migrations := migrate.NewMigrations()
+	if err := migrations.Discover(fsys); err != nil {
+		return nil, fmt.Errorf("マイグレーションの読み込みエラー: %w", err)
+	}
+	migrator := migrate.NewMigrator(db, migrations)
+	if err := migrator.Init(ctx); err != nil {
+		return nil, fmt.Errorf("マイグレーション管理テーブルの作成エラー: %w", err)
+	}
+	if err := migrator.Lock(ctx); err != nil {
+		return nil, fmt.Errorf("マイグレーションのロックエラー: %w", err)
+	}
+	defer migrator.Unlock(ctx)
+
+	var group *migrate.MigrationGroup
+	var err error
+	if direction == "up" {
+		group, err = migrator.Migrate(ctx)
+	} else {
+		group, err = migrator.Rollback(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("マイグレーションの実行エラー: %w", err)
+	}
+	return group, nil
+}
@@ -0,0 +1,83 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/database"
+	"github.com/testcontainers/testcontainers-go/modules/mysql"
+	"github.com/uptrace/bun"
+	"go.uber.org/fx/fxtest"
+)
+
+// migrations/schema のすべてのマイグレーションをMySQLに適用し、取り消せることを確認する
+// 実行にはDockerが必要: go test -tags integration ./cmd/slackbot -run TestMigrateUpDown
+func TestMigrateUpDown(t *testing.T) {
+	ctx := context.Background()
+	container, err := mysql.Run(ctx, "mysql:8.0", mysql.WithDatabase("slackbot"))
+	if err != nil {
+		t.Fatalf("MySQLのコンテナの起動エラー: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Logf("MySQLのコンテナの停止エラー: %v", err)
+		}
+	})
+	dsn, err := container.ConnectionString(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lc := fxtest.NewLifecycle(t)
+	db, err := database.NewMigrationDB(lc, &config.AppConfig{Database: config.DatabaseConfig{Enabled: true, DSN: dsn}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lc.RequireStart()
+	t.Cleanup(func() { lc.RequireStop() })
+
+	schema := os.DirFS("../../../migrations/schema")
+	group, err := migrateDB(ctx, db, schema, "up")
+	if err != nil {
+		t.Fatalf("up の実行エラー: %v", err)
+	}
+	if group.IsZero() {
+		t.Fatal("up で適用したマイグレーションがありません")
+	}
+	for _, table := range []string{"slack_mentions", "outbox", "failed_mentions", "conversations", "processed_events"} {
+		if !tableExists(t, ctx, db, table) {
+			t.Errorf("up の後にテーブル %s がありません", table)
+		}
+	}
+
+	// 適用済みの場合は何もしない
+	if group, err := migrateDB(ctx, db, schema, "up"); err != nil || !group.IsZero() {
+		t.Fatalf("2回目の up = %v, %v, want 空のグループ", group, err)
+	}
+
+	if _, err := migrateDB(ctx, db, schema, "down"); err != nil {
+		t.Fatalf("down の実行エラー: %v", err)
+	}
+	if tableExists(t, ctx, db, "slack_mentions") {
+		t.Error("down の後もテーブル slack_mentions が残っています")
+	}
+}
+
+func tableExists(t *testing.T, ctx context.Context, db *bun.DB, table string) bool {
+	t.Helper()
+	var count int
+	err := db.NewSelect().
+		TableExpr("information_schema.tables").
+		ColumnExpr("COUNT(*)").
+		Where("table_schema = DATABASE()").
+		Where("table_name = ?", table).
+		Scan(ctx, &count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
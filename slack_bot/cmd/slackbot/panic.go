@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+// panicPayloadMaxLength はパニックの発生時にログに出力するイベントの最大文字数
+const panicPayloadMaxLength = 4096
+
+// panicReplyTarget はパニックが発生したイベントのエラーを返信するスレッドと宛先
+type panicReplyTarget struct {
+	channel  string
+	threadTS string
+	user     string
+}
+
+// イベントの処理中のパニックから復帰するメソッド
+// 処理を行う関数で defer して呼び出し、パニックの内容・スタックトレース・イベントをログに出力してメトリクスに記録する
+// メンションなど返信先が分かるイベントの場合はスレッドにエラーを返信する
+func (app *SlackBotApp) recoverEvent(eventType string, payload any) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	app.Logger.Error("イベントの処理中にパニックが発生しました", "event_type", eventType, "panic", r, "event", panicPayload(payload), "stack", string(debug.Stack()))
+	app.Metrics.Panics.WithLabelValues(app.Workspace.Name, eventType).Inc()
+
+	target, ok := replyTargetOf(payload)
+	if !ok {
+		return
+	}
+	// 返信中のパニックでワーカーが止まらないよう、ここでも復帰する
+	defer func() {
+		if r := recover(); r != nil {
+			app.Logger.Error("パニックのエラーの返信中にパニックが発生しました", "event_type", eventType, "panic", r)
+		}
+	}()
+	ctx, cancel := app.withEventTimeout(context.Background())
+	defer cancel()
+	app.postThreadReply(ctx, target.channel, target.threadTS, target.user, app.t(ctx, target.user, "error.internal"))
+}
+
+// イベントからエラーを返信するスレッドを取得する
+// Botへのメンションの場合のみ返信する。チャンネルのメッセージは依頼かどうかを判定する前の場合があるため返信しない
+func replyTargetOf(payload any) (panicReplyTarget, bool) {
+	switch ev := payload.(type) {
+	case slackevents.EventsAPIEvent:
+		return replyTargetOf(ev.InnerEvent.Data)
+	case *slackevents.AppMentionEvent:
+		if ev.Channel == "" || ev.TimeStamp == "" {
+			return panicReplyTarget{}, false
+		}
+		return panicReplyTarget{channel: ev.Channel, threadTS: threadTimeStamp(ev), user: ev.User}, true
+	}
+	return panicReplyTarget{}, false
+}
+
+// ログに出力するイベントの内容を返す
+// JSONに変換できない場合は fmt の形式で出力し、長いイベントは切り詰める
+func panicPayload(payload any) string {
+	var s string
+	if b, err := json.Marshal(payload); err == nil {
+		s = string(b)
+	} else {
+		s = fmt.Sprintf("%+v", payload)
+	}
+	return truncateRunes(s, panicPayloadMaxLength)
+}
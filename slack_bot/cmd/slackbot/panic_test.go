@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+)
+
+// パニックが発生したイベントがあっても、後続のイベントを処理し続ける
+func TestDispatchRecoversFromPanic(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	app := &SlackBotApp{
+		Logger:    logger,
+		Metrics:   metrics.NewMetrics(prometheus.NewRegistry()),
+		Workspace: config.SlackBotConfig{Name: "default"},
+		workers:   newWorkerPool(logger, 1, 10),
+	}
+
+	var handled atomic.Int32
+	app.dispatch("message", nil, func() {
+		var m map[string]int
+		m["panic"] = 1
+	})
+	for range 3 {
+		app.dispatch("message", nil, func() { handled.Add(1) })
+	}
+	if err := app.workers.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := handled.Load(); got != 3 {
+		t.Errorf("パニックの後に処理したイベント = %d件, want 3件", got)
+	}
+	if got := testutil.ToFloat64(app.Metrics.Panics.WithLabelValues("default", "message")); got != 1 {
+		t.Errorf("panics_total = %v, want 1", got)
+	}
+}
+
+func TestReplyTargetOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload any
+		want    panicReplyTarget
+		wantOK  bool
+	}{
+		{
+			name:    "メンションはスレッドに返信する",
+			payload: &slackevents.AppMentionEvent{Channel: "C001", TimeStamp: "1712345678.000200", User: "U001"},
+			want:    panicReplyTarget{channel: "C001", threadTS: "1712345678.000200", user: "U001"},
+			wantOK:  true,
+		},
+		{
+			name:    "スレッド内のメンションはスレッドの親に返信する",
+			payload: &slackevents.AppMentionEvent{Channel: "C001", TimeStamp: "1712345678.000300", ThreadTimeStamp: "1712345678.000200", User: "U001"},
+			want:    panicReplyTarget{channel: "C001", threadTS: "1712345678.000200", user: "U001"},
+			wantOK:  true,
+		},
+		{
+			name: "Events APIのイベントは内側のイベントで判定する",
+			payload: slackevents.EventsAPIEvent{InnerEvent: slackevents.EventsAPIInnerEvent{
+				Data: &slackevents.AppMentionEvent{Channel: "C001", TimeStamp: "1712345678.000200", User: "U001"},
+			}},
+			want:   panicReplyTarget{channel: "C001", threadTS: "1712345678.000200", user: "U001"},
+			wantOK: true,
+		},
+		{
+			name:    "タイムスタンプのないメンションには返信しない",
+			payload: &slackevents.AppMentionEvent{Channel: "C001", User: "U001"},
+		},
+		{
+			name:    "チャンネルのメッセージには返信しない",
+			payload: &slackevents.MessageEvent{Channel: "C001", TimeStamp: "1712345678.000200"},
+		},
+		{name: "イベントがない場合は返信しない", payload: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := replyTargetOf(tt.payload)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("replyTargetOf() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPanicPayload(t *testing.T) {
+	if got := panicPayload(map[string]string{"text": "質問"}); got != `{"text":"質問"}` {
+		t.Errorf("panicPayload() = %s, want JSON", got)
+	}
+	// JSONに変換できない値は fmt の形式で出力する
+	if got := panicPayload(func() {}); got == "" {
+		t.Error("panicPayload(func) = 空文字列")
+	}
+	long := panicPayload(strings.Repeat("あ", panicPayloadMaxLength*2))
+	if n := utf8.RuneCountInString(long); n != panicPayloadMaxLength {
+		t.Errorf("panicPayload() = %d文字, want %d文字", n, panicPayloadMaxLength)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// Slackにメッセージを投稿するメソッド
+// レート制限された場合は post_message の設定に従って再試行する（postMessageWithRetry を参照）
+func (app *SlackBotApp) postMessage(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	return postMessageWithRetry(ctx, app.SlackClient, app.AppConfig.PostMessage, channelID, options...)
+}
+
+// chat.postMessage を呼び出し、レート制限のエラー (slack.RateLimitedError) の場合は Retry-After の時間待って再試行する
+// Retry-After が max_retry_wait を超える場合と、待っている間にコンテキストが終了した場合はエラーを返す
+// 待つ間は呼び出し元のゴルーチンを止めるため、イベントの受信ループではなくワーカーや回答キューの処理から呼び出す
+func postMessageWithRetry(ctx context.Context, client *slack.Client, cfg config.PostMessageConfig, channelID string, options ...slack.MsgOption) (string, string, error) {
+	var channel, ts string
+	err := retryRateLimited(ctx, cfg, "chat.postMessage", channelID, func() error {
+		var err error
+		channel, ts, err = client.PostMessageContext(ctx, channelID, options...)
+		return err
+	})
+	return channel, ts, err
+}
+
+// chat.update を呼び出し、レート制限された場合は postMessageWithRetry と同じように再試行する
+func updateMessageWithRetry(ctx context.Context, client *slack.Client, cfg config.PostMessageConfig, channelID, ts string, options ...slack.MsgOption) error {
+	return retryRateLimited(ctx, cfg, "chat.update", channelID, func() error {
+		_, _, _, err := client.UpdateMessageContext(ctx, channelID, ts, options...)
+		return err
+	})
+}
+
+// call がレート制限のエラーを返した場合に、post_message の設定に従って再試行する
+func retryRateLimited(ctx context.Context, cfg config.PostMessageConfig, method, channelID string, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		var rateLimited *slack.RateLimitedError
+		if !errors.As(err, &rateLimited) || attempt >= cfg.MaxRetries || rateLimited.RetryAfter > cfg.MaxRetryWait {
+			return err
+		}
+
+		logger.Printf(ctx, "%s のレート制限に達したため %s 後に再試行します (channel=%s %d/%d)", method, rateLimited.RetryAfter, channelID, attempt+1, cfg.MaxRetries)
+		timer := time.NewTimer(rateLimited.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
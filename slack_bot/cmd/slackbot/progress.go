@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/cache"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// 回答キューのメッセージの種類
+const (
+	responseTypeAnswer   = "answer"
+	responseTypeProgress = "progress"
+	responseTypeDraft    = "draft"
+)
+
+// ProgressNotifier は回答が届くまでの途中経過をスレッドに表示する
+// 途中経過はコンテキストの相関IDごとに1つのメッセージにまとめる
+type ProgressNotifier interface {
+	// Update は途中経過の段階（"検索中" など）を表示する
+	Update(ctx context.Context, channel, threadTS, stage string) error
+	// Done は回答が届いた後に途中経過の表示を削除する
+	Done(ctx context.Context, channel string) error
+}
+
+// slackProgressNotifier は最初の途中経過を投稿し、以降は chat.update で同じメッセージを書き換える
+type slackProgressNotifier struct {
+	client *slack.Client
+	retry  config.PostMessageConfig
+	// statuses は相関IDごとの途中経過のメッセージのタイムスタンプ
+	statuses *cache.TTLCache[string]
+	// mu は同じ相関IDの途中経過が同時に届いた場合に、メッセージを重複して投稿しないようにする
+	mu sync.Mutex
+}
+
+func newSlackProgressNotifier(client *slack.Client, retry config.PostMessageConfig, ttl time.Duration) *slackProgressNotifier {
+	return &slackProgressNotifier{client: client, retry: retry, statuses: cache.NewTTLCache[string](ttl, 0)}
+}
+
+func (n *slackProgressNotifier) Update(ctx context.Context, channel, threadTS, stage string) error {
+	correlationID := logger.CorrelationID(ctx)
+	if correlationID == "" {
+		return fmt.Errorf("途中経過の相関IDがありません")
+	}
+	text := fmt.Sprintf(":hourglass_flowing_sand: %s…", stage)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if ts, ok := n.statuses.Get(correlationID); ok {
+		if _, _, _, err := n.client.UpdateMessageContext(ctx, channel, ts, slack.MsgOptionText(text, false)); err != nil {
+			return fmt.Errorf("途中経過の更新エラー: %w", err)
+		}
+		n.statuses.Set(correlationID, ts)
+		return nil
+	}
+
+	options := []slack.MsgOption{
+		slack.MsgOptionText(text, false),
+		correlationMetadata(ctx),
+	}
+	if threadTS != "" {
+		options = append(options, slack.MsgOptionTS(threadTS))
+	}
+	_, ts, err := postMessageWithRetry(ctx, n.client, n.retry, channel, options...)
+	if err != nil {
+		return fmt.Errorf("途中経過の投稿エラー: %w", err)
+	}
+	n.statuses.Set(correlationID, ts)
+	return nil
+}
+
+func (n *slackProgressNotifier) Done(ctx context.Context, channel string) error {
+	correlationID := logger.CorrelationID(ctx)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ts, ok := n.statuses.Get(correlationID)
+	if !ok {
+		return nil
+	}
+	n.statuses.Delete(correlationID)
+	if _, _, err := n.client.DeleteMessageContext(ctx, channel, ts); err != nil {
+		return fmt.Errorf("途中経過の削除エラー: %w", err)
+	}
+	return nil
+}
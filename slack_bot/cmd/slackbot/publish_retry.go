@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// publish がキューへの送信に一時的に失敗した場合に、elasticmq.retry の設定に従って再試行する
+// 再試行しても解決しないエラー（サイズの超過・送信先の未設定）と処理の期限切れはそのまま返す
+func retryPublish(ctx context.Context, cfg config.ElasticMQRetryConfig, queueKey string, publish func() (string, error)) (string, error) {
+	for attempt := 1; ; attempt++ {
+		messageID, err := publish()
+		if err == nil || attempt >= cfg.MaxAttempts || !isRetryablePublishError(ctx, err) {
+			return messageID, err
+		}
+
+		wait := publishBackoff(cfg, attempt)
+		logger.Warnf(ctx, "キュー (%s) への送信に失敗したため %s 後に再試行します (%d/%d): %v", queueKey, wait, attempt, cfg.MaxAttempts-1, err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			// 期限切れとして扱えるよう、待っている間に期限を過ぎた場合は直前の送信エラーに期限切れを加えて返す
+			return "", errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryablePublishError は再試行で解決する可能性がある送信エラーかどうかを返す
+func isRetryablePublishError(ctx context.Context, err error) bool {
+	var tooLarge *queuemodel.MessageTooLargeError
+	var unknownQueue *queue.UnknownQueueError
+	switch {
+	case errors.As(err, &tooLarge), errors.As(err, &unknownQueue):
+		return false
+	case ctx.Err() != nil:
+		return false
+	}
+	return true
+}
+
+// publishBackoff は attempt 回目の送信に失敗した後に待つ時間を返す
+// 複数のイベントの再試行が同時に集中しないよう、jitter の割合だけ前後にずらす
+func publishBackoff(cfg config.ElasticMQRetryConfig, attempt int) time.Duration {
+	d := cfg.BaseDelay
+	for i := 1; i < attempt && d < cfg.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, cfg.MaxDelay)
+	return time.Duration(float64(d) * (1 + cfg.Jitter*(2*rand.Float64()-1)))
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/queue"
+)
+
+func TestPublishBackoff(t *testing.T) {
+	cfg := config.ElasticMQRetryConfig{BaseDelay: 200 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 200 * time.Millisecond},
+		{attempt: 2, want: 400 * time.Millisecond},
+		{attempt: 3, want: 800 * time.Millisecond},
+		// 2倍にすると上限を超えるため上限で止める
+		{attempt: 4, want: time.Second},
+		{attempt: 10, want: time.Second},
+	}
+	for _, tt := range tests {
+		if got := publishBackoff(cfg, tt.attempt); got != tt.want {
+			t.Errorf("publishBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestPublishBackoffJitter(t *testing.T) {
+	cfg := config.ElasticMQRetryConfig{BaseDelay: time.Second, MaxDelay: time.Second, Jitter: 0.2}
+	for range 100 {
+		if got := publishBackoff(cfg, 1); got < 800*time.Millisecond || got > 1200*time.Millisecond {
+			t.Fatalf("publishBackoff(1) = %v, want 800ms〜1.2s", got)
+		}
+	}
+}
+
+func TestRetryPublish(t *testing.T) {
+	cfg := config.ElasticMQRetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	tests := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "成功した場合は再試行しない", errs: []error{nil}, wantAttempts: 1},
+		{name: "一時的なエラーは成功するまで再試行する", errs: []error{errors.New("throttled"), nil}, wantAttempts: 2},
+		{name: "max_attempts 回で諦める", errs: []error{errors.New("a"), errors.New("b"), errors.New("c")}, wantAttempts: 3},
+		{name: "サイズの超過は再試行しない", errs: []error{&queuemodel.MessageTooLargeError{Size: 300 * 1024}}, wantAttempts: 1},
+		{name: "送信先の未設定は再試行しない", errs: []error{&queue.UnknownQueueError{Key: "unknown"}}, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			_, err := retryPublish(context.Background(), cfg, "mention", func() (string, error) {
+				err := tt.errs[attempts]
+				attempts++
+				if err != nil {
+					return "", err
+				}
+				return "message-id", nil
+			})
+			if attempts != tt.wantAttempts {
+				t.Errorf("送信回数 = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if want := tt.errs[len(tt.errs)-1]; !errors.Is(err, want) {
+				t.Errorf("retryPublish() error = %v, want %v", err, want)
+			}
+		})
+	}
+}
+
+// 再試行を待っている間に処理の期限を過ぎた場合は、直前の送信エラーと期限切れを返す
+func TestRetryPublishContextDone(t *testing.T) {
+	cfg := config.ElasticMQRetryConfig{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	sendErr := errors.New("throttled")
+	attempts := 0
+	_, err := retryPublish(ctx, cfg, "mention", func() (string, error) {
+		attempts++
+		return "", sendErr
+	})
+	if attempts != 1 {
+		t.Errorf("送信回数 = %d, want 1", attempts)
+	}
+	if !errors.Is(err, sendErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("retryPublish() error = %v, want %v と %v", err, sendErr, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// リアクション処理メソッド
+// 設定された絵文字がメッセージに付けられた場合、そのメッセージをリアクションしたユーザーからの依頼としてキューに送信する
+func (app *SlackBotApp) handleReactionAdded(ctx context.Context, evt *slackevents.ReactionAddedEvent) {
+	cfg := app.AppConfig.Reaction
+	if !cfg.Enabled || !slices.Contains(cfg.Reactions, evt.Reaction) || evt.Item.Type != "message" {
+		return
+	}
+
+	// Bot自身によるリアクションとBotのメッセージへのリアクションは無視する
+	if app.BotUserID != "" && (evt.User == app.BotUserID || evt.ItemUser == app.BotUserID) {
+		return
+	}
+
+	ctx = logger.With(ctx, "channel", evt.Item.Channel, "user", evt.User, "ts", evt.Item.Timestamp)
+
+	// メンションのIDを相関IDとしてログとキューのメッセージに引き継ぐ
+	mentionID, err := app.newReactionMentionID(evt.Item.Channel, evt.Item.Timestamp, evt.User, evt.EventTimestamp)
+	if err != nil {
+		logger.Errorf(ctx, "メンションIDの発行エラー: %v", err)
+		return
+	}
+	ctx, cancel := app.withEventTimeout(logger.WithCorrelationID(ctx, mentionID.String()))
+	defer cancel()
+
+	channelID := evt.Item.Channel
+	// 管理コマンドで一時停止している場合はリアクションによる依頼も受け付けない
+	if app.Maintenance.Paused() {
+		logger.Printf(ctx, "メンテナンス中のためリアクションを無視しました: channel=%s user=%s", channelID, evt.User)
+		return
+	}
+	if reason, denied := app.AccessPolicy.DenyReason(slackmodel.ChannelID(channelID), slackmodel.UserID(evt.User)); denied {
+		logger.Printf(ctx, "アクセス制御によりリアクションを無視しました: channel=%s user=%s reason=%s", channelID, evt.User, reason)
+		app.Metrics.AccessDenied.WithLabelValues(app.Workspace.Name, string(reason)).Inc()
+		return
+	}
+	// 受付時間外のリアクションはメンションと同じく受け付けない（リアクションには返信しない）
+	if !app.isOpen() {
+		logger.Printf(ctx, "受付時間外のためリアクションを無視しました: channel=%s user=%s", channelID, evt.User)
+		return
+	}
+
+	// 同じメッセージへの重複したリアクションは一定時間無視する
+	// キューに送信する前に処理を中止した場合は、再びリアクションされたときに処理できるよう記録を取り消す
+	dedupKey := channelID + ":" + evt.Item.Timestamp
+	if !app.reactionDedup.Add(dedupKey) {
+		logger.Printf(ctx, "処理済みのメッセージへのリアクションのため無視しました: channel=%s ts=%s", channelID, evt.Item.Timestamp)
+		return
+	}
+
+	// メンションと同じユーザーごとのレート制限を適用し、本人にだけ通知する
+	if ok, retryAfter := app.allowMention(channelID, evt.User); !ok {
+		app.reactionDedup.Remove(dedupKey)
+		logger.Printf(ctx, "レート制限によりリアクションを処理しませんでした: channel=%s user=%s", channelID, evt.User)
+		_, err := app.SlackClient.PostEphemeralContext(ctx, channelID, evt.User,
+			slack.MsgOptionText(app.t(ctx, evt.User, "rate_limited", int(math.Ceil(retryAfter.Seconds()))), false),
+		)
+		if err != nil {
+			logger.Errorf(ctx, "返信エラー: %v", err)
+		}
+		return
+	}
+
+	msg, err := app.fetchMessage(ctx, channelID, evt.Item.Timestamp, "")
+	if err != nil {
+		app.reactionDedup.Remove(dedupKey)
+		logger.Errorf(ctx, "リアクション対象のメッセージの取得エラー: %v", err)
+		return
+	}
+	if msg.BotID != "" || msg.SubType == slack.MsgSubTypeBotMessage || msg.Text == "" {
+		return
+	}
+
+	threadTS := msg.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = msg.Timestamp
+	}
+
+	timestamp, eventTime := mentionTimes(ctx, msg.Timestamp, evt.EventTimestamp)
+	mention, err := slackmodel.NewMention(
+		mentionID,
+		slackmodel.MessageSourceReaction,
+		slackmodel.UserID(evt.User),
+		slackmodel.ChannelID(channelID),
+		slackmodel.Text(msg.Text),
+		timestamp,
+		eventTime,
+		nil,
+		app.mentionTextOption(),
+		slackmodel.WithTeamID(slackmodel.TeamID(app.TeamID)),
+		slackmodel.WithWorkspace(slackmodel.Workspace(app.Workspace.Name)),
+		slackmodel.WithSlackTS(msg.Timestamp, msg.ThreadTimestamp),
+		app.channelSettingsOption(channelID),
+		app.userNamesOption(ctx, evt.User),
+	)
+	if err != nil {
+		logger.Errorf(ctx, "リアクションの検証エラー: %v", err)
+		return
+	}
+
+	app.assignConversation(ctx, mention, threadTS)
+
+	queueMsg := queuemodel.NewReactionMessage(mention, evt.Reaction, msg.User)
+	app.applyBroadcastMention(queueMsg)
+	queueMsg.Lang = string(app.userLang(ctx, evt.User))
+	app.Enrichment.Enrich(ctx, queueMsg)
+
+	err = app.enqueueMention(ctx, mention, queueMsg)
+	if err != nil {
+		// 処理の期限を過ぎた場合も返信できるようにする
+		ctx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		logger.Errorf(ctx, "ElasticMQへの送信エラー: %v", err)
+		app.Metrics.EnqueueFailures.WithLabelValues(app.Workspace.Name).Inc()
+		key, _ := enqueueErrorKey(err)
+		app.postThreadReply(ctx, channelID, threadTS, evt.User, app.t(ctx, evt.User, key))
+		return
+	}
+
+	app.Metrics.MentionsEnqueued.WithLabelValues(app.Workspace.Name).Inc()
+	logger.Printf(ctx, "リアクションされたメッセージをキューに送信しました: channel=%s ts=%s reaction=%s", channelID, msg.Timestamp, evt.Reaction)
+}
+
+// Botのメッセージへのリアクション処理メソッド
+// Botが投稿したメッセージ（回答）へのリアクションの追加・削除を、回答へのフィードバックとしてキューに送信する
+func (app *SlackBotApp) handleReactionFeedback(ctx context.Context, action string, evt *slackevents.ReactionAddedEvent) {
+	cfg := app.AppConfig.Feedback
+	if !cfg.Enabled || evt.Item.Type != "message" {
+		return
+	}
+	if app.BotUserID == "" || evt.ItemUser != app.BotUserID || evt.User == app.BotUserID {
+		return
+	}
+	if len(cfg.Reactions) > 0 && !slices.Contains(cfg.Reactions, evt.Reaction) {
+		return
+	}
+
+	ctx = logger.With(ctx, "channel", evt.Item.Channel, "user", evt.User, "ts", evt.Item.Timestamp)
+	eventTime, err := slackmodel.ParseSlackTimestamp(evt.EventTimestamp)
+	if err != nil {
+		logger.Errorf(ctx, "タイムスタンプの変換エラー: %v", err)
+	}
+	msg := queuemodel.NewFeedbackMessage(action, evt.Reaction, evt.User, evt.Item.Channel, evt.Item.Timestamp, evt.ItemUser, eventTime)
+	msg.TeamID = app.TeamID
+	msg.Workspace = app.Workspace.Name
+
+	if err := app.Publisher.PublishTo(ctx, app.queueKey(config.QueueKeyFeedback), msg); err != nil {
+		logger.Errorf(ctx, "フィードバックの送信エラー: channel=%s ts=%s reaction=%s: %v", evt.Item.Channel, evt.Item.Timestamp, evt.Reaction, err)
+		app.Metrics.EnqueueFailures.WithLabelValues(app.Workspace.Name).Inc()
+		return
+	}
+	logger.Printf(ctx, "フィードバックをキューに送信しました: action=%s channel=%s ts=%s reaction=%s user=%s", action, evt.Item.Channel, evt.Item.Timestamp, evt.Reaction, evt.User)
+}
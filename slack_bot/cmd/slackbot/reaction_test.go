@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/cache"
+)
+
+// testReactionEvent はテストで受信する依頼用のリアクションのイベントと、リアクションされたメッセージ
+func testReactionEvent() (*slackevents.ReactionAddedEvent, slack.Message) {
+	evt := &slackevents.ReactionAddedEvent{
+		Type:     "reaction_added",
+		User:     "U001",
+		Reaction: "robot_face",
+		ItemUser: "U002",
+		Item: slackevents.Item{
+			Type:      "message",
+			Channel:   "C001",
+			Timestamp: "1712311200.000100",
+		},
+		EventTimestamp: "1712311260.000100",
+	}
+	msg := slack.Message{Msg: slack.Msg{User: "U002", Text: "このエラーの原因を教えてください", Timestamp: "1712311200.000100"}}
+	return evt, msg
+}
+
+// newTestReactionApp はリアクションによる依頼を受け付けるアプリケーションを作成する
+func newTestReactionApp(t *testing.T, publisher *fakePublisher) (*SlackBotApp, *fakeSlackAPI) {
+	t.Helper()
+	cfg := testAppConfig()
+	cfg.Reaction.Enabled = true
+	cfg.Reaction.Reactions = []string{"robot_face"}
+	return newTestApp(t, cfg, publisher)
+}
+
+// リアクションによる依頼も受付時間外は受け付けない（返信はしない）
+func TestHandleReactionAddedOfficeHours(t *testing.T) {
+	officeHours, err := slackmodel.ParseOfficeHours("Asia/Tokyo", map[string][]string{"monday": {"09:00-18:00"}})
+	if err != nil {
+		t.Fatalf("ParseOfficeHours() error = %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		wantPublished int
+	}{
+		{name: "受付時間内はキューに送信する", now: time.Date(2024, 4, 1, 12, 0, 0, 0, tokyo), wantPublished: 1},
+		{name: "受付時間外は送信しない", now: time.Date(2024, 4, 1, 8, 0, 0, 0, tokyo), wantPublished: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakePublisher{}
+			app, api := newTestReactionApp(t, publisher)
+			app.OfficeHours = officeHours
+			app.now = func() time.Time { return tt.now }
+			evt, msg := testReactionEvent()
+			api.messages = []slack.Message{msg}
+
+			app.handleReactionAdded(context.Background(), evt)
+
+			if got := len(publisher.messages()); got != tt.wantPublished {
+				t.Errorf("キューへの送信 = %d件, want %d件", got, tt.wantPublished)
+			}
+			if got := len(api.callsTo("chat.postMessage")); got != 0 {
+				t.Errorf("chat.postMessage の呼び出し = %d回, want 0回", got)
+			}
+		})
+	}
+}
+
+// メッセージの取得に失敗したリアクションは処理済みとして記録せず、同じメッセージへの再度のリアクションを処理する
+func TestHandleReactionAddedRetriesAfterFetchFailure(t *testing.T) {
+	publisher := &fakePublisher{}
+	app, api := newTestReactionApp(t, publisher)
+	evt, msg := testReactionEvent()
+	api.messages = []slack.Message{msg}
+
+	api.mu.Lock()
+	api.fail["conversations.history"] = true
+	api.mu.Unlock()
+	app.handleReactionAdded(context.Background(), evt)
+	if got := len(publisher.messages()); got != 0 {
+		t.Fatalf("取得に失敗した場合のキューへの送信 = %d件, want 0件", got)
+	}
+
+	api.mu.Lock()
+	api.fail["conversations.history"] = false
+	api.mu.Unlock()
+	app.handleReactionAdded(context.Background(), evt)
+	if got := len(publisher.messages()); got != 1 {
+		t.Fatalf("再度のリアクションでのキューへの送信 = %d件, want 1件", got)
+	}
+
+	// 送信したメッセージへの重複したリアクションは無視する
+	app.handleReactionAdded(context.Background(), evt)
+	if got := len(publisher.messages()); got != 1 {
+		t.Errorf("重複したリアクションでのキューへの送信 = %d件, want 1件", got)
+	}
+}
+
+// リアクションによる依頼もメンションと同じユーザーごとのレート制限を適用し、本人にだけ通知する
+func TestHandleReactionAddedRateLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		exempt        []string
+		wantPublished int
+		wantEphemeral int
+	}{
+		{name: "上限を超えたリアクションは送信しない", wantPublished: 1, wantEphemeral: 1},
+		{name: "制限しないチャンネルでは上限を超えても送信する", exempt: []string{"C001"}, wantPublished: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakePublisher{}
+			app, api := newTestReactionApp(t, publisher)
+			app.rateLimiter = cache.NewRateLimiter(1, time.Minute)
+			app.AppConfig.RateLimit.ExemptChannels = tt.exempt
+			first, msg := testReactionEvent()
+			second, other := testReactionEvent()
+			second.Item.Timestamp = "1712311300.000100"
+			other.Timestamp = second.Item.Timestamp
+			api.messages = []slack.Message{msg, other}
+
+			app.handleReactionAdded(context.Background(), first)
+			app.handleReactionAdded(context.Background(), second)
+
+			if got := len(publisher.messages()); got != tt.wantPublished {
+				t.Errorf("キューへの送信 = %d件, want %d件", got, tt.wantPublished)
+			}
+			ephemeral := api.callsTo("chat.postEphemeral")
+			if len(ephemeral) != tt.wantEphemeral {
+				t.Fatalf("chat.postEphemeral の呼び出し = %d回, want %d回", len(ephemeral), tt.wantEphemeral)
+			}
+			if tt.wantEphemeral > 0 && ephemeral[0].Form.Get("user") != "U001" {
+				t.Errorf("通知先 = %q, want %q", ephemeral[0].Form.Get("user"), "U001")
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/bootstrap"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+// runReplay はキューに送信できずに failed_mentions テーブルに保存したメッセージを古い順に再送する
+// 再送に失敗したメッセージは残るため、原因を取り除いてから再度実行する
+// メンションのID（ULID）を指定した場合は、保存したメンションからメッセージを作り直して1件だけ送信する（AIワーカーの調査用）
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	limit := fs.Int("limit", 100, "再送するメッセージの最大件数（メンションのIDを指定しない場合）")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "使い方: replay [flags] [mention-id]\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("メンションのIDは1件だけ指定してください")
+	}
+	var mentionID ulid.ULID
+	if fs.NArg() == 1 {
+		id, err := ulid.ParseStrict(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("メンションのIDが不正です: %q: %w", fs.Arg(0), err)
+		}
+		mentionID = id
+	}
+	if *limit <= 0 {
+		return fmt.Errorf("-limit には正の値を指定してください: %d", *limit)
+	}
+
+	var cfg *config.AppConfig
+	var replayer *usecase.DeadLetterReplayer
+	var mentionReplayer *usecase.MentionReplayer
+	app := bootstrap.NewApp(fx.NopLogger, bootstrap.ReplayModule, fx.Populate(&cfg, &replayer, &mentionReplayer))
+	if err := app.Err(); err != nil {
+		return err
+	}
+	if !cfg.Database.Enabled {
+		return fmt.Errorf("データベースが無効です (database.enabled)")
+	}
+	ctx := context.Background()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	defer app.Stop(ctx)
+
+	if mentionID != (ulid.ULID{}) {
+		messageID, err := mentionReplayer.Replay(ctx, mentionID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("メンション %s を再送しました: message_id=%s\n", mentionID, messageID)
+		return nil
+	}
+
+	replayed, failed, err := replayer.Replay(ctx, *limit)
+	fmt.Printf("再送しました: 成功 %d 件, 失敗 %d 件\n", replayed, failed)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 件のメッセージを再送できませんでした", failed)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/blockkit"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+// AIワーカーが回答キューに送信するメッセージ
+type responseMessage struct {
+	// Type は answer（回答、省略可能）、progress（途中経過）または draft（承認を待つ回答の下書き）
+	Type string `json:"type"`
+	// TeamID は投稿先のワークスペースのID（ワークスペースが1つの場合は省略可能）
+	TeamID        string `json:"team_id"`
+	Channel       string `json:"channel"`
+	ThreadTS      string `json:"thread_ts"`
+	Text          string `json:"text"`
+	CorrelationID string `json:"correlation_id"`
+	// MentionID は回答したメンションのID（キューのメッセージの id）。省略した場合は相関IDをメンションのIDとして扱う
+	MentionID string `json:"mention_id"`
+	// Stage は途中経過の段階（"検索中", "回答を生成中" など）
+	Stage string `json:"stage"`
+	// Sources は回答の根拠とした資料（answer_actions.enabled の場合に「根拠を表示」で表示する）
+	Sources []responseSource `json:"sources"`
+}
+
+// responseSource は回答の根拠とした資料
+type responseSource struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// 回答キューのコンシューマーをアプリケーションのライフサイクルに合わせて起動・停止する
+func startResponseConsumer(lc fx.Lifecycle, apps SlackBotApps, consumer *queue.SQSConsumer) {
+	if consumer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				consumer.Run(ctx, apps.handleResponse)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
+
+// AIワーカーの回答を元のワークスペースのチャンネル・スレッドに投稿するメソッド
+// 形式が不正なメッセージは再試行しても処理できないため、ログに出力して破棄する
+func (apps SlackBotApps) handleResponse(ctx context.Context, body []byte) error {
+	var res responseMessage
+	if err := json.Unmarshal(body, &res); err != nil {
+		logger.Warnf(ctx, "回答メッセージのパースエラー（破棄します）: %v", err)
+		return nil
+	}
+	ctx = logger.WithCorrelationID(ctx, res.CorrelationID)
+	app, ok := apps.ForTeam(res.TeamID)
+	if !ok {
+		logger.Warnf(ctx, "回答メッセージの team_id に対応するワークスペースがありません（破棄します）: team_id=%s", res.TeamID)
+		return nil
+	}
+
+	// 回答キューのメッセージ属性からワーカーのトレースを引き継ぐ
+	ctx, span := app.Tracer.Start(ctx, "slack.response",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("slack.channel", res.Channel),
+			attribute.String("response.type", res.Type),
+			attribute.String("correlation_id", res.CorrelationID),
+		),
+	)
+	defer span.End()
+
+	var err error
+	switch res.Type {
+	case "", responseTypeAnswer:
+		err = app.postResponse(ctx, res)
+	case responseTypeProgress:
+		app.updateProgress(ctx, res)
+	case responseTypeDraft:
+		err = app.postDraft(ctx, res)
+	default:
+		logger.Warnf(ctx, "回答メッセージの type が不正です（破棄します）: type=%s", res.Type)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// 回答を投稿し、途中経過の表示を削除するメソッド
+func (app *SlackBotApp) postResponse(ctx context.Context, res responseMessage) error {
+	if res.Channel == "" || res.Text == "" {
+		logger.Warnf(ctx, "回答メッセージに channel または text がありません（破棄します）")
+		return nil
+	}
+
+	options := []slack.MsgOption{
+		slack.MsgOptionText(res.Text, false),
+		correlationMetadata(ctx),
+	}
+	if app.AppConfig.AnswerActions.Enabled {
+		blocks, err := app.answerBlocks(res)
+		if err != nil {
+			// ボタンを付けられない場合も回答はテキストで投稿する
+			logger.Errorf(ctx, "回答のブロックの作成エラー（テキストのみ投稿します）: %v", err)
+		} else {
+			options = append(options, slack.MsgOptionBlocks(blocks...))
+		}
+	}
+	if res.ThreadTS != "" {
+		options = append(options, slack.MsgOptionTS(res.ThreadTS))
+	}
+	_, ts, err := app.postMessage(ctx, res.Channel, options...)
+	if err != nil {
+		return fmt.Errorf("回答の投稿エラー: %w", err)
+	}
+
+	logger.Printf(ctx, "回答を投稿しました: channel=%s thread_ts=%s", res.Channel, res.ThreadTS)
+	app.markAnswered(ctx, res)
+	recordConversationMessage(ctx, app.Conversations, res.Channel, res.ThreadTS, ts, slackmodel.ConversationRoleAssistant, "", res.Text)
+
+	// 回答は投稿済みのため、途中経過の削除に失敗しても再試行しない
+	if app.progress != nil {
+		if err := app.progress.Done(ctx, res.Channel); err != nil {
+			logger.Errorf(ctx, "%v", err)
+		}
+	}
+	return nil
+}
+
+// 回答の本文と「再生成」「根拠を表示」「削除」のボタンのブロックを作成するメソッド
+func (app *SlackBotApp) answerBlocks(res responseMessage) ([]slack.Block, error) {
+	mentionID := res.MentionID
+	if mentionID == "" {
+		mentionID = res.CorrelationID
+	}
+	sources := make([]slackmodel.AnswerSource, 0, len(res.Sources))
+	for _, source := range res.Sources {
+		sources = append(sources, slackmodel.AnswerSource{Title: source.Title, URL: source.URL})
+	}
+	answer, err := slackmodel.NewAnswer(mentionID, slackmodel.Text(res.Text), sources)
+	if err != nil {
+		return nil, err
+	}
+	return blockkit.AnswerBlocks(answer, app.answerLabels())
+}
+
+// 回答したメンションの状態を answered にするメソッド
+// メンションのIDが含まれていない・保存されていない場合は何もしない
+func (app *SlackBotApp) markAnswered(ctx context.Context, res responseMessage) {
+	if app.MentionCommand == nil {
+		return
+	}
+	mentionID := res.MentionID
+	if mentionID == "" {
+		mentionID = res.CorrelationID
+	}
+	id, err := ulid.ParseStrict(mentionID)
+	if err != nil {
+		return
+	}
+	err = app.MentionCommand.UpdateStatus(ctx, slackmodel.MentionID(id), string(slackmodel.MentionStatusAnswered), "")
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf(ctx, "メンションの状態の更新エラー (%s): %v", slackmodel.MentionStatusAnswered, err)
+	}
+}
+
+// 途中経過の表示を更新するメソッド
+// 途中経過は次の途中経過か回答で置き換わるため、失敗しても再試行せずにログに出力するのみ
+func (app *SlackBotApp) updateProgress(ctx context.Context, res responseMessage) {
+	if app.progress == nil {
+		logger.Warnf(ctx, "途中経過の表示が無効なため破棄しました")
+		return
+	}
+	if res.Channel == "" || res.Stage == "" {
+		logger.Warnf(ctx, "途中経過のメッセージに channel または stage がありません（破棄します）")
+		return
+	}
+	if err := app.progress.Update(ctx, res.Channel, res.ThreadTS, res.Stage); err != nil {
+		logger.Errorf(ctx, "%v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/bootstrap"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"go.uber.org/fx"
+)
+
+// shutdownGracePeriod は停止時に処理中のイベントを待つ時間（event_workers.drain_timeout）に加えて、
+// Webhookサーバー・キューへの送信・データベースの接続などの停止を待つ時間
+const shutdownGracePeriod = 15 * time.Second
+
+// serveModule は serve コマンドでSlackに接続するアプリケーションと、その起動時に開始する処理
+var serveModule = fx.Options(
+	SlashCommandModule,
+	fx.Provide(NewSlackBotApps),
+	fx.Invoke(startResponseConsumer),
+	fx.Invoke(startWebhookServer),
+	fx.Invoke(startDigest),
+	fx.Invoke(func(apps SlackBotApps, l *slog.Logger) {
+		// 依存性の注入が完了したことを確認するだけ
+		l.Info("Slack Botのアプリケーションを作成しました", "workspaces", len(apps))
+	}),
+)
+
+// runServe はSlackに接続し、停止されるまでメンションを処理する
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var cfg *config.AppConfig
+	var l *slog.Logger
+	app := bootstrap.NewApp(bootstrap.CommandModule, serveModule, fx.Populate(&cfg, &l))
+	if err := app.Err(); err != nil {
+		return err
+	}
+	return runUntilStopped(app, l, cfg.EventWorkers.DrainTimeout+shutdownGracePeriod)
+}
+
+// runUntilStopped はアプリケーションを起動し、シグナルまたは Shutdown で停止されるまで待つ
+// fx.App.Run と異なり、処理中のイベントを待てるよう停止の期限に stopTimeout を使用する
+func runUntilStopped(app *fx.App, l *slog.Logger, stopTimeout time.Duration) error {
+	startCtx, cancel := context.WithTimeout(context.Background(), app.StartTimeout())
+	defer cancel()
+	if err := app.Start(startCtx); err != nil {
+		return err
+	}
+
+	signal := <-app.Wait()
+	if signal.Signal != nil {
+		l.Info("シグナルを受信したため停止します", "signal", signal.Signal)
+	} else {
+		l.Info("停止処理を開始します", "exit_code", signal.ExitCode)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := app.Stop(stopCtx); err != nil {
+		return fmt.Errorf("停止処理エラー: %w", err)
+	}
+	if signal.ExitCode != 0 {
+		return fmt.Errorf("終了コード %d で停止しました", signal.ExitCode)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+const (
+	// shortcutModalCallbackID はメッセージショートカットで開くモーダルの Callback ID
+	shortcutModalCallbackID = "ask_ai_modal"
+	// shortcutMessageBlockID, shortcutQuestionBlockID はモーダルの入力欄のブロックID（アクションIDも同じ値を使用する）
+	shortcutMessageBlockID  = "ask_ai_message"
+	shortcutQuestionBlockID = "ask_ai_question"
+	// shortcutMaxInputLength はモーダルの入力欄に設定できる最大文字数（Slackの上限）
+	shortcutMaxInputLength = 3000
+)
+
+// shortcutTarget はモーダルの private_metadata に保存する、ショートカットを実行したメッセージ
+type shortcutTarget struct {
+	Channel  string `json:"channel"`
+	TS       string `json:"ts"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+}
+
+// 回答を投稿するスレッド（スレッド内のメッセージの場合はそのスレッド、それ以外は元のメッセージ）
+func (t shortcutTarget) threadTS() string {
+	if t.ThreadTS != "" {
+		return t.ThreadTS
+	}
+	return t.TS
+}
+
+// メッセージショートカットの処理メソッド
+// 選択したメッセージのテキストと質問の入力欄を表示するモーダルを開く
+func (app *SlackBotApp) handleMessageShortcut(callback slack.InteractionCallback) {
+	if !app.AppConfig.Shortcut.Enabled || callback.CallbackID != app.AppConfig.Shortcut.CallbackID {
+		return
+	}
+	ctx := logger.With(context.Background(), "workspace", app.Workspace.Name, "channel", callback.Channel.ID, "user", callback.User.ID, "ts", callback.Message.Timestamp)
+
+	metadata, err := json.Marshal(shortcutTarget{
+		Channel:  callback.Channel.ID,
+		TS:       callback.Message.Timestamp,
+		ThreadTS: callback.Message.ThreadTimestamp,
+	})
+	if err != nil {
+		logger.Errorf(ctx, "ショートカットの対象のエンコードエラー: %v", err)
+		return
+	}
+
+	view := app.shortcutModal(ctx, callback.User.ID, callback.Message.Text, string(metadata))
+	if _, err := app.SlackClient.OpenViewContext(ctx, callback.TriggerID, view); err != nil {
+		logger.Errorf(ctx, "ショートカットのモーダルを開けませんでした: user=%s channel=%s: %v", callback.User.ID, callback.Channel.ID, err)
+	}
+}
+
+// メッセージショートカットのモーダルを作成するメソッド
+// 対象のメッセージは編集できる入力欄に表示し、送信時の値をAIに送信する
+func (app *SlackBotApp) shortcutModal(ctx context.Context, userID, text, metadata string) slack.ModalViewRequest {
+	plainText := func(key string) *slack.TextBlockObject {
+		return slack.NewTextBlockObject(slack.PlainTextType, app.t(ctx, userID, key), false, false)
+	}
+
+	messageInput := slack.NewPlainTextInputBlockElement(nil, shortcutMessageBlockID)
+	messageInput.Multiline = true
+	messageInput.InitialValue = truncateRunes(text, shortcutMaxInputLength)
+	messageInput.MaxLength = shortcutMaxInputLength
+	messageBlock := slack.NewInputBlock(shortcutMessageBlockID, plainText("shortcut.message_label"), nil, messageInput)
+	messageBlock.Optional = true
+
+	questionInput := slack.NewPlainTextInputBlockElement(plainText("shortcut.question_placeholder"), shortcutQuestionBlockID)
+	questionInput.Multiline = true
+	questionInput.MaxLength = shortcutMaxInputLength
+	// 空の質問はBotが検証してモーダルにエラーを表示する（interactionResponse）
+	questionBlock := slack.NewInputBlock(shortcutQuestionBlockID, plainText("shortcut.question_label"), nil, questionInput)
+	questionBlock.Optional = true
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      shortcutModalCallbackID,
+		Title:           plainText("shortcut.title"),
+		Submit:          plainText("shortcut.submit"),
+		Close:           plainText("shortcut.close"),
+		PrivateMetadata: metadata,
+		Blocks:          slack.Blocks{BlockSet: []slack.Block{messageBlock, questionBlock}},
+	}
+}
+
+// インタラクションに応答で返す内容を作成するメソッド
+// モーダルの入力エラーは response_action: errors で返し、モーダルを閉じずに表示する。応答が不要な場合は nil を返す
+func (app *SlackBotApp) interactionResponse(callback slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	if callback.Type != slack.InteractionTypeViewSubmission || callback.View.CallbackID != shortcutModalCallbackID {
+		return nil
+	}
+	if strings.TrimSpace(viewInputValue(callback.View, shortcutQuestionBlockID)) == "" {
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			shortcutQuestionBlockID: app.t(context.Background(), callback.User.ID, "shortcut.question_required"),
+		})
+	}
+	return nil
+}
+
+// メッセージショートカットのモーダルの送信を処理するメソッド
+// 質問と対象のメッセージをまとめてメンションとして保存・キューに送信し、回答は元のメッセージのスレッドに投稿される
+func (app *SlackBotApp) handleShortcutSubmission(callback slack.InteractionCallback) {
+	if callback.View.CallbackID != shortcutModalCallbackID {
+		return
+	}
+	ctx := logger.With(context.Background(), "workspace", app.Workspace.Name, "user", callback.User.ID)
+	var target shortcutTarget
+	if err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &target); err != nil || target.Channel == "" {
+		logger.Errorf(ctx, "ショートカットの対象のデコードエラー: %v", err)
+		return
+	}
+	ctx = logger.With(ctx, "channel", target.Channel, "ts", target.TS)
+	userID := callback.User.ID
+	question := strings.TrimSpace(viewInputValue(callback.View, shortcutQuestionBlockID))
+	message := viewInputValue(callback.View, shortcutMessageBlockID)
+
+	// 同じメッセージに何度でも質問できるよう、IDはイベントから導出しない
+	mentionID, err := slackmodel.NewMentionID()
+	if err != nil {
+		logger.Errorf(ctx, "メンションIDの発行エラー: %v", err)
+		return
+	}
+	ctx, cancel := app.withEventTimeout(logger.WithCorrelationID(ctx, mentionID.String()))
+	defer cancel()
+	threadTS := target.threadTS()
+
+	if app.Maintenance.Paused() {
+		logger.Printf(ctx, "メンテナンス中のためショートカットを処理しませんでした: channel=%s user=%s", target.Channel, userID)
+		app.postThreadReply(ctx, target.Channel, threadTS, userID, app.t(ctx, userID, "maintenance.paused"))
+		return
+	}
+	if reason, denied := app.AccessPolicy.DenyReason(slackmodel.ChannelID(target.Channel), slackmodel.UserID(userID)); denied {
+		logger.Printf(ctx, "アクセス制御によりショートカットを拒否しました: channel=%s user=%s reason=%s", target.Channel, userID, reason)
+		app.Metrics.AccessDenied.WithLabelValues(app.Workspace.Name, string(reason)).Inc()
+		if app.AppConfig.AccessControl.NotifyDenied {
+			app.postThreadReply(ctx, target.Channel, threadTS, userID, app.t(ctx, userID, "access.denied"))
+		}
+		return
+	}
+
+	timestamp, _ := mentionTimes(ctx, target.TS, "")
+	rawEvent, err := json.Marshal(callback)
+	if err != nil {
+		logger.Errorf(ctx, "イベントのJSON変換エラー（保存せずに続行します）: %v", err)
+	}
+	mention, err := slackmodel.NewMention(
+		mentionID,
+		slackmodel.MessageSourceShortcut,
+		slackmodel.UserID(userID),
+		slackmodel.ChannelID(target.Channel),
+		slackmodel.Text(shortcutText(question, message)),
+		timestamp,
+		slackmodel.EventTime(time.Now()),
+		nil,
+		app.mentionTextOption(),
+		slackmodel.WithTeamID(slackmodel.TeamID(app.TeamID)),
+		slackmodel.WithWorkspace(slackmodel.Workspace(app.Workspace.Name)),
+		slackmodel.WithRawEvent(slackmodel.RawEvent(rawEvent)),
+		slackmodel.WithSlackTS(target.TS, target.ThreadTS),
+		app.channelSettingsOption(target.Channel),
+		app.userNamesOption(ctx, userID),
+	)
+	if err != nil {
+		logger.Errorf(ctx, "ショートカットの検証エラー: %v", err)
+		return
+	}
+
+	app.assignConversation(ctx, mention, threadTS)
+
+	msg := queuemodel.NewMentionMessage(mention, nil)
+	msg.Lang = string(app.userLang(ctx, userID))
+	app.Enrichment.Enrich(ctx, msg)
+
+	if err := app.enqueueMention(ctx, mention, msg); err != nil {
+		ctx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		logger.Errorf(ctx, "ElasticMQへの送信エラー: %v", err)
+		app.Metrics.EnqueueFailures.WithLabelValues(app.Workspace.Name).Inc()
+		key, _ := enqueueErrorKey(err)
+		app.postThreadReply(ctx, target.Channel, threadTS, userID, app.t(ctx, userID, key))
+		return
+	}
+	app.Metrics.MentionsEnqueued.WithLabelValues(app.Workspace.Name).Inc()
+	logger.Printf(ctx, "ショートカットの質問をキューに送信しました: channel=%s ts=%s", target.Channel, target.TS)
+}
+
+// モーダルの入力欄の値を返す（ブロックIDとアクションIDは同じ値を使用している）
+func viewInputValue(view slack.View, blockID string) string {
+	if view.State == nil {
+		return ""
+	}
+	return view.State.Values[blockID][blockID].Value
+}
+
+// 質問と対象のメッセージを1つのテキストにまとめる
+// 対象のメッセージは質問の後に引用（> ）として続ける
+func shortcutText(question, message string) string {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return question
+	}
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return question + "\n\n" + strings.Join(lines, "\n")
+}
+
+// 文字列を最大 n 文字（rune数）に切り詰める
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
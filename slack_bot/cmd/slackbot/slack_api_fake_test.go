@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/i18n"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/cache"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// slackCall はフェイクのSlack APIが受け付けた1回の呼び出し
+type slackCall struct {
+	Method string
+	Form   url.Values
+}
+
+// fakeSlackAPI はSlack Web APIの呼び出しを記録し、成功の応答を返すHTTPサーバー
+// messages に設定したメッセージを conversations.history / conversations.replies で返す
+type fakeSlackAPI struct {
+	mu       sync.Mutex
+	calls    []slackCall
+	messages []slack.Message
+	// fail に含まれるメソッドは ok=false を返す
+	fail map[string]bool
+	// userLocale は users.info で返すユーザーの言語設定
+	userLocale string
+}
+
+func newFakeSlackAPI(t *testing.T) (*fakeSlackAPI, *slack.Client) {
+	t.Helper()
+	api := &fakeSlackAPI{fail: map[string]bool{}}
+	server := httptest.NewServer(http.HandlerFunc(api.serveHTTP))
+	t.Cleanup(server.Close)
+	return api, slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))
+}
+
+func (api *fakeSlackAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := strings.TrimPrefix(r.URL.Path, "/")
+
+	api.mu.Lock()
+	api.calls = append(api.calls, slackCall{Method: method, Form: r.Form})
+	failed := api.fail[method]
+	messages := api.messages
+	userLocale := api.userLocale
+	api.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if failed {
+		fmt.Fprint(w, `{"ok":false,"error":"fake_error"}`)
+		return
+	}
+	var res any
+	switch method {
+	case "users.info":
+		res = map[string]any{"ok": true, "user": map[string]any{"id": r.Form.Get("user"), "real_name": "テストユーザー", "locale": userLocale}}
+	case "conversations.history", "conversations.replies":
+		res = map[string]any{"ok": true, "messages": messages}
+	case "chat.postMessage":
+		res = map[string]any{"ok": true, "channel": r.Form.Get("channel"), "ts": "1712311999.000100"}
+	default:
+		res = map[string]any{"ok": true}
+	}
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// callsTo は指定したメソッドの呼び出しを返す
+func (api *fakeSlackAPI) callsTo(method string) []slackCall {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	var calls []slackCall
+	for _, c := range api.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// fakePublisher はキューへの送信を記録する QueuePublisher
+// block が true の場合はコンテキストが終了するまで送信を止める
+type fakePublisher struct {
+	di.QueuePublisher
+	mu        sync.Mutex
+	published []*queuemodel.MentionMessage
+	block     bool
+	err       error
+}
+
+func (p *fakePublisher) PublishWithID(ctx context.Context, queueKey string, msg any) (string, error) {
+	if p.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published = append(p.published, msg.(*queuemodel.MentionMessage))
+	return fmt.Sprintf("message-%d", len(p.published)), nil
+}
+
+func (p *fakePublisher) messages() []*queuemodel.MentionMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*queuemodel.MentionMessage(nil), p.published...)
+}
+
+// testAppConfig はメンションの処理に必要な最小限の設定を返す
+func testAppConfig() *config.AppConfig {
+	return &config.AppConfig{
+		EventWorkers: config.EventWorkersConfig{Timeout: 10 * time.Second},
+		ElasticMQ:    config.ElasticMQConfig{Retry: config.ElasticMQRetryConfig{MaxAttempts: 1}},
+		Mention:      config.MentionConfig{MaxTextLength: 4000},
+	}
+}
+
+// newTestApp はフェイクのSlack APIとキューを使うアプリケーションを作成する
+// データベースなどの任意の依存先は nil のため、必要なテストで設定する
+func newTestApp(t *testing.T, cfg *config.AppConfig, publisher di.QueuePublisher) (*SlackBotApp, *fakeSlackAPI) {
+	t.Helper()
+	api, client := newFakeSlackAPI(t)
+	translator, err := i18n.NewTranslator("ja")
+	if err != nil {
+		t.Fatalf("NewTranslator() error = %v", err)
+	}
+	enrichment, err := usecase.NewEnrichmentPipeline(nil)
+	if err != nil {
+		t.Fatalf("NewEnrichmentPipeline() error = %v", err)
+	}
+	app := &SlackBotApp{
+		SlackClient:   client,
+		AppConfig:     cfg,
+		Workspace:     config.SlackBotConfig{Name: "default"},
+		AccessPolicy:  slackmodel.NewAccessPolicy(nil, nil, nil, nil, true),
+		Translator:    translator,
+		Enrichment:    enrichment,
+		Tracer:        noop.NewTracerProvider().Tracer(tracerName),
+		Metrics:       metrics.NewMetrics(prometheus.NewRegistry()),
+		Publisher:     publisher,
+		Maintenance:   usecase.NewMaintenance(),
+		BotUserID:     "UBOT",
+		TeamID:        "T001",
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		reactionDedup: cache.NewTTLSet(time.Minute, 0),
+		localeCache:   cache.NewTTLCache[string](time.Minute, 0),
+		userNames:     cache.NewTTLCache[userNames](time.Minute, 0),
+		infoLimiter:   cache.NewTokenBucket(time.Millisecond, 10),
+		now:           time.Now,
+	}
+	return app, api
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+)
+
+// NewSlackClient はワークスペースのSlackクライアントを作成する
+// デバッグログは slack_bot.debug（workspaces[].debug）が有効な場合、または log.level が debug の場合に l に出力する
+func NewSlackClient(l *slog.Logger, workspace config.SlackBotConfig) *slack.Client {
+	debug, debugLog := slackDebugLogger(l, workspace, "slack")
+	return slack.New(
+		workspace.BotToken,
+		slack.OptionAppLevelToken(workspace.AppToken), // Socketモードに必要なAppトークンを設定
+		slack.OptionDebug(debug),
+		slack.OptionLog(debugLog),
+	)
+}
+
+// NewSocketModeClient はワークスペースのSocketModeクライアントを作成する
+func NewSocketModeClient(l *slog.Logger, api *slack.Client, workspace config.SlackBotConfig) *socketmode.Client {
+	debug, debugLog := slackDebugLogger(l, workspace, "socketmode")
+	return socketmode.New(
+		api,
+		socketmode.OptionDebug(debug),
+		socketmode.OptionLog(debugLog),
+	)
+}
+
+// Slackクライアントのデバッグログを出力するかどうかと出力先を返す
+// slack_bot.debug で明示的に有効にした場合は log.level に関係なく info レベルで出力する
+func slackDebugLogger(l *slog.Logger, workspace config.SlackBotConfig, component string) (bool, *log.Logger) {
+	level := slog.LevelDebug
+	if workspace.Debug {
+		level = slog.LevelInfo
+	}
+	l = l.With("component", component, "workspace", workspace.Name)
+	return l.Enabled(context.Background(), level), slog.NewLogLogger(l.Handler(), level)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+func TestSlackDebugLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     string
+		debug     bool
+		wantDebug bool
+		wantLevel string
+	}{
+		{name: "log.level が info の場合は出力しない", level: "info"},
+		{name: "log.level が debug の場合は debug レベルで出力する", level: "debug", wantDebug: true, wantLevel: "level=DEBUG"},
+		{name: "slack_bot.debug の場合は info レベルで出力する", level: "info", debug: true, wantDebug: true, wantLevel: "level=INFO"},
+		{name: "slack_bot.debug でも log.level より低い場合は出力しない", level: "warn", debug: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l, err := logger.New(&buf, tt.level, "text")
+			if err != nil {
+				t.Fatal(err)
+			}
+			debug, debugLog := slackDebugLogger(l, config.SlackBotConfig{Name: "default", Debug: tt.debug}, "socketmode")
+			if debug != tt.wantDebug {
+				t.Errorf("debug = %v, want %v", debug, tt.wantDebug)
+			}
+			debugLog.Print("Sending pong")
+
+			out := buf.String()
+			if !tt.wantDebug {
+				if out != "" {
+					t.Errorf("出力 = %q, want 出力なし", out)
+				}
+				return
+			}
+			// Slackクライアントのログも構造化ログとしてコンポーネントとワークスペースを付与する
+			for _, want := range []string{tt.wantLevel, "msg=\"Sending pong\"", "component=socketmode", "workspace=default"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("出力 = %q, want %q を含む", out, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/slack-go/slack"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"go.uber.org/fx"
+)
+
+// slashCommandEventType はメトリクス・ワーカーでスラッシュコマンドを表すイベントの種類
+const slashCommandEventType = "slash_command"
+
+// SlashCommandModule はスラッシュコマンドの処理とルーターを提供する
+// コマンドを追加する場合は CommandHandler を実装し、asCommandHandler で登録する
+var SlashCommandModule = fx.Options(
+	fx.Provide(
+		asCommandHandler(newAskCommand),
+		asCommandHandler(newSummarizeCommand),
+		asCommandHandler(newHelpCommand),
+		NewCommandRouter,
+	),
+)
+
+// asCommandHandler はコンストラクタが返す処理を CommandHandler としてルーターに登録するよう注釈を付ける
+func asCommandHandler(constructor any) any {
+	return fx.Annotate(constructor, fx.As(new(CommandHandler)), fx.ResultTags(`group:"slash_commands"`))
+}
+
+// SlashCommandRequest は受信したスラッシュコマンド
+type SlashCommandRequest struct {
+	slack.SlashCommand
+	// ID は受け付けたコマンドのID。相関IDとして使用し、質問をキューに送信する場合はメンションのIDにも使用する
+	ID slackmodel.MentionID
+}
+
+// CommandHandler はスラッシュコマンドの処理
+// エラーを返した場合、ルーターがコマンドを実行したユーザーにだけ見えるメッセージで失敗を通知する
+type CommandHandler interface {
+	// Command はコマンド名（先頭の / を含む。例: /ask）
+	Command() string
+	// Description は /help に表示するコマンドの説明の翻訳キー
+	Description() string
+	Handle(ctx context.Context, app *SlackBotApp, req SlashCommandRequest) error
+}
+
+// CommandRouter はスラッシュコマンドをコマンド名に対応する CommandHandler に振り分ける
+type CommandRouter struct {
+	handlers map[string]CommandHandler
+}
+
+type commandRouterParams struct {
+	fx.In
+
+	Handlers []CommandHandler `group:"slash_commands"`
+}
+
+func NewCommandRouter(p commandRouterParams) (*CommandRouter, error) {
+	handlers := make(map[string]CommandHandler, len(p.Handlers))
+	for _, handler := range p.Handlers {
+		if _, ok := handlers[handler.Command()]; ok {
+			return nil, fmt.Errorf("スラッシュコマンドが重複して登録されています: %s", handler.Command())
+		}
+		handlers[handler.Command()] = handler
+	}
+	return &CommandRouter{handlers: handlers}, nil
+}
+
+// Handler はコマンド名に対応する処理を返す
+func (r *CommandRouter) Handler(command string) (CommandHandler, bool) {
+	handler, ok := r.handlers[command]
+	return handler, ok
+}
+
+// Handlers は登録されている処理をコマンド名の順に返す
+func (r *CommandRouter) Handlers() []CommandHandler {
+	handlers := make([]CommandHandler, 0, len(r.handlers))
+	for _, handler := range r.handlers {
+		handlers = append(handlers, handler)
+	}
+	sort.Slice(handlers, func(i, j int) bool { return handlers[i].Command() < handlers[j].Command() })
+	return handlers
+}
+
+// スラッシュコマンドを登録された処理に振り分けるメソッド
+// 処理が失敗した場合と、登録されていないコマンドの場合は、実行したユーザーにだけ見えるメッセージで返信する
+func (app *SlackBotApp) handleSlashCommand(cmd slack.SlashCommand) {
+	if !app.AppConfig.SlashCommands.Enabled || app.Commands == nil {
+		return
+	}
+	ctx := logger.With(context.Background(), "workspace", app.Workspace.Name, "channel", cmd.ChannelID, "user", cmd.UserID)
+	id, err := slackmodel.NewMentionID()
+	if err != nil {
+		logger.Errorf(ctx, "コマンドのIDの発行エラー: %v", err)
+		return
+	}
+	ctx, cancel := app.withEventTimeout(logger.WithCorrelationID(ctx, id.String()))
+	defer cancel()
+	logger.Printf(ctx, "スラッシュコマンドを受信しました: command=%s channel=%s user=%s", cmd.Command, cmd.ChannelID, cmd.UserID)
+
+	handler, ok := app.Commands.Handler(cmd.Command)
+	if !ok {
+		app.replyCommand(ctx, cmd, app.t(ctx, cmd.UserID, "command.unknown", cmd.Command))
+		return
+	}
+	if err := handler.Handle(ctx, app, SlashCommandRequest{SlashCommand: cmd, ID: id}); err != nil {
+		ctx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		logger.Errorf(ctx, "スラッシュコマンドの処理エラー: command=%s: %v", cmd.Command, err)
+		app.replyCommand(ctx, cmd, app.t(ctx, cmd.UserID, "command.failed", cmd.Command, id.String()))
+	}
+}
+
+// コマンドを実行したユーザーにだけ見えるメッセージを返信するメソッド
+// Botが参加していないチャンネルでも返信できるよう、chat.postEphemeral ではなく response_url に送信する
+func (app *SlackBotApp) replyCommand(ctx context.Context, cmd slack.SlashCommand, text string) {
+	err := slack.PostWebhookContext(ctx, cmd.ResponseURL, &slack.WebhookMessage{
+		Text:         text,
+		ResponseType: slack.ResponseTypeEphemeral,
+	})
+	if err != nil {
+		logger.Errorf(ctx, "コマンドへの返信エラー: %v", err)
+	}
+}
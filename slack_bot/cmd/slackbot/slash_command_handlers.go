@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// askCommand は /ask <質問> で質問を受け付ける
+type askCommand struct{}
+
+func newAskCommand() *askCommand {
+	return &askCommand{}
+}
+
+func (c *askCommand) Command() string     { return "/ask" }
+func (c *askCommand) Description() string { return "command.ask.description" }
+
+func (c *askCommand) Handle(ctx context.Context, app *SlackBotApp, req SlashCommandRequest) error {
+	question := strings.TrimSpace(req.Text)
+	if question == "" {
+		app.replyCommand(ctx, req.SlashCommand, app.t(ctx, req.UserID, "command.ask.usage"))
+		return nil
+	}
+	return app.askInThread(ctx, req, app.t(ctx, req.UserID, "command.ask.posted", req.UserID, question), question, nil)
+}
+
+// summarizeCommand は /summarize でチャンネルの最近の会話の要約を依頼する
+// 会話は thread_context の件数・文字数の上限の範囲で会話履歴として送信する
+type summarizeCommand struct{}
+
+func newSummarizeCommand() *summarizeCommand {
+	return &summarizeCommand{}
+}
+
+func (c *summarizeCommand) Command() string     { return "/summarize" }
+func (c *summarizeCommand) Description() string { return "command.summarize.description" }
+
+func (c *summarizeCommand) Handle(ctx context.Context, app *SlackBotApp, req SlashCommandRequest) error {
+	res, err := app.SlackClient.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+		ChannelID: req.ChannelID,
+		Limit:     app.AppConfig.ThreadContext.MaxMessages,
+	})
+	if err != nil {
+		return fmt.Errorf("チャンネルの会話の取得エラー: %w", err)
+	}
+
+	// conversations.history は新しい順に返すため、古い順に並べ替える
+	var history []queuemodel.HistoryItem
+	for _, msg := range slices.Backward(res.Messages) {
+		if msg.Text == "" {
+			continue
+		}
+		user := msg.User
+		if user == "" {
+			user = msg.BotID
+		}
+		history = append(history, queuemodel.HistoryItem{User: user, Text: msg.Text})
+	}
+	history = trimThreadContext(history, app.AppConfig.ThreadContext.MaxChars)
+	if len(history) == 0 {
+		app.replyCommand(ctx, req.SlashCommand, app.t(ctx, req.UserID, "command.summarize.empty"))
+		return nil
+	}
+	return app.askInThread(ctx, req,
+		app.t(ctx, req.UserID, "command.summarize.posted", req.UserID),
+		app.t(ctx, req.UserID, "command.summarize.prompt"),
+		history,
+	)
+}
+
+// helpCommand は /help で使用できるコマンドの一覧を表示する
+type helpCommand struct{}
+
+func newHelpCommand() *helpCommand {
+	return &helpCommand{}
+}
+
+func (c *helpCommand) Command() string     { return "/help" }
+func (c *helpCommand) Description() string { return "command.help.description" }
+
+func (c *helpCommand) Handle(ctx context.Context, app *SlackBotApp, req SlashCommandRequest) error {
+	lines := []string{app.t(ctx, req.UserID, "command.help.title")}
+	for _, handler := range app.Commands.Handlers() {
+		lines = append(lines, fmt.Sprintf("`%s` %s", handler.Command(), app.t(ctx, req.UserID, handler.Description())))
+	}
+	app.replyCommand(ctx, req.SlashCommand, strings.Join(lines, "\n"))
+	return nil
+}
+
+// コマンドで受け付けた質問をチャンネルに投稿し、そのメッセージのスレッドで回答するようキューに送信するメソッド
+// スラッシュコマンドのテキストはチャンネルに残らないため、rootText を投稿して回答先のスレッドにする
+func (app *SlackBotApp) askInThread(ctx context.Context, req SlashCommandRequest, rootText, text string, history []queuemodel.HistoryItem) error {
+	userID := req.UserID
+	if app.Maintenance.Paused() {
+		logger.Printf(ctx, "メンテナンス中のためコマンドを処理しませんでした: channel=%s user=%s", req.ChannelID, userID)
+		app.replyCommand(ctx, req.SlashCommand, app.t(ctx, userID, "maintenance.paused"))
+		return nil
+	}
+	if reason, denied := app.AccessPolicy.DenyReason(slackmodel.ChannelID(req.ChannelID), slackmodel.UserID(userID)); denied {
+		logger.Printf(ctx, "アクセス制御によりコマンドを拒否しました: channel=%s user=%s reason=%s", req.ChannelID, userID, reason)
+		app.Metrics.AccessDenied.WithLabelValues(app.Workspace.Name, string(reason)).Inc()
+		app.replyCommand(ctx, req.SlashCommand, app.t(ctx, userID, "access.denied"))
+		return nil
+	}
+
+	_, ts, err := app.postMessage(ctx, req.ChannelID,
+		slack.MsgOptionText(rootText, false),
+		correlationMetadata(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("質問の投稿エラー: %w", err)
+	}
+
+	timestamp, _ := mentionTimes(ctx, ts, "")
+	// 検証用のトークンは保存しない
+	cmd := req.SlashCommand
+	cmd.Token = ""
+	rawEvent, err := json.Marshal(cmd)
+	if err != nil {
+		logger.Errorf(ctx, "イベントのJSON変換エラー（保存せずに続行します）: %v", err)
+	}
+	mention, err := slackmodel.NewMention(
+		req.ID,
+		slackmodel.MessageSourceSlashCommand,
+		slackmodel.UserID(userID),
+		slackmodel.ChannelID(req.ChannelID),
+		slackmodel.Text(text),
+		timestamp,
+		slackmodel.EventTime(time.Now()),
+		nil,
+		app.mentionTextOption(),
+		slackmodel.WithTeamID(slackmodel.TeamID(app.TeamID)),
+		slackmodel.WithWorkspace(slackmodel.Workspace(app.Workspace.Name)),
+		slackmodel.WithRawEvent(slackmodel.RawEvent(rawEvent)),
+		slackmodel.WithSlackTS(ts, ""),
+		app.channelSettingsOption(req.ChannelID),
+		app.userNamesOption(ctx, userID),
+	)
+	if err != nil {
+		return fmt.Errorf("質問の検証エラー: %w", err)
+	}
+
+	app.assignConversation(ctx, mention, ts)
+
+	msg := queuemodel.NewMentionMessage(mention, history)
+	msg.Lang = string(app.userLang(ctx, userID))
+	app.Enrichment.Enrich(ctx, msg)
+
+	if err := app.enqueueMention(ctx, mention, msg); err != nil {
+		ctx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		logger.Errorf(ctx, "ElasticMQへの送信エラー: %v", err)
+		app.Metrics.EnqueueFailures.WithLabelValues(app.Workspace.Name).Inc()
+		key, _ := enqueueErrorKey(err)
+		app.postThreadReply(ctx, req.ChannelID, ts, userID, app.t(ctx, userID, key))
+		return nil
+	}
+	app.Metrics.MentionsEnqueued.WithLabelValues(app.Workspace.Name).Inc()
+	recordConversationMessage(ctx, app.Conversations, req.ChannelID, ts, ts, slackmodel.ConversationRoleUser, userID, msg.Text)
+	logger.Printf(ctx, "コマンドの質問をキューに送信しました: command=%s channel=%s ts=%s", req.Command, req.ChannelID, ts)
+	return nil
+}
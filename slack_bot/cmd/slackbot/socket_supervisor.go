@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+// socketModeRunner はSocket Modeの接続を行い、接続が終了するまで戻らない（SocketModeClient.RunContext）
+type socketModeRunner func(ctx context.Context) error
+
+// socketModeSupervisor はSocket Modeの接続が終了した場合に間隔を空けて再接続する
+// 認証エラーなど再接続しても回復しない失敗が続いた場合のみ、アプリケーションを停止する
+type socketModeSupervisor struct {
+	workspace string
+	log       *slog.Logger
+	run       socketModeRunner
+	cfg       config.SocketModeConfig
+	health    *usecase.ConnectionHealth
+	// onFailures は連続した失敗回数が変わるたびに呼び出す（メトリクスの更新）
+	onFailures func(failures int)
+	// shutdown は致命的な失敗が続いた場合に呼び出す
+	shutdown func()
+}
+
+// Run は ctx が終了するまで接続と再接続を繰り返す
+func (s *socketModeSupervisor) Run(ctx context.Context) {
+	attempts := 0
+	fatalFailures := 0
+	for {
+		started := time.Now()
+		// 接続が StableAfter 以上続いた時点で失敗回数を戻す（接続中でも readyz とメトリクスに反映する）
+		stable := time.AfterFunc(s.cfg.StableAfter, func() {
+			s.health.Reset(s.workspace)
+			s.onFailures(0)
+		})
+		err := s.run(ctx)
+		stable.Stop()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(started) >= s.cfg.StableAfter {
+			attempts = 0
+			fatalFailures = 0
+		}
+		if isFatalSocketModeError(err) {
+			fatalFailures++
+		} else {
+			fatalFailures = 0
+		}
+		failures := s.health.RecordFailure(s.workspace)
+		s.onFailures(failures)
+
+		if fatalFailures >= s.cfg.MaxFatalFailures {
+			s.log.Error("Socket Modeの接続が回復しないため停止します", "failures", fatalFailures, "error", err)
+			s.shutdown()
+			return
+		}
+
+		wait := s.backoff(attempts)
+		attempts++
+		s.log.Warn("Socket Modeの接続が終了したため再接続します", "backoff", wait, "failures", failures, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff は attempts 回続けて再接続した後に待つ時間を返す
+func (s *socketModeSupervisor) backoff(attempts int) time.Duration {
+	d := s.cfg.InitialBackoff
+	for i := 0; i < attempts && d < s.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, s.cfg.MaxBackoff)
+}
+
+// isFatalSocketModeError は再接続しても回復しないエラー（トークンの誤り・失効など）かどうかを返す
+// slack-go が再接続せずに RunContext を終了するエラーと同じものを対象にする
+func isFatalSocketModeError(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch err.Error() {
+	case "invalid_auth", "account_inactive", "not_authed", "token_revoked":
+		return true
+	}
+	var statusErr slack.StatusCodeError
+	return errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound
+}
+
+// Socket Modeの接続を監視する処理を作成するメソッド
+// 連続した失敗回数はメトリクスと readyz に反映し、致命的な失敗が続いた場合はアプリケーションを終了コード1で停止する
+func (app *SlackBotApp) newSocketModeSupervisor(health *usecase.ConnectionHealth, shutdowner fx.Shutdowner) *socketModeSupervisor {
+	return &socketModeSupervisor{
+		workspace: app.Workspace.Name,
+		log:       app.Logger,
+		run:       app.SocketModeClient.RunContext,
+		cfg:       app.AppConfig.SocketMode,
+		health:    health,
+		onFailures: func(failures int) {
+			app.Metrics.SocketModeFailures.WithLabelValues(app.Workspace.Name).Set(float64(failures))
+		},
+		shutdown: func() {
+			if err := shutdowner.Shutdown(fx.ExitCode(1)); err != nil {
+				app.Logger.Error("アプリケーションの停止エラー", "error", err)
+			}
+		},
+	}
+}
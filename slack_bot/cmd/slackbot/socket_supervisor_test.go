@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+)
+
+// newTestSocketModeSupervisor は results の順にエラーを返す runner で接続を監視する
+// results を返し終えた後は ctx が終了するまで接続を続ける
+func newTestSocketModeSupervisor(cfg config.SocketModeConfig, results []func() error) (*socketModeSupervisor, func() []int, *bool) {
+	var mu sync.Mutex
+	var failures []int
+	shutdown := false
+	calls := 0
+	s := &socketModeSupervisor{
+		workspace: "default",
+		log:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		cfg:       cfg,
+		health:    usecase.NewConnectionHealth(),
+		run: func(ctx context.Context) error {
+			if calls >= len(results) {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			calls++
+			return results[calls-1]()
+		},
+		// 失敗回数の Reset は接続中に別の goroutine から呼び出される
+		onFailures: func(n int) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, n)
+		},
+		shutdown: func() { shutdown = true },
+	}
+	recorded := func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(failures)
+	}
+	return s, recorded, &shutdown
+}
+
+func failWith(err error) func() error {
+	return func() error { return err }
+}
+
+func TestSocketModeSupervisorBackoff(t *testing.T) {
+	s := &socketModeSupervisor{cfg: config.SocketModeConfig{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Second},
+		{attempts: 1, want: 2 * time.Second},
+		{attempts: 3, want: 8 * time.Second},
+		{attempts: 4, want: 10 * time.Second},
+		{attempts: 100, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := s.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestIsFatalSocketModeError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: false},
+		{err: errors.New("invalid_auth"), want: true},
+		{err: errors.New("token_revoked"), want: true},
+		{err: slack.StatusCodeError{Code: http.StatusNotFound}, want: true},
+		{err: slack.StatusCodeError{Code: http.StatusInternalServerError}, want: false},
+		{err: errors.New("dial tcp: i/o timeout"), want: false},
+	}
+	for _, tt := range tests {
+		if got := isFatalSocketModeError(tt.err); got != tt.want {
+			t.Errorf("isFatalSocketModeError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// 一時的なエラーは再接続を続け、致命的なエラーが MaxFatalFailures 回続いた場合だけ停止する
+func TestSocketModeSupervisorShutsDownAfterFatalFailures(t *testing.T) {
+	network := errors.New("dial tcp: i/o timeout")
+	auth := errors.New("invalid_auth")
+	cfg := config.SocketModeConfig{InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, StableAfter: time.Hour, MaxFatalFailures: 2}
+	s, failures, shutdown := newTestSocketModeSupervisor(cfg, []func() error{
+		failWith(network), failWith(auth), failWith(network), failWith(auth), failWith(auth),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.Run(ctx)
+
+	if !*shutdown {
+		t.Fatal("致命的なエラーが続いたのに停止しませんでした")
+	}
+	if want := []int{1, 2, 3, 4, 5}; !slices.Equal(failures(), want) {
+		t.Errorf("failures = %v, want %v", failures(), want)
+	}
+	if got := s.health.Failures()["default"]; got != 5 {
+		t.Errorf("health failures = %d, want 5", got)
+	}
+}
+
+// 接続が StableAfter 以上続いた場合は失敗回数を戻す
+func TestSocketModeSupervisorResetsAfterStableConnection(t *testing.T) {
+	network := errors.New("dial tcp: i/o timeout")
+	cfg := config.SocketModeConfig{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, StableAfter: 20 * time.Millisecond, MaxFatalFailures: 1}
+	s, failures, shutdown := newTestSocketModeSupervisor(cfg, []func() error{
+		failWith(network),
+		func() error {
+			time.Sleep(50 * time.Millisecond)
+			return network
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+
+	if *shutdown {
+		t.Error("一時的なエラーで停止しました")
+	}
+	// 2回目の接続中に失敗回数を 0 に戻し、切断後は 1 から数え直す。3回目の接続も続いたため再び 0 に戻す
+	if want := []int{1, 0, 1, 0}; !slices.Equal(failures(), want) {
+		t.Errorf("failures = %v, want %v", failures(), want)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import "sync"
+
+// startupGate は起動待ち時間（slack_bot.startup_delay）の間に受信したイベントの処理を保留する
+// イベントにはすぐにACKを返し、保留した処理は待ち時間の経過後に受信順に実行する
+type startupGate struct {
+	mu      sync.Mutex
+	open    bool
+	limit   int
+	pending []func() bool
+}
+
+// newStartupGate は待ち時間中に最大 limit 件の処理を保留するゲートを作成する
+func newStartupGate(limit int) *startupGate {
+	return &startupGate{limit: limit}
+}
+
+// Submit は待ち時間の経過後であれば fn を実行してその結果を返す
+// 待ち時間中は fn を保留して true を返し、保留している処理が limit 件に達している場合は保留せずに false を返す
+func (g *startupGate) Submit(fn func() bool) bool {
+	g.mu.Lock()
+	if g.open {
+		g.mu.Unlock()
+		return fn()
+	}
+	defer g.mu.Unlock()
+	if len(g.pending) >= g.limit {
+		return false
+	}
+	g.pending = append(g.pending, fn)
+	return true
+}
+
+// Open は保留していた処理を受信順に実行し、以降の処理はすぐに実行する
+// 保留していた処理が終わるまで Submit は待つため、後から受信したイベントが先に処理されることはない
+func (g *startupGate) Open() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, fn := range g.pending {
+		fn()
+	}
+	g.pending = nil
+	g.open = true
+}
+
+// Discard は保留していた処理を実行せずに破棄し、破棄した件数を返す
+// 待ち時間の経過前に停止した場合に使用する（ACKを返しているためSlackは再送しない）
+func (g *startupGate) Discard() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n := len(g.pending)
+	g.pending = nil
+	return n
+}
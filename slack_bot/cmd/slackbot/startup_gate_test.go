@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestStartupGateBuffersUntilOpen(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	app := &SlackBotApp{
+		Logger:      logger,
+		workers:     newWorkerPool(logger, 1, 10),
+		startupGate: newStartupGate(2),
+	}
+
+	var mu sync.Mutex
+	var handled []string
+	handle := func(name string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			handled = append(handled, name)
+		}
+	}
+
+	// 待ち時間中は処理せずに保留し、保留できる件数を超えたイベントは破棄する
+	if !app.dispatch("app_mention", nil, handle("first")) {
+		t.Fatal("1件目のイベントが保留されませんでした")
+	}
+	if !app.dispatch("app_mention", nil, handle("second")) {
+		t.Fatal("2件目のイベントが保留されませんでした")
+	}
+	if app.dispatch("app_mention", nil, handle("overflow")) {
+		t.Error("保留できる件数を超えたイベントが破棄されませんでした")
+	}
+	mu.Lock()
+	if len(handled) != 0 {
+		t.Errorf("待ち時間中に処理されたイベント = %v, want なし", handled)
+	}
+	mu.Unlock()
+
+	// 待ち時間の経過後は保留していたイベントを受信順に処理し、以降のイベントはすぐに処理する
+	app.startupGate.Open()
+	if !app.dispatch("app_mention", nil, handle("third")) {
+		t.Fatal("待ち時間の経過後のイベントが処理されませんでした")
+	}
+	if err := app.workers.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"first", "second", "third"}; !slices.Equal(handled, want) {
+		t.Errorf("handled = %v, want %v", handled, want)
+	}
+}
+
+func TestStartupGateDiscard(t *testing.T) {
+	gate := newStartupGate(10)
+	ran := 0
+	for range 3 {
+		gate.Submit(func() bool {
+			ran++
+			return true
+		})
+	}
+	if n := gate.Discard(); n != 3 {
+		t.Errorf("Discard() = %d, want 3", n)
+	}
+	gate.Open()
+	if ran != 0 {
+		t.Errorf("破棄した処理が %d 件実行されました", ran)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// streamingCursor は生成中の回答の末尾に表示する文字
+const streamingCursor = "…"
+
+// StreamingResponder は生成中の回答をスレッドの1つのメッセージに順に反映する
+// Start でプレースホルダーを投稿し、Append で受け取った回答を worker.streaming の間隔で chat.update で反映する
+// 途中の更新に失敗しても次の更新で追いつくため、ログに出力するのみ
+type StreamingResponder struct {
+	client   *slack.Client
+	retry    config.PostMessageConfig
+	cfg      config.StreamingConfig
+	channel  string
+	threadTS string
+
+	// ts はプレースホルダーのメッセージのタイムスタンプ
+	ts string
+
+	mu sync.Mutex
+	// text は受け取った回答、posted は最後にメッセージに反映した回答
+	text   strings.Builder
+	posted string
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func NewStreamingResponder(client *slack.Client, retry config.PostMessageConfig, cfg config.StreamingConfig, channel, threadTS string) *StreamingResponder {
+	return &StreamingResponder{
+		client:   client,
+		retry:    retry,
+		cfg:      cfg,
+		channel:  channel,
+		threadTS: threadTS,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Start はプレースホルダーを投稿し、メッセージの更新を開始する
+func (r *StreamingResponder) Start(ctx context.Context) error {
+	_, ts, err := postMessageWithRetry(ctx, r.client, r.retry, r.channel,
+		slack.MsgOptionText(r.cfg.Placeholder, false),
+		slack.MsgOptionTS(r.threadTS),
+		correlationMetadata(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("プレースホルダーの投稿エラー: %w", err)
+	}
+	r.ts = ts
+	go r.flushLoop(ctx)
+	return nil
+}
+
+// Append は生成された回答の差分を追加する
+func (r *StreamingResponder) Append(delta string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.text.WriteString(delta)
+}
+
+// Finish はメッセージの更新を止め、回答の全文に置き換える
+func (r *StreamingResponder) Finish(ctx context.Context, answer string) error {
+	r.halt()
+	if err := updateMessageWithRetry(ctx, r.client, r.retry, r.channel, r.ts, slack.MsgOptionText(answer, false)); err != nil {
+		return fmt.Errorf("回答の更新エラー: %w", err)
+	}
+	return nil
+}
+
+// TS はプレースホルダー（回答）のメッセージのタイムスタンプを返す
+func (r *StreamingResponder) TS() string {
+	return r.ts
+}
+
+// Abort はメッセージの更新を止め、プレースホルダーを削除する
+// 回答の生成に失敗したメンションを再試行したときに、途中までの回答が残らないようにする
+func (r *StreamingResponder) Abort(ctx context.Context) {
+	r.halt()
+	if _, _, err := r.client.DeleteMessageContext(ctx, r.channel, r.ts); err != nil {
+		logger.Errorf(ctx, "プレースホルダーの削除エラー: %v", err)
+	}
+}
+
+// メッセージの更新を止め、実行中の更新が終わるまで待つメソッド
+func (r *StreamingResponder) halt() {
+	close(r.stop)
+	<-r.stopped
+}
+
+// 回答が増えていれば更新の間隔ごとにメッセージに反映するメソッド
+func (r *StreamingResponder) flushLoop(ctx context.Context) {
+	defer close(r.stopped)
+	ticker := time.NewTicker(r.cfg.UpdateInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.Lock()
+		text := r.text.String()
+		r.mu.Unlock()
+		if text == "" || text == r.posted {
+			continue
+		}
+		if _, _, _, err := r.client.UpdateMessageContext(ctx, r.channel, r.ts, slack.MsgOptionText(text+streamingCursor, false)); err != nil {
+			logger.Errorf(ctx, "生成中の回答の更新エラー: %v", err)
+			continue
+		}
+		r.posted = text
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+)
+
+// スレッド履歴を取得する際の1ページあたりの件数
+const threadRepliesPageSize = 200
+
+// スレッド内のメンションの場合に、メンション以前の会話履歴を古い順に取得するメソッド
+// 件数と合計文字数の上限を超えた分は古いメッセージから切り捨てる
+func (app *SlackBotApp) fetchThreadContext(ctx context.Context, evt *slackevents.AppMentionEvent) ([]queuemodel.HistoryItem, error) {
+	if evt.ThreadTimeStamp == "" {
+		return nil, nil
+	}
+
+	maxMessages := app.AppConfig.ThreadContext.MaxMessages
+	var messages []queuemodel.HistoryItem
+	cursor := ""
+	for {
+		replies, hasMore, nextCursor, err := app.SlackClient.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+			ChannelID: evt.Channel,
+			Timestamp: evt.ThreadTimeStamp,
+			Cursor:    cursor,
+			Limit:     threadRepliesPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, msg := range replies {
+			// メンション自体は text として別途送信するため除外する
+			if msg.Timestamp == evt.TimeStamp || msg.Text == "" {
+				continue
+			}
+			user := msg.User
+			if user == "" {
+				user = msg.BotID
+			}
+			messages = append(messages, queuemodel.HistoryItem{User: user, Text: msg.Text})
+			if len(messages) > maxMessages {
+				messages = messages[1:]
+			}
+		}
+
+		if !hasMore || nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	return trimThreadContext(messages, app.AppConfig.ThreadContext.MaxChars), nil
+}
+
+// 新しいメッセージから順に合計文字数が maxChars に収まる範囲だけを残す
+func trimThreadContext(messages []queuemodel.HistoryItem, maxChars int) []queuemodel.HistoryItem {
+	total := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		total += len([]rune(messages[i].Text))
+		if total > maxChars {
+			return messages[i+1:]
+		}
+	}
+	return messages
+}
+
+// スレッド履歴を取得し、失敗した場合はメンションのみを送信するようにログを出して nil を返す
+// conversation.store_messages が有効で、保存した会話のメッセージがある場合はSlackから取得せずにそれを使用する
+func (app *SlackBotApp) threadContextOrNil(ctx context.Context, evt *slackevents.AppMentionEvent) []queuemodel.HistoryItem {
+	if history := app.storedThreadContext(ctx, evt); len(history) > 0 {
+		return history
+	}
+	messages, err := app.fetchThreadContext(ctx, evt)
+	if err != nil {
+		var rateLimitedErr *slack.RateLimitedError
+		if errors.As(err, &rateLimitedErr) {
+			logger.Printf(ctx, "スレッド履歴の取得がレート制限されました（%s後に再試行可能）。メンションのみを送信します", rateLimitedErr.RetryAfter)
+		} else {
+			logger.Errorf(ctx, "スレッド履歴の取得エラー (channel=%s thread_ts=%s): %v。メンションのみを送信します", evt.Channel, evt.ThreadTimeStamp, err)
+		}
+		return nil
+	}
+	return messages
+}
+
+// 会話が続いているスレッドの保存したメンションと回答を、メンション以前の会話履歴として古い順に返すメソッド
+// メッセージを保存していない・会話がない場合や、取得に失敗した場合は nil を返す
+func (app *SlackBotApp) storedThreadContext(ctx context.Context, evt *slackevents.AppMentionEvent) []queuemodel.HistoryItem {
+	if evt.ThreadTimeStamp == "" || app.Conversations == nil || !app.Conversations.StoresMessages() {
+		return nil
+	}
+	conversation, err := app.Conversations.Load(ctx, slackmodel.ChannelID(evt.Channel), slackmodel.ThreadTS(evt.ThreadTimeStamp))
+	if err != nil {
+		logger.Errorf(ctx, "保存した会話の取得エラー（Slackからスレッド履歴を取得します）: %v", err)
+		return nil
+	}
+	if conversation == nil {
+		return nil
+	}
+
+	var messages []queuemodel.HistoryItem
+	for _, message := range conversation.PreviousTurns(evt.TimeStamp) {
+		user := string(message.UserID)
+		if message.Role == slackmodel.ConversationRoleAssistant {
+			user = app.BotUserID
+		}
+		messages = append(messages, queuemodel.HistoryItem{User: user, Text: string(message.Text)})
+	}
+	return trimThreadContext(messages, app.AppConfig.ThreadContext.MaxChars)
+}
+
+// スレッドに投稿されたメンションまたは回答を会話のメッセージとして保存する
+// 保存に失敗しても回答には影響しないため、ログに出力して続行する
+func recordConversationMessage(ctx context.Context, tracker *usecase.ConversationTracker, channelID, threadTS, ts string, role slackmodel.ConversationRole, userID, text string) {
+	if tracker == nil || !tracker.StoresMessages() || threadTS == "" || ts == "" {
+		return
+	}
+	postedAt, err := slackmodel.ParseSlackTimestamp(ts)
+	if err != nil || postedAt.IsZero() {
+		postedAt = time.Now()
+	}
+	message, err := slackmodel.NewConversationMessage(
+		slackmodel.ChannelID(channelID),
+		slackmodel.ThreadTS(threadTS),
+		ts,
+		role,
+		slackmodel.UserID(userID),
+		slackmodel.Text(text),
+		postedAt,
+	)
+	if err != nil {
+		logger.Errorf(ctx, "会話のメッセージの検証エラー（保存せずに続行します）: %v", err)
+		return
+	}
+	if err := tracker.Record(ctx, message); err != nil {
+		logger.Errorf(ctx, "%v", err)
+	}
+}
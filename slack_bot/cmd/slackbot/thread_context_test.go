@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+)
+
+// stubConversationRepository は1件の会話だけを返す ConversationRepository
+type stubConversationRepository struct {
+	conversation *entity.Conversation
+}
+
+func (r *stubConversationRepository) FindByChannelAndThread(ctx context.Context, channelID, threadTS string) (*entity.Conversation, error) {
+	return r.conversation, nil
+}
+
+func (r *stubConversationRepository) Create(ctx context.Context, conversation *entity.Conversation, staleBefore time.Time) error {
+	return nil
+}
+
+func (r *stubConversationRepository) Touch(ctx context.Context, id ulid.ULID, at time.Time) error {
+	return nil
+}
+
+// stubConversationMessageRepository は保存済みのスレッドのメッセージを返す ConversationMessageRepository
+type stubConversationMessageRepository struct {
+	messages []*entity.ConversationMessage
+}
+
+func (r *stubConversationMessageRepository) Save(ctx context.Context, message *entity.ConversationMessage) error {
+	return nil
+}
+
+func (r *stubConversationMessageRepository) FindByThread(ctx context.Context, channelID, threadTS string, limit int) ([]*entity.ConversationMessage, error) {
+	return r.messages, nil
+}
+
+// スレッドのメンションには、保存した会話のうちメンションより前のメッセージを会話履歴として添付する
+func TestStoredThreadContext(t *testing.T) {
+	const threadTS = "1712311200.000100"
+	conversation := &entity.Conversation{
+		ID:           dbtypes.ULID(ulid.Make()),
+		ChannelID:    "C001",
+		ThreadTS:     threadTS,
+		StartedBy:    "U001",
+		LastActivity: time.Now(),
+		MessageCount: 3,
+	}
+	messages := []*entity.ConversationMessage{
+		{ChannelID: "C001", ThreadTS: threadTS, TS: "1712311200.000100", Role: "user", UserID: "U001", Text: "最初の質問"},
+		{ChannelID: "C001", ThreadTS: threadTS, TS: "1712311260.000100", Role: "assistant", Text: "最初の回答"},
+		{ChannelID: "C001", ThreadTS: threadTS, TS: "1712311320.000100", Role: "user", UserID: "U002", Text: "次の質問"},
+	}
+	app := &SlackBotApp{
+		AppConfig:     &config.AppConfig{ThreadContext: config.ThreadContextConfig{MaxMessages: 10, MaxChars: 1000}},
+		Conversations: usecase.NewConversationTracker(&stubConversationRepository{conversation: conversation}, &stubConversationMessageRepository{messages: messages}, time.Hour, 10),
+		BotUserID:     "UBOT",
+	}
+
+	tests := []struct {
+		name string
+		evt  *slackevents.AppMentionEvent
+		want []queuemodel.HistoryItem
+	}{
+		{
+			name: "メンションより前のメッセージを古い順に添付し、回答はBotのユーザーIDにする",
+			evt:  &slackevents.AppMentionEvent{Channel: "C001", ThreadTimeStamp: threadTS, TimeStamp: "1712311320.000100"},
+			want: []queuemodel.HistoryItem{{User: "U001", Text: "最初の質問"}, {User: "UBOT", Text: "最初の回答"}},
+		},
+		{
+			name: "スレッド外のメンションには添付しない",
+			evt:  &slackevents.AppMentionEvent{Channel: "C001", TimeStamp: "1712311320.000100"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := app.storedThreadContext(context.Background(), tt.evt)
+			if len(got) != len(tt.want) {
+				t.Fatalf("storedThreadContext() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("storedThreadContext()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTrimThreadContext(t *testing.T) {
+	messages := []queuemodel.HistoryItem{{User: "U001", Text: "あいう"}, {User: "U002", Text: "えお"}, {User: "U003", Text: "か"}}
+	tests := []struct {
+		maxChars int
+		want     int
+	}{
+		{maxChars: 6, want: 3},
+		{maxChars: 5, want: 2},
+		{maxChars: 3, want: 2},
+		{maxChars: 2, want: 1},
+		{maxChars: 0, want: 0},
+	}
+	for _, tt := range tests {
+		// 新しいメッセージから文字数の上限に収まる分だけを残す
+		if got := trimThreadContext(messages, tt.maxChars); len(got) != tt.want || (tt.want > 0 && got[len(got)-1] != messages[2]) {
+			t.Errorf("trimThreadContext(maxChars=%d) = %v, want 最新の %d 件", tt.maxChars, got, tt.want)
+		}
+	}
+}
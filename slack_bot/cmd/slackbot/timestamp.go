@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// メッセージのタイムスタンプ（ts）とイベントの発生時刻（event_ts）をメンションの時刻に変換する
+// 発生時刻は event_ts、ts、現在時刻の順に、タイムスタンプは ts、現在時刻の順に使用し、保存する時刻がゼロ値にならないようにする
+// 変換できない値と現在時刻で補った場合はログに出力する（ts が変換できない場合はメンションの作成時に WithSlackTS の検証でエラーになる）
+func mentionTimes(ctx context.Context, ts, eventTS string) (slackmodel.Timestamp, slackmodel.EventTime) {
+	return resolveMentionTimes(ctx, ts, eventTS, time.Now)
+}
+
+// resolveMentionTimes は現在時刻を now から取得する mentionTimes
+func resolveMentionTimes(ctx context.Context, ts, eventTS string, now func() time.Time) (slackmodel.Timestamp, slackmodel.EventTime) {
+	timestamp, err := slackmodel.ParseSlackTimestamp(ts)
+	if err != nil {
+		logger.Errorf(ctx, "タイムスタンプの変換エラー: %v", err)
+	}
+	eventTime, err := slackmodel.ParseSlackTimestamp(eventTS)
+	if err != nil {
+		logger.Errorf(ctx, "タイムスタンプの変換エラー: %v", err)
+	}
+	if timestamp.IsZero() {
+		timestamp = now()
+		logger.Printf(ctx, "イベントにタイムスタンプがないため現在時刻を使用します: ts=%q event_ts=%q", ts, eventTS)
+	}
+	if eventTime.IsZero() {
+		eventTime = timestamp
+	}
+	return slackmodel.Timestamp(timestamp), slackmodel.EventTime(eventTime)
+}
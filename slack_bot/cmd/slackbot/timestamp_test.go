@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResolveMentionTimes(t *testing.T) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	tsTime := time.Unix(1712345678, 200*int64(time.Microsecond))
+	eventTSTime := time.Unix(1712345679, 0)
+
+	tests := []struct {
+		name          string
+		ts            string
+		eventTS       string
+		wantTimestamp time.Time
+		wantEventTime time.Time
+	}{
+		{
+			name:          "発生時刻は event_ts を優先する",
+			ts:            "1712345678.000200",
+			eventTS:       "1712345679",
+			wantTimestamp: tsTime,
+			wantEventTime: eventTSTime,
+		},
+		{
+			name:          "event_ts がない場合は発生時刻に ts を使用する",
+			ts:            "1712345678.000200",
+			wantTimestamp: tsTime,
+			wantEventTime: tsTime,
+		},
+		{
+			name:          "event_ts が不正な場合は発生時刻に ts を使用する",
+			ts:            "1712345678.000200",
+			eventTS:       "garbage",
+			wantTimestamp: tsTime,
+			wantEventTime: tsTime,
+		},
+		{
+			name:          "ts がない場合はタイムスタンプを現在時刻で補い、発生時刻は event_ts を使用する",
+			eventTS:       "1712345679",
+			wantTimestamp: now,
+			wantEventTime: eventTSTime,
+		},
+		{
+			name:          "小数部なしの ts",
+			ts:            "1712345678",
+			wantTimestamp: time.Unix(1712345678, 0),
+			wantEventTime: time.Unix(1712345678, 0),
+		},
+		{
+			name:          "どちらもない場合は現在時刻で補う",
+			wantTimestamp: now,
+			wantEventTime: now,
+		},
+		{
+			name:          "小数部が6桁を超える ts は現在時刻で補う",
+			ts:            "1712345678.0002001",
+			wantTimestamp: now,
+			wantEventTime: now,
+		},
+		{
+			name:          "どちらも不正な場合は現在時刻で補う",
+			ts:            "garbage",
+			eventTS:       "garbage",
+			wantTimestamp: now,
+			wantEventTime: now,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timestamp, eventTime := resolveMentionTimes(context.Background(), tt.ts, tt.eventTS, clock)
+			if got := time.Time(timestamp); !got.Equal(tt.wantTimestamp) {
+				t.Errorf("Timestamp = %v, want %v", got, tt.wantTimestamp)
+			}
+			if got := time.Time(eventTime); !got.Equal(tt.wantEventTime) {
+				t.Errorf("EventTime = %v, want %v", got, tt.wantEventTime)
+			}
+		})
+	}
+}
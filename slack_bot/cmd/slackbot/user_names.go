@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// userNames はユーザーの表示名と氏名
+type userNames struct {
+	name     string
+	realName string
+}
+
+// ユーザーの表示名と氏名を返すメソッド
+// enrichment.user_cache の期間キャッシュし、キャッシュにない場合は users.info で取得する
+// 取得に失敗した場合はメンションの処理を止めないよう、キャッシュせずにユーザーIDを返す
+func (app *SlackBotApp) resolveUserNames(ctx context.Context, userID string) userNames {
+	if names, ok := app.userNames.Get(userID); ok {
+		return names
+	}
+	user, err := app.userInfo(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, "ユーザー情報の取得エラー (user=%s): %v", userID, err)
+		return userNames{name: userID, realName: userID}
+	}
+
+	names := userNames{name: user.Profile.DisplayName, realName: user.RealName}
+	if names.realName == "" {
+		names.realName = userID
+	}
+	if names.name == "" {
+		names.name = names.realName
+	}
+	app.userNames.Set(userID, names)
+	return names
+}
+
+// メンションにユーザーの表示名と氏名を設定するオプションを返すメソッド
+func (app *SlackBotApp) userNamesOption(ctx context.Context, userID string) slackmodel.MentionOption {
+	names := app.resolveUserNames(ctx, userID)
+	return slackmodel.WithUserNames(slackmodel.UserName(names.name), slackmodel.UserName(names.realName))
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"go.uber.org/fx"
+)
+
+const (
+	// webhookMaxBodySize はWebhookで受け付けるリクエストボディの最大サイズ
+	webhookMaxBodySize = 1 << 20
+	// webhookShutdownTimeout は停止時に処理中のリクエストを待つ時間
+	webhookShutdownTimeout = 10 * time.Second
+)
+
+// webhookHandler はEvents API・インタラクションのHTTPリクエストを受け取り、Socket Modeと同じ処理に渡す
+// リクエストはワークスペースごとのSigning Secretで署名を検証し、検証できたワークスペースで処理する
+type webhookHandler struct {
+	apps SlackBotApps
+	log  *slog.Logger
+}
+
+// mode が webhook のワークスペースがある場合に、イベントを受信するHTTPサーバーを起動・停止する
+func startWebhookServer(lc fx.Lifecycle, cfg *config.AppConfig, apps SlackBotApps, l *slog.Logger) {
+	var webhookApps SlackBotApps
+	for _, app := range apps {
+		if app.Workspace.IsWebhook() {
+			webhookApps = append(webhookApps, app)
+		}
+	}
+	if len(webhookApps) == 0 {
+		return
+	}
+
+	handler := &webhookHandler{apps: webhookApps, log: l}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+cfg.Webhook.EventsPath, handler.handleEvents)
+	mux.HandleFunc("POST "+cfg.Webhook.InteractionsPath, handler.handleInteractions)
+
+	server := &http.Server{
+		Addr:              cfg.Webhook.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			l.Info("Webhookの受信を開始しました", "addr", server.Addr, "events_path", cfg.Webhook.EventsPath, "interactions_path", cfg.Webhook.InteractionsPath)
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					l.Error("Webhookサーバーエラー", "error", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, webhookShutdownTimeout)
+			defer cancel()
+			return server.Shutdown(ctx)
+		},
+	})
+}
+
+// Events APIのリクエストを処理するハンドラ
+// url_verification にはチャレンジを返し、イベントはワーカーに渡してから応答する
+func (h *webhookHandler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	app, body, ok := h.verify(w, r)
+	if !ok {
+		return
+	}
+
+	eventsAPIEvent, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		app.Logger.Warn("Webhookのイベントの解析エラー", "error", err)
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	switch eventsAPIEvent.Type {
+	case slackevents.URLVerification:
+		var challenge slackevents.ChallengeResponse
+		if err := json.Unmarshal(body, &challenge); err != nil {
+			http.Error(w, "invalid challenge", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(challenge.Challenge))
+	case slackevents.CallbackEvent:
+		app.handleEventsAPIEvent(eventsAPIEvent, eventJSON(body))
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// インタラクション（ボタンの操作など）のリクエストを処理するハンドラ
+// ペイロードは payload パラメータのJSONで送信される
+func (h *webhookHandler) handleInteractions(w http.ResponseWriter, r *http.Request) {
+	app, body, ok := h.verify(w, r)
+	if !ok {
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(form.Get("payload")), &callback); err != nil {
+		app.Logger.Warn("Webhookのインタラクションの解析エラー", "error", err)
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	// モーダルの入力エラーはレスポンスで返す（モーダルを閉じずにエラーを表示する）
+	if res := app.interactionResponse(callback); res != nil {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			app.Logger.Error("Webhookのインタラクションの応答エラー", "error", err)
+		}
+		return
+	}
+	app.handleInteractionCallback(callback)
+	w.WriteHeader(http.StatusOK)
+}
+
+// リクエストボディを読み込み、署名を検証できたワークスペースのアプリケーションを返す
+// 検証できない場合（署名の不一致・5分以上前のタイムスタンプ）はエラーを応答して false を返す
+func (h *webhookHandler) verify(w http.ResponseWriter, r *http.Request) (*SlackBotApp, []byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBodySize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	for _, app := range h.apps {
+		if err := verifySignature(r.Header, body, app.Workspace.SigningSecret); err == nil {
+			return app, body, true
+		}
+	}
+	h.log.Warn("Webhookのリクエストの署名を検証できませんでした", "path", r.URL.Path)
+	http.Error(w, "invalid signature", http.StatusUnauthorized)
+	return nil, nil, false
+}
+
+// リクエストのタイムスタンプとHMAC署名を Signing Secret で検証する
+func verifySignature(header http.Header, body []byte, signingSecret string) error {
+	verifier, err := slack.NewSecretsVerifier(header, signingSecret)
+	if err != nil {
+		return err
+	}
+	if _, err := verifier.Write(body); err != nil {
+		return err
+	}
+	return verifier.Ensure()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// signedHeader はSlackと同じ方法で body に署名したリクエストヘッダーを作成する
+func signedHeader(secret string, timestamp time.Time, body string) http.Header {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", ts)
+	header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return header
+}
+
+func TestVerifySignature(t *testing.T) {
+	const body = `token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&command=%2Fask&text=hello`
+	now := time.Now()
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    string
+		wantErr bool
+	}{
+		{
+			name:   "正しい署名",
+			header: signedHeader(testSigningSecret, now, body),
+			body:   body,
+		},
+		{
+			name:    "署名後に本文が改ざんされた",
+			header:  signedHeader(testSigningSecret, now, body),
+			body:    body + "&user_id=U999",
+			wantErr: true,
+		},
+		{
+			name:    "別の Signing Secret で署名された",
+			header:  signedHeader("another-secret", now, body),
+			body:    body,
+			wantErr: true,
+		},
+		{
+			// リプレイ攻撃を防ぐため、5分より前のタイムスタンプは署名が正しくても拒否する
+			name:    "古いタイムスタンプ",
+			header:  signedHeader(testSigningSecret, now.Add(-10*time.Minute), body),
+			body:    body,
+			wantErr: true,
+		},
+		{
+			name: "署名のヘッダーがない",
+			header: func() http.Header {
+				h := signedHeader(testSigningSecret, now, body)
+				h.Del("X-Slack-Signature")
+				return h
+			}(),
+			body:    body,
+			wantErr: true,
+		},
+		{
+			name: "タイムスタンプのヘッダーがない",
+			header: func() http.Header {
+				h := signedHeader(testSigningSecret, now, body)
+				h.Del("X-Slack-Request-Timestamp")
+				return h
+			}(),
+			body:    body,
+			wantErr: true,
+		},
+		{
+			name:    "ヘッダーがない",
+			header:  http.Header{},
+			body:    body,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySignature(tt.header, []byte(tt.body), testSigningSecret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/slack-go/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/bootstrap"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/modules"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+// worker はキューのメンションにAIで回答し、元のスレッドに投稿する
+type worker struct {
+	cfg            *config.AppConfig
+	answerer       *usecase.MentionAnswerer
+	mentionCommand di.SlackMentionCommand
+	// conversations は会話の追跡が無効な場合 nil
+	conversations *usecase.ConversationTracker
+	// clients はワークスペース名ごとのSlackクライアント
+	clients map[string]*slack.Client
+	tracer  trace.Tracer
+}
+
+func runWorker(args []string) error {
+	fs := flag.NewFlagSet("worker", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "使い方: worker\n\nキューのメンションにAIで回答し、元のスレッドに投稿します\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var cfg *config.AppConfig
+	var answerer *usecase.MentionAnswerer
+	var consumers modules.WorkerConsumers
+	var mentionCommand di.SlackMentionCommand
+	var conversations *usecase.ConversationTracker
+	var tracerProvider trace.TracerProvider
+	var l *slog.Logger
+	app := bootstrap.NewApp(fx.NopLogger, bootstrap.WorkerModule, fx.Populate(&cfg, &answerer, &consumers, &mentionCommand, &conversations, &tracerProvider, &l))
+	if err := app.Err(); err != nil {
+		return err
+	}
+
+	w := &worker{
+		cfg:            cfg,
+		answerer:       answerer,
+		mentionCommand: mentionCommand,
+		conversations:  conversations,
+		clients:        make(map[string]*slack.Client),
+		tracer:         tracerProvider.Tracer(tracerName),
+	}
+	for _, workspace := range cfg.SlackWorkspaces() {
+		w.clients[workspace.Name] = NewSlackClient(l, workspace)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	defer app.Stop(context.Background())
+
+	done := make(chan struct{})
+	for _, consumer := range consumers {
+		go func(consumer *queue.SQSConsumer) {
+			defer func() { done <- struct{}{} }()
+			consumer.Run(ctx, w.handleMention)
+		}(consumer)
+	}
+	l.Info("ワーカーを起動しました", "provider", cfg.Worker.Provider, "queues", len(consumers))
+	for range consumers {
+		<-done
+	}
+	l.Info("ワーカーを停止します")
+	return nil
+}
+
+// キューのメンションに回答して元のスレッドに投稿するメソッド
+// 形式が不正なメッセージは再試行しても処理できないため、ログに出力して破棄する
+// 回答の生成・投稿に失敗した場合はエラーを返し、worker.retry_delay 後に再試行する
+func (w *worker) handleMention(ctx context.Context, body []byte) error {
+	var msg queuemodel.MentionMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		logger.Warnf(ctx, "メンションのメッセージのパースエラー（破棄します）: %v", err)
+		return nil
+	}
+	correlationID := msg.CorrelationID
+	if correlationID == "" {
+		correlationID = msg.ID
+	}
+	ctx = logger.WithCorrelationID(ctx, correlationID)
+	if err := msg.Validate(); err != nil {
+		logger.Warnf(ctx, "メンションのメッセージが不正です（破棄します）: %v", err)
+		return nil
+	}
+	client, ok := w.clientFor(msg.Workspace)
+	if !ok {
+		logger.Warnf(ctx, "メンションの workspace に対応するワークスペースがありません（破棄します）: workspace=%s", msg.Workspace)
+		return nil
+	}
+
+	threadTS := msg.ThreadTS
+	if threadTS == "" {
+		threadTS = msg.TS
+	}
+	// キューのメッセージ属性からBotのトレースを引き継ぎ、回答の生成・投稿をスパンとして記録する
+	ctx, span := w.tracer.Start(ctx, "worker.answer",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("slack.channel", msg.Channel),
+			attribute.String("slack.thread_ts", threadTS),
+			attribute.String("correlation_id", correlationID),
+		),
+	)
+	defer span.End()
+
+	answer := w.postAnswer
+	if w.cfg.Worker.Streaming.Enabled {
+		answer = w.streamAnswer
+	}
+	text, ts, err := answer(ctx, client, &msg, threadTS)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	logger.Printf(ctx, "回答を投稿しました: channel=%s thread_ts=%s", msg.Channel, threadTS)
+	w.markAnswered(ctx, msg.ID)
+	recordConversationMessage(ctx, w.conversations, msg.Channel, threadTS, ts, slackmodel.ConversationRoleAssistant, "", text)
+	return nil
+}
+
+// 回答を生成し、生成し終えてからスレッドに投稿するメソッド
+// 投稿した回答とそのメッセージのタイムスタンプを返す
+func (w *worker) postAnswer(ctx context.Context, client *slack.Client, msg *queuemodel.MentionMessage, threadTS string) (string, string, error) {
+	answer, err := w.answerer.Answer(ctx, msg)
+	if err != nil {
+		return "", "", err
+	}
+	_, ts, err := postMessageWithRetry(ctx, client, w.cfg.PostMessage, msg.Channel,
+		slack.MsgOptionText(answer, false),
+		slack.MsgOptionTS(threadTS),
+		correlationMetadata(ctx),
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("回答の投稿エラー: %w", err)
+	}
+	return answer, ts, nil
+}
+
+// プレースホルダーを投稿し、生成中の回答で順に更新するメソッド（worker.streaming.enabled の場合）
+// 回答の生成に失敗した場合はプレースホルダーを削除してエラーを返す
+func (w *worker) streamAnswer(ctx context.Context, client *slack.Client, msg *queuemodel.MentionMessage, threadTS string) (string, string, error) {
+	responder := NewStreamingResponder(client, w.cfg.PostMessage, w.cfg.Worker.Streaming, msg.Channel, threadTS)
+	if err := responder.Start(ctx); err != nil {
+		return "", "", err
+	}
+	answer, err := w.answerer.AnswerStream(ctx, msg, responder.Append)
+	if err != nil {
+		responder.Abort(ctx)
+		return "", "", err
+	}
+	if err := responder.Finish(ctx, answer); err != nil {
+		return "", "", err
+	}
+	return answer, responder.TS(), nil
+}
+
+// メッセージのワークスペース名に対応するSlackクライアントを返すメソッド
+// ワークスペースが1つの場合は、ワークスペース名がなくてもそのクライアントを返す
+func (w *worker) clientFor(name string) (*slack.Client, bool) {
+	if client, ok := w.clients[name]; ok {
+		return client, true
+	}
+	if len(w.clients) == 1 {
+		for _, client := range w.clients {
+			return client, true
+		}
+	}
+	return nil, false
+}
+
+// 回答したメンションの状態を answered に更新するメソッド
+// データベースが無効な場合や、保存されていないメンションの場合は何もしない
+func (w *worker) markAnswered(ctx context.Context, mentionID string) {
+	if w.mentionCommand == nil {
+		return
+	}
+	id, err := ulid.ParseStrict(mentionID)
+	if err != nil {
+		return
+	}
+	err = w.mentionCommand.UpdateStatus(ctx, slackmodel.MentionID(id), string(slackmodel.MentionStatusAnswered), "")
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf(ctx, "メンションの状態の更新エラー (%s): %v", slackmodel.MentionStatusAnswered, err)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// workerPool は受信したイベントの処理を一定数のワーカーで並行して実行する
+// SQSへの送信やスレッドの履歴の取得が遅い場合にも、後続のイベントの処理が止まらないようにする
+type workerPool struct {
+	tasks chan workerTask
+	wg    sync.WaitGroup
+	log   *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+type workerTask struct {
+	name string
+	fn   func()
+}
+
+func newWorkerPool(l *slog.Logger, size, queueSize int) *workerPool {
+	p := &workerPool{tasks: make(chan workerTask, queueSize), log: l}
+	p.wg.Add(size)
+	for range size {
+		go p.work()
+	}
+	return p
+}
+
+// Submit は処理をキューに追加する
+// キューが一杯の場合は空くまで待つ。停止後は処理を追加せずに false を返す
+func (p *workerPool) Submit(name string, fn func()) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+	p.tasks <- workerTask{name: name, fn: fn}
+	return true
+}
+
+// Stop は新しい処理の受け付けを止め、キューに残っている処理が終わるまで待つ
+// ctx が終了した場合は処理の完了を待たずにエラーを返す
+func (p *workerPool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *workerPool) work() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		p.run(task)
+	}
+}
+
+// run は処理を実行する
+// 処理中のパニックはプロセスを終了させずにログに出力し、ワーカーは次の処理を続ける
+func (p *workerPool) run(task workerTask) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.log.Error("イベントの処理中にパニックが発生しました", "event_type", task.name, "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	task.fn()
+}
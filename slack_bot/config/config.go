@@ -1,7 +1,13 @@
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/fx"
@@ -11,33 +17,925 @@ var Module = fx.Options(
 	fx.Provide(NewAppConfig),
 )
 
+// configPathEnv は設定ファイルのパスを指定する環境変数名
+const configPathEnv = "CONFIG_PATH"
+
+var configPath = flag.String("config", "", "設定ファイルのパス（未指定の場合は環境変数 CONFIG_PATH、./config/config.yml の順に探索）")
+
+// configSets は --set で指定された設定の上書き
+// 優先順位は 環境変数 (AISLACKBOT_*) > --set > 設定ファイル > デフォルト
+var configSets = func() *setFlag {
+	f := &setFlag{}
+	flag.Var(f, "set", "設定ファイルの値を上書きする（例: --set queue.backend=redis。複数指定可。環境変数 AISLACKBOT_* が優先）")
+	return f
+}()
+
 type AppConfig struct {
-	SlackBot  SlackBotConfig  `mapstructure:"slack_bot"`
-	ElasticMQ ElasticMQConfig `mapstructure:"elasticmq"`
+	SlackBot SlackBotConfig `mapstructure:"slack_bot"`
+	// Workspaces は1つのプロセスで複数のワークスペースに接続する場合の設定（指定した場合は SlackBot を使用しない）
+	Workspaces    []SlackBotConfig    `mapstructure:"workspaces"`
+	ElasticMQ     ElasticMQConfig     `mapstructure:"elasticmq"`
+	Queue         QueueConfig         `mapstructure:"queue"`
+	AccessControl AccessControlConfig `mapstructure:"access_control"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	Retention     RetentionConfig     `mapstructure:"retention"`
+	ThreadContext ThreadContextConfig `mapstructure:"thread_context"`
+	Attachments   AttachmentsConfig   `mapstructure:"attachments"`
+	Reaction      ReactionConfig      `mapstructure:"reaction"`
+	Feedback      FeedbackConfig      `mapstructure:"feedback"`
+	Tracing       TracingConfig       `mapstructure:"tracing"`
+	RateLimit     RateLimitConfig     `mapstructure:"rate_limit"`
+	Broadcast     BroadcastConfig     `mapstructure:"broadcast"`
+	HTTPServer    HTTPServerConfig    `mapstructure:"http_server"`
+	Webhook       WebhookConfig       `mapstructure:"webhook"`
+	Outbox        OutboxConfig        `mapstructure:"outbox"`
+	Mention       MentionConfig       `mapstructure:"mention"`
+	Enrichment    EnrichmentConfig    `mapstructure:"enrichment"`
+	AutoReply     AutoReplyConfig     `mapstructure:"auto_reply"`
+	ThreadDedup   ThreadDedupConfig   `mapstructure:"thread_dedup"`
+	EventDedup    EventDedupConfig    `mapstructure:"event_dedup"`
+	Digest        DigestConfig        `mapstructure:"digest"`
+	Pushgateway   PushgatewayConfig   `mapstructure:"pushgateway"`
+	LoopGuard     LoopGuardConfig     `mapstructure:"loop_guard"`
+	// ChannelMessages はメンションされていないチャンネルのメッセージを処理する設定
+	ChannelMessages ChannelMessagesConfig `mapstructure:"channel_messages"`
+	DirectMessages  DirectMessagesConfig  `mapstructure:"direct_messages"`
+	Encryption      EncryptionConfig      `mapstructure:"encryption"`
+	EventWorkers    EventWorkersConfig    `mapstructure:"event_workers"`
+	Progress        ProgressConfig        `mapstructure:"progress"`
+	DeadLetter      DeadLetterConfig      `mapstructure:"dead_letter"`
+	I18n            I18nConfig            `mapstructure:"i18n"`
+	Conversation    ConversationConfig    `mapstructure:"conversation"`
+	DeletedMessages DeletedMessagesConfig `mapstructure:"deleted_messages"`
+	AppHome         AppHomeConfig         `mapstructure:"app_home"`
+	Admin           AdminConfig           `mapstructure:"admin"`
+	SocketMode      SocketModeConfig      `mapstructure:"socket_mode"`
+	Shortcut        ShortcutConfig        `mapstructure:"shortcut"`
+	Approval        ApprovalConfig        `mapstructure:"approval"`
+	PostMessage     PostMessageConfig     `mapstructure:"post_message"`
+	ChannelSettings ChannelSettingsConfig `mapstructure:"channel_settings"`
+	Worker          WorkerConfig          `mapstructure:"worker"`
+	SlashCommands   SlashCommandsConfig   `mapstructure:"slash_commands"`
+	AnswerActions   AnswerActionsConfig   `mapstructure:"answer_actions"`
+	Secrets         SecretsConfig         `mapstructure:"secrets"`
+	Log             LogConfig             `mapstructure:"log"`
+}
+
+// ログの出力形式（log.format）
+const (
+	// LogFormatText は key=value 形式のテキスト
+	LogFormatText = "text"
+	// LogFormatJSON は1行に1つのJSON
+	LogFormatJSON = "json"
+)
+
+// LogConfig はログの出力の設定
+// Level は debug, info, warn, error のいずれかで、指定したレベル以上のログを出力する
+type LogConfig struct {
+	Level  string `mapstructure:"level"`
+	Format string `mapstructure:"format"`
+}
+
+// AIプロバイダーの種類（worker.provider）
+const (
+	// AIProviderOpenAI はOpenAIのChat Completions API（互換のAPIは worker.openai.base_url で指定する）
+	AIProviderOpenAI = "openai"
+	// AIProviderAnthropic はAnthropicのMessages API
+	AIProviderAnthropic = "anthropic"
+	// AIProviderBedrock はAmazon BedrockのAnthropicのモデル（InvokeModel）
+	AIProviderBedrock = "bedrock"
+)
+
+// WorkerConfig は worker コマンド（キューのメンションにAIで回答し、元のスレッドに投稿する）の設定
+// QueueKeys のキーに対応するキューから受信する。受信の設定は回答キューと同じ形式で指定する
+type WorkerConfig struct {
+	Provider     string   `mapstructure:"provider"`
+	QueueKeys    []string `mapstructure:"queue_keys"`
+	SystemPrompt string   `mapstructure:"system_prompt"`
+	// RetryDelay は回答の生成・投稿に失敗したメンションを再度受信するまでの時間
+	RetryDelay time.Duration          `mapstructure:"retry_delay"`
+	Consumer   ResponseConsumerConfig `mapstructure:"consumer"`
+	OpenAI     OpenAIConfig           `mapstructure:"openai"`
+	Anthropic  AnthropicConfig        `mapstructure:"anthropic"`
+	Bedrock    BedrockConfig          `mapstructure:"bedrock"`
+	Streaming  StreamingConfig        `mapstructure:"streaming"`
+}
+
+// StreamingConfig は回答を生成しながら投稿する設定
+// 最初に Placeholder を投稿し、生成された回答で FlushInterval ごとに chat.update で置き換える
+// Slackのレート制限を超えないよう、1つのメッセージの更新は1分あたり MaxUpdatesPerMinute 回までにする
+type StreamingConfig struct {
+	Enabled             bool          `mapstructure:"enabled"`
+	Placeholder         string        `mapstructure:"placeholder"`
+	FlushInterval       time.Duration `mapstructure:"flush_interval"`
+	MaxUpdatesPerMinute int           `mapstructure:"max_updates_per_minute"`
+}
+
+// UpdateInterval はメッセージを更新する間隔（flush_interval と max_updates_per_minute の長い方）
+func (c StreamingConfig) UpdateInterval() time.Duration {
+	return max(c.FlushInterval, time.Minute/time.Duration(c.MaxUpdatesPerMinute))
+}
+
+// OpenAIConfig はOpenAI（互換）のChat Completions APIの設定
+// APIKey が空の場合は環境変数 OPENAI_API_KEY を使用する
+type OpenAIConfig struct {
+	BaseURL   string        `mapstructure:"base_url"`
+	APIKey    string        `mapstructure:"api_key"`
+	Model     string        `mapstructure:"model"`
+	MaxTokens int           `mapstructure:"max_tokens"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
+// AnthropicConfig はAnthropicのMessages APIの設定
+// APIKey が空の場合は環境変数 ANTHROPIC_API_KEY を使用する。MaxTokens はAPIで必須のため正の値を指定する
+type AnthropicConfig struct {
+	BaseURL   string        `mapstructure:"base_url"`
+	APIKey    string        `mapstructure:"api_key"`
+	Model     string        `mapstructure:"model"`
+	MaxTokens int           `mapstructure:"max_tokens"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
+// BedrockConfig はAmazon BedrockのAnthropicのモデルの設定
+// AccessKey が空の場合はAWS SDKのデフォルトの認証情報（環境変数・IAMロールなど）を使用する
+type BedrockConfig struct {
+	Region    string        `mapstructure:"region"`
+	ModelID   string        `mapstructure:"model_id"`
+	AccessKey string        `mapstructure:"access_key"`
+	SecretKey string        `mapstructure:"secret_key"`
+	MaxTokens int           `mapstructure:"max_tokens"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
+// ChannelSettingsConfig はチャンネルごとにAIワーカーへ渡す回答の言語・ペルソナの設定
+// Channels に含まれないチャンネルには Default を渡す（Bot自身は回答の生成に使用しない）
+// チャンネルIDは設定ファイルのキーにすると小文字に変換されるため、マップではなく一覧で指定する
+type ChannelSettingsConfig struct {
+	Default  ChannelSetting   `mapstructure:"default"`
+	Channels []ChannelSetting `mapstructure:"channels"`
+}
+
+// ChannelSetting は1つのチャンネルの言語とペルソナ（Default では ChannelID を使用しない）
+type ChannelSetting struct {
+	ChannelID string `mapstructure:"channel_id"`
+	Language  string `mapstructure:"language"`
+	Persona   string `mapstructure:"persona"`
+}
+
+// For はチャンネルの設定を返し、設定がない場合はデフォルトの設定を返す
+func (c ChannelSettingsConfig) For(channelID string) ChannelSetting {
+	for _, setting := range c.Channels {
+		if setting.ChannelID == channelID {
+			return setting
+		}
+	}
+	return c.Default
+}
+
+// PostMessageConfig はSlackへの投稿（chat.postMessage）がレート制限された場合の再試行の設定
+// Retry-After の時間待って MaxRetries 回まで再試行する。Retry-After が MaxRetryWait を超える場合は再試行しない
+type PostMessageConfig struct {
+	MaxRetries   int           `mapstructure:"max_retries"`
+	MaxRetryWait time.Duration `mapstructure:"max_retry_wait"`
+}
+
+// SocketModeConfig はSocket Modeの接続が終了した場合に再接続する設定
+// 再接続の間隔は InitialBackoff から倍にしていき MaxBackoff で止める。StableAfter 以上接続が続いた場合は間隔と失敗回数を戻す
+// 認証エラーなど再接続しても回復しない失敗が MaxFatalFailures 回続いた場合はプロセスを停止する（それ以外の失敗は再接続を続ける）
+type SocketModeConfig struct {
+	InitialBackoff   time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff       time.Duration `mapstructure:"max_backoff"`
+	StableAfter      time.Duration `mapstructure:"stable_after"`
+	MaxFatalFailures int           `mapstructure:"max_fatal_failures"`
+}
+
+// AdminConfig は管理コマンド（@bot admin pause / resume / status / export / dead_letters / replay）の設定
+// UserIDs に含まれるユーザーのみが実行でき、空の場合は管理コマンドを使用しない
+type AdminConfig struct {
+	UserIDs []string          `mapstructure:"user_ids"`
+	Export  AdminExportConfig `mapstructure:"export"`
+}
+
+// AdminExportConfig は管理コマンドの export（メンションの履歴のエクスポート）の設定
+// MaxRows を超える件数はエクスポートせずにエラーを返信する。テキストは MaxTextLength 文字を超える部分を省略する（0 の場合は省略しない）
+type AdminExportConfig struct {
+	MaxRows       int `mapstructure:"max_rows"`
+	MaxTextLength int `mapstructure:"max_text_length"`
+}
+
+// AppHomeConfig はApp HomeのHomeタブの設定
+// 有効にする場合はSlack Appのマニフェストで Home Tab と app_home_opened イベントを有効にする必要がある
+type AppHomeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RecentLimit はHomeタブに表示する最近の質問の件数（1〜50）
+	RecentLimit int `mapstructure:"recent_limit"`
+}
+
+// DeletedMessagesConfig はSlackで削除されたメッセージの扱いの設定
+// 有効な場合は message_deleted イベントを受け取ったときに、そのメッセージのメンションを論理削除する
+// 削除したメッセージからメンションのIDを導出するため mention.deterministic_id を有効にする必要がある
+type DeletedMessagesConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// ConversationConfig はスレッドごとの会話の追跡の設定
+// 有効な場合は同じスレッドのメンションを1つの会話としてまとめ、保存するメンションとキューのメッセージに conversation_id を設定する
+// 最後のメッセージから TTL 以上経過した会話は終了したものとして、同じスレッドでも新しい会話を始める
+// StoreMessages が有効な場合はスレッドのメンションと回答を保存し、会話が続いているスレッドのメンションの会話履歴に使用する
+type ConversationConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	TTL           time.Duration `mapstructure:"ttl"`
+	StoreMessages bool          `mapstructure:"store_messages"`
+}
+
+// I18nConfig はBotがユーザーに返信するメッセージの言語の設定
+// ユーザーがApp Homeで言語を設定していない場合は DefaultLang（ja または en）で返信する
+type I18nConfig struct {
+	DefaultLang string `mapstructure:"default_lang"`
+}
+
+// DeadLetterConfig はキューへの送信に失敗したメッセージの保存の設定
+// 有効な場合は送信できなかったメッセージを failed_mentions テーブルに保存し、replay コマンドで再送できるようにする
+// アウトボックスが有効な場合は送信待ちとして保存されるため使用しない
+// ワーカーが MaxReceives 回受信しても処理できなかったメンションも failed_mentions テーブルに保存し、
+// QueueName が設定されている場合はそのキュー（デッドレターキュー）に移す（MaxReceives が0の場合は再試行を続ける）
+type DeadLetterConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	MaxReceives int    `mapstructure:"max_receives"`
+	QueueName   string `mapstructure:"queue_name"`
+}
+
+// ProgressConfig は回答が届くまでの途中経過の表示の設定
+// 有効な場合はキューのメッセージに status_updates を設定し、回答キューの progress メッセージでスレッドの状況表示を更新する
+// 状況表示のメッセージは相関IDごとに TTL の間だけ記録する
+type ProgressConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	TTL     time.Duration `mapstructure:"ttl"`
+}
+
+// EventWorkersConfig は受信したイベントを処理するワーカーの設定
+// イベントはACKを返した後に QueueSize までキューに溜め、Size 個のワーカーで並行して処理する（キューが一杯の場合は受信を待たせる）
+type EventWorkersConfig struct {
+	Size      int `mapstructure:"size"`
+	QueueSize int `mapstructure:"queue_size"`
+	// Timeout はメンション1件の処理（保存・キューへの送信・Slackへの返信）の期限
+	Timeout time.Duration `mapstructure:"timeout"`
+	// DrainTimeout は停止時に処理中・処理待ちのイベントの完了を待つ時間
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
+}
+
+// EncryptionConfig は保存するメンションのテキストの暗号化の設定
+// Key が空の場合は暗号化しない。鍵を切り替える場合は以前の鍵を PreviousKeys に残しておくと既存の行を復号できる
+type EncryptionConfig struct {
+	// Key はBase64でエンコードした32バイトの鍵（AES-256-GCM）
+	Key          string   `mapstructure:"key"`
+	PreviousKeys []string `mapstructure:"previous_keys"`
 }
 
 type SlackBotConfig struct {
+	// Name はワークスペースの名前。ログ・メトリクスのラベルと、保存するメンションやキューのメッセージに付与する
+	Name     string `mapstructure:"name"`
 	BotToken string `mapstructure:"bot_token"`
+	// AppToken はSocket Modeで接続するためのApp-Level Token（mode が webhook の場合は不要）
 	AppToken string `mapstructure:"app_token"`
+	// Debug はSlackクライアントとSocket Modeのデバッグログを出力するかどうか（log.level が debug の場合は無効でも出力する）
+	Debug bool `mapstructure:"debug"`
+	// Mode はイベントの受信方法（socket: Socket Mode（デフォルト）、webhook: HTTPSのEvents API）
+	Mode string `mapstructure:"mode"`
+	// SigningSecret はWebhookのリクエストの署名を検証するSigning Secret（mode が webhook の場合は必須）
+	SigningSecret string `mapstructure:"signing_secret"`
+	// QueueName はこのワークスペースのメッセージの送信先（空の場合は elasticmq の設定に従う）
+	QueueName   string            `mapstructure:"queue_name"`
+	OfficeHours OfficeHoursConfig `mapstructure:"office_hours"`
+	// StartupDelay は起動後にイベントの処理を開始するまでの待ち時間
+	StartupDelay time.Duration `mapstructure:"startup_delay"`
+}
+
+// イベントの受信方法（SlackBotConfig.Mode）
+const (
+	SlackModeSocket  = "socket"
+	SlackModeWebhook = "webhook"
+)
+
+// IsWebhook はWebhookでイベントを受信するワークスペースかどうかを返す
+func (c SlackBotConfig) IsWebhook() bool {
+	return c.Mode == SlackModeWebhook
+}
+
+// WebhookConfig は mode が webhook のワークスペースのイベントを受信するHTTPサーバーの設定
+// すべてのWebhookのワークスペースで共有し、リクエストの署名を検証できたワークスペースで処理する
+type WebhookConfig struct {
+	Addr string `mapstructure:"addr"`
+	// EventsPath, InteractionsPath はSlack Appの Event Subscriptions, Interactivity に設定するパス
+	EventsPath       string `mapstructure:"events_path"`
+	InteractionsPath string `mapstructure:"interactions_path"`
+}
+
+// OfficeHoursConfig はBotがメンションとリアクションによる依頼を受け付ける時間帯の設定
+// Schedule は曜日名（monday など）をキーに "09:00-18:00" 形式の時間帯を列挙する
+type OfficeHoursConfig struct {
+	Enabled  bool                `mapstructure:"enabled"`
+	Timezone string              `mapstructure:"timezone"`
+	Schedule map[string][]string `mapstructure:"schedule"`
 }
 
 type ElasticMQConfig struct {
-	Endpoint  string `mapstructure:"endpoint"`
+	Endpoint string `mapstructure:"endpoint"`
+	// QueueName は Queues に設定されていない種類のメッセージの送信先
 	QueueName string `mapstructure:"queue_name"`
+	// Queues はメッセージの種類（mention, reaction など）ごとの送信先のキュー名
+	Queues    map[string]string `mapstructure:"queues"`
+	Region    string            `mapstructure:"region"`
+	AccessKey string            `mapstructure:"access_key"`
+	SecretKey string            `mapstructure:"secret_key"`
+	// ResponseQueueName はAIワーカーの回答を受け取るキュー名（空の場合は受信しない）
+	ResponseQueueName string `mapstructure:"response_queue_name"`
+	// ResponseRetryDelay はSlackへの投稿に失敗した回答を再度受信するまでの時間
+	ResponseRetryDelay time.Duration        `mapstructure:"response_retry_delay"`
+	Batch              ElasticMQBatchConfig `mapstructure:"batch"`
+	Retry              ElasticMQRetryConfig `mapstructure:"retry"`
+	// ResponseConsumer は回答キューからの受信の設定
+	ResponseConsumer ResponseConsumerConfig `mapstructure:"response_consumer"`
+}
+
+// ResponseConsumerConfig は回答キューからメッセージを受信する ReceiveMessage の設定
+type ResponseConsumerConfig struct {
+	// WaitTimeSeconds はロングポーリングでメッセージを待つ秒数（0〜20。0 の場合はショートポーリング）
+	WaitTimeSeconds int `mapstructure:"wait_time_seconds"`
+	// MaxMessages は1回の受信で取得するメッセージ数の上限（1〜10）
+	MaxMessages int `mapstructure:"max_messages"`
+	// VisibilityTimeout は受信したメッセージを他の受信者から隠す時間（0 の場合はキューの設定に従う）
+	VisibilityTimeout time.Duration `mapstructure:"visibility_timeout"`
+	// EmptyReceiveBackoff はショートポーリングでメッセージがなかった場合に次の受信まで待つ時間
+	EmptyReceiveBackoff time.Duration `mapstructure:"empty_receive_backoff"`
+}
+
+// 受信の設定が ReceiveMessage で指定できる範囲かどうかを返す
+func (c ResponseConsumerConfig) valid() bool {
+	return c.WaitTimeSeconds >= 0 && c.WaitTimeSeconds <= 20 && c.MaxMessages >= 1 && c.MaxMessages <= 10 &&
+		c.VisibilityTimeout >= 0 && c.VisibilityTimeout <= 12*time.Hour && c.EmptyReceiveBackoff > 0
+}
+
+// ElasticMQBatchConfig は短時間に集中した送信を SendMessageBatch にまとめる設定
+type ElasticMQBatchConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Size は1回の SendMessageBatch で送信する最大件数（SQSの上限は10件）
+	Size int `mapstructure:"size"`
+	// FlushInterval は件数に達していなくても、最初のメッセージを受け取ってから送信するまでの待ち時間
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+// ElasticMQRetryConfig はメンションのキューへの送信に一時的に失敗した場合に再試行する設定
+// 待ち時間は BaseDelay から失敗するたびに倍にして MaxDelay で止め、Jitter の割合（0.0〜1.0）だけ前後にずらす
+// MaxAttempts 回（最初の送信を含む）失敗した場合に送信エラーとしてユーザーに返信する
+type ElasticMQRetryConfig struct {
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	BaseDelay   time.Duration `mapstructure:"base_delay"`
+	MaxDelay    time.Duration `mapstructure:"max_delay"`
+	Jitter      float64       `mapstructure:"jitter"`
+}
+
+// QueueConfig はAIワーカーへのメッセージの送信に使用するキューの設定
+// 送信先の名前はどのバックエンドでも elasticmq.queue_name / elasticmq.queues（ワークスペースの queue_name）を使用する
+type QueueConfig struct {
+	// Backend は送信に使用するバックエンド（sqs, redis, nats。空の場合は sqs）
+	Backend string           `mapstructure:"backend"`
+	Redis   RedisQueueConfig `mapstructure:"redis"`
+	NATS    NATSQueueConfig  `mapstructure:"nats"`
+}
+
+// 送信に使用するバックエンド（QueueConfig.Backend）
+const (
+	QueueBackendSQS   = "sqs"
+	QueueBackendRedis = "redis"
+	QueueBackendNATS  = "nats"
+)
+
+// RedisQueueConfig はRedis Streamsに送信する場合の設定
+type RedisQueueConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	// StreamPrefix はキュー名の前に付けるストリームのキーの接頭辞
+	StreamPrefix string `mapstructure:"stream_prefix"`
+	// MaxLen はストリームに残すおおよそのメッセージ数（0の場合は制限しない）
+	MaxLen int64 `mapstructure:"max_len"`
+}
+
+// NATSQueueConfig はNATSに送信する場合の設定
+type NATSQueueConfig struct {
+	URL string `mapstructure:"url"`
+	// SubjectPrefix はキュー名の前に付けるサブジェクトの接頭辞
+	SubjectPrefix string `mapstructure:"subject_prefix"`
+	// JetStream はJetStreamで送信し、ストリームへの保存の確認を待つかどうか
+	// false の場合はCore NATSで送信するため、購読しているワーカーがいないメッセージは失われる
+	JetStream bool `mapstructure:"jetstream"`
+	// ConnectTimeout は接続を確立するまでの最大の待ち時間
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+}
+
+// キューのキー。メッセージの種類（MessageSource）と同じ値を使用する
+const (
+	QueueKeyMention  = "mention"
+	QueueKeyReaction = "reaction"
+	QueueKeyFeedback = "feedback"
+	QueueKeyShortcut = "shortcut"
+	// QueueKeySlashCommand はスラッシュコマンド（/ask, /summarize）の質問を送信するキュー
+	QueueKeySlashCommand = "slash_command"
+	// QueueKeyInteraction はメッセージのボタンなどの操作を送信するキュー
+	QueueKeyInteraction = "interaction"
+)
+
+// WorkspaceQueueKey は queue_name を指定したワークスペースのメッセージの送信に使用するキー
+func WorkspaceQueueKey(workspace, key string) string {
+	return workspace + "/" + key
+}
+
+// QueueKey はワークスペースから msgType のメッセージを送信するキューのキーを返す
+// queue_name を指定したワークスペースは WorkspaceQueueKey、それ以外は msgType をそのまま使用する
+func (config *AppConfig) QueueKey(workspace, msgType string) string {
+	for _, w := range config.SlackWorkspaces() {
+		if w.Name == workspace && w.QueueName != "" {
+			return WorkspaceQueueKey(workspace, msgType)
+		}
+	}
+	return msgType
+}
+
+// QueueNameFor はキーに対応するキュー名を返す
+// Queues に設定されていない場合は QueueName を使用し、どちらもない場合は false を返す
+func (c ElasticMQConfig) QueueNameFor(key string) (string, bool) {
+	if name := c.Queues[key]; name != "" {
+		return name, true
+	}
+	return c.QueueName, c.QueueName != ""
+}
+
+// AccessControlConfig はBotを利用できるチャンネル・ユーザーを制限する設定
+// 拒否リストが許可リストより優先され、許可リストが空の場合はすべて許可する
+type AccessControlConfig struct {
+	AllowedChannels []string `mapstructure:"allowed_channels"`
+	DeniedChannels  []string `mapstructure:"denied_channels"`
+	AllowedUsers    []string `mapstructure:"allowed_users"`
+	DeniedUsers     []string `mapstructure:"denied_users"`
+	// AllowDirectMessages はダイレクトメッセージでの利用を許可するかどうか（AllowedChannels の対象外）
+	AllowDirectMessages bool `mapstructure:"allow_direct_messages"`
+	// NotifyDenied は利用が許可されていない場合にスレッドで通知するかどうか（false の場合はログのみ）
+	NotifyDenied bool `mapstructure:"notify_denied"`
+}
+
+// DatabaseConfig はメンションなどを保存するデータベースの設定
+// Enabled が false の場合はデータベースに接続せず、キューへの送信のみを行う
+type DatabaseConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	DSN     string `mapstructure:"dsn"`
+	// InMemory はデータベースの代わりにプロセスのメモリにメンションを保存するかどうか（ローカルでの動作確認用。再起動すると失われる）
+	InMemory bool `mapstructure:"in_memory"`
+	// MaxOpenConns, MaxIdleConns はコネクションプールの最大接続数と保持するアイドル接続数
+	MaxOpenConns int `mapstructure:"max_open_conns"`
+	MaxIdleConns int `mapstructure:"max_idle_conns"`
+	// ConnMaxLifetime は接続を再利用する最大の期間（0 の場合は無制限）
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+}
+
+// RetentionConfig は保持期間を過ぎたメンションの削除設定
+type RetentionConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	MaxAge    time.Duration `mapstructure:"max_age"`
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batch_size"`
+	Archive   ArchiveConfig `mapstructure:"archive"`
+}
+
+// ArchiveConfig は削除前にメンションをS3へJSONLとして退避する設定
+type ArchiveConfig struct {
+	Enabled  bool `mapstructure:"enabled"`
+	S3Config `mapstructure:",squash"`
+}
+
+// AttachmentsConfig はメンションに添付されたファイルの扱いに関する設定
+type AttachmentsConfig struct {
+	MaxFiles         int                    `mapstructure:"max_files"`
+	MaxSize          int                    `mapstructure:"max_size"`
+	AllowedMimetypes []string               `mapstructure:"allowed_mimetypes"`
+	Upload           AttachmentUploadConfig `mapstructure:"upload"`
+}
+
+// AttachmentUploadConfig はファイルをダウンロードしてS3互換ストレージに転送する設定
+// 有効な場合、キューにはSlackのURLの代わりにオブジェクトキーを含める
+type AttachmentUploadConfig struct {
+	Enabled  bool `mapstructure:"enabled"`
+	S3Config `mapstructure:",squash"`
+}
+
+// S3Config はS3互換ストレージへの接続設定
+// AccessKey が空の場合はAWS SDKのデフォルト認証情報を使用する
+type S3Config struct {
+	Bucket    string `mapstructure:"bucket"`
+	Prefix    string `mapstructure:"prefix"`
 	Region    string `mapstructure:"region"`
+	Endpoint  string `mapstructure:"endpoint"`
 	AccessKey string `mapstructure:"access_key"`
 	SecretKey string `mapstructure:"secret_key"`
 }
 
+// ReactionConfig は絵文字リアクションでメッセージをAIに送信する設定
+// 同じメッセージへのリアクションは DedupTTL の間1回だけ処理する
+type ReactionConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Reactions []string      `mapstructure:"reactions"`
+	DedupTTL  time.Duration `mapstructure:"dedup_ttl"`
+}
+
+// ShortcutConfig はメッセージショートカット（「AIに質問」）でメッセージについて質問する設定
+// CallbackID はSlack Appに登録したメッセージショートカットの Callback ID
+type ShortcutConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	CallbackID string `mapstructure:"callback_id"`
+}
+
+// SlashCommandsConfig はSocket Modeで受信したスラッシュコマンド（/ask, /summarize, /help）を処理する設定
+// コマンドはSlack Appに登録しておく。質問は投稿したメッセージのスレッドで回答する
+type SlashCommandsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// AnswerActionsConfig は回答キューの回答に「再生成」「根拠を表示」「削除」のボタンを付ける設定
+// 再生成・削除は質問したユーザーと管理者 (admin.user_ids) のみ実行でき、質問したユーザーの判定と再生成にはデータベースを使用する
+type AnswerActionsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// ApprovalConfig はAIワーカーの回答の下書きをモデレーターが承認・却下する設定
+// 下書きは ChannelID のチャンネルにボタン付きで投稿し、押されたボタンをAIワーカーに送信する
+// ModeratorIDs が空の場合はチャンネルのすべてのユーザーが承認・却下できる
+type ApprovalConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	ChannelID    string   `mapstructure:"channel_id"`
+	ModeratorIDs []string `mapstructure:"moderator_ids"`
+}
+
+// FeedbackConfig はBotが投稿したメッセージへのリアクションを回答へのフィードバックとしてAIに送信する設定
+// Reactions が空の場合はすべてのリアクションを送信する
+type FeedbackConfig struct {
+	Enabled   bool     `mapstructure:"enabled"`
+	Reactions []string `mapstructure:"reactions"`
+}
+
+// ThreadContextConfig はスレッド内のメンションに添付する会話履歴の上限
+type ThreadContextConfig struct {
+	MaxMessages int `mapstructure:"max_messages"`
+	MaxChars    int `mapstructure:"max_chars"`
+}
+
+// TracingConfig はOpenTelemetryによるトレースの設定
+// SampleRate は親スパンを持たないイベントをトレースする割合（0.0〜1.0）
+type TracingConfig struct {
+	Enabled    bool    `mapstructure:"enabled"`
+	SampleRate float64 `mapstructure:"sample_rate"`
+	// ServiceName はスパンに記録するサービス名（service.name）
+	ServiceName string     `mapstructure:"service_name"`
+	OTLP        OTLPConfig `mapstructure:"otlp"`
+}
+
+// OTLPConfig は記録したスパンをOTLP/HTTPで送信する設定（Jaeger・Tempo・OpenTelemetry Collectorなど）
+// Endpoint は送信先のURL（例: http://localhost:4318/v1/traces）で、空の場合は送信しない
+type OTLPConfig struct {
+	Endpoint string            `mapstructure:"endpoint"`
+	Headers  map[string]string `mapstructure:"headers"`
+	Timeout  time.Duration     `mapstructure:"timeout"`
+}
+
+// RateLimitConfig はユーザーごとのメンション数の制限
+// Window の間に MaxRequests 回を超えたメンションはキューに送信しない
+// ExemptChannels に含まれるチャンネルでは制限しない
+type RateLimitConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	MaxRequests    int           `mapstructure:"max_requests"`
+	Window         time.Duration `mapstructure:"window"`
+	ExemptChannels []string      `mapstructure:"exempt_channels"`
+}
+
+// LoopGuardConfig はBot同士の応答の繰り返しを止めるための、スレッドごとの応答数の制限
+// Window の間に同じスレッドで MaxThreadResponses 回を超えたメンションはキューに送信せず、一度だけ通知する
+type LoopGuardConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	MaxThreadResponses int           `mapstructure:"max_thread_responses"`
+	Window             time.Duration `mapstructure:"window"`
+}
+
+// ChannelMessagesConfig はBotがチャンネルのすべてのメッセージ（message イベント）を読む場合の設定
+// AddressedOnly が true の場合は Prefixes のいずれかで始まるメッセージだけを処理し、それ以外の会話は無視する
+// Botへのメンションを含むメッセージは app_mention イベントとして処理するため対象外
+type ChannelMessagesConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Channels はすべてのメッセージを読むチャンネルID（空の場合はBotが参加しているすべてのチャンネル）
+	Channels      []string `mapstructure:"channels"`
+	AddressedOnly bool     `mapstructure:"addressed_only"`
+	Prefixes      []string `mapstructure:"prefixes"`
+}
+
+// DirectMessagesConfig はBotへのダイレクトメッセージ（message.im イベント）を処理する設定
+// 利用できるユーザーは access_control（allow_direct_messages など）で制限する
+type DirectMessagesConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// BroadcastConfig は @here / @channel / @everyone を含むメッセージの扱い
+// 含まれている場合はキューのメッセージに broadcast_mention: true を付与し、
+// Strip が有効な場合はテキストから取り除いて送信する
+type BroadcastConfig struct {
+	Strip bool `mapstructure:"strip"`
+}
+
+// HTTPServerConfig は運用向けエンドポイント（/metrics など）を公開するHTTPサーバーの設定
+type HTTPServerConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	Addr        string `mapstructure:"addr"`
+	MetricsPath string `mapstructure:"metrics_path"`
+	// ReadyzPath は処理を受け付けられるか（管理コマンドで一時停止していないか）を返すパス
+	ReadyzPath string `mapstructure:"readyz_path"`
+}
+
+// PushgatewayConfig はメトリクスをPrometheus Pushgatewayに送信する設定
+// スクレイプできない環境向けで、有効にしても http_server のエンドポイントは引き続き利用できる
+type PushgatewayConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	URL      string        `mapstructure:"url"`
+	Job      string        `mapstructure:"job"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// MentionConfig はキューに送信するメッセージのテキストの制限
+// TruncateText が false の場合、MaxTextLength（文字数）を超えるメッセージは受け付けない
+type MentionConfig struct {
+	MaxTextLength int  `mapstructure:"max_text_length"`
+	TruncateText  bool `mapstructure:"truncate_text"`
+	// DeterministicID はメンションのIDをイベントのチャンネルとタイムスタンプから導出するかどうか
+	// リアクションによる依頼はリアクションしたユーザーとリアクションの時刻も含めて導出する
+	// 同じイベントを再処理した場合に重複して保存しない
+	DeterministicID bool `mapstructure:"deterministic_id"`
+	// StoreSQSMessageID はキューへの送信後にSQSのメッセージID (MessageId) をメンションに記録するかどうか
+	// データベースの行とキューのメッセージ・AIワーカーのログを対応付けるために使用する
+	StoreSQSMessageID bool `mapstructure:"store_sqs_message_id"`
+}
+
+// OutboxConfig はメンションをデータベースに保存してからバックグラウンドでキューに送信する設定
+// 無効な場合はイベントの処理中に直接キューに送信する
+type OutboxConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	Lease        time.Duration `mapstructure:"lease"`
+	MaxBackoff   time.Duration `mapstructure:"max_backoff"`
+}
+
+// EnrichmentConfig はキューに送信するメッセージに情報を付加する処理の設定
+// Steps に記載した順番で実行し、Enabled が false の処理は実行しない
+type EnrichmentConfig struct {
+	Steps []EnrichmentStepConfig `mapstructure:"steps"`
+	// LocaleCacheTTL はユーザーのロケール（locale）をキャッシュする時間
+	LocaleCacheTTL time.Duration   `mapstructure:"locale_cache_ttl"`
+	InfoAPI        InfoAPIConfig   `mapstructure:"info_api"`
+	UserCache      UserCacheConfig `mapstructure:"user_cache"`
+}
+
+// UserCacheConfig はメンションしたユーザーの表示名・氏名のキャッシュの設定
+// Size を超えた場合は期限が最も近いユーザーから削除する
+type UserCacheConfig struct {
+	TTL  time.Duration `mapstructure:"ttl"`
+	Size int           `mapstructure:"size"`
+}
+
+// InfoAPIConfig はユーザー・チャンネルの情報を取得するAPI（users.info, conversations.info）の呼び出し頻度の制限
+// ワークスペースごとに RequestsPerMinute の頻度まで呼び出し、Burst 回までは待たずに呼び出す
+// Slackのレート制限に達した場合は Retry-After の時間待ってから MaxRetries 回まで再試行する
+type InfoAPIConfig struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute"`
+	Burst             int `mapstructure:"burst"`
+	MaxRetries        int `mapstructure:"max_retries"`
+}
+
+// Interval は呼び出しの間隔を返す
+func (c InfoAPIConfig) Interval() time.Duration {
+	return time.Minute / time.Duration(c.RequestsPerMinute)
+}
+
+type EnrichmentStepConfig struct {
+	Name    string `mapstructure:"name"`
+	Enabled bool   `mapstructure:"enabled"`
+}
+
+// EnabledSteps は有効な付加処理の名前を実行順に返す
+func (c EnrichmentConfig) EnabledSteps() []string {
+	var names []string
+	for _, step := range c.Steps {
+		if step.Enabled {
+			names = append(names, step.Name)
+		}
+	}
+	return names
+}
+
+// AutoReplyConfig はAIに送信せずに定型文で返信するメッセージの設定
+// Rules は定義順に評価し、メンションを取り除いたテキストが Pattern（正規表現）に一致した最初の規則の Reply を返信する
+type AutoReplyConfig struct {
+	Enabled bool                  `mapstructure:"enabled"`
+	Rules   []AutoReplyRuleConfig `mapstructure:"rules"`
+}
+
+type AutoReplyRuleConfig struct {
+	Name    string `mapstructure:"name"`
+	Pattern string `mapstructure:"pattern"`
+	Reply   string `mapstructure:"reply"`
+}
+
+// ThreadDedupConfig は同じスレッドで続けてメンションされた場合の集約の設定
+// 最初のメンションから Window の間に同じスレッドで受けたメンションは、最後のメンションのみをキューに送信する
+type ThreadDedupConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Window  time.Duration `mapstructure:"window"`
+}
+
+// EventDedupConfig はSlackが再送したイベントの重複の排除の設定
+// 有効な場合は event_id（ない場合は client_msg_id）ごとに TTL の間1回だけ処理する
+// データベースが有効な場合は processed_events に保存し、無効な場合はプロセスのメモリに最大 CacheSize 件保持する
+type EventDedupConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	TTL       time.Duration `mapstructure:"ttl"`
+	CacheSize int           `mapstructure:"cache_size"`
+}
+
+// DigestConfig は利用状況のまとめを管理者用チャンネルに投稿する設定
+// AdminChannelID が空の場合は投稿しない。Schedule はcron形式（CRON_TZ= でタイムゾーンを指定できる）
+// TeamID は複数のワークスペースに接続している場合の投稿先のワークスペース
+type DigestConfig struct {
+	AdminChannelID string `mapstructure:"admin_channel_id"`
+	Schedule       string `mapstructure:"schedule"`
+	TeamID         string `mapstructure:"team_id"`
+}
+
 func NewAppConfig() (*AppConfig, error) {
+	return NewAppConfigFromPath(resolveConfigPath())
+}
+
+// NewAppConfigFromPath は指定されたパスの設定ファイルを読み込み、--set と環境変数で上書きする
+// パスが空の場合はローカル開発用の ./config, ./slack_bot/config から config.yml を探索し、見つからない場合は --set と環境変数のみを使用する
+func NewAppConfigFromPath(path string) (*AppConfig, error) {
+	return loadAppConfig(path, *configSets)
+}
+
+// resolveConfigPath は --config フラグ、CONFIG_PATH 環境変数の順に設定ファイルのパスを解決する
+// どちらも指定されていない場合は空文字を返す
+func resolveConfigPath() string {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	if *configPath != "" {
+		return *configPath
+	}
+	return os.Getenv(configPathEnv)
+}
+
+func loadAppConfig(path string, sets setFlag) (*AppConfig, error) {
 	v := viper.New()
-	v.SetConfigName("config")
-	v.SetConfigType("yml")
-	v.AddConfigPath("./config")
-	v.AddConfigPath("./slack_bot/config")
+	if path != "" {
+		v.SetConfigFile(path)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yml")
+		v.AddConfigPath("./config")
+		v.AddConfigPath("./slack_bot/config")
+	}
+
+	v.SetDefault("slack_bot.name", "default")
+	v.SetDefault("slack_bot.office_hours.timezone", "Asia/Tokyo")
+	v.SetDefault("elasticmq.response_retry_delay", 30*time.Second)
+	v.SetDefault("worker.provider", AIProviderOpenAI)
+	v.SetDefault("worker.queue_keys", []string{QueueKeyMention})
+	v.SetDefault("worker.system_prompt", "あなたはSlackのワークスペースで質問に回答するアシスタントです。簡潔に回答してください。")
+	v.SetDefault("worker.retry_delay", 30*time.Second)
+	v.SetDefault("worker.consumer.wait_time_seconds", 20)
+	v.SetDefault("worker.consumer.max_messages", 10)
+	v.SetDefault("worker.consumer.empty_receive_backoff", time.Second)
+	v.SetDefault("worker.openai.base_url", "https://api.openai.com/v1")
+	v.SetDefault("worker.openai.model", "gpt-4o-mini")
+	v.SetDefault("worker.openai.timeout", 60*time.Second)
+	v.SetDefault("worker.anthropic.base_url", "https://api.anthropic.com/v1")
+	v.SetDefault("worker.anthropic.model", "claude-3-5-haiku-latest")
+	v.SetDefault("worker.anthropic.max_tokens", 1024)
+	v.SetDefault("worker.anthropic.timeout", 60*time.Second)
+	v.SetDefault("worker.bedrock.region", "us-east-1")
+	v.SetDefault("worker.bedrock.model_id", "anthropic.claude-3-5-haiku-20241022-v1:0")
+	v.SetDefault("worker.bedrock.max_tokens", 1024)
+	v.SetDefault("worker.bedrock.timeout", 60*time.Second)
+	v.SetDefault("worker.streaming.placeholder", ":hourglass_flowing_sand: 回答を生成しています…")
+	v.SetDefault("worker.streaming.flush_interval", time.Second)
+	v.SetDefault("worker.streaming.max_updates_per_minute", 20)
+	v.SetDefault("elasticmq.batch.size", 10)
+	v.SetDefault("elasticmq.response_consumer.wait_time_seconds", 20)
+	v.SetDefault("elasticmq.response_consumer.max_messages", 10)
+	v.SetDefault("elasticmq.response_consumer.empty_receive_backoff", time.Second)
+	v.SetDefault("elasticmq.batch.flush_interval", 200*time.Millisecond)
+	v.SetDefault("elasticmq.retry.max_attempts", 3)
+	v.SetDefault("elasticmq.retry.base_delay", 200*time.Millisecond)
+	v.SetDefault("elasticmq.retry.max_delay", 2*time.Second)
+	v.SetDefault("elasticmq.retry.jitter", 0.2)
+	v.SetDefault("dead_letter.max_receives", 5)
+	v.SetDefault("queue.backend", QueueBackendSQS)
+	v.SetDefault("queue.redis.addr", "localhost:6379")
+	v.SetDefault("queue.nats.url", "nats://localhost:4222")
+	v.SetDefault("queue.nats.connect_timeout", 5*time.Second)
+	v.SetDefault("access_control.allow_direct_messages", true)
+	v.SetDefault("access_control.notify_denied", true)
+	v.SetDefault("retention.max_age", 30*24*time.Hour)
+	v.SetDefault("retention.interval", 24*time.Hour)
+	v.SetDefault("retention.batch_size", 500)
+	v.SetDefault("retention.archive.prefix", "slack-mentions/")
+	v.SetDefault("thread_context.max_messages", 20)
+	v.SetDefault("thread_context.max_chars", 4000)
+	v.SetDefault("reaction.reactions", []string{"robot_face"})
+	v.SetDefault("reaction.dedup_ttl", 10*time.Minute)
+	v.SetDefault("shortcut.callback_id", "ask_ai")
+	v.SetDefault("attachments.max_files", 5)
+	v.SetDefault("attachments.max_size", 10*1024*1024)
+	v.SetDefault("attachments.upload.prefix", "slack-files/")
+	v.SetDefault("tracing.sample_rate", 1.0)
+	v.SetDefault("tracing.service_name", "ai-slack-bot")
+	v.SetDefault("tracing.otlp.timeout", 10*time.Second)
+	v.SetDefault("rate_limit.max_requests", 5)
+	v.SetDefault("rate_limit.window", time.Minute)
+	v.SetDefault("database.enabled", true)
+	v.SetDefault("database.max_open_conns", 10)
+	v.SetDefault("database.max_idle_conns", 5)
+	v.SetDefault("database.conn_max_lifetime", 30*time.Minute)
+	v.SetDefault("i18n.default_lang", "ja")
+	v.SetDefault("loop_guard.enabled", true)
+	v.SetDefault("channel_messages.addressed_only", true)
+	v.SetDefault("loop_guard.max_thread_responses", 20)
+	v.SetDefault("loop_guard.window", 10*time.Minute)
+	v.SetDefault("event_workers.size", 8)
+	v.SetDefault("event_workers.queue_size", 100)
+	v.SetDefault("event_workers.timeout", 10*time.Second)
+	v.SetDefault("event_workers.drain_timeout", 30*time.Second)
+	v.SetDefault("secrets.timeout", 10*time.Second)
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.format", LogFormatText)
+	v.SetDefault("socket_mode.initial_backoff", time.Second)
+	v.SetDefault("socket_mode.max_backoff", time.Minute)
+	v.SetDefault("socket_mode.stable_after", 30*time.Second)
+	v.SetDefault("socket_mode.max_fatal_failures", 1)
+	v.SetDefault("progress.ttl", 30*time.Minute)
+	v.SetDefault("conversation.ttl", 24*time.Hour)
+	v.SetDefault("post_message.max_retries", 2)
+	v.SetDefault("post_message.max_retry_wait", 30*time.Second)
+	v.SetDefault("admin.export.max_rows", 10000)
+	v.SetDefault("admin.export.max_text_length", 500)
+	v.SetDefault("app_home.enabled", true)
+	v.SetDefault("app_home.recent_limit", 10)
+	v.SetDefault("http_server.addr", ":8080")
+	v.SetDefault("webhook.addr", ":3000")
+	v.SetDefault("webhook.events_path", "/slack/events")
+	v.SetDefault("webhook.interactions_path", "/slack/interactions")
+	v.SetDefault("http_server.metrics_path", "/metrics")
+	v.SetDefault("http_server.readyz_path", "/readyz")
+	v.SetDefault("pushgateway.job", "slack_bot")
+	v.SetDefault("pushgateway.interval", 15*time.Second)
+	v.SetDefault("mention.max_text_length", 10000)
+	v.SetDefault("thread_dedup.window", 5*time.Second)
+	v.SetDefault("event_dedup.ttl", time.Hour)
+	v.SetDefault("event_dedup.cache_size", 10000)
+	v.SetDefault("enrichment.locale_cache_ttl", time.Hour)
+	v.SetDefault("enrichment.info_api.requests_per_minute", 50)
+	v.SetDefault("enrichment.user_cache.ttl", time.Hour)
+	v.SetDefault("enrichment.user_cache.size", 10000)
+	v.SetDefault("enrichment.info_api.burst", 10)
+	v.SetDefault("enrichment.info_api.max_retries", 3)
+	v.SetDefault("digest.schedule", "CRON_TZ=Asia/Tokyo 0 9 * * *")
+	v.SetDefault("outbox.poll_interval", 500*time.Millisecond)
+	v.SetDefault("outbox.batch_size", 100)
+	v.SetDefault("outbox.lease", 30*time.Second)
+	v.SetDefault("outbox.max_backoff", 5*time.Minute)
 
 	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("設定ファイルの読み込みに失敗しました: %w", err)
+		// コンテナなど設定ファイルを置かない環境では、探索して見つからない場合も環境変数だけで設定できるようにする
+		var notFound viper.ConfigFileNotFoundError
+		if path != "" || !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("設定ファイルの読み込みに失敗しました: %w", err)
+		}
+	}
+	// --set は設定ファイルに重ねて読み込み、環境変数は viper が最も優先する
+	if err := v.MergeConfigMap(sets.toMap()); err != nil {
+		return nil, fmt.Errorf("--set の値の読み込みに失敗しました: %w", err)
+	}
+	if err := bindEnv(v); err != nil {
+		return nil, fmt.Errorf("環境変数の設定に失敗しました: %w", err)
+	}
+
+	// 型の誤りは Unmarshal のエラーだと原因の項目が分かりにくいため、先に検証して項目ごとに報告する
+	if err := validateTypes(v.AllSettings()); err != nil {
+		return nil, fmt.Errorf("設定ファイルの値が不正です:\n%w", err)
 	}
 
 	var config AppConfig
@@ -45,13 +943,308 @@ func NewAppConfig() (*AppConfig, error) {
 		return nil, fmt.Errorf("設定ファイルのパースに失敗しました: %w", err)
 	}
 
+	// リージョンが未設定の場合はAWS SDKと同じ環境変数から補完する
+	if config.ElasticMQ.Region == "" {
+		config.ElasticMQ.Region = regionFromEnv()
+	}
+	if config.Retention.Archive.Region == "" {
+		config.Retention.Archive.Region = regionFromEnv()
+	}
+	if config.Attachments.Upload.Region == "" {
+		config.Attachments.Upload.Region = regionFromEnv()
+	}
+	if config.Secrets.Region == "" {
+		config.Secrets.Region = regionFromEnv()
+	}
+
+	// トークンなどの必須項目を検証する前に、シークレットストアから取得する値を置き換える
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, config.Log.Level) {
+		return nil, fmt.Errorf("ログの出力レベル (log.level) には debug, info, warn, error のいずれかを指定してください: %s", config.Log.Level)
+	}
+	if !slices.Contains([]string{LogFormatText, LogFormatJSON}, config.Log.Format) {
+		return nil, fmt.Errorf("ログの出力形式 (log.format) には %s または %s を指定してください: %s", LogFormatText, LogFormatJSON, config.Log.Format)
+	}
+	if !slices.Contains([]string{"", SecretsProviderSecretsManager, SecretsProviderSSM}, config.Secrets.Provider) {
+		return nil, fmt.Errorf("シークレットの取得元 (secrets.provider) には %s または %s を指定してください: %s", SecretsProviderSecretsManager, SecretsProviderSSM, config.Secrets.Provider)
+	}
+	if config.Secrets.Timeout <= 0 {
+		return nil, fmt.Errorf("シークレットの取得の待ち時間 (secrets.timeout) には正の値を指定してください")
+	}
+	if err := config.resolveSecrets(newSecretFetcher); err != nil {
+		return nil, err
+	}
+
+	for i := range config.Workspaces {
+		if config.Workspaces[i].OfficeHours.Timezone == "" {
+			config.Workspaces[i].OfficeHours.Timezone = config.SlackBot.OfficeHours.Timezone
+		}
+	}
+
+	// ワークスペースごとの送信先を elasticmq.queues に登録し、他のキューと同様に解決できるようにする
+	for _, workspace := range config.SlackWorkspaces() {
+		if workspace.QueueName == "" {
+			continue
+		}
+		if config.ElasticMQ.Queues == nil {
+			config.ElasticMQ.Queues = make(map[string]string)
+		}
+		for _, key := range config.messageQueueKeys() {
+			config.ElasticMQ.Queues[WorkspaceQueueKey(workspace.Name, key)] = workspace.QueueName
+		}
+	}
+
 	// 必須項目の検証
-	if config.SlackBot.BotToken == "" {
-		return nil, fmt.Errorf("Slack Bot Token (slack_bot.bot_token) が設定されていません")
+	names := make(map[string]bool)
+	for i, workspace := range config.SlackWorkspaces() {
+		key := "slack_bot"
+		if len(config.Workspaces) > 0 {
+			key = fmt.Sprintf("workspaces[%d]", i)
+		}
+		if workspace.Name == "" {
+			return nil, fmt.Errorf("ワークスペースの名前 (%s.name) が設定されていません", key)
+		}
+		if names[workspace.Name] {
+			return nil, fmt.Errorf("ワークスペースの名前 (%s.name) が重複しています: %s", key, workspace.Name)
+		}
+		names[workspace.Name] = true
+		if workspace.BotToken == "" {
+			return nil, fmt.Errorf("Slack Bot Token (%s.bot_token) が設定されていません", key)
+		}
+		switch workspace.Mode {
+		case "", SlackModeSocket:
+			if workspace.AppToken == "" {
+				return nil, fmt.Errorf("Slack App Token (%s.app_token) が設定されていません", key)
+			}
+		case SlackModeWebhook:
+			if workspace.SigningSecret == "" {
+				return nil, fmt.Errorf("Webhookで受信する場合は Signing Secret (%s.signing_secret) を設定してください", key)
+			}
+		default:
+			return nil, fmt.Errorf("イベントの受信方法 (%s.mode) には socket または webhook を指定してください: %s", key, workspace.Mode)
+		}
+	}
+	switch config.Queue.Backend {
+	case QueueBackendSQS:
+	case QueueBackendRedis:
+		if config.Queue.Redis.Addr == "" || config.Queue.Redis.MaxLen < 0 {
+			return nil, fmt.Errorf("Redisのアドレス (queue.redis.addr) を設定し、ストリームの最大長 (queue.redis.max_len) は0以上を指定してください")
+		}
+	case QueueBackendNATS:
+		if config.Queue.NATS.URL == "" || config.Queue.NATS.ConnectTimeout <= 0 {
+			return nil, fmt.Errorf("NATSのURL (queue.nats.url) を設定し、接続の待ち時間 (queue.nats.connect_timeout) は正の値を指定してください")
+		}
+	default:
+		return nil, fmt.Errorf("キューのバックエンド (queue.backend) には sqs, redis, nats のいずれかを指定してください: %s", config.Queue.Backend)
+	}
+	// 回答キューはバックエンドに関わらずElasticMQから受信する
+	if (config.Queue.Backend == QueueBackendSQS || config.ElasticMQ.ResponseQueueName != "") && config.ElasticMQ.Region == "" {
+		return nil, fmt.Errorf("ElasticMQのリージョン (elasticmq.region) が設定されていません。設定ファイルまたは環境変数 AWS_REGION / AWS_DEFAULT_REGION で指定してください")
+	}
+	for _, key := range config.QueueKeys() {
+		if _, ok := config.ElasticMQ.QueueNameFor(key); !ok {
+			return nil, fmt.Errorf("%s の送信先のキュー (elasticmq.queues.%s または elasticmq.queue_name) が設定されていません", key, key)
+		}
+	}
+	if !config.ElasticMQ.ResponseConsumer.valid() {
+		return nil, fmt.Errorf("回答キューの受信の設定が不正です。elasticmq.response_consumer の wait_time_seconds は0〜20、max_messages は1〜10、visibility_timeout は0〜12時間、empty_receive_backoff は正の値を指定してください")
+	}
+	if !slices.Contains([]string{AIProviderOpenAI, AIProviderAnthropic, AIProviderBedrock}, config.Worker.Provider) {
+		return nil, fmt.Errorf("ワーカーのAIプロバイダー (worker.provider) には %s, %s, %s のいずれかを指定してください: %s", AIProviderOpenAI, AIProviderAnthropic, AIProviderBedrock, config.Worker.Provider)
+	}
+	if !config.Worker.Consumer.valid() || config.Worker.RetryDelay < 0 || config.Worker.OpenAI.Timeout <= 0 || config.Worker.OpenAI.MaxTokens < 0 {
+		return nil, fmt.Errorf("ワーカーの設定が不正です。worker.consumer は elasticmq.response_consumer と同じ範囲、retry_delay は0以上、openai.timeout は正の値、openai.max_tokens は0以上を指定してください")
+	}
+	if config.Worker.Anthropic.Timeout <= 0 || config.Worker.Anthropic.MaxTokens <= 0 || config.Worker.Bedrock.Timeout <= 0 || config.Worker.Bedrock.MaxTokens <= 0 {
+		return nil, fmt.Errorf("ワーカーのAIプロバイダーの設定が不正です。worker.anthropic, worker.bedrock の timeout と max_tokens は正の値を指定してください")
+	}
+	if config.Worker.Streaming.Enabled && (config.Worker.Streaming.Placeholder == "" || config.Worker.Streaming.FlushInterval <= 0 || config.Worker.Streaming.MaxUpdatesPerMinute <= 0) {
+		return nil, fmt.Errorf("回答のストリーミングの設定が不正です。worker.streaming の placeholder は空以外、flush_interval と max_updates_per_minute は正の値を指定してください")
+	}
+	if config.ElasticMQ.Batch.Enabled && (config.ElasticMQ.Batch.Size < 1 || config.ElasticMQ.Batch.Size > 10 || config.ElasticMQ.Batch.FlushInterval <= 0) {
+		return nil, fmt.Errorf("一括送信の件数 (elasticmq.batch.size) は1〜10、待ち時間 (elasticmq.batch.flush_interval) は正の値を指定してください")
+	}
+	if r := config.ElasticMQ.Retry; r.MaxAttempts < 1 || r.BaseDelay <= 0 || r.MaxDelay < r.BaseDelay || r.Jitter < 0 || r.Jitter > 1 {
+		return nil, fmt.Errorf("キューへの送信の再試行の設定が不正です。elasticmq.retry の max_attempts は1以上、base_delay は正の値、max_delay は base_delay 以上、jitter は0.0〜1.0を指定してください")
+	}
+	if config.DeadLetter.MaxReceives < 0 {
+		return nil, fmt.Errorf("デッドレターに移すまでの受信回数 (dead_letter.max_receives) には0以上の値を指定してください: %d", config.DeadLetter.MaxReceives)
+	}
+	if config.Database.Enabled && (config.Database.MaxOpenConns <= 0 || config.Database.MaxIdleConns < 0 || config.Database.MaxIdleConns > config.Database.MaxOpenConns || config.Database.ConnMaxLifetime < 0) {
+		return nil, fmt.Errorf("データベースの最大接続数 (database.max_open_conns) は正の値、アイドル接続数 (database.max_idle_conns) は0以上で最大接続数以下、接続の再利用期間 (database.conn_max_lifetime) は0以上を指定してください")
+	}
+	if config.Database.Enabled && config.Database.InMemory {
+		return nil, fmt.Errorf("database.enabled と database.in_memory は同時に有効にできません")
+	}
+	if !config.Database.Enabled {
+		// データベースに保存したメンションやメッセージを使用する機能は無効にする必要がある
+		requiresDatabase := []struct {
+			key     string
+			enabled bool
+		}{
+			{"outbox.enabled", config.Outbox.Enabled},
+			{"retention.enabled", config.Retention.Enabled},
+			{"dead_letter.enabled", config.DeadLetter.Enabled},
+			{"conversation.enabled", config.Conversation.Enabled},
+			{"deleted_messages.enabled", config.DeletedMessages.Enabled},
+			{"digest.admin_channel_id", config.Digest.AdminChannelID != ""},
+			{"mention.store_sqs_message_id", config.Mention.StoreSQSMessageID},
+		}
+		for _, feature := range requiresDatabase {
+			if feature.enabled {
+				return nil, fmt.Errorf("%s はデータベースを使用するため、database.enabled を false にする場合は無効にしてください", feature.key)
+			}
+		}
+	}
+	if config.DeletedMessages.Enabled && !config.Mention.DeterministicID {
+		return nil, fmt.Errorf("削除されたメッセージのメンションを特定するため、deleted_messages.enabled を有効にする場合は mention.deterministic_id も有効にしてください")
+	}
+	if config.Shortcut.Enabled && config.Shortcut.CallbackID == "" {
+		return nil, fmt.Errorf("メッセージショートカットの Callback ID (shortcut.callback_id) が設定されていません")
+	}
+	if config.Approval.Enabled && (config.Approval.ChannelID == "" || config.ElasticMQ.ResponseQueueName == "") {
+		return nil, fmt.Errorf("回答の承認 (approval.enabled) を有効にする場合は、下書きを投稿するチャンネル (approval.channel_id) と回答キュー (elasticmq.response_queue_name) を設定してください")
+	}
+	channelSettings := make(map[string]bool)
+	for _, setting := range config.ChannelSettings.Channels {
+		if setting.ChannelID == "" {
+			return nil, fmt.Errorf("チャンネルの設定 (channel_settings.channels) に channel_id が設定されていません")
+		}
+		if channelSettings[setting.ChannelID] {
+			return nil, fmt.Errorf("チャンネルの設定 (channel_settings.channels) で %s が重複しています", setting.ChannelID)
+		}
+		channelSettings[setting.ChannelID] = true
+	}
+	if config.PostMessage.MaxRetries < 0 || config.PostMessage.MaxRetryWait < 0 {
+		return nil, fmt.Errorf("投稿の再試行回数 (post_message.max_retries) と待ち時間の上限 (post_message.max_retry_wait) には0以上の値を指定してください")
+	}
+	if config.Admin.Export.MaxRows < 1 || config.Admin.Export.MaxTextLength < 0 {
+		return nil, fmt.Errorf("エクスポートの最大件数 (admin.export.max_rows) には正の値、テキストの最大文字数 (admin.export.max_text_length) には0以上の値を指定してください")
+	}
+	if config.AppHome.Enabled && (config.AppHome.RecentLimit < 1 || config.AppHome.RecentLimit > 50) {
+		return nil, fmt.Errorf("Homeタブに表示する質問の件数 (app_home.recent_limit) は1〜50を指定してください")
+	}
+	if config.Conversation.Enabled && config.Conversation.TTL <= 0 {
+		return nil, fmt.Errorf("会話の有効期間 (conversation.ttl) には正の値を指定してください")
+	}
+	if config.Conversation.StoreMessages && !config.Conversation.Enabled {
+		return nil, fmt.Errorf("conversation.store_messages を有効にする場合は conversation.enabled も有効にしてください")
+	}
+	if config.Retention.Enabled && config.Retention.Archive.Enabled && config.Retention.Archive.Bucket == "" {
+		return nil, fmt.Errorf("アーカイブ先のバケット (retention.archive.bucket) が設定されていません")
+	}
+	if config.Attachments.Upload.Enabled && config.Attachments.Upload.Bucket == "" {
+		return nil, fmt.Errorf("添付ファイルの転送先バケット (attachments.upload.bucket) が設定されていません")
+	}
+	if config.RateLimit.Enabled && (config.RateLimit.MaxRequests <= 0 || config.RateLimit.Window <= 0) {
+		return nil, fmt.Errorf("レート制限 (rate_limit.max_requests, rate_limit.window) には正の値を指定してください")
+	}
+	if config.ChannelMessages.Enabled && config.ChannelMessages.AddressedOnly && len(config.ChannelMessages.Prefixes) == 0 {
+		return nil, fmt.Errorf("メンション以外で処理するメッセージのプレフィックス (channel_messages.prefixes) を設定するか、channel_messages.addressed_only を false にしてください")
 	}
-	if config.SlackBot.AppToken == "" {
-		return nil, fmt.Errorf("Slack App Token (slack_bot.app_token) が設定されていません")
+	if config.LoopGuard.Enabled && (config.LoopGuard.MaxThreadResponses <= 0 || config.LoopGuard.Window <= 0) {
+		return nil, fmt.Errorf("スレッドごとの応答数の制限 (loop_guard.max_thread_responses, loop_guard.window) には正の値を指定してください")
+	}
+	if config.Progress.Enabled && config.Progress.TTL <= 0 {
+		return nil, fmt.Errorf("途中経過の表示を記録する時間 (progress.ttl) には正の値を指定してください")
+	}
+	if config.EventWorkers.Size <= 0 || config.EventWorkers.QueueSize < 0 {
+		return nil, fmt.Errorf("イベントを処理するワーカー数 (event_workers.size) には正の値を、キューの長さ (event_workers.queue_size) には0以上の値を指定してください")
+	}
+	if config.EventWorkers.Timeout <= 0 {
+		return nil, fmt.Errorf("イベントの処理の期限 (event_workers.timeout) には正の値を指定してください")
+	}
+	if config.EventWorkers.DrainTimeout <= 0 {
+		return nil, fmt.Errorf("停止時にイベントの処理を待つ時間 (event_workers.drain_timeout) には正の値を指定してください")
+	}
+	if sm := config.SocketMode; sm.InitialBackoff <= 0 || sm.MaxBackoff < sm.InitialBackoff || sm.StableAfter <= 0 {
+		return nil, fmt.Errorf("再接続の間隔 (socket_mode.initial_backoff, socket_mode.max_backoff) と安定とみなす接続時間 (socket_mode.stable_after) には正の値を指定し、max_backoff は initial_backoff 以上にしてください")
+	}
+	if config.SocketMode.MaxFatalFailures <= 0 {
+		return nil, fmt.Errorf("プロセスを停止するまでの致命的な接続エラーの回数 (socket_mode.max_fatal_failures) には正の値を指定してください")
+	}
+	if config.Enrichment.UserCache.TTL <= 0 || config.Enrichment.UserCache.Size <= 0 {
+		return nil, fmt.Errorf("ユーザー名のキャッシュの期間 (enrichment.user_cache.ttl) と件数 (enrichment.user_cache.size) には正の値を指定してください")
+	}
+	if info := config.Enrichment.InfoAPI; info.RequestsPerMinute <= 0 || info.Burst <= 0 || info.MaxRetries < 0 {
+		return nil, fmt.Errorf("情報取得APIの呼び出し頻度 (enrichment.info_api.requests_per_minute, enrichment.info_api.burst) には正の値を、再試行回数 (enrichment.info_api.max_retries) には0以上の値を指定してください")
+	}
+	if config.Outbox.Enabled && (config.Outbox.PollInterval <= 0 || config.Outbox.BatchSize <= 0) {
+		return nil, fmt.Errorf("アウトボックスの送信間隔 (outbox.poll_interval) と件数 (outbox.batch_size) には正の値を指定してください")
+	}
+	if config.ThreadDedup.Enabled && config.ThreadDedup.Window <= 0 {
+		return nil, fmt.Errorf("スレッド内のメンションを集約する時間 (thread_dedup.window) には正の値を指定してください")
+	}
+	if config.EventDedup.Enabled && config.EventDedup.TTL <= 0 {
+		return nil, fmt.Errorf("処理済みのイベントを保持する時間 (event_dedup.ttl) には正の値を指定してください")
+	}
+	if config.EventDedup.Enabled && config.EventDedup.CacheSize < 0 {
+		return nil, fmt.Errorf("処理済みのイベントをメモリに保持する件数 (event_dedup.cache_size) には0以上を指定してください")
+	}
+	if config.Pushgateway.Enabled && (config.Pushgateway.URL == "" || config.Pushgateway.Interval <= 0) {
+		return nil, fmt.Errorf("PushgatewayのURL (pushgateway.url) と送信間隔 (pushgateway.interval) を設定してください")
+	}
+	if config.Tracing.SampleRate < 0 || config.Tracing.SampleRate > 1 {
+		return nil, fmt.Errorf("トレースのサンプリング率 (tracing.sample_rate) は0.0〜1.0の範囲で指定してください: %v", config.Tracing.SampleRate)
+	}
+	if endpoint := config.Tracing.OTLP.Endpoint; endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("スパンの送信先 (tracing.otlp.endpoint) には http:// または https:// で始まるURLを指定してください: %s", endpoint)
+		}
 	}
 
 	return &config, nil
 }
+
+// SlackWorkspaces は接続するワークスペースの設定を返す
+// workspaces が指定されていない場合は slack_bot の1件のみ
+func (config *AppConfig) SlackWorkspaces() []SlackBotConfig {
+	if len(config.Workspaces) > 0 {
+		return config.Workspaces
+	}
+	return []SlackBotConfig{config.SlackBot}
+}
+
+// QueueKeys は有効な機能が送信に使用するキューのキーを返す
+// queue_name を指定したワークスペースがある場合はそのワークスペース用のキーも含む
+func (config *AppConfig) QueueKeys() []string {
+	keys := config.messageQueueKeys()
+	for _, workspace := range config.SlackWorkspaces() {
+		if workspace.QueueName == "" {
+			continue
+		}
+		for _, key := range config.messageQueueKeys() {
+			keys = append(keys, WorkspaceQueueKey(workspace.Name, key))
+		}
+	}
+	return keys
+}
+
+// messageQueueKeys は有効な機能が送信するメッセージの種類を返す
+func (config *AppConfig) messageQueueKeys() []string {
+	keys := []string{QueueKeyMention}
+	if config.Reaction.Enabled {
+		keys = append(keys, QueueKeyReaction)
+	}
+	if config.Feedback.Enabled {
+		keys = append(keys, QueueKeyFeedback)
+	}
+	if config.Shortcut.Enabled {
+		keys = append(keys, QueueKeyShortcut)
+	}
+	if config.SlashCommands.Enabled {
+		keys = append(keys, QueueKeySlashCommand)
+	}
+	if config.Approval.Enabled {
+		keys = append(keys, QueueKeyInteraction)
+	}
+	return keys
+}
+
+// regionFromEnv は AWS_REGION、AWS_DEFAULT_REGION の順にリージョンを返す
+func regionFromEnv() string {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
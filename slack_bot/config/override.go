@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// envPrefix は設定を上書きする環境変数の接頭辞
+// 設定のキーの "." を "_" に置き換えて大文字にしたものを付ける（例: slack_bot.bot_token は AISLACKBOT_SLACK_BOT_BOT_TOKEN）
+const envPrefix = "AISLACKBOT"
+
+// setFlag は --set key=value で指定された設定の上書き（複数指定できる）
+type setFlag []string
+
+func (f *setFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *setFlag) Set(value string) error {
+	if key, _, ok := strings.Cut(value, "="); !ok || key == "" {
+		return fmt.Errorf("key=value の形式で指定してください: %s", value)
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// toMap は --set の値を設定ファイルと同じ入れ子のマップにする（同じキーは後に指定した値を使用する）
+func (f setFlag) toMap() map[string]any {
+	root := make(map[string]any)
+	for _, item := range f {
+		key, value, _ := strings.Cut(item, "=")
+		parts := strings.Split(strings.ToLower(key), ".")
+		m := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := m[part].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				m[part] = child
+			}
+			m = child
+		}
+		m[parts[len(parts)-1]] = value
+	}
+	return root
+}
+
+// bindEnv は AppConfig のすべての項目を環境変数で上書きできるようにする
+// viper の AutomaticEnv は設定ファイルかデフォルトにあるキーしか Unmarshal しないため、構造体の項目のキーをすべて登録する
+func bindEnv(v *viper.Viper) error {
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	for _, key := range configKeys("", reflect.TypeOf(AppConfig{})) {
+		if err := v.BindEnv(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configKeys は構造体の項目の設定のキーを返す
+// 構造体のリスト（workspaces など）とマップ（elasticmq.queues など）は要素のキーが決まらないため含めない
+// 文字列のリストは環境変数ではカンマ区切りで指定する
+func configKeys(prefix string, t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if options == "squash" && field.Type.Kind() == reflect.Struct {
+				keys = append(keys, configKeys(prefix, field.Type)...)
+			}
+			continue
+		}
+		key := joinKey(prefix, name)
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			keys = append(keys, configKeys(key, field.Type)...)
+		case field.Type.Kind() == reflect.Map:
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
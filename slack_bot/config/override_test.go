@@ -0,0 +1,233 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testConfigYAML は必須項目だけを設定した設定ファイル
+const testConfigYAML = `
+slack_bot:
+  bot_token: xoxb-file
+  app_token: xapp-file
+  mode: socket
+elasticmq:
+  region: ap-northeast-1
+  queue_name: mentions
+`
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSetFlagSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "queue.backend=redis"},
+		{value: "slack_bot.bot_token=xoxb-a=b"},
+		{value: "log.level="},
+		{value: "queue.backend", wantErr: true},
+		{value: "=redis", wantErr: true},
+	}
+	for _, tt := range tests {
+		var f setFlag
+		if err := f.Set(tt.value); (err != nil) != tt.wantErr {
+			t.Errorf("Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSetFlagToMap(t *testing.T) {
+	f := setFlag{"queue.backend=redis", "Queue.Redis.Addr=redis:6379", "log.level=debug", "log.level=warn", "slack_bot.bot_token=xoxb-a=b"}
+	want := map[string]any{
+		"queue": map[string]any{
+			"backend": "redis",
+			"redis":   map[string]any{"addr": "redis:6379"},
+		},
+		// 同じキーは後に指定した値を使用する
+		"log":       map[string]any{"level": "warn"},
+		"slack_bot": map[string]any{"bot_token": "xoxb-a=b"},
+	}
+	if got := f.toMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("toMap() = %v, want %v", got, want)
+	}
+}
+
+// 設定ファイル < --set < 環境変数 の順に優先する
+func TestLoadAppConfigPrecedence(t *testing.T) {
+	path := writeTestConfig(t, testConfigYAML)
+	tests := []struct {
+		name  string
+		sets  setFlag
+		env   map[string]string
+		check func(t *testing.T, cfg *AppConfig)
+	}{
+		{
+			name: "設定ファイルの値を使用する",
+			check: func(t *testing.T, cfg *AppConfig) {
+				if cfg.SlackBot.BotToken != "xoxb-file" || cfg.Log.Level != "info" {
+					t.Errorf("bot_token, log.level = %q, %q, want %q, %q", cfg.SlackBot.BotToken, cfg.Log.Level, "xoxb-file", "info")
+				}
+			},
+		},
+		{
+			name: "--set は設定ファイルとデフォルトより優先する",
+			sets: setFlag{"slack_bot.bot_token=xoxb-set", "log.level=debug"},
+			check: func(t *testing.T, cfg *AppConfig) {
+				if cfg.SlackBot.BotToken != "xoxb-set" || cfg.Log.Level != "debug" {
+					t.Errorf("bot_token, log.level = %q, %q, want %q, %q", cfg.SlackBot.BotToken, cfg.Log.Level, "xoxb-set", "debug")
+				}
+				if cfg.SlackBot.AppToken != "xapp-file" {
+					t.Errorf("app_token = %q, want 設定ファイルの値 %q", cfg.SlackBot.AppToken, "xapp-file")
+				}
+			},
+		},
+		{
+			name: "環境変数は --set より優先する",
+			sets: setFlag{"slack_bot.bot_token=xoxb-set"},
+			env:  map[string]string{"AISLACKBOT_SLACK_BOT_BOT_TOKEN": "xoxb-env"},
+			check: func(t *testing.T, cfg *AppConfig) {
+				if cfg.SlackBot.BotToken != "xoxb-env" {
+					t.Errorf("bot_token = %q, want %q", cfg.SlackBot.BotToken, "xoxb-env")
+				}
+			},
+		},
+		{
+			name: "設定ファイルにない項目も環境変数で設定できる",
+			env: map[string]string{
+				"AISLACKBOT_EVENT_WORKERS_TIMEOUT":       "3s",
+				"AISLACKBOT_ACCESS_CONTROL_DENIED_USERS": "U001,U002",
+			},
+			check: func(t *testing.T, cfg *AppConfig) {
+				if cfg.EventWorkers.Timeout != 3*time.Second {
+					t.Errorf("event_workers.timeout = %v, want 3s", cfg.EventWorkers.Timeout)
+				}
+				if want := []string{"U001", "U002"}; !reflect.DeepEqual(cfg.AccessControl.DeniedUsers, want) {
+					t.Errorf("access_control.denied_users = %v, want %v", cfg.AccessControl.DeniedUsers, want)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := loadAppConfig(path, tt.sets)
+			if err != nil {
+				t.Fatalf("loadAppConfig() error = %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+// コンテナなどで設定ファイルを置かない場合は環境変数だけで設定できる
+func TestLoadAppConfigFromEnvOnly(t *testing.T) {
+	for k, v := range map[string]string{
+		"AISLACKBOT_SLACK_BOT_BOT_TOKEN":  "xoxb-env",
+		"AISLACKBOT_SLACK_BOT_APP_TOKEN":  "xapp-env",
+		"AISLACKBOT_SLACK_BOT_MODE":       "socket",
+		"AISLACKBOT_ELASTICMQ_REGION":     "ap-northeast-1",
+		"AISLACKBOT_ELASTICMQ_QUEUE_NAME": "mentions",
+	} {
+		t.Setenv(k, v)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	cfg, err := loadAppConfig("", nil)
+	if err != nil {
+		t.Fatalf("loadAppConfig() error = %v", err)
+	}
+	if cfg.SlackBot.BotToken != "xoxb-env" || cfg.ElasticMQ.QueueName != "mentions" {
+		t.Errorf("bot_token, queue_name = %q, %q, want %q, %q", cfg.SlackBot.BotToken, cfg.ElasticMQ.QueueName, "xoxb-env", "mentions")
+	}
+}
+
+// 明示的に指定した設定ファイルがない場合はエラーにする
+func TestLoadAppConfigMissingFile(t *testing.T) {
+	if _, err := loadAppConfig(filepath.Join(t.TempDir(), "missing.yml"), nil); err == nil {
+		t.Error("loadAppConfig() error = nil, want error")
+	}
+}
+
+// elasticmq.region が未設定の場合は AWS_REGION、AWS_DEFAULT_REGION の順に環境変数から補完する
+func TestLoadAppConfigRegionFromEnv(t *testing.T) {
+	withoutRegion := strings.Replace(testConfigYAML, "  region: ap-northeast-1\n", "", 1)
+	tests := []struct {
+		name    string
+		yaml    string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "AWS_REGION を使用する",
+			yaml: withoutRegion,
+			env:  map[string]string{"AWS_REGION": "us-west-2", "AWS_DEFAULT_REGION": ""},
+			want: "us-west-2",
+		},
+		{
+			name: "AWS_REGION がない場合は AWS_DEFAULT_REGION を使用する",
+			yaml: withoutRegion,
+			env:  map[string]string{"AWS_REGION": "", "AWS_DEFAULT_REGION": "eu-west-1"},
+			want: "eu-west-1",
+		},
+		{
+			name: "AWS_REGION は AWS_DEFAULT_REGION より優先する",
+			yaml: withoutRegion,
+			env:  map[string]string{"AWS_REGION": "us-west-2", "AWS_DEFAULT_REGION": "eu-west-1"},
+			want: "us-west-2",
+		},
+		{
+			name: "設定ファイルの値は環境変数より優先する",
+			yaml: testConfigYAML,
+			env:  map[string]string{"AWS_REGION": "us-west-2"},
+			want: "ap-northeast-1",
+		},
+		{
+			name:    "設定ファイルにも環境変数にもない場合はエラーにする",
+			yaml:    withoutRegion,
+			env:     map[string]string{"AWS_REGION": "", "AWS_DEFAULT_REGION": ""},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := loadAppConfig(writeTestConfig(t, tt.yaml), nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("loadAppConfig() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadAppConfig() error = %v", err)
+			}
+			if cfg.ElasticMQ.Region != tt.want {
+				t.Errorf("elasticmq.region = %q, want %q", cfg.ElasticMQ.Region, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/secrets"
+)
+
+const (
+	SecretsProviderSecretsManager = "secretsmanager"
+	SecretsProviderSSM            = "ssm"
+)
+
+// secretRefPrefix はシークレットストアから取得する値の接頭辞
+// "secret:<名前>" はシークレットの値をそのまま使用し、"secret:<名前>#<キー>" はJSONのシークレットの項目の値を使用する
+const secretRefPrefix = "secret:"
+
+// SecretsConfig はトークンなどをシークレットストアから取得する設定
+// Provider が空の場合は取得せず、secret: で始まる値を指定するとエラーになる
+type SecretsConfig struct {
+	Provider  string        `mapstructure:"provider"`
+	Region    string        `mapstructure:"region"`
+	Endpoint  string        `mapstructure:"endpoint"`
+	AccessKey string        `mapstructure:"access_key"`
+	SecretKey string        `mapstructure:"secret_key"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
+// secretField はシークレットストアから取得できる設定の項目
+type secretField struct {
+	key   string
+	value *string
+}
+
+// secretFields はシークレットストアから取得できる項目（トークン・Signing Secret・AIのAPIキー）を返す
+func (config *AppConfig) secretFields() []secretField {
+	workspaceFields := func(prefix string, workspace *SlackBotConfig) []secretField {
+		return []secretField{
+			{prefix + ".bot_token", &workspace.BotToken},
+			{prefix + ".app_token", &workspace.AppToken},
+			{prefix + ".signing_secret", &workspace.SigningSecret},
+		}
+	}
+	fields := workspaceFields("slack_bot", &config.SlackBot)
+	for i := range config.Workspaces {
+		fields = append(fields, workspaceFields(fmt.Sprintf("workspaces[%d]", i), &config.Workspaces[i])...)
+	}
+	return append(fields,
+		secretField{"worker.openai.api_key", &config.Worker.OpenAI.APIKey},
+		secretField{"worker.anthropic.api_key", &config.Worker.Anthropic.APIKey},
+	)
+}
+
+// resolveSecrets は secret: で始まる項目の値をシークレットストアから取得して置き換える
+// 同じシークレットは1回だけ取得する。newFetcher は secret: で始まる値がある場合だけ呼び出す
+func (config *AppConfig) resolveSecrets(newFetcher func(SecretsConfig) (secrets.Fetcher, error)) error {
+	var refs []secretField
+	for _, field := range config.secretFields() {
+		if strings.HasPrefix(*field.value, secretRefPrefix) {
+			refs = append(refs, field)
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	fetcher, err := newFetcher(config.Secrets)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Secrets.Timeout)
+	defer cancel()
+
+	fetched := make(map[string]string)
+	for _, field := range refs {
+		name, jsonKey, _ := strings.Cut(strings.TrimPrefix(*field.value, secretRefPrefix), "#")
+		if name == "" {
+			return fmt.Errorf("%s のシークレットの名前が指定されていません", field.key)
+		}
+		value, ok := fetched[name]
+		if !ok {
+			if value, err = fetcher.Fetch(ctx, name); err != nil {
+				return fmt.Errorf("%s のシークレットを取得できませんでした: %w", field.key, err)
+			}
+			fetched[name] = value
+		}
+		if jsonKey != "" {
+			if value, err = secretJSONValue(value, jsonKey); err != nil {
+				return fmt.Errorf("%s のシークレット (%s) の値が不正です: %w", field.key, name, err)
+			}
+		}
+		*field.value = value
+	}
+	return nil
+}
+
+func newSecretFetcher(cfg SecretsConfig) (secrets.Fetcher, error) {
+	opts := secrets.AWSOptions{
+		Region:    cfg.Region,
+		Endpoint:  cfg.Endpoint,
+		AccessKey: cfg.AccessKey,
+		SecretKey: cfg.SecretKey,
+	}
+	switch cfg.Provider {
+	case SecretsProviderSecretsManager:
+		return secrets.NewSecretsManagerFetcher(opts)
+	case SecretsProviderSSM:
+		return secrets.NewSSMFetcher(opts)
+	default:
+		return nil, fmt.Errorf("secret: で始まる値を使用する場合はシークレットの取得元 (secrets.provider) に %s または %s を指定してください", SecretsProviderSecretsManager, SecretsProviderSSM)
+	}
+}
+
+// secretJSONValue はJSONのオブジェクトのシークレットから key の文字列の値を取り出す
+func secretJSONValue(secret, key string) (string, error) {
+	var values map[string]any
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", fmt.Errorf("JSONのオブジェクトではありません: %w", err)
+	}
+	value, ok := values[key].(string)
+	if !ok {
+		return "", fmt.Errorf("文字列の項目 %s がありません", key)
+	}
+	return value, nil
+}
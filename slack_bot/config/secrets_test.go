@@ -0,0 +1,121 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/secrets"
+)
+
+// fakeSecretFetcher は名前ごとの値を返し、取得した回数を記録する
+type fakeSecretFetcher struct {
+	values map[string]string
+	calls  map[string]int
+}
+
+func (f *fakeSecretFetcher) Fetch(ctx context.Context, name string) (string, error) {
+	f.calls[name]++
+	value, ok := f.values[name]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func newFakeSecretFetcher(values map[string]string) (*fakeSecretFetcher, func(SecretsConfig) (secrets.Fetcher, error)) {
+	f := &fakeSecretFetcher{values: values, calls: make(map[string]int)}
+	return f, func(SecretsConfig) (secrets.Fetcher, error) { return f, nil }
+}
+
+func TestResolveSecrets(t *testing.T) {
+	fetcher, newFetcher := newFakeSecretFetcher(map[string]string{
+		"slackbot/tokens": `{"bot_token":"xoxb-secret","app_token":"xapp-secret"}`,
+		"slackbot/openai": "sk-secret",
+	})
+	config := &AppConfig{
+		SlackBot: SlackBotConfig{
+			BotToken:      "secret:slackbot/tokens#bot_token",
+			AppToken:      "secret:slackbot/tokens#app_token",
+			SigningSecret: "plain-signing-secret",
+		},
+		Workspaces: []SlackBotConfig{{BotToken: "secret:slackbot/tokens#bot_token"}},
+	}
+	config.Worker.OpenAI.APIKey = "secret:slackbot/openai"
+
+	if err := config.resolveSecrets(newFetcher); err != nil {
+		t.Fatalf("resolveSecrets() error = %v", err)
+	}
+	for _, tt := range []struct {
+		key, got, want string
+	}{
+		{key: "slack_bot.bot_token", got: config.SlackBot.BotToken, want: "xoxb-secret"},
+		{key: "slack_bot.app_token", got: config.SlackBot.AppToken, want: "xapp-secret"},
+		{key: "slack_bot.signing_secret", got: config.SlackBot.SigningSecret, want: "plain-signing-secret"},
+		{key: "workspaces[0].bot_token", got: config.Workspaces[0].BotToken, want: "xoxb-secret"},
+		{key: "worker.openai.api_key", got: config.Worker.OpenAI.APIKey, want: "sk-secret"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.key, tt.got, tt.want)
+		}
+	}
+	// 同じシークレットは1回だけ取得する
+	if got := fetcher.calls["slackbot/tokens"]; got != 1 {
+		t.Errorf("slackbot/tokens の取得回数 = %d, want 1", got)
+	}
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		botToken string
+	}{
+		{name: "存在しないシークレット", botToken: "secret:slackbot/missing"},
+		{name: "シークレットの名前が空", botToken: "secret:#bot_token"},
+		{name: "JSONではないシークレットの項目", botToken: "secret:slackbot/plain#bot_token"},
+		{name: "JSONにない項目", botToken: "secret:slackbot/tokens#missing"},
+		{name: "文字列ではない項目", botToken: "secret:slackbot/tokens#count"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, newFetcher := newFakeSecretFetcher(map[string]string{
+				"slackbot/plain":  "xoxb-plain",
+				"slackbot/tokens": `{"bot_token":"xoxb-secret","count":1}`,
+			})
+			config := &AppConfig{SlackBot: SlackBotConfig{BotToken: tt.botToken}}
+			if err := config.resolveSecrets(newFetcher); err == nil {
+				t.Errorf("resolveSecrets() error = nil, want error (bot_token = %q)", config.SlackBot.BotToken)
+			}
+		})
+	}
+}
+
+// secret: で始まる値がない場合はシークレットストアに接続しない
+func TestResolveSecretsWithoutRefs(t *testing.T) {
+	config := &AppConfig{SlackBot: SlackBotConfig{BotToken: "xoxb-plain"}}
+	err := config.resolveSecrets(func(SecretsConfig) (secrets.Fetcher, error) {
+		t.Error("secret: で始まる値がないのに取得元を作成しています")
+		return nil, errors.New("unexpected")
+	})
+	if err != nil || config.SlackBot.BotToken != "xoxb-plain" {
+		t.Errorf("resolveSecrets() = %v (bot_token = %q), want nil (%q)", err, config.SlackBot.BotToken, "xoxb-plain")
+	}
+}
+
+func TestNewSecretFetcher(t *testing.T) {
+	tests := []struct {
+		provider string
+		wantErr  bool
+	}{
+		{provider: SecretsProviderSecretsManager},
+		{provider: SecretsProviderSSM},
+		// secrets.provider を設定せずに secret: の値を使用した場合はエラー
+		{provider: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := newSecretFetcher(SecretsConfig{Provider: tt.provider, Region: "ap-northeast-1"})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newSecretFetcher(%q) error = %v, wantErr %v", tt.provider, err, tt.wantErr)
+		}
+	}
+}
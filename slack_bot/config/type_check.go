@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ConfigTypeError は設定値を設定項目の型に変換できない場合のエラー
+type ConfigTypeError struct {
+	// Key は設定ファイル上のキー（例: rate_limit.max_requests, workspaces[0].bot_token）
+	Key string
+	// Expected は期待する値の説明
+	Expected string
+	Value    any
+}
+
+func (e *ConfigTypeError) Error() string {
+	return fmt.Sprintf("設定値の型が不正です: %s には%sを指定してください（指定された値: %#v）", e.Key, e.Expected, e.Value)
+}
+
+// validateTypes は読み込んだ設定値が AppConfig の各項目の型に変換できるかを検証する
+// viper の Unmarshal と同様に文字列の数値・真偽値などは変換できるものとして扱う
+func validateTypes(settings map[string]any) error {
+	return errors.Join(checkType("", settings, reflect.TypeOf(AppConfig{}))...)
+}
+
+func checkType(key string, value any, t reflect.Type) []error {
+	if value == nil {
+		return nil
+	}
+	if t == durationType {
+		if !isDuration(value) {
+			return []error{&ConfigTypeError{Key: key, Expected: `時間（例: "30s", "5m"）`, Value: value}}
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return []error{&ConfigTypeError{Key: key, Expected: "項目を持つマップ", Value: value}}
+		}
+		var errs []error
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := field.Tag.Get("mapstructure")
+			if name == "" || name == "-" {
+				continue
+			}
+			if v, ok := lookupKey(m, name); ok {
+				errs = append(errs, checkType(joinKey(key, name), v, field.Type)...)
+			}
+		}
+		return errs
+	case reflect.Map:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return []error{&ConfigTypeError{Key: key, Expected: "マップ", Value: value}}
+		}
+		var errs []error
+		for k, v := range m {
+			errs = append(errs, checkType(joinKey(key, k), v, t.Elem())...)
+		}
+		return errs
+	case reflect.Slice:
+		items, ok := value.([]any)
+		if !ok {
+			// 単一の値はリストの要素として変換される
+			return checkType(key, value, t.Elem())
+		}
+		var errs []error
+		for i, item := range items {
+			errs = append(errs, checkType(fmt.Sprintf("%s[%d]", key, i), item, t.Elem())...)
+		}
+		return errs
+	case reflect.String:
+		if !isScalar(value) {
+			return []error{&ConfigTypeError{Key: key, Expected: "文字列", Value: value}}
+		}
+	case reflect.Bool:
+		if !isBool(value) {
+			return []error{&ConfigTypeError{Key: key, Expected: "真偽値（true または false）", Value: value}}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isInt(value) {
+			return []error{&ConfigTypeError{Key: key, Expected: "整数", Value: value}}
+		}
+	case reflect.Float32, reflect.Float64:
+		if !isFloat(value) {
+			return []error{&ConfigTypeError{Key: key, Expected: "数値", Value: value}}
+		}
+	}
+	return nil
+}
+
+// lookupKey はキーの大文字・小文字を区別せずに値を取得する（viper はキーを小文字にする）
+func lookupKey(m map[string]any, name string) (any, bool) {
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func isScalar(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return true
+}
+
+func isBool(value any) bool {
+	switch v := value.(type) {
+	case bool, int, int64, float64:
+		return true
+	case string:
+		_, err := strconv.ParseBool(v)
+		return v == "" || err == nil
+	}
+	return false
+}
+
+func isInt(value any) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, bool:
+		return true
+	case float64:
+		return v == float64(int64(v))
+	case string:
+		_, err := strconv.ParseInt(v, 0, 64)
+		return v == "" || err == nil
+	}
+	return false
+}
+
+func isFloat(value any) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		return true
+	case string:
+		_, err := strconv.ParseFloat(v, 64)
+		return v == "" || err == nil
+	}
+	return false
+}
+
+// isDuration は time.Duration に変換できるか（"30s" 形式の文字列またはナノ秒の整数）を返す
+func isDuration(value any) bool {
+	switch v := value.(type) {
+	case time.Duration:
+		return true
+	case string:
+		if v == "" {
+			return true
+		}
+		if _, err := time.ParseDuration(v); err == nil {
+			return true
+		}
+		_, err := strconv.ParseInt(v, 0, 64)
+		return err == nil
+	}
+	return isInt(value)
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateTypes(t *testing.T) {
+	tests := []struct {
+		name         string
+		settings     map[string]any
+		wantKey      string
+		wantExpected string
+	}{
+		{
+			name:     "正しい型の値はエラーにしない",
+			settings: map[string]any{"rate_limit": map[string]any{"enabled": true, "max_requests": 5, "window": "1m"}},
+		},
+		{
+			name:     "数値・真偽値に変換できる文字列はエラーにしない",
+			settings: map[string]any{"rate_limit": map[string]any{"enabled": "true", "max_requests": "5"}},
+		},
+		{
+			name:         "整数の項目に数値ではない文字列",
+			settings:     map[string]any{"rate_limit": map[string]any{"max_requests": "five"}},
+			wantKey:      "rate_limit.max_requests",
+			wantExpected: "整数",
+		},
+		{
+			name:         "整数の項目に小数",
+			settings:     map[string]any{"rate_limit": map[string]any{"max_requests": 1.5}},
+			wantKey:      "rate_limit.max_requests",
+			wantExpected: "整数",
+		},
+		{
+			name:         "真偽値の項目に真偽値ではない文字列",
+			settings:     map[string]any{"rate_limit": map[string]any{"enabled": "yes please"}},
+			wantKey:      "rate_limit.enabled",
+			wantExpected: "真偽値",
+		},
+		{
+			name:         "時間の項目に単位のない不正な文字列",
+			settings:     map[string]any{"rate_limit": map[string]any{"window": "1 minute"}},
+			wantKey:      "rate_limit.window",
+			wantExpected: "時間",
+		},
+		{
+			name:         "項目を持つ設定にマップ以外の値",
+			settings:     map[string]any{"rate_limit": "on"},
+			wantKey:      "rate_limit",
+			wantExpected: "項目を持つマップ",
+		},
+		{
+			name:         "リストの要素の誤りは要素の位置をキーに含める",
+			settings:     map[string]any{"workspaces": []any{map[string]any{"name": "a"}, map[string]any{"name": map[string]any{"x": 1}}}},
+			wantKey:      "workspaces[1].name",
+			wantExpected: "文字列",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTypes(tt.settings)
+			if tt.wantKey == "" {
+				if err != nil {
+					t.Fatalf("validateTypes() error = %v, want nil", err)
+				}
+				return
+			}
+			var typeErr *ConfigTypeError
+			if !errors.As(err, &typeErr) {
+				t.Fatalf("validateTypes() error = %v, want *ConfigTypeError", err)
+			}
+			if typeErr.Key != tt.wantKey {
+				t.Errorf("ConfigTypeError.Key = %q, want %q", typeErr.Key, tt.wantKey)
+			}
+			if !strings.Contains(typeErr.Expected, tt.wantExpected) {
+				t.Errorf("ConfigTypeError.Expected = %q, want %q を含む", typeErr.Expected, tt.wantExpected)
+			}
+		})
+	}
+}
+
+// 設定ファイルの型の誤りは、項目のキーと期待する型がわかるエラーで読み込みを中止する
+func TestLoadAppConfigTypeError(t *testing.T) {
+	path := writeTestConfig(t, testConfigYAML+`
+rate_limit:
+  enabled: true
+  max_requests: "five"
+`)
+	_, err := loadAppConfig(path, nil)
+	var typeErr *ConfigTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("loadAppConfig() error = %v, want *ConfigTypeError", err)
+	}
+	for _, want := range []string{"rate_limit.max_requests", "整数", `"five"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("loadAppConfig() error = %q, want %q を含む", err.Error(), want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package blockkit はドメインのオブジェクトからSlackに投稿するBlock Kitのブロックを組み立てる
+package blockkit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	slackmodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+)
+
+// 回答に付けるボタンの action_id
+const (
+	RegenerateAnswerActionID = "regenerate_answer"
+	ShowSourcesActionID      = "show_sources"
+	DeleteAnswerActionID     = "delete_answer"
+)
+
+const (
+	// sectionTextMaxLength はセクションブロックに表示できる最大文字数（Slackの上限）
+	sectionTextMaxLength = 3000
+	// buttonValueMaxLength はボタンの value の最大文字数（Slackの上限）
+	buttonValueMaxLength = 2000
+	// maxAnswerSections は回答の本文に使用するセクションブロックの最大数（メッセージのブロックは50個まで）
+	maxAnswerSections = 45
+)
+
+// AnswerLabels は回答のボタンと確認ダイアログの表示文言
+type AnswerLabels struct {
+	Regenerate    string
+	ShowSources   string
+	Delete        string
+	ConfirmTitle  string
+	ConfirmText   string
+	ConfirmDelete string
+	Cancel        string
+}
+
+// AnswerActionValue は回答のボタンの value に埋め込む、操作の対象の回答
+// 根拠の資料は保存していないため、「根拠を表示」のボタンにだけ value の上限に収まる分を含める
+type AnswerActionValue struct {
+	MentionID string        `json:"mention_id,omitempty"`
+	Sources   []sourceValue `json:"sources,omitempty"`
+}
+
+type sourceValue struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
+
+// ParseAnswerActionValue は回答のボタンの value をデコードする
+func ParseAnswerActionValue(value string) (AnswerActionValue, error) {
+	var v AnswerActionValue
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return AnswerActionValue{}, fmt.Errorf("回答のボタンの value のデコードエラー: %w", err)
+	}
+	return v, nil
+}
+
+// AnswerSources は value に含まれる根拠の資料を返す
+func (v AnswerActionValue) AnswerSources() []slackmodel.AnswerSource {
+	sources := make([]slackmodel.AnswerSource, 0, len(v.Sources))
+	for _, s := range v.Sources {
+		sources = append(sources, slackmodel.AnswerSource{Title: s.Title, URL: s.URL})
+	}
+	return sources
+}
+
+// AnswerBlocks は回答の本文と「再生成」「根拠を表示」「削除」のボタンのブロックを作成する
+// 本文はセクションブロックの上限ごとに分割し、根拠の資料がない場合は「根拠を表示」を付けない
+func AnswerBlocks(answer *slackmodel.Answer, labels AnswerLabels) ([]slack.Block, error) {
+	var blocks []slack.Block
+	for _, chunk := range splitRunes(string(answer.Text), sectionTextMaxLength, maxAnswerSections) {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, chunk, false, false), nil, nil))
+	}
+
+	value, err := json.Marshal(AnswerActionValue{MentionID: answer.MentionID})
+	if err != nil {
+		return nil, err
+	}
+	plainText := func(text string) *slack.TextBlockObject {
+		return slack.NewTextBlockObject(slack.PlainTextType, text, false, false)
+	}
+	buttons := []slack.BlockElement{
+		slack.NewButtonBlockElement(RegenerateAnswerActionID, string(value), plainText(labels.Regenerate)),
+	}
+	if len(answer.Sources) > 0 {
+		sourcesValue, err := sourcesButtonValue(answer)
+		if err != nil {
+			return nil, err
+		}
+		buttons = append(buttons, slack.NewButtonBlockElement(ShowSourcesActionID, sourcesValue, plainText(labels.ShowSources)))
+	}
+	confirm := slack.NewConfirmationBlockObject(
+		plainText(labels.ConfirmTitle),
+		plainText(labels.ConfirmText),
+		plainText(labels.ConfirmDelete),
+		plainText(labels.Cancel),
+	).WithStyle(slack.StyleDanger)
+	buttons = append(buttons,
+		slack.NewButtonBlockElement(DeleteAnswerActionID, string(value), plainText(labels.Delete)).WithStyle(slack.StyleDanger).WithConfirm(confirm),
+	)
+
+	return append(blocks, slack.NewActionBlock("", buttons...)), nil
+}
+
+// SourcesBlocks は根拠の資料の一覧を表示するブロックを作成する
+func SourcesBlocks(title string, sources []slackmodel.AnswerSource) []slack.Block {
+	text := title
+	for _, source := range sources {
+		text += fmt.Sprintf("\n• <%s|%s>", source.URL, source.Label())
+	}
+	var blocks []slack.Block
+	for _, chunk := range splitRunes(text, sectionTextMaxLength, maxAnswerSections) {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, chunk, false, false), nil, nil))
+	}
+	return blocks
+}
+
+// 「根拠を表示」のボタンの value を作成する
+// value の上限を超える場合は、収まるところまで資料を含める
+func sourcesButtonValue(answer *slackmodel.Answer) (string, error) {
+	v := AnswerActionValue{MentionID: answer.MentionID}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	for _, source := range answer.Sources {
+		v.Sources = append(v.Sources, sourceValue{Title: source.Title, URL: source.URL})
+		next, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		if len([]rune(string(next))) > buttonValueMaxLength {
+			break
+		}
+		encoded = next
+	}
+	return string(encoded), nil
+}
+
+// 文字列を最大 size 文字（rune数）ごとに分割する。最大 limit 個までで、それ以降は切り捨てる
+func splitRunes(s string, size, limit int) []string {
+	runes := []rune(s)
+	var chunks []string
+	for len(runes) > 0 && len(chunks) < limit {
+		n := min(size, len(runes))
+		chunks = append(chunks, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return chunks
+}
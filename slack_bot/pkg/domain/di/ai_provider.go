@@ -0,0 +1,32 @@
+package di
+
+import (
+	"context"
+)
+
+// チャットのメッセージの役割
+const (
+	ChatRoleSystem    = "system"
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+// ChatMessage はAIに送信する会話の1メッセージ
+type ChatMessage struct {
+	Role    string
+	Content string
+}
+
+// AIProvider はユーザーの入力 (prompt) への回答を生成する
+// history は prompt より前の会話で、役割が system のメッセージはAIへの指示として扱う
+// 実装は worker.provider で選択する（pkg/infra/ai）
+type AIProvider interface {
+	Complete(ctx context.Context, prompt string, history []ChatMessage) (string, error)
+}
+
+// StreamingAIProvider は回答を生成しながら順に返せるAIプロバイダー
+// onDelta には生成された回答の差分を渡し、戻り値は回答の全文を返す
+type StreamingAIProvider interface {
+	AIProvider
+	Stream(ctx context.Context, prompt string, history []ChatMessage, onDelta func(delta string)) (string, error)
+}
@@ -0,0 +1,14 @@
+package di
+
+import (
+	"context"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+type ConversationMessageRepository interface {
+	// Save はスレッドのメッセージを保存する（同じメッセージが既に保存されている場合は何もしない）
+	Save(ctx context.Context, message *entity.ConversationMessage) error
+	// FindByThread はスレッドの最新 limit 件のメッセージを古い順に取得する
+	FindByThread(ctx context.Context, channelID, threadTS string, limit int) ([]*entity.ConversationMessage, error)
+}
@@ -0,0 +1,19 @@
+package di
+
+import (
+	"context"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+type ConversationRepository interface {
+	// FindByChannelAndThread はスレッドの会話を取得する（存在しない場合は sql.ErrNoRows）
+	FindByChannelAndThread(ctx context.Context, channelID, threadTS string) (*entity.Conversation, error)
+	// Create は会話を保存する
+	// 同じスレッドの会話が既にある場合は、最後のメッセージが staleBefore 以前（Conversation.IsClosed と同じく終了済み）のときだけ置き換え、それ以外は何もしない
+	Create(ctx context.Context, conversation *entity.Conversation, staleBefore time.Time) error
+	// Touch は会話のメッセージ数を1増やし、最後のメッセージの日時を更新する
+	Touch(ctx context.Context, id ulid.ULID, at time.Time) error
+}
@@ -0,0 +1,17 @@
+package di
+
+import (
+	"context"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+type FailedMentionRepository interface {
+	Create(context.Context, *entity.FailedMention) error
+	// FindPending は再送していないメッセージを古い順に最大 limit 件取得する
+	FindPending(ctx context.Context, limit int) ([]*entity.FailedMention, error)
+	MarkReplayed(ctx context.Context, id int64, replayedAt time.Time) error
+	// MarkFailed は再送に失敗したメッセージの試行回数を増やし、失敗の理由と日時を更新する
+	MarkFailed(ctx context.Context, id int64, failedAt time.Time, reason string) error
+}
@@ -0,0 +1,9 @@
+package di
+
+import (
+	"context"
+)
+
+type FileStore interface {
+	Put(ctx context.Context, key string, contentType string, body []byte) error
+}
@@ -0,0 +1,11 @@
+package di
+
+import (
+	"context"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+type MentionArchiver interface {
+	Archive(context.Context, []*entity.SlackMention) error
+}
@@ -0,0 +1,18 @@
+package di
+
+import (
+	"context"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+type OutboxRepository interface {
+	// CreateWithMention はメンションと送信待ちのメッセージを同じトランザクションで保存する
+	CreateWithMention(context.Context, *entity.SlackMention, *entity.OutboxMessage) error
+	// ClaimPending は送信可能なメッセージを古い順に最大 limit 件取得し、lease の間は他のインスタンスが取得しないようにする
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.OutboxMessage, error)
+	MarkSent(ctx context.Context, id int64, sentAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error
+	CountPending(context.Context) (int, error)
+}
@@ -0,0 +1,14 @@
+package di
+
+import (
+	"context"
+	"time"
+)
+
+type ProcessedEventRepository interface {
+	// Claim はイベントのキーを保存し、処理してよい場合は true を返す
+	// 同じキーが既にある場合は、保存した日時が staleBefore より前（期限切れ）のときだけ置き換えて true を返す
+	Claim(ctx context.Context, eventKey string, at, staleBefore time.Time) (bool, error)
+	// DeleteBefore は保存した日時が before より前のキーを削除し、削除した件数を返す
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,19 @@
+package di
+
+import (
+	"context"
+)
+
+// QueuePublisher はAIワーカーへのメッセージをキューに送信する
+// 実装は queue.backend の設定で選択する（SQS・Redis Streams・NATS）
+type QueuePublisher interface {
+	PublishTo(ctx context.Context, queueKey string, msg any) error
+	// PublishWithID は PublishTo と同様に送信し、キューが発行したメッセージIDを返す
+	PublishWithID(ctx context.Context, queueKey string, msg any) (string, error)
+	// Check は送信先のキューに到達できるかを確認する
+	Check(ctx context.Context) error
+	// Backlog はキューに溜まっているメッセージのおおよその件数を返す
+	Backlog(ctx context.Context, queueKey string) (int, error)
+	// Close は送信を待っているメッセージを送信し、キューへの接続を閉じる
+	Close() error
+}
@@ -2,12 +2,69 @@ package di
 
 import (
 	"context"
+	"time"
 
-	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
 	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
 )
 
+// SlackMentionFilter はメンションを検索する条件
+// ゼロ値の項目は条件に含めない
+type SlackMentionFilter struct {
+	UserID        string
+	ChannelID     string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// SlackMentionRepository はメンションの読み取りと書き込みの両方を行う
+// 利用側は必要な方の SlackMentionQuery / SlackMentionCommand に依存し、読み取りと書き込みの保存先を分けられるようにする
 type SlackMentionRepository interface {
+	SlackMentionQuery
+	SlackMentionCommand
+}
+
+// SlackMentionCommand はメンションの書き込み
+type SlackMentionCommand interface {
 	Create(context.Context, *entity.SlackMention) error
-	FindByID(context.Context, ulid.ULID) (*entity.SlackMention, error)
+	// UpdateSQSMessageID はメンションを送信したキューのメッセージIDを記録する
+	UpdateSQSMessageID(ctx context.Context, id slack.MentionID, messageID string) error
+	// UpdateStatus はメンションの状態（slack.MentionStatus）と理由を更新する
+	// 現在の状態から遷移できない場合は slack.ErrInvalidStatusTransition を返す
+	UpdateStatus(ctx context.Context, id slack.MentionID, status string, detail string) error
+	// Delete はメンションを論理削除する（存在しない・削除済みの場合は sql.ErrNoRows）
+	Delete(ctx context.Context, id slack.MentionID) error
+	// DeleteByTS は種類が source で、チャンネル・タイムスタンプ（ts）が一致するメンションをすべて論理削除し、削除した件数を返す
+	// 同じメッセージに複数のメンションがある種類（リアクション）を削除したメッセージから特定するために使用する
+	DeleteByTS(ctx context.Context, source string, channelID string, ts string) (int, error)
+	DeleteByIDs(context.Context, []slack.MentionID) error
+}
+
+// SlackMentionQuery はメンションの読み取り
+type SlackMentionQuery interface {
+	FindByID(context.Context, slack.MentionID) (*entity.SlackMention, error)
+	// FindRawEventByID はメンションの受信時に保存したSlackのイベントのJSONを v にデコードする
+	FindRawEventByID(ctx context.Context, id slack.MentionID, v any) error
+	FindCreatedBefore(ctx context.Context, before time.Time, limit int) ([]*entity.SlackMention, error)
+	// ListByUser はユーザーの削除されていないメンションを event_time の新しい順（同じ日時の場合はIDの降順）に最大 limit 件取得する
+	ListByUser(ctx context.Context, userID string, limit int) ([]*entity.SlackMention, error)
+	// FindLatestByUser はユーザーの削除されていないメンションを event_time の新しい順（同じ日時の場合はIDの降順）に最大 limit 件（上限 100 件）取得する
+	FindLatestByUser(ctx context.Context, userID string, limit int) ([]*entity.SlackMention, error)
+	// CountByUser, CountByChannel はユーザー・チャンネルの削除されていないメンション数を返す（行は取得しない）
+	CountByUser(ctx context.Context, userID string) (int, error)
+	CountByChannel(ctx context.Context, channelID string) (int, error)
+	// CountSince は event_time が since 以降の削除されていないメンション数を返す
+	CountSince(ctx context.Context, since time.Time) (int, error)
+	// FindByEventTimeRange は event_time が from 以上 to 以下のメンションを古い順（同じ日時の場合はIDの昇順）に取得する（削除済みは含まない）
+	FindByEventTimeRange(ctx context.Context, from, to time.Time, limit int) ([]*entity.SlackMention, error)
+	// CountGroupedByChannelBetween は event_time が [from, to) のメンション数をチャンネルごとに多い順で返す
+	CountGroupedByChannelBetween(ctx context.Context, from, to time.Time) ([]*entity.MentionCount, error)
+	// CountGroupedByUserBetween は event_time が [from, to) のメンション数をユーザーごとに多い順で limit 件返す
+	CountGroupedByUserBetween(ctx context.Context, from, to time.Time, limit int) ([]*entity.MentionCount, error)
+	// ListBetween は event_time が [from, to) の削除されていないメンションをIDの順に最大 limit 件取得する
+	// cursor には前回の呼び出しが返した次のカーソルを指定し（最初は空文字列）、続きがない場合は次のカーソルに空文字列を返す
+	ListBetween(ctx context.Context, from, to time.Time, cursor string, limit int) ([]*entity.SlackMention, string, error)
+	// Stream は条件に一致するメンションをページ単位で取得してチャネルに送信する
+	// 取得が終わるとどちらのチャネルも閉じられ、エラーが発生した場合はエラーのチャネルに1件送信される
+	Stream(ctx context.Context, filter SlackMentionFilter) (<-chan *entity.SlackMention, <-chan error)
 }
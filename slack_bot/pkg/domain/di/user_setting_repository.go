@@ -0,0 +1,12 @@
+package di
+
+import (
+	"context"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+type UserSettingRepository interface {
+	FindByUserID(ctx context.Context, userID string) (*entity.UserSetting, error)
+	Upsert(context.Context, *entity.UserSetting) error
+}
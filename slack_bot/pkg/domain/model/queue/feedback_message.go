@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"errors"
+	"time"
+)
+
+// SourceReaction はBotのメッセージへの絵文字リアクションであることを表す
+const SourceReaction = "reaction"
+
+// FeedbackMessageType はBotの回答へのフィードバックのメッセージの種類
+const FeedbackMessageType = "feedback"
+
+// リアクションの操作
+const (
+	ReactionActionAdded   = "added"
+	ReactionActionRemoved = "removed"
+)
+
+// FeedbackMessage はBotが投稿したメッセージへのリアクション（回答へのフィードバック）をAIワーカーに送信するメッセージ
+type FeedbackMessage struct {
+	Version   int       `json:"version"`
+	Type      string    `json:"type"`
+	Source    string    `json:"source"`
+	TeamID    string    `json:"team_id,omitempty"`
+	Workspace string    `json:"workspace,omitempty"`
+	Action    string    `json:"action"`
+	Reaction  string    `json:"reaction"`
+	User      string    `json:"user"`
+	Channel   string    `json:"channel"`
+	TS        string    `json:"ts"`
+	ItemUser  string    `json:"item_user"`
+	EventTime time.Time `json:"event_time"`
+
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// NewFeedbackMessage はリアクションの情報からメッセージを作成する
+// channel, ts はリアクションが付けられたメッセージ、itemUser はそのメッセージの投稿者（Bot）
+func NewFeedbackMessage(action, reaction, user, channel, ts, itemUser string, eventTime time.Time) *FeedbackMessage {
+	return &FeedbackMessage{
+		Version:   MentionMessageVersion,
+		Type:      FeedbackMessageType,
+		Source:    SourceReaction,
+		Action:    action,
+		Reaction:  reaction,
+		User:      user,
+		Channel:   channel,
+		TS:        ts,
+		ItemUser:  itemUser,
+		EventTime: eventTime,
+	}
+}
+
+// Validate はAIワーカーが処理に必要なフィールドが設定されているか検証する
+func (m *FeedbackMessage) Validate() error {
+	if m.Action != ReactionActionAdded && m.Action != ReactionActionRemoved {
+		return errors.New("action must be added or removed")
+	}
+	if m.Reaction == "" {
+		return errors.New("reaction is required")
+	}
+	if m.User == "" {
+		return errors.New("user is required")
+	}
+	if m.Channel == "" {
+		return errors.New("channel is required")
+	}
+	if m.TS == "" {
+		return errors.New("ts is required")
+	}
+	return nil
+}
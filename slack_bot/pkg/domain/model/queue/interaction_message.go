@@ -0,0 +1,66 @@
+package queue
+
+import (
+	"errors"
+	"time"
+)
+
+// SourceInteraction はメッセージのボタンなどの操作であることを表す
+const SourceInteraction = "interaction"
+
+// InteractionMessageType はメッセージの操作のメッセージの種類
+const InteractionMessageType = "interaction"
+
+// InteractionMessage はユーザーがBotのメッセージのボタンを押したこと（回答の承認・却下など）をAIワーカーに送信するメッセージ
+type InteractionMessage struct {
+	Version   int    `json:"version"`
+	Type      string `json:"type"`
+	Source    string `json:"source"`
+	TeamID    string `json:"team_id,omitempty"`
+	Workspace string `json:"workspace,omitempty"`
+	// ActionID, Value は押されたボタンの action_id と value
+	ActionID string `json:"action_id"`
+	Value    string `json:"value"`
+	// User はボタンを押したユーザー
+	User string `json:"user"`
+	// Channel, TS, ThreadTS, MessageText はボタンが付いていたメッセージ
+	Channel     string    `json:"channel"`
+	TS          string    `json:"ts"`
+	ThreadTS    string    `json:"thread_ts,omitempty"`
+	MessageText string    `json:"message_text,omitempty"`
+	ActionTime  time.Time `json:"action_time"`
+
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// NewInteractionMessage はボタンの操作からメッセージを作成する
+func NewInteractionMessage(actionID, value, user, channel, ts string, actionTime time.Time) *InteractionMessage {
+	return &InteractionMessage{
+		Version:    MentionMessageVersion,
+		Type:       InteractionMessageType,
+		Source:     SourceInteraction,
+		ActionID:   actionID,
+		Value:      value,
+		User:       user,
+		Channel:    channel,
+		TS:         ts,
+		ActionTime: actionTime,
+	}
+}
+
+// Validate はAIワーカーが処理に必要なフィールドが設定されているか検証する
+func (m *InteractionMessage) Validate() error {
+	if m.ActionID == "" {
+		return errors.New("action_id is required")
+	}
+	if m.User == "" {
+		return errors.New("user is required")
+	}
+	if m.Channel == "" {
+		return errors.New("channel is required")
+	}
+	if m.TS == "" {
+		return errors.New("ts is required")
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+)
+
+// MentionMessageVersion はキューに送信するメッセージ形式のバージョン
+// フィールドの意味を変える・削除するなど、互換性のない変更をした場合に上げる
+const MentionMessageVersion = 1
+
+// MaxMessageSize はキューに送信できるメッセージの最大サイズ（SQSの上限）
+const MaxMessageSize = 256 * 1024
+
+// SourceSlack はSlackから受け付けたメッセージであることを表す
+const SourceSlack = "slack"
+
+// SourceShortcut はメッセージショートカットで受け付けたメッセージであることを表す
+const SourceShortcut = "shortcut"
+
+// SourceSlashCommand はスラッシュコマンドで受け付けたメッセージであることを表す
+const SourceSlashCommand = "slash_command"
+
+type (
+	// MentionMessage はAIワーカーに送信するメッセージ
+	MentionMessage struct {
+		Version       int           `json:"version"`
+		ID            string        `json:"id"`
+		Type          string        `json:"type"`
+		TeamID        string        `json:"team_id,omitempty"`
+		Workspace     string        `json:"workspace,omitempty"`
+		Text          string        `json:"text"`
+		User          string        `json:"user"`
+		Channel       string        `json:"channel"`
+		TS            string        `json:"ts"`
+		ThreadTS      string        `json:"thread_ts"`
+		EventTime     time.Time     `json:"event_time"`
+		Source        string        `json:"source"`
+		History       []HistoryItem `json:"history,omitempty"`
+		Attachments   []Attachment  `json:"attachments,omitempty"`
+		Lang          string        `json:"lang,omitempty"`
+		CorrelationID string        `json:"correlation_id,omitempty"`
+
+		// TextTruncated は Text が最大文字数を超えたために切り詰められたかどうか
+		TextTruncated bool `json:"text_truncated,omitempty"`
+		// BroadcastMention は元のテキストに @here / @channel / @everyone が含まれていたかどうか
+		BroadcastMention bool `json:"broadcast_mention,omitempty"`
+		// Reaction はリアクションによる依頼の場合の絵文字名
+		Reaction string `json:"reaction,omitempty"`
+		// MessageUser はリアクションによる依頼の場合の元のメッセージの投稿者
+		MessageUser string `json:"message_user,omitempty"`
+		// StatusUpdates は途中経過（type: "progress"）を回答キューに送信してよいかどうか
+		StatusUpdates bool `json:"status_updates,omitempty"`
+		// UserName, UserRealName は依頼したユーザーの表示名と氏名（取得できない場合はユーザーID）
+		UserName     string `json:"user_name,omitempty"`
+		UserRealName string `json:"user_real_name,omitempty"`
+		// ConversationID は同じスレッドでのやり取りをまとめる会話のID（conversation.enabled の場合のみ）
+		ConversationID string `json:"conversation_id,omitempty"`
+		// Settings はチャンネルに設定された回答の言語・ペルソナ（channel_settings。設定がない場合は省略）
+		Settings *ChannelSettings `json:"settings,omitempty"`
+
+		// 以下は設定で有効にした付加処理によって設定される
+		ChannelName string `json:"channel_name,omitempty"`
+		Permalink   string `json:"permalink,omitempty"`
+		Locale      string `json:"locale,omitempty"`
+	}
+
+	// ChannelSettings はAIワーカーが回答を調整するためのチャンネルの設定
+	ChannelSettings struct {
+		Language string `json:"language,omitempty"`
+		Persona  string `json:"persona,omitempty"`
+	}
+
+	// HistoryItem はスレッド内の会話履歴の1メッセージ
+	HistoryItem struct {
+		User string `json:"user"`
+		Text string `json:"text"`
+	}
+
+	// Attachment は添付ファイルの情報
+	// ストレージに転送した場合は url_private の代わりに object_key を設定する
+	Attachment struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Mimetype   string `json:"mimetype"`
+		URLPrivate string `json:"url_private,omitempty"`
+		Size       int    `json:"size"`
+		ObjectKey  string `json:"object_key,omitempty"`
+	}
+)
+
+// ErrThrottled はキューが送信を制限している場合のエラー
+var ErrThrottled = errors.New("queue is throttling requests")
+
+// MessageTooLargeError はメッセージがキューに送信できるサイズを超えている場合のエラー
+type MessageTooLargeError struct {
+	Size int
+}
+
+func (e *MessageTooLargeError) Error() string {
+	return fmt.Sprintf("message size %d bytes exceeds the limit of %d bytes", e.Size, MaxMessageSize)
+}
+
+// NewMentionMessage はメンションからメッセージを作成する
+// ts, thread_ts にはメンションが保持するSlackのタイムスタンプの文字列をそのまま設定する
+func NewMentionMessage(mention *slack.Mention, history []HistoryItem) *MentionMessage {
+	m := newMessage(mention)
+	m.History = history
+	return m
+}
+
+// NewReactionMessage はリアクションによる依頼からメッセージを作成する
+func NewReactionMessage(mention *slack.Mention, reaction, messageUser string) *MentionMessage {
+	m := newMessage(mention)
+	m.Reaction = reaction
+	m.MessageUser = messageUser
+	return m
+}
+
+func newMessage(mention *slack.Mention) *MentionMessage {
+	attachments := make([]Attachment, 0, len(mention.Attachments))
+	for _, a := range mention.Attachments {
+		attachments = append(attachments, Attachment{
+			ID:         string(a.ID),
+			Name:       a.Name,
+			Mimetype:   a.Mimetype,
+			URLPrivate: a.URLPrivate,
+			Size:       a.Size,
+			ObjectKey:  a.ObjectKey,
+		})
+	}
+
+	var conversationID string
+	if !mention.ConversationID.IsZero() {
+		conversationID = mention.ConversationID.String()
+	}
+
+	var settings *ChannelSettings
+	if mention.Settings != (slack.ChannelSettings{}) {
+		settings = &ChannelSettings{Language: mention.Settings.Language, Persona: mention.Settings.Persona}
+	}
+
+	// メッセージショートカット・スラッシュコマンドで受け付けたメッセージはワーカーが区別できるよう送信元を分ける
+	source := SourceSlack
+	switch mention.Source {
+	case slack.MessageSourceShortcut:
+		source = SourceShortcut
+	case slack.MessageSourceSlashCommand:
+		source = SourceSlashCommand
+	}
+
+	return &MentionMessage{
+		Version:        MentionMessageVersion,
+		ID:             mention.ID.String(),
+		Type:           string(mention.Source),
+		TeamID:         string(mention.TeamID),
+		Workspace:      string(mention.Workspace),
+		Text:           string(mention.Text),
+		User:           string(mention.UserID),
+		UserName:       string(mention.UserName),
+		UserRealName:   string(mention.UserRealName),
+		ConversationID: conversationID,
+		Settings:       settings,
+		Channel:        string(mention.ChannelID),
+		TS:             string(mention.TS),
+		ThreadTS:       string(mention.ThreadTS),
+		EventTime:      time.Time(mention.EventTime),
+		Source:         source,
+		Attachments:    attachments,
+		TextTruncated:  mention.TextTruncated,
+	}
+}
+
+// Validate はAIワーカーが処理に必要なフィールドが設定されているか検証する
+func (m *MentionMessage) Validate() error {
+	if m.Version <= 0 {
+		return errors.New("version is required")
+	}
+	if m.ID == "" {
+		return errors.New("id is required")
+	}
+	if m.Type == "" {
+		return errors.New("type is required")
+	}
+	if m.Text == "" {
+		return errors.New("text is required")
+	}
+	if m.User == "" {
+		return errors.New("user is required")
+	}
+	if m.Channel == "" {
+		return errors.New("channel is required")
+	}
+	if m.TS == "" {
+		return errors.New("ts is required")
+	}
+	return nil
+}
+
+// Marshal はメッセージを検証してJSONに変換する
+// キューに送信できるサイズを超えている場合は *MessageTooLargeError を返す
+func (m *MentionMessage) Marshal() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > MaxMessageSize {
+		return nil, &MessageTooLargeError{Size: len(body)}
+	}
+	return body, nil
+}
+
+// TruncateHistory は会話履歴の古い方から半分を取り除き、取り除いた場合は true を返す
+// メッセージがキューに送信できるサイズを超えた場合に、サイズを減らして再送するために使用する
+func (m *MentionMessage) TruncateHistory() bool {
+	if len(m.History) == 0 {
+		return false
+	}
+	m.History = m.History[len(m.History)/2+len(m.History)%2:]
+	return true
+}
@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+)
+
+// go test ./pkg/domain/model/queue -update で testdata の golden ファイルを現在の出力で更新する
+var updateGolden = flag.Bool("update", false, "testdata の golden ファイルを更新する")
+
+// assertGolden は got が testdata/name の内容と一致することを確認する
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("golden ファイルの更新エラー: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden ファイルの読み込みエラー: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s と一致しません (-update で更新できます)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// AIワーカーが受け取るJSONの項目名・省略される項目が変わっていないことを確認する
+// 項目を変更した場合は golden ファイルを更新し、互換性がない場合は MentionMessageVersion を上げる
+func TestMentionMessageMarshalGolden(t *testing.T) {
+	eventTime := time.Date(2024, 4, 5, 10, 0, 0, 200000, time.UTC)
+	id := slack.MentionID(ulid.MustParse("01HTGZ0000000000000000000A"))
+
+	tests := []struct {
+		name    string
+		golden  string
+		message func(t *testing.T) *MentionMessage
+	}{
+		{
+			name:   "省略できる項目を設定しないメンション",
+			golden: "mention_message_minimal.golden.json",
+			message: func(t *testing.T) *MentionMessage {
+				mention, err := slack.NewMention(id, slack.MessageSourceMention, "U001", "C001", "質問", slack.Timestamp(eventTime), slack.EventTime(eventTime), nil,
+					slack.WithSlackTS("1712311200.000200", ""))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return NewMentionMessage(mention, nil)
+			},
+		},
+		{
+			name:   "すべての項目を設定したメンション",
+			golden: "mention_message_full.golden.json",
+			message: func(t *testing.T) *MentionMessage {
+				attachments := []slack.Attachment{{ID: "F001", Name: "log.txt", Mimetype: "text/plain", URLPrivate: "https://files.slack.com/F001", Size: 42}}
+				mention, err := slack.NewMention(id, slack.MessageSourceMention, "U001", "C001", "質問", slack.Timestamp(eventTime), slack.EventTime(eventTime), attachments,
+					slack.WithSlackTS("1712311200.000200", "1712311000.000100"),
+					slack.WithTeamID("T001"),
+					slack.WithWorkspace("example"),
+					slack.WithUserNames("alice", "Alice Example"),
+					slack.WithChannelSettings(slack.ChannelSettings{Language: "ja", Persona: "engineer"}),
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				mention.ConversationID = slack.ConversationID(ulid.MustParse("01HTGZ0000000000000000000B"))
+				m := NewMentionMessage(mention, []HistoryItem{{User: "U002", Text: "前の質問"}})
+				m.Lang = "ja"
+				m.CorrelationID = "01HTGZ0000000000000000000A"
+				m.TextTruncated = true
+				m.BroadcastMention = true
+				m.StatusUpdates = true
+				m.ChannelName = "general"
+				m.Permalink = "https://example.slack.com/archives/C001/p1712311200000200"
+				m.Locale = "ja-JP"
+				return m
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := tt.message(t)
+			body, err := message.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, body, "", "  "); err != nil {
+				t.Fatal(err)
+			}
+			indented.WriteByte('\n')
+			assertGolden(t, tt.golden, indented.Bytes())
+
+			// ワーカーが受け取ったJSONから送信前と同じメッセージに戻せることを確認する
+			var decoded MentionMessage
+			if err := json.Unmarshal(indented.Bytes(), &decoded); err != nil {
+				t.Fatalf("golden ファイルのJSONの Unmarshal() error = %v", err)
+			}
+			// 空のリストは省略して送信するため、受信側では nil になる
+			want := *message
+			if len(want.History) == 0 {
+				want.History = nil
+			}
+			if len(want.Attachments) == 0 {
+				want.Attachments = nil
+			}
+			if !reflect.DeepEqual(decoded, want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", decoded, want)
+			}
+			again, err := decoded.Marshal()
+			if err != nil {
+				t.Fatalf("Unmarshal したメッセージの Marshal() error = %v", err)
+			}
+			if !bytes.Equal(again, body) {
+				t.Errorf("Marshal() を繰り返した結果が一致しません\ngot:\n%s\nwant:\n%s", again, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package slack
+
+import "strings"
+
+// AccessPolicy はチャンネル・ユーザー単位でBotの利用可否を判定する
+type AccessPolicy struct {
+	allowedChannels     map[ChannelID]struct{}
+	deniedChannels      map[ChannelID]struct{}
+	allowedUsers        map[UserID]struct{}
+	deniedUsers         map[UserID]struct{}
+	allowDirectMessages bool
+}
+
+// NewAccessPolicy はアクセス制御を作成する
+// allowDirectMessages はダイレクトメッセージでの利用を許可するかどうかで、
+// DMのチャンネルIDは会話ごとに異なるため、チャンネルの許可リストではなくこの設定で判定する
+func NewAccessPolicy(
+	allowedChannels []string,
+	deniedChannels []string,
+	allowedUsers []string,
+	deniedUsers []string,
+	allowDirectMessages bool,
+) *AccessPolicy {
+	return &AccessPolicy{
+		allowedChannels:     toSet[ChannelID](allowedChannels),
+		deniedChannels:      toSet[ChannelID](deniedChannels),
+		allowedUsers:        toSet[UserID](allowedUsers),
+		deniedUsers:         toSet[UserID](deniedUsers),
+		allowDirectMessages: allowDirectMessages,
+	}
+}
+
+// IsDirectMessage は1対1のダイレクトメッセージのチャンネルかどうかを返す
+// グループDM（mpim）のIDは非公開チャンネルと区別できないため、通常のチャンネルとして扱う
+func (c ChannelID) IsDirectMessage() bool {
+	return strings.HasPrefix(string(c), "D")
+}
+
+// AccessDenyReason はアクセス制御で利用を拒否した理由
+type AccessDenyReason string
+
+const (
+	// AccessDeniedChannel, AccessDeniedUser は拒否リストに含まれていたことを表す
+	AccessDeniedChannel AccessDenyReason = "denied_channel"
+	AccessDeniedUser    AccessDenyReason = "denied_user"
+	// AccessDirectMessageNotAllowed はダイレクトメッセージでの利用が許可されていないことを表す
+	AccessDirectMessageNotAllowed AccessDenyReason = "direct_message_not_allowed"
+	// AccessChannelNotAllowed, AccessUserNotAllowed は許可リストに含まれていなかったことを表す
+	AccessChannelNotAllowed AccessDenyReason = "channel_not_allowed"
+	AccessUserNotAllowed    AccessDenyReason = "user_not_allowed"
+)
+
+// Allows はチャンネルとユーザーの組み合わせが利用可能かを返す
+// 拒否リストは許可リストより優先され、許可リストが空の場合はすべて許可する
+func (p *AccessPolicy) Allows(channelID ChannelID, userID UserID) bool {
+	_, denied := p.DenyReason(channelID, userID)
+	return !denied
+}
+
+// DenyReason は利用できない場合に denied を true にして拒否した理由を返す
+func (p *AccessPolicy) DenyReason(channelID ChannelID, userID UserID) (reason AccessDenyReason, denied bool) {
+	if contains(p.deniedChannels, channelID) {
+		return AccessDeniedChannel, true
+	}
+	if contains(p.deniedUsers, userID) {
+		return AccessDeniedUser, true
+	}
+	if channelID.IsDirectMessage() {
+		if !p.allowDirectMessages {
+			return AccessDirectMessageNotAllowed, true
+		}
+	} else if len(p.allowedChannels) > 0 && !contains(p.allowedChannels, channelID) {
+		return AccessChannelNotAllowed, true
+	}
+	if len(p.allowedUsers) > 0 && !contains(p.allowedUsers, userID) {
+		return AccessUserNotAllowed, true
+	}
+	return "", false
+}
+
+func toSet[T ~string](values []string) map[T]struct{} {
+	set := make(map[T]struct{}, len(values))
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		set[T(v)] = struct{}{}
+	}
+	return set
+}
+
+func contains[T comparable](set map[T]struct{}, v T) bool {
+	_, ok := set[v]
+	return ok
+}
@@ -0,0 +1,122 @@
+package slack
+
+import "testing"
+
+func TestAccessPolicyDenyReason(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     *AccessPolicy
+		channelID  ChannelID
+		userID     UserID
+		wantReason AccessDenyReason
+		wantDenied bool
+	}{
+		{
+			name:      "許可リストが空の場合はすべて許可する",
+			policy:    NewAccessPolicy(nil, nil, nil, nil, false),
+			channelID: "C001",
+			userID:    "U001",
+		},
+		{
+			name:      "空文字の要素は許可リストに含めない",
+			policy:    NewAccessPolicy([]string{""}, nil, []string{""}, nil, false),
+			channelID: "C001",
+			userID:    "U001",
+		},
+		{
+			name:      "許可リストに含まれるチャンネルは許可する",
+			policy:    NewAccessPolicy([]string{"C001", "C002"}, nil, nil, nil, false),
+			channelID: "C002",
+			userID:    "U001",
+		},
+		{
+			name:       "許可リストに含まれないチャンネルは拒否する",
+			policy:     NewAccessPolicy([]string{"C001"}, nil, nil, nil, false),
+			channelID:  "C999",
+			userID:     "U001",
+			wantReason: AccessChannelNotAllowed,
+			wantDenied: true,
+		},
+		{
+			name:       "許可リストに含まれないユーザーは拒否する",
+			policy:     NewAccessPolicy(nil, nil, []string{"U001"}, nil, false),
+			channelID:  "C001",
+			userID:     "U999",
+			wantReason: AccessUserNotAllowed,
+			wantDenied: true,
+		},
+		{
+			name:       "拒否リストのチャンネルは許可リストより優先する",
+			policy:     NewAccessPolicy([]string{"C001"}, []string{"C001"}, nil, nil, false),
+			channelID:  "C001",
+			userID:     "U001",
+			wantReason: AccessDeniedChannel,
+			wantDenied: true,
+		},
+		{
+			name:       "拒否リストのユーザーは許可リストより優先する",
+			policy:     NewAccessPolicy(nil, nil, []string{"U001"}, []string{"U001"}, false),
+			channelID:  "C001",
+			userID:     "U001",
+			wantReason: AccessDeniedUser,
+			wantDenied: true,
+		},
+		{
+			name:       "チャンネルとユーザーの両方が拒否リストにある場合はチャンネルを理由にする",
+			policy:     NewAccessPolicy(nil, []string{"C001"}, nil, []string{"U001"}, false),
+			channelID:  "C001",
+			userID:     "U001",
+			wantReason: AccessDeniedChannel,
+			wantDenied: true,
+		},
+		{
+			name:       "拒否リストのユーザーは許可されたチャンネルでも拒否する",
+			policy:     NewAccessPolicy([]string{"C001"}, nil, nil, []string{"U001"}, false),
+			channelID:  "C001",
+			userID:     "U001",
+			wantReason: AccessDeniedUser,
+			wantDenied: true,
+		},
+		{
+			name:       "ダイレクトメッセージは許可していない場合に拒否する",
+			policy:     NewAccessPolicy(nil, nil, nil, nil, false),
+			channelID:  "D001",
+			userID:     "U001",
+			wantReason: AccessDirectMessageNotAllowed,
+			wantDenied: true,
+		},
+		{
+			name:      "ダイレクトメッセージはチャンネルの許可リストで判定しない",
+			policy:    NewAccessPolicy([]string{"C001"}, nil, nil, nil, true),
+			channelID: "D001",
+			userID:    "U001",
+		},
+		{
+			name:       "ダイレクトメッセージでもユーザーの許可リストで判定する",
+			policy:     NewAccessPolicy(nil, nil, []string{"U001"}, nil, true),
+			channelID:  "D001",
+			userID:     "U999",
+			wantReason: AccessUserNotAllowed,
+			wantDenied: true,
+		},
+		{
+			name:       "ダイレクトメッセージでもチャンネルの拒否リストを優先する",
+			policy:     NewAccessPolicy(nil, []string{"D001"}, nil, nil, true),
+			channelID:  "D001",
+			userID:     "U001",
+			wantReason: AccessDeniedChannel,
+			wantDenied: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, denied := tt.policy.DenyReason(tt.channelID, tt.userID)
+			if reason != tt.wantReason || denied != tt.wantDenied {
+				t.Errorf("DenyReason(%q, %q) = (%q, %v), want (%q, %v)", tt.channelID, tt.userID, reason, denied, tt.wantReason, tt.wantDenied)
+			}
+			if allowed := tt.policy.Allows(tt.channelID, tt.userID); allowed == tt.wantDenied {
+				t.Errorf("Allows(%q, %q) = %v, want %v", tt.channelID, tt.userID, allowed, !tt.wantDenied)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package slack
+
+import (
+	"errors"
+)
+
+type (
+	// Answer はAIワーカーがメンションに回答した内容
+	Answer struct {
+		// MentionID は回答したメンションのID（キューのメッセージの id。分からない場合は空）
+		MentionID string
+		Text      Text
+		// Sources は回答の根拠とした資料（ない場合は空）
+		Sources []AnswerSource
+	}
+	// AnswerSource は回答の根拠とした資料
+	AnswerSource struct {
+		Title string
+		URL   string
+	}
+)
+
+// ErrSourceURLRequired は回答の根拠の資料にURLが設定されていない場合のエラー
+var ErrSourceURLRequired = errors.New("source url is required")
+
+func NewAnswer(mentionID string, text Text, sources []AnswerSource) (*Answer, error) {
+	a := &Answer{
+		MentionID: mentionID,
+		Text:      text,
+		Sources:   sources,
+	}
+	if err := newValidationError(a.validate()); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *Answer) validate() []error {
+	var errs []error
+	if a.Text == "" {
+		errs = append(errs, ErrTextRequired)
+	}
+	for _, source := range a.Sources {
+		if source.URL == "" {
+			errs = append(errs, ErrSourceURLRequired)
+			break
+		}
+	}
+	return errs
+}
+
+// Label は資料の表示名（タイトルがない場合はURL）
+func (s AnswerSource) Label() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.URL
+}
@@ -0,0 +1,54 @@
+package slack
+
+import (
+	"errors"
+)
+
+type (
+	// Attachment はメンションに添付されたファイルのメタデータ
+	// ObjectKey はファイルをストレージに転送した場合のみ設定される
+	Attachment struct {
+		ID         FileID
+		Name       string
+		Mimetype   string
+		URLPrivate string
+		Size       int
+		ObjectKey  string
+	}
+	FileID string
+)
+
+func NewAttachment(
+	id FileID,
+	name string,
+	mimetype string,
+	urlPrivate string,
+	size int,
+) (*Attachment, error) {
+	a := &Attachment{
+		ID:         id,
+		Name:       name,
+		Mimetype:   mimetype,
+		URLPrivate: urlPrivate,
+		Size:       size,
+	}
+
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a Attachment) validate() error {
+	if a.ID == "" {
+		return errors.New("file id is required")
+	}
+	if a.URLPrivate == "" && a.ObjectKey == "" {
+		return errors.New("file url or object key is required")
+	}
+	if a.Size < 0 {
+		return errors.New("file size must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// userMentionPattern はユーザーへのメンションを表すSlackのトークン（<@U123> や <@U123|name>）
+var userMentionPattern = regexp.MustCompile(`<@[A-Z0-9]+(?:\|[^>]*)?>`)
+
+type (
+	// AutoReplyRules はAIに送信せずに定型文で返信するメッセージの規則
+	// 規則は定義順に評価し、最初に一致した規則の返信を使用する
+	AutoReplyRules struct {
+		rules []autoReplyRule
+	}
+	autoReplyRule struct {
+		name    string
+		pattern *regexp.Regexp
+		reply   string
+	}
+	// AutoReplyRuleSpec は規則の定義
+	AutoReplyRuleSpec struct {
+		Name    string
+		Pattern string
+		Reply   string
+	}
+)
+
+// NewAutoReplyRules は規則の定義から正規表現をコンパイルする
+func NewAutoReplyRules(specs []AutoReplyRuleSpec) (*AutoReplyRules, error) {
+	rules := make([]autoReplyRule, 0, len(specs))
+	for _, spec := range specs {
+		if spec.Pattern == "" {
+			return nil, fmt.Errorf("pattern is required for rule %q", spec.Name)
+		}
+		if spec.Reply == "" {
+			return nil, fmt.Errorf("reply is required for rule %q", spec.Name)
+		}
+		pattern, err := regexp.Compile(spec.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for rule %q: %w", spec.Name, err)
+		}
+		rules = append(rules, autoReplyRule{name: spec.Name, pattern: pattern, reply: spec.Reply})
+	}
+	if len(rules) == 0 {
+		return nil, errors.New("at least one rule is required")
+	}
+	return &AutoReplyRules{rules: rules}, nil
+}
+
+// Match はメンションを取り除いたテキストに一致する規則を探し、その名前と返信を返す
+func (r *AutoReplyRules) Match(text Text) (name string, reply string, ok bool) {
+	normalized := strings.TrimSpace(userMentionPattern.ReplaceAllString(string(text), ""))
+	for _, rule := range r.rules {
+		if rule.pattern.MatchString(normalized) {
+			return rule.name, rule.reply, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,23 @@
+package slack
+
+import (
+	"regexp"
+	"strings"
+)
+
+// broadcastMentionPattern は @here / @channel / @everyone を表すSlackのトークン
+// ラベル付きの形式（<!here|@here> など）にも一致する
+var broadcastMentionPattern = regexp.MustCompile(`<!(?:here|channel|everyone)(?:\|[^>]*)?>`)
+
+// 取り除く際はトークンの後ろの空白もまとめて取り除く
+var broadcastMentionStripPattern = regexp.MustCompile(`<!(?:here|channel|everyone)(?:\|[^>]*)?>[ \t]*`)
+
+// HasBroadcastMention はテキストに全体メンションが含まれているかを返す
+func HasBroadcastMention(text Text) bool {
+	return broadcastMentionPattern.MatchString(string(text))
+}
+
+// StripBroadcastMentions はテキストから全体メンションを取り除く
+func StripBroadcastMentions(text Text) Text {
+	return Text(strings.TrimSpace(broadcastMentionStripPattern.ReplaceAllString(string(text), "")))
+}
@@ -0,0 +1,32 @@
+package slack
+
+import "testing"
+
+func TestBroadcastMention(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      Text
+		wantHas   bool
+		wantStrip Text
+	}{
+		{name: "@here", text: "<!here> デプロイは終わりましたか", wantHas: true, wantStrip: "デプロイは終わりましたか"},
+		{name: "@channel", text: "<@UBOT> <!channel> 障害の状況を教えて", wantHas: true, wantStrip: "<@UBOT> 障害の状況を教えて"},
+		{name: "@everyone", text: "<!everyone> お知らせの要約をお願いします", wantHas: true, wantStrip: "お知らせの要約をお願いします"},
+		{name: "ラベル付きの形式", text: "<!here|@here> 確認して", wantHas: true, wantStrip: "確認して"},
+		{name: "複数の全体メンション", text: "<!here> <!channel>\t確認して <!everyone>", wantHas: true, wantStrip: "確認して"},
+		{name: "全体メンションのみ", text: "<!channel>", wantHas: true, wantStrip: ""},
+		{name: "ユーザーのメンションは対象外", text: "<@U001> 確認して", wantHas: false, wantStrip: "<@U001> 確認して"},
+		{name: "ユーザーグループのメンションは対象外", text: "<!subteam^S001> 確認して", wantHas: false, wantStrip: "<!subteam^S001> 確認して"},
+		{name: "トークンではない文字列は対象外", text: "@here や here は通常の文字列", wantHas: false, wantStrip: "@here や here は通常の文字列"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasBroadcastMention(tt.text); got != tt.wantHas {
+				t.Errorf("HasBroadcastMention(%q) = %t, want %t", tt.text, got, tt.wantHas)
+			}
+			if got := StripBroadcastMentions(tt.text); got != tt.wantStrip {
+				t.Errorf("StripBroadcastMentions(%q) = %q, want %q", tt.text, got, tt.wantStrip)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package slack
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+type (
+	// Conversation は同じスレッドでのBotとのやり取り（セッション）
+	// 最後のメッセージから一定時間が経過した会話は終了したものとして扱い、同じスレッドでも新しい会話を始める
+	Conversation struct {
+		ID           ConversationID
+		ChannelID    ChannelID
+		ThreadTS     ThreadTS
+		StartedBy    UserID
+		LastActivity time.Time
+		MessageCount int
+		// Messages は保存したスレッドのメッセージ（古い順）
+		Messages []ConversationMessage
+	}
+	ConversationID ulid.ULID
+	// ThreadTS はスレッドの起点のメッセージのタイムスタンプ（Slackの文字列のまま）
+	ThreadTS string
+
+	// ConversationMessage はスレッドの会話の1メッセージ（ユーザーの質問またはBotの回答）
+	ConversationMessage struct {
+		ChannelID ChannelID
+		ThreadTS  ThreadTS
+		// TS はメッセージのタイムスタンプ（Slackの文字列のまま）
+		TS       string
+		Role     ConversationRole
+		UserID   UserID
+		Text     Text
+		PostedAt time.Time
+	}
+	// ConversationRole はメッセージの発言者の種類
+	ConversationRole string
+)
+
+const (
+	ConversationRoleUser      ConversationRole = "user"
+	ConversationRoleAssistant ConversationRole = "assistant"
+)
+
+// NewConversationID は会話のIDを発行する
+func NewConversationID() (ConversationID, error) {
+	id, err := ulid.New(ulid.Timestamp(time.Now()), ulid.Monotonic(rand.New(rand.NewSource(time.Now().UnixNano())), 0))
+	if err != nil {
+		return ConversationID{}, err
+	}
+	return ConversationID(id), nil
+}
+
+func (id ConversationID) String() string {
+	return ulid.ULID(id).String()
+}
+
+// IsZero はIDが設定されていないかどうかを返す
+func (id ConversationID) IsZero() bool {
+	return ulid.ULID(id) == ulid.ULID{}
+}
+
+// NewConversation はスレッドの最初のメッセージから会話を作成する
+func NewConversation(id ConversationID, channelID ChannelID, threadTS ThreadTS, startedBy UserID, startedAt time.Time) (*Conversation, error) {
+	c := &Conversation{
+		ID:           id,
+		ChannelID:    channelID,
+		ThreadTS:     threadTS,
+		StartedBy:    startedBy,
+		LastActivity: startedAt,
+		MessageCount: 1,
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Conversation) validate() error {
+	if c.ID.IsZero() {
+		return errors.New("id is required")
+	}
+	if c.ChannelID == "" {
+		return errors.New("channelID is required")
+	}
+	if c.ThreadTS == "" {
+		return errors.New("threadTS is required")
+	}
+	if c.StartedBy == "" {
+		return errors.New("startedBy is required")
+	}
+	return nil
+}
+
+// IsClosed は最後のメッセージから ttl 以上経過して終了した会話かどうかを返す
+func (c *Conversation) IsClosed(now time.Time, ttl time.Duration) bool {
+	return !c.LastActivity.After(now.Add(-ttl))
+}
+
+// AddMessage は同じスレッドのメッセージを会話に追加する
+func (c *Conversation) AddMessage(message ConversationMessage) error {
+	if message.ChannelID != c.ChannelID || message.ThreadTS != c.ThreadTS {
+		return errors.New("message belongs to another thread")
+	}
+	c.Messages = append(c.Messages, message)
+	return nil
+}
+
+// PreviousTurns は ts より前に投稿されたメッセージを古い順に返す（ts が空の場合はすべて）
+// Slackのタイムスタンプは桁数が同じため、文字列のまま比較する
+func (c *Conversation) PreviousTurns(ts string) []ConversationMessage {
+	var turns []ConversationMessage
+	for _, message := range c.Messages {
+		if ts == "" || message.TS < ts {
+			turns = append(turns, message)
+		}
+	}
+	return turns
+}
+
+// NewConversationMessage はスレッドに投稿されたメッセージから会話のメッセージを作成する
+func NewConversationMessage(channelID ChannelID, threadTS ThreadTS, ts string, role ConversationRole, userID UserID, text Text, postedAt time.Time) (*ConversationMessage, error) {
+	m := &ConversationMessage{
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		TS:        ts,
+		Role:      role,
+		UserID:    userID,
+		Text:      text,
+		PostedAt:  postedAt,
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *ConversationMessage) validate() error {
+	if m.ChannelID == "" {
+		return errors.New("channelID is required")
+	}
+	if m.ThreadTS == "" {
+		return errors.New("threadTS is required")
+	}
+	if m.TS == "" {
+		return errors.New("ts is required")
+	}
+	if m.Role != ConversationRoleUser && m.Role != ConversationRoleAssistant {
+		return errors.New("role must be user or assistant")
+	}
+	if m.Text == "" {
+		return errors.New("text is required")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package slack
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewConversationValidation(t *testing.T) {
+	id, err := NewConversationID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		id        ConversationID
+		channelID ChannelID
+		threadTS  ThreadTS
+		startedBy UserID
+		wantErr   bool
+	}{
+		{name: "すべて設定", id: id, channelID: "C001", threadTS: "1712345678.000200", startedBy: "U001"},
+		{name: "IDが空", channelID: "C001", threadTS: "1712345678.000200", startedBy: "U001", wantErr: true},
+		{name: "チャンネルIDが空", id: id, threadTS: "1712345678.000200", startedBy: "U001", wantErr: true},
+		{name: "thread_ts が空", id: id, channelID: "C001", startedBy: "U001", wantErr: true},
+		{name: "開始したユーザーが空", id: id, channelID: "C001", threadTS: "1712345678.000200", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConversation(tt.id, tt.channelID, tt.threadTS, tt.startedBy, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewConversation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (c.MessageCount != 1 || !c.LastActivity.Equal(now)) {
+				t.Errorf("MessageCount, LastActivity = %d, %v, want 1, %v", c.MessageCount, c.LastActivity, now)
+			}
+		})
+	}
+}
+
+func TestConversationIsClosed(t *testing.T) {
+	lastActivity := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	c := &Conversation{LastActivity: lastActivity}
+	ttl := 30 * time.Minute
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{name: "ttl 未満は続いている", now: lastActivity.Add(ttl - time.Second), want: false},
+		{name: "ちょうど ttl で終了する", now: lastActivity.Add(ttl), want: true},
+		{name: "ttl を超えると終了する", now: lastActivity.Add(ttl + time.Second), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.IsClosed(tt.now, ttl); got != tt.want {
+				t.Errorf("IsClosed(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConversationPreviousTurns(t *testing.T) {
+	c := &Conversation{ChannelID: "C001", ThreadTS: "1712345600.000100"}
+	for _, ts := range []string{"1712345600.000100", "1712345650.000100", "1712345700.000100"} {
+		if err := c.AddMessage(ConversationMessage{ChannelID: "C001", ThreadTS: "1712345600.000100", TS: ts, Role: ConversationRoleUser, Text: "質問"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 別のスレッドのメッセージは追加しない
+	if err := c.AddMessage(ConversationMessage{ChannelID: "C001", ThreadTS: "1712349999.000100", TS: "1712349999.000100"}); err == nil {
+		t.Error("別のスレッドのメッセージで AddMessage() error = nil, want error")
+	}
+	if err := c.AddMessage(ConversationMessage{ChannelID: "C002", ThreadTS: "1712345600.000100", TS: "1712345800.000100"}); err == nil {
+		t.Error("別のチャンネルのメッセージで AddMessage() error = nil, want error")
+	}
+
+	tests := []struct {
+		name string
+		ts   string
+		want []string
+	}{
+		{name: "メンションより前のメッセージだけを返す", ts: "1712345700.000100", want: []string{"1712345600.000100", "1712345650.000100"}},
+		{name: "ts が空の場合はすべて返す", ts: "", want: []string{"1712345600.000100", "1712345650.000100", "1712345700.000100"}},
+		{name: "スレッドの起点より前の場合は空", ts: "1712345600.000100", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range c.PreviousTurns(tt.ts) {
+				got = append(got, m.TS)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("PreviousTurns(%q) = %v, want %v", tt.ts, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("PreviousTurns(%q) = %v, want %v", tt.ts, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestNewConversationMessageValidation(t *testing.T) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		ts      string
+		role    ConversationRole
+		text    Text
+		wantErr bool
+	}{
+		{name: "ユーザーの質問", ts: "1712345678.000200", role: ConversationRoleUser, text: "質問"},
+		{name: "Botの回答", ts: "1712345678.000200", role: ConversationRoleAssistant, text: "回答"},
+		{name: "ts が空", role: ConversationRoleUser, text: "質問", wantErr: true},
+		{name: "不明な発言者", ts: "1712345678.000200", role: "system", text: "質問", wantErr: true},
+		{name: "テキストが空", ts: "1712345678.000200", role: ConversationRoleUser, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConversationMessage("C001", "1712345600.000100", tt.ts, tt.role, "U001", tt.text, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewConversationMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package slack
+
+import (
+	"errors"
+	"strings"
+)
+
+// メンションの検証で返すエラー
+// 呼び出し元は errors.Is で原因ごとに処理を分けられる
+var (
+	// ErrSourceRequired はメッセージの種類が設定されていない場合のエラー
+	ErrSourceRequired = errors.New("source is required")
+	// ErrUserIDRequired はユーザーIDが設定されていない場合のエラー
+	ErrUserIDRequired = errors.New("userID is required")
+	// ErrChannelIDRequired はチャンネルIDが設定されていない場合のエラー
+	ErrChannelIDRequired = errors.New("channelID is required")
+	// ErrTextRequired はテキストが空の場合のエラー
+	ErrTextRequired = errors.New("text is required")
+	// ErrTextTooLong はテキストが最大文字数を超えている場合のエラー
+	ErrTextTooLong = errors.New("text exceeds maximum length")
+	// ErrTimestampRequired はタイムスタンプが設定されていない場合のエラー
+	ErrTimestampRequired = errors.New("timestamp is required")
+	// ErrInvalidTimestamp はSlackのタイムスタンプの文字列が不正な場合のエラー
+	ErrInvalidTimestamp = errors.New("invalid slack timestamp")
+)
+
+// ValidationError は検証で見つかったすべてのエラーをまとめたエラー
+// errors.Is / errors.As は含まれるそれぞれのエラーに対して判定する
+type ValidationError struct {
+	Errs []error
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errs
+}
+
+// newValidationError はエラーがある場合のみ *ValidationError を返す
+func newValidationError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errs: errs}
+}
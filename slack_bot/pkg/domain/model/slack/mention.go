@@ -1,82 +1,310 @@
 package slack
 
 import (
-	"errors"
+	"crypto/sha256"
+	"fmt"
 	"math/rand"
 	"time"
+	"unicode/utf8"
 
 	"github.com/oklog/ulid/v2"
 )
 
 type (
 	Mention struct {
-		ID        MentionID
-		UserID    UserID
-		ChannelID ChannelID
-		Text      Text
-		Timestamp Timestamp
-		EventTime EventTime
-	}
-	MentionID ulid.ULID
-	ChannelID string
-	UserID    string
-	Text      string
-	Timestamp time.Time
-	EventTime time.Time
+		ID          MentionID
+		Source      MessageSource
+		TeamID      TeamID
+		Workspace   Workspace
+		UserID      UserID
+		ChannelID   ChannelID
+		Text        Text
+		Timestamp   Timestamp
+		EventTime   EventTime
+		Attachments []Attachment
+		// TS, ThreadTS はSlackから受信したタイムスタンプの文字列（スレッド外のメッセージの場合 ThreadTS は空）
+		// Timestamp は TS を変換した時刻で、保存時の並び替えや保持期間の判定に使用する
+		TS       SlackTS
+		ThreadTS SlackTS
+		// TextTruncated は Text が最大文字数を超えたために切り詰められたかどうか
+		TextTruncated bool
+		// RawEvent はSlackから受信したイベントのJSON（調査・再実行用。取得できない場合は空）
+		RawEvent RawEvent
+		// UserName, UserRealName はユーザーの表示名と氏名（取得できない場合はユーザーID）
+		UserName     UserName
+		UserRealName UserName
+		// ConversationID はメンションのスレッドの会話（会話の追跡が無効な場合はゼロ値）
+		ConversationID ConversationID
+		// Status は処理の状態（作成時は MentionStatusReceived）
+		Status MentionStatus
+		// Settings はチャンネルに設定された回答の言語・ペルソナ（AIワーカーへのヒント。設定がない場合はゼロ値）
+		Settings ChannelSettings
+	}
+	// ChannelSettings はチャンネルごとにAIワーカーへ渡す回答の言語とペルソナ
+	ChannelSettings struct {
+		Language string
+		Persona  string
+	}
+	MentionID     ulid.ULID
+	MessageSource string
+	TeamID        string
+	Workspace     string
+	ChannelID     string
+	UserID        string
+	UserName      string
+	Text          string
+	Timestamp     time.Time
+	EventTime     time.Time
+	RawEvent      string
 )
 
+// MentionOption はメンション作成時の検証方法や付加情報を指定する
+type MentionOption func(*mentionOptions)
+
+type mentionOptions struct {
+	maxTextLength int
+	truncateText  bool
+	teamID        TeamID
+	workspace     Workspace
+	rawEvent      RawEvent
+	userName      UserName
+	userRealName  UserName
+	ts            SlackTS
+	threadTS      SlackTS
+	settings      ChannelSettings
+}
+
+// WithChannelSettings はメンションを受け付けたチャンネルの回答の言語とペルソナを設定する
+func WithChannelSettings(settings ChannelSettings) MentionOption {
+	return func(o *mentionOptions) {
+		o.settings = settings
+	}
+}
+
+// WithSlackTS はSlackから受信したメッセージの ts と thread_ts の文字列を設定する
+// 変換できない文字列の場合は作成時に ErrInvalidTimestamp を返す
+func WithSlackTS(ts, threadTS string) MentionOption {
+	return func(o *mentionOptions) {
+		o.ts = SlackTS(ts)
+		o.threadTS = SlackTS(threadTS)
+	}
+}
+
+// WithTeamID はメンションを受け付けたワークスペースのIDを設定する
+func WithTeamID(teamID TeamID) MentionOption {
+	return func(o *mentionOptions) {
+		o.teamID = teamID
+	}
+}
+
+// WithWorkspace はメンションを受け付けたワークスペースの名前（設定ファイルの name）を設定する
+func WithWorkspace(workspace Workspace) MentionOption {
+	return func(o *mentionOptions) {
+		o.workspace = workspace
+	}
+}
+
+// WithRawEvent はSlackから受信したイベントのJSONを設定する
+func WithRawEvent(rawEvent RawEvent) MentionOption {
+	return func(o *mentionOptions) {
+		o.rawEvent = rawEvent
+	}
+}
+
+// WithUserNames はユーザーの表示名と氏名を設定する
+func WithUserNames(name, realName UserName) MentionOption {
+	return func(o *mentionOptions) {
+		o.userName = name
+		o.userRealName = realName
+	}
+}
+
+// WithMaxTextLength はテキストの最大文字数（rune数）を設定する
+// truncate が true の場合は超えた分を切り詰め、false の場合は ErrTextTooLong を返す
+func WithMaxTextLength(maxLength int, truncate bool) MentionOption {
+	return func(o *mentionOptions) {
+		o.maxTextLength = maxLength
+		o.truncateText = truncate
+	}
+}
+
+const (
+	// MessageSourceMention はBotへのメンション
+	MessageSourceMention MessageSource = "mention"
+	// MessageSourceReaction は設定された絵文字のリアクション
+	MessageSourceReaction MessageSource = "reaction"
+	// MessageSourceShortcut はメッセージショートカットで入力された質問
+	MessageSourceShortcut MessageSource = "shortcut"
+	// MessageSourceDirectMessage はBotとのダイレクトメッセージ
+	MessageSourceDirectMessage MessageSource = "direct_message"
+	// MessageSourceSlashCommand はスラッシュコマンド（/ask, /summarize）で入力された質問
+	MessageSourceSlashCommand MessageSource = "slash_command"
+)
+
+// NewMentionID はメンションのIDを発行する
+// イベント受信時に発行し、ログやキューのメッセージを紐付ける相関IDとしても使用する
+func NewMentionID() (MentionID, error) {
+	id, err := ulid.New(ulid.Timestamp(time.Now()), ulid.Monotonic(rand.New(rand.NewSource(time.Now().UnixNano())), 0))
+	if err != nil {
+		return MentionID{}, err
+	}
+	return MentionID(id), nil
+}
+
+// NewMentionIDFromEvent はSlackのイベントからメンションのIDを決定的に導出する
+// 同じイベントを再処理しても同じIDになるため、保存時に主キーの重複として検出できる
+// ULIDの時刻部分にはメッセージのタイムスタンプを、乱数部分には種類・チャンネル・タイムスタンプのハッシュを使用する
+// リアクションは NewReactionMentionIDFromEvent を使用する
+func NewMentionIDFromEvent(source MessageSource, channelID ChannelID, timestamp Timestamp) (MentionID, error) {
+	t := time.Time(timestamp)
+	if t.IsZero() {
+		return MentionID{}, ErrTimestampRequired
+	}
+	return deriveMentionID(t, fmt.Sprintf("%s:%s:%d", source, channelID, t.UnixMicro()))
+}
+
+// NewReactionMentionIDFromEvent はリアクションのイベントからメンションのIDを決定的に導出する
+// timestamp はリアクションされたメッセージのものであり、同じメッセージへの別のユーザーのリアクションや、
+// 付け直したリアクションを別の依頼にするため、リアクションしたユーザーとリアクションの時刻もハッシュに含める
+func NewReactionMentionIDFromEvent(channelID ChannelID, timestamp Timestamp, userID UserID, reactedAt Timestamp) (MentionID, error) {
+	t, r := time.Time(timestamp), time.Time(reactedAt)
+	if t.IsZero() || r.IsZero() {
+		return MentionID{}, ErrTimestampRequired
+	}
+	return deriveMentionID(t, fmt.Sprintf("%s:%s:%d:%s:%d", MessageSourceReaction, channelID, t.UnixMicro(), userID, r.UnixMicro()))
+}
+
+// deriveMentionID は時刻部分が t、乱数部分が key のハッシュのULIDを作成する
+func deriveMentionID(t time.Time, key string) (MentionID, error) {
+	sum := sha256.Sum256([]byte(key))
+
+	var id ulid.ULID
+	if err := id.SetTime(ulid.Timestamp(t)); err != nil {
+		return MentionID{}, err
+	}
+	if err := id.SetEntropy(sum[:10]); err != nil {
+		return MentionID{}, err
+	}
+	return MentionID(id), nil
+}
+
+func (id MentionID) String() string {
+	return ulid.ULID(id).String()
+}
+
 func NewMention(
+	id MentionID,
+	source MessageSource,
 	userID UserID,
 	channelID ChannelID,
 	text Text,
 	timestamp Timestamp,
 	eventTime EventTime,
+	attachments []Attachment,
+	opts ...MentionOption,
 ) (*Mention, error) {
-	id, err := ulid.New(ulid.Timestamp(time.Now()), ulid.Monotonic(rand.New(rand.NewSource(time.Now().UnixNano())), 0))
-	if err != nil {
-		return nil, err
-	}
-	m, err := newMention(MentionID(id), userID, channelID, text, timestamp, eventTime)
+	return newMention(id, source, userID, channelID, text, timestamp, eventTime, attachments, opts...)
+}
+
+// NewMentionFromEvent はIDをイベントから導出してメンションを作成する（NewMentionIDFromEvent を参照）
+func NewMentionFromEvent(
+	source MessageSource,
+	userID UserID,
+	channelID ChannelID,
+	text Text,
+	timestamp Timestamp,
+	eventTime EventTime,
+	attachments []Attachment,
+	opts ...MentionOption,
+) (*Mention, error) {
+	id, err := NewMentionIDFromEvent(source, channelID, timestamp)
 	if err != nil {
 		return nil, err
 	}
-
-	return m, nil
+	return newMention(id, source, userID, channelID, text, timestamp, eventTime, attachments, opts...)
 }
 
 func newMention(
 	id MentionID,
+	source MessageSource,
 	userID UserID,
 	channelID ChannelID,
 	text Text,
 	timestamp Timestamp,
 	eventTime EventTime,
+	attachments []Attachment,
+	opts ...MentionOption,
 ) (*Mention, error) {
+	var o mentionOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var errs []error
+	truncated := false
+	if o.maxTextLength > 0 && utf8.RuneCountInString(string(text)) > o.maxTextLength {
+		if o.truncateText {
+			// マルチバイト文字の途中で切らないように rune 単位で切り詰める
+			text = Text([]rune(string(text))[:o.maxTextLength])
+			truncated = true
+		} else {
+			errs = append(errs, fmt.Errorf("%w (%d characters)", ErrTextTooLong, o.maxTextLength))
+		}
+	}
+
 	m := &Mention{
-		ID:        id,
-		UserID:    userID,
-		ChannelID: channelID,
-		Text:      text,
-		Timestamp: timestamp,
-		EventTime: eventTime,
+		ID:            id,
+		Source:        source,
+		TeamID:        o.teamID,
+		Workspace:     o.workspace,
+		UserID:        userID,
+		ChannelID:     channelID,
+		Text:          text,
+		Timestamp:     timestamp,
+		EventTime:     eventTime,
+		Attachments:   attachments,
+		TS:            o.ts,
+		ThreadTS:      o.threadTS,
+		TextTruncated: truncated,
+		RawEvent:      o.rawEvent,
+		UserName:      o.userName,
+		UserRealName:  o.userRealName,
+		Status:        MentionStatusReceived,
+		Settings:      o.settings,
 	}
 
-	if err := m.validate(); err != nil {
+	if err := newValidationError(append(errs, m.validate()...)); err != nil {
 		return nil, err
 	}
 
 	return m, nil
 }
 
-func (m Mention) validate() error {
+// 必須項目と添付ファイルを検証し、見つかったすべてのエラーを返す
+func (m Mention) validate() []error {
+	var errs []error
+	if m.Source == "" {
+		errs = append(errs, ErrSourceRequired)
+	}
 	if m.UserID == "" {
-		return errors.New("userID is required")
+		errs = append(errs, ErrUserIDRequired)
 	}
 	if m.ChannelID == "" {
-		return errors.New("channelID is required")
+		errs = append(errs, ErrChannelIDRequired)
 	}
 	if m.Text == "" {
-		return errors.New("text is required")
+		errs = append(errs, ErrTextRequired)
+	}
+	if _, err := ParseSlackTimestamp(string(m.TS)); err != nil {
+		errs = append(errs, fmt.Errorf("ts: %w", err))
+	}
+	if _, err := ParseSlackTimestamp(string(m.ThreadTS)); err != nil {
+		errs = append(errs, fmt.Errorf("thread_ts: %w", err))
+	}
+	for _, a := range m.Attachments {
+		if err := a.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("attachment %s: %w", a.ID, err))
+		}
 	}
-	return nil
+	return errs
 }
@@ -0,0 +1,83 @@
+package slack
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MentionStatus は保存したメンションの処理の状態
+type MentionStatus string
+
+const (
+	// MentionStatusReceived は受け付けたメンション
+	MentionStatusReceived MentionStatus = "received"
+	// MentionStatusQueued はキューへの送信が完了したメンション
+	MentionStatusQueued MentionStatus = "queued"
+	// MentionStatusAnswered はAIワーカーの回答をスレッドに投稿したメンション
+	MentionStatusAnswered MentionStatus = "answered"
+	// MentionStatusFailed はキューへの送信に失敗したメンション
+	MentionStatusFailed MentionStatus = "failed"
+	// MentionStatusTimedOut はキューへの送信が完了する前に処理の期限を過ぎたメンション
+	MentionStatusTimedOut MentionStatus = "timed_out"
+)
+
+var (
+	// ErrInvalidMentionStatus は定義されていない状態の場合のエラー
+	ErrInvalidMentionStatus = errors.New("mention status is invalid")
+	// ErrInvalidStatusTransition は許可されていない状態の遷移の場合のエラー
+	ErrInvalidStatusTransition = errors.New("mention status transition is not allowed")
+)
+
+// mentionStatusTransitions は状態ごとに遷移できる次の状態
+// 送信に失敗・期限切れになったメンションもデッドレターの再送などで送信・回答される場合がある。回答済みからは遷移しない
+var mentionStatusTransitions = map[MentionStatus][]MentionStatus{
+	MentionStatusReceived: {MentionStatusQueued, MentionStatusFailed, MentionStatusTimedOut},
+	MentionStatusQueued:   {MentionStatusAnswered, MentionStatusFailed},
+	MentionStatusFailed:   {MentionStatusQueued, MentionStatusAnswered},
+	MentionStatusTimedOut: {MentionStatusQueued, MentionStatusFailed, MentionStatusAnswered},
+	MentionStatusAnswered: nil,
+}
+
+// ParseMentionStatus は文字列を状態に変換する
+func ParseMentionStatus(s string) (MentionStatus, error) {
+	status := MentionStatus(s)
+	if !status.Valid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidMentionStatus, s)
+	}
+	return status, nil
+}
+
+func (s MentionStatus) Valid() bool {
+	_, ok := mentionStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo は s から next に遷移できるかを返す
+func (s MentionStatus) CanTransitionTo(next MentionStatus) bool {
+	for _, allowed := range mentionStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo は s から next に遷移できない場合に ErrInvalidStatusTransition を返す
+func (s MentionStatus) TransitionTo(next MentionStatus) error {
+	if !s.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, s, next)
+	}
+	return nil
+}
+
+// MentionStatusesBefore は next に遷移できる状態の一覧を返す
+// 保存先で現在の状態を条件にして更新し、許可されていない遷移を防ぐために使用する
+func MentionStatusesBefore(next MentionStatus) []MentionStatus {
+	var statuses []MentionStatus
+	for status := range mentionStatusTransitions {
+		if status.CanTransitionTo(next) {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,87 @@
+package slack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+var allMentionStatuses = []MentionStatus{
+	MentionStatusReceived,
+	MentionStatusQueued,
+	MentionStatusAnswered,
+	MentionStatusFailed,
+	MentionStatusTimedOut,
+}
+
+func TestMentionStatusTransitionTo(t *testing.T) {
+	// 許可する遷移（ここにない組み合わせはすべて拒否する）
+	allowed := map[[2]MentionStatus]bool{
+		{MentionStatusReceived, MentionStatusQueued}:   true,
+		{MentionStatusReceived, MentionStatusFailed}:   true,
+		{MentionStatusReceived, MentionStatusTimedOut}: true,
+		{MentionStatusQueued, MentionStatusAnswered}:   true,
+		{MentionStatusQueued, MentionStatusFailed}:     true,
+		{MentionStatusFailed, MentionStatusQueued}:     true,
+		{MentionStatusFailed, MentionStatusAnswered}:   true,
+		{MentionStatusTimedOut, MentionStatusQueued}:   true,
+		{MentionStatusTimedOut, MentionStatusFailed}:   true,
+		{MentionStatusTimedOut, MentionStatusAnswered}: true,
+	}
+	for _, from := range allMentionStatuses {
+		for _, to := range allMentionStatuses {
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				want := allowed[[2]MentionStatus{from, to}]
+				if got := from.CanTransitionTo(to); got != want {
+					t.Errorf("CanTransitionTo() = %v, want %v", got, want)
+				}
+				err := from.TransitionTo(to)
+				if want && err != nil {
+					t.Errorf("TransitionTo() error = %v, want nil", err)
+				}
+				if !want && !errors.Is(err, ErrInvalidStatusTransition) {
+					t.Errorf("TransitionTo() error = %v, want %v", err, ErrInvalidStatusTransition)
+				}
+			})
+		}
+	}
+}
+
+func TestMentionStatusTransitionFromUnknown(t *testing.T) {
+	if err := MentionStatus("unknown").TransitionTo(MentionStatusQueued); !errors.Is(err, ErrInvalidStatusTransition) {
+		t.Errorf("TransitionTo() error = %v, want %v", err, ErrInvalidStatusTransition)
+	}
+}
+
+func TestParseMentionStatus(t *testing.T) {
+	for _, status := range allMentionStatuses {
+		if got, err := ParseMentionStatus(string(status)); err != nil || got != status {
+			t.Errorf("ParseMentionStatus(%q) = %q, %v, want %q, nil", status, got, err, status)
+		}
+	}
+	for _, s := range []string{"", "unknown", "QUEUED"} {
+		if _, err := ParseMentionStatus(s); !errors.Is(err, ErrInvalidMentionStatus) {
+			t.Errorf("ParseMentionStatus(%q) error = %v, want %v", s, err, ErrInvalidMentionStatus)
+		}
+	}
+}
+
+func TestMentionStatusesBefore(t *testing.T) {
+	tests := []struct {
+		next MentionStatus
+		want []MentionStatus
+	}{
+		{next: MentionStatusReceived, want: nil},
+		{next: MentionStatusQueued, want: []MentionStatus{MentionStatusFailed, MentionStatusReceived, MentionStatusTimedOut}},
+		{next: MentionStatusAnswered, want: []MentionStatus{MentionStatusFailed, MentionStatusQueued, MentionStatusTimedOut}},
+		{next: MentionStatusFailed, want: []MentionStatus{MentionStatusQueued, MentionStatusReceived, MentionStatusTimedOut}},
+		{next: MentionStatusTimedOut, want: []MentionStatus{MentionStatusReceived}},
+	}
+	for _, tt := range tests {
+		got := MentionStatusesBefore(tt.next)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("MentionStatusesBefore(%s) = %v, want %v", tt.next, got, tt.want)
+		}
+	}
+}
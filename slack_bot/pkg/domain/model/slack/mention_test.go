@@ -0,0 +1,276 @@
+package slack
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestNewMentionValidation(t *testing.T) {
+	id, err := NewMentionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		source    MessageSource
+		userID    UserID
+		channelID ChannelID
+		text      Text
+		opts      []MentionOption
+		wantErrs  []error
+	}{
+		{
+			name:      "メッセージの種類が空",
+			userID:    "U001",
+			channelID: "C001",
+			text:      "質問",
+			wantErrs:  []error{ErrSourceRequired},
+		},
+		{
+			name:      "ユーザーIDが空",
+			source:    MessageSourceMention,
+			channelID: "C001",
+			text:      "質問",
+			wantErrs:  []error{ErrUserIDRequired},
+		},
+		{
+			name:     "チャンネルIDが空",
+			source:   MessageSourceMention,
+			userID:   "U001",
+			text:     "質問",
+			wantErrs: []error{ErrChannelIDRequired},
+		},
+		{
+			name:      "テキストが空",
+			source:    MessageSourceMention,
+			userID:    "U001",
+			channelID: "C001",
+			wantErrs:  []error{ErrTextRequired},
+		},
+		{
+			name:      "テキストが最大文字数を超えている",
+			source:    MessageSourceMention,
+			userID:    "U001",
+			channelID: "C001",
+			text:      "あいうえお",
+			opts:      []MentionOption{WithMaxTextLength(4, false)},
+			wantErrs:  []error{ErrTextTooLong},
+		},
+		{
+			name:      "タイムスタンプの文字列が不正",
+			source:    MessageSourceMention,
+			userID:    "U001",
+			channelID: "C001",
+			text:      "質問",
+			opts:      []MentionOption{WithSlackTS("invalid", "")},
+			wantErrs:  []error{ErrInvalidTimestamp},
+		},
+		{
+			name:      "スレッドのタイムスタンプの文字列が不正",
+			source:    MessageSourceMention,
+			userID:    "U001",
+			channelID: "C001",
+			text:      "質問",
+			opts:      []MentionOption{WithSlackTS("1712345678.000200", "1712345678.")},
+			wantErrs:  []error{ErrInvalidTimestamp},
+		},
+		{
+			name:     "すべてのエラーをまとめて返す",
+			source:   MessageSourceMention,
+			wantErrs: []error{ErrUserIDRequired, ErrChannelIDRequired, ErrTextRequired},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMention(id, tt.source, tt.userID, tt.channelID, tt.text, Timestamp(now), EventTime(now), nil, tt.opts...)
+			if m != nil {
+				t.Errorf("NewMention() = %+v, want nil", m)
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("NewMention() error = %v, want *ValidationError", err)
+			}
+			if len(validationErr.Errs) != len(tt.wantErrs) {
+				t.Errorf("len(Errs) = %d, want %d (%v)", len(validationErr.Errs), len(tt.wantErrs), err)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("errors.Is(%v, %v) = false", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMentionTruncatesText(t *testing.T) {
+	id, err := NewMentionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		text          Text
+		maxLength     int
+		truncate      bool
+		wantText      Text
+		wantTruncated bool
+		wantErr       error
+	}{
+		{name: "最大文字数を超えた分を切り詰める", text: "あいうえお", maxLength: 4, truncate: true, wantText: "あいうえ", wantTruncated: true},
+		{name: "最大文字数ちょうどは切り詰めない", text: "あいうえ", maxLength: 4, truncate: true, wantText: "あいうえ"},
+		{name: "最大文字数より1文字多い場合は1文字切り詰める", text: "abcde", maxLength: 4, truncate: true, wantText: "abcd", wantTruncated: true},
+		{name: "境界の4バイトの文字は1文字として数える", text: "abc🍣d", maxLength: 4, truncate: true, wantText: "abc🍣", wantTruncated: true},
+		{name: "境界の直後の4バイトの文字は途中で切らずに取り除く", text: "abc🍣d", maxLength: 3, truncate: true, wantText: "abc", wantTruncated: true},
+		{name: "切り詰めない設定では最大文字数ちょうどは受け付ける", text: "あいうえ", maxLength: 4, wantText: "あいうえ"},
+		{name: "切り詰めない設定では最大文字数を超えるとエラー", text: "あいうえお", maxLength: 4, wantErr: ErrTextTooLong},
+		{name: "空のテキストは切り詰める設定でもエラー", text: "", maxLength: 4, truncate: true, wantErr: ErrTextRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMention(id, MessageSourceMention, "U001", "C001", tt.text, Timestamp(now), EventTime(now), nil, WithMaxTextLength(tt.maxLength, tt.truncate))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewMention() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewMention() error = %v", err)
+			}
+			if m.Text != tt.wantText || m.TextTruncated != tt.wantTruncated {
+				t.Errorf("Text = %q, TextTruncated = %v, want %q, %v", m.Text, m.TextTruncated, tt.wantText, tt.wantTruncated)
+			}
+			if !utf8.ValidString(string(m.Text)) {
+				t.Errorf("Text = %q は不正なUTF-8です", m.Text)
+			}
+			if m.Status != MentionStatusReceived {
+				t.Errorf("Status = %s, want %s", m.Status, MentionStatusReceived)
+			}
+		})
+	}
+}
+
+func TestNewMentionKeepsSlackTS(t *testing.T) {
+	id, err := NewMentionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		ts       string
+		threadTS string
+	}{
+		{name: "スレッド外のメッセージは thread_ts が空", ts: "1712345678.000200"},
+		{name: "小数部のないタイムスタンプ", ts: "1712345678", threadTS: "1712345000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMention(id, MessageSourceMention, "U001", "C001", "質問", Timestamp(now), EventTime(now), nil, WithSlackTS(tt.ts, tt.threadTS))
+			if err != nil {
+				t.Fatalf("NewMention() error = %v", err)
+			}
+			// 返信に使うため文字列は変換せずにそのまま保持する
+			if m.TS != SlackTS(tt.ts) || m.ThreadTS != SlackTS(tt.threadTS) {
+				t.Errorf("TS, ThreadTS = %q, %q, want %q, %q", m.TS, m.ThreadTS, tt.ts, tt.threadTS)
+			}
+		})
+	}
+}
+
+func TestNewMentionIDFromEvent(t *testing.T) {
+	ts := Timestamp(time.Unix(1712345678, 200*int64(time.Microsecond)))
+	id, err := NewMentionIDFromEvent(MessageSourceMention, "C001", ts)
+	if err != nil {
+		t.Fatalf("NewMentionIDFromEvent() error = %v", err)
+	}
+
+	// 同じイベントを再処理しても同じIDになる
+	again, err := NewMentionIDFromEvent(MessageSourceMention, "C001", ts)
+	if err != nil || again != id {
+		t.Errorf("同じイベントの NewMentionIDFromEvent() = %s, %v, want %s", again, err, id)
+	}
+	if got := ulid.ULID(id).Time(); got != ulid.Timestamp(time.Time(ts)) {
+		t.Errorf("IDの時刻 = %d, want %d", got, ulid.Timestamp(time.Time(ts)))
+	}
+
+	tests := []struct {
+		name      string
+		source    MessageSource
+		channelID ChannelID
+		ts        Timestamp
+	}{
+		{name: "種類が異なる", source: MessageSourceDirectMessage, channelID: "C001", ts: ts},
+		{name: "チャンネルが異なる", source: MessageSourceMention, channelID: "C002", ts: ts},
+		{name: "タイムスタンプがマイクロ秒だけ異なる", source: MessageSourceMention, channelID: "C001", ts: Timestamp(time.Time(ts).Add(time.Microsecond))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMentionIDFromEvent(tt.source, tt.channelID, tt.ts)
+			if err != nil {
+				t.Fatalf("NewMentionIDFromEvent() error = %v", err)
+			}
+			if got == id {
+				t.Errorf("NewMentionIDFromEvent() = %s, want %s と異なるID", got, id)
+			}
+		})
+	}
+
+	if _, err := NewMentionIDFromEvent(MessageSourceMention, "C001", Timestamp{}); !errors.Is(err, ErrTimestampRequired) {
+		t.Errorf("タイムスタンプなしの NewMentionIDFromEvent() error = %v, want %v", err, ErrTimestampRequired)
+	}
+}
+
+func TestNewReactionMentionIDFromEvent(t *testing.T) {
+	ts := Timestamp(time.Unix(1712345678, 200*int64(time.Microsecond)))
+	reactedAt := Timestamp(time.Unix(1712345900, 100*int64(time.Microsecond)))
+	id, err := NewReactionMentionIDFromEvent("C001", ts, "U001", reactedAt)
+	if err != nil {
+		t.Fatalf("NewReactionMentionIDFromEvent() error = %v", err)
+	}
+
+	// Slackが同じイベントを再送した場合は同じIDになる
+	again, err := NewReactionMentionIDFromEvent("C001", ts, "U001", reactedAt)
+	if err != nil || again != id {
+		t.Errorf("同じイベントの NewReactionMentionIDFromEvent() = %s, %v, want %s", again, err, id)
+	}
+	// 時刻部分はリアクションされたメッセージのタイムスタンプ
+	if got := ulid.ULID(id).Time(); got != ulid.Timestamp(time.Time(ts)) {
+		t.Errorf("IDの時刻 = %d, want %d", got, ulid.Timestamp(time.Time(ts)))
+	}
+	// リアクションされたメッセージへのメンションとは別のIDになる
+	if mentionID, _ := NewMentionIDFromEvent(MessageSourceReaction, "C001", ts); mentionID == id {
+		t.Errorf("NewReactionMentionIDFromEvent() = NewMentionIDFromEvent() = %s, want 異なるID", id)
+	}
+
+	tests := []struct {
+		name      string
+		userID    UserID
+		reactedAt Timestamp
+	}{
+		{name: "同じメッセージへの別のユーザーのリアクション", userID: "U002", reactedAt: reactedAt},
+		{name: "同じユーザーが付け直したリアクション", userID: "U001", reactedAt: Timestamp(time.Time(reactedAt).Add(time.Hour))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewReactionMentionIDFromEvent("C001", ts, tt.userID, tt.reactedAt)
+			if err != nil {
+				t.Fatalf("NewReactionMentionIDFromEvent() error = %v", err)
+			}
+			if got == id {
+				t.Errorf("NewReactionMentionIDFromEvent() = %s, want %s と異なるID", got, id)
+			}
+		})
+	}
+
+	if _, err := NewReactionMentionIDFromEvent("C001", ts, "U001", Timestamp{}); !errors.Is(err, ErrTimestampRequired) {
+		t.Errorf("リアクションの時刻なしの NewReactionMentionIDFromEvent() error = %v, want %v", err, ErrTimestampRequired)
+	}
+}
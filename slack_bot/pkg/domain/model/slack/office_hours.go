@@ -0,0 +1,104 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type (
+	// OfficeHours はBotが受け付ける曜日ごとの時間帯
+	OfficeHours struct {
+		location *time.Location
+		schedule map[time.Weekday][]TimeRange
+	}
+	// TimeRange は 0時からの経過時間で表した [Start, End) の時間帯
+	TimeRange struct {
+		Start time.Duration
+		End   time.Duration
+	}
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// ParseOfficeHours は曜日名をキー、"09:00-18:00" 形式の時間帯の一覧を値とするスケジュールを解析する
+// スケジュールに含まれない曜日は終日受け付けない
+func ParseOfficeHours(timezone string, schedule map[string][]string) (*OfficeHours, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	parsed := make(map[time.Weekday][]TimeRange, len(schedule))
+	for day, ranges := range schedule {
+		weekday, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", day)
+		}
+		for _, r := range ranges {
+			tr, err := parseTimeRange(r)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time range %q on %s: %w", r, day, err)
+			}
+			parsed[weekday] = append(parsed[weekday], tr)
+		}
+	}
+
+	return &OfficeHours{
+		location: location,
+		schedule: parsed,
+	}, nil
+}
+
+// IsOpen は指定した時刻が受付時間内かを返す
+func (o *OfficeHours) IsOpen(t time.Time) bool {
+	local := t.In(o.location)
+	sinceMidnight := time.Duration(local.Hour())*time.Hour +
+		time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+	for _, r := range o.schedule[local.Weekday()] {
+		if sinceMidnight >= r.Start && sinceMidnight < r.End {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTimeRange(s string) (TimeRange, error) {
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return TimeRange{}, fmt.Errorf("expected HH:MM-HH:MM")
+	}
+	startAt, err := parseClock(strings.TrimSpace(start))
+	if err != nil {
+		return TimeRange{}, err
+	}
+	endAt, err := parseClock(strings.TrimSpace(end))
+	if err != nil {
+		return TimeRange{}, err
+	}
+	if startAt >= endAt {
+		return TimeRange{}, fmt.Errorf("start must be before end")
+	}
+	return TimeRange{Start: startAt, End: endAt}, nil
+}
+
+// parseClock は "HH:MM" を 0時からの経過時間に変換する（終了時刻として "24:00" も許可する）
+func parseClock(s string) (time.Duration, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(s, "%d:%d", &hour, &minute); err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, nil
+}
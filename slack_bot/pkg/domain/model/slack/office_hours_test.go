@@ -0,0 +1,145 @@
+package slack
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOfficeHoursIsOpen(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2024-04-01 は月曜日
+	at := func(day, hour, minute, second int) time.Time {
+		return time.Date(2024, 4, day, hour, minute, second, 0, tokyo)
+	}
+
+	tests := []struct {
+		name     string
+		timezone string
+		schedule map[string][]string
+		t        time.Time
+		want     bool
+	}{
+		{
+			name:     "開始時刻ちょうどは受付時間内",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"09:00-18:00"}},
+			t:        at(1, 9, 0, 0),
+			want:     true,
+		},
+		{
+			name:     "開始時刻の直前は受付時間外",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"09:00-18:00"}},
+			t:        at(1, 8, 59, 59),
+			want:     false,
+		},
+		{
+			name:     "終了時刻の直前は受付時間内",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"09:00-18:00"}},
+			t:        at(1, 17, 59, 59),
+			want:     true,
+		},
+		{
+			name:     "終了時刻ちょうどは受付時間外",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"09:00-18:00"}},
+			t:        at(1, 18, 0, 0),
+			want:     false,
+		},
+		{
+			name:     "スケジュールにない曜日は終日受付時間外",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"09:00-18:00"}},
+			t:        at(2, 12, 0, 0),
+			want:     false,
+		},
+		{
+			name:     "複数の時間帯のどちらかに含まれれば受付時間内",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"09:00-12:00", "13:00-18:00"}},
+			t:        at(1, 13, 30, 0),
+			want:     true,
+		},
+		{
+			name:     "時間帯の間は受付時間外",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"09:00-12:00", "13:00-18:00"}},
+			t:        at(1, 12, 30, 0),
+			want:     false,
+		},
+		{
+			name:     "日をまたぐ受付時間は 24:00 までと翌日の 00:00 からに分けて設定する（当日の夜）",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"22:00-24:00"}, "tuesday": {"00:00-06:00"}},
+			t:        at(1, 23, 59, 59),
+			want:     true,
+		},
+		{
+			name:     "日をまたぐ受付時間は 24:00 までと翌日の 00:00 からに分けて設定する（翌日の朝）",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"22:00-24:00"}, "tuesday": {"00:00-06:00"}},
+			t:        at(2, 5, 59, 59),
+			want:     true,
+		},
+		{
+			name:     "日をまたぐ受付時間の終了後は受付時間外",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"22:00-24:00"}, "tuesday": {"00:00-06:00"}},
+			t:        at(2, 6, 0, 0),
+			want:     false,
+		},
+		{
+			name:     "UTCの時刻は設定したタイムゾーンの時刻に変換して判定する",
+			timezone: "Asia/Tokyo",
+			schedule: map[string][]string{"monday": {"09:00-18:00"}},
+			// 月曜 00:30 UTC は月曜 09:30 JST
+			t:    time.Date(2024, 4, 1, 0, 30, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name:     "タイムゾーンの変換で曜日が変わる場合は変換後の曜日で判定する",
+			timezone: "America/New_York",
+			schedule: map[string][]string{"sunday": {"20:00-24:00"}},
+			// 月曜 10:00 JST は日曜 21:00 EDT
+			t:    at(1, 10, 0, 0),
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := ParseOfficeHours(tt.timezone, tt.schedule)
+			if err != nil {
+				t.Fatalf("ParseOfficeHours() error = %v", err)
+			}
+			if got := o.IsOpen(tt.t); got != tt.want {
+				t.Errorf("IsOpen(%v) = %t, want %t", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOfficeHoursInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		schedule map[string][]string
+	}{
+		{name: "存在しないタイムゾーン", timezone: "Asia/Nowhere", schedule: map[string][]string{"monday": {"09:00-18:00"}}},
+		{name: "存在しない曜日", timezone: "UTC", schedule: map[string][]string{"someday": {"09:00-18:00"}}},
+		{name: "日をまたぐ時間帯は1つの範囲では指定できない", timezone: "UTC", schedule: map[string][]string{"monday": {"22:00-06:00"}}},
+		{name: "開始と終了が同じ", timezone: "UTC", schedule: map[string][]string{"monday": {"09:00-09:00"}}},
+		{name: "24:00 より後の時刻", timezone: "UTC", schedule: map[string][]string{"monday": {"09:00-24:30"}}},
+		{name: "区切りがない", timezone: "UTC", schedule: map[string][]string{"monday": {"09:00"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseOfficeHours(tt.timezone, tt.schedule); err == nil {
+				t.Errorf("ParseOfficeHours(%q, %v) error = nil, want error", tt.timezone, tt.schedule)
+			}
+		})
+	}
+}
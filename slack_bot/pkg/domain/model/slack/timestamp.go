@@ -0,0 +1,54 @@
+package slack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SlackTS はSlackのタイムスタンプの文字列（"1623456789.000200" 形式）
+// スレッドへの返信などSlackのAPIにはこの文字列をそのまま渡す必要があるため、変換した時刻とは別に保持する
+type SlackTS string
+
+// ParseSlackTimestamp はSlackのタイムスタンプを time.Time に変換する
+// 小数部はマイクロ秒（最大6桁）として扱い、省略できる。空文字列（スレッド外のメッセージの thread_ts など）の場合はゼロ値を返す
+func ParseSlackTimestamp(ts string) (time.Time, error) {
+	if ts == "" {
+		return time.Time{}, nil
+	}
+	sec, frac, hasFrac := strings.Cut(ts, ".")
+	if !isDigits(sec) || (hasFrac && (!isDigits(frac) || len(frac) > 6)) {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimestamp, ts)
+	}
+	seconds, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimestamp, ts)
+	}
+	var micros int64
+	if hasFrac {
+		micros, err = strconv.ParseInt((frac + "000000")[:6], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimestamp, ts)
+		}
+	}
+	return time.Unix(seconds, micros*int64(time.Microsecond)), nil
+}
+
+// FormatSlackTimestamp は時刻をSlackのタイムスタンプの形式に変換する（マイクロ秒未満は切り捨てる）
+func FormatSlackTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/int(time.Microsecond))
+}
+
+// s が1文字以上の数字のみで構成されているかを返す（符号や空白は受け付けない）
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
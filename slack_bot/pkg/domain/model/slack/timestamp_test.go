@@ -0,0 +1,84 @@
+package slack
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSlackTimestamp(t *testing.T) {
+	tests := []struct {
+		name    string
+		ts      string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "空文字列（スレッド外の thread_ts）はゼロ値",
+			ts:   "",
+			want: time.Time{},
+		},
+		{
+			name: "マイクロ秒までの小数部",
+			ts:   "1712345678.000200",
+			want: time.Unix(1712345678, 200*int64(time.Microsecond)),
+		},
+		{
+			name: "小数部を省略",
+			ts:   "1712345678",
+			want: time.Unix(1712345678, 0),
+		},
+		{
+			name: "6桁未満の小数部は右を0で埋める",
+			ts:   "1712345678.5",
+			want: time.Unix(1712345678, 500*int64(time.Millisecond)),
+		},
+		{name: "小数点のみで小数部が空", ts: "1712345678.", wantErr: true},
+		{name: "整数部が空", ts: ".000200", wantErr: true},
+		{name: "7桁以上の小数部", ts: "1712345678.0002001", wantErr: true},
+		{name: "数字以外を含む", ts: "1712345678.00a200", wantErr: true},
+		{name: "符号付き", ts: "-1712345678.000200", wantErr: true},
+		{name: "前後の空白", ts: " 1712345678.000200", wantErr: true},
+		{name: "整数部が int64 の範囲外", ts: "99999999999999999999.000000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSlackTimestamp(tt.ts)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTimestamp) {
+					t.Errorf("ParseSlackTimestamp(%q) error = %v, want %v", tt.ts, err, ErrInvalidTimestamp)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSlackTimestamp(%q) error = %v", tt.ts, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSlackTimestamp(%q) = %v, want %v", tt.ts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSlackTimestamp(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{t: time.Unix(1712345678, 0), want: "1712345678.000000"},
+		{t: time.Unix(1712345678, 200*int64(time.Microsecond)), want: "1712345678.000200"},
+		// マイクロ秒未満は切り捨てる
+		{t: time.Unix(1712345678, 200*int64(time.Microsecond)+999), want: "1712345678.000200"},
+	}
+	for _, tt := range tests {
+		got := FormatSlackTimestamp(tt.t)
+		if got != tt.want {
+			t.Errorf("FormatSlackTimestamp(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+		// 変換した文字列は元の時刻（マイクロ秒単位）に戻せる
+		parsed, err := ParseSlackTimestamp(got)
+		if err != nil || !parsed.Equal(tt.t.Truncate(time.Microsecond)) {
+			t.Errorf("ParseSlackTimestamp(%q) = %v, %v, want %v", got, parsed, err, tt.t.Truncate(time.Microsecond))
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package slack
+
+import (
+	"errors"
+)
+
+type (
+	// UserSetting はユーザーごとのBotの設定
+	UserSetting struct {
+		UserID UserID
+		Lang   Language
+	}
+	Language string
+)
+
+const (
+	LanguageJa Language = "ja"
+	LanguageEn Language = "en"
+)
+
+// SupportedLanguages は設定可能な言語の一覧（表示順）
+var SupportedLanguages = []Language{LanguageJa, LanguageEn}
+
+func NewUserSetting(userID UserID, lang Language) (*UserSetting, error) {
+	s := &UserSetting{
+		UserID: userID,
+		Lang:   lang,
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *UserSetting) validate() error {
+	if s.UserID == "" {
+		return errors.New("userID is required")
+	}
+	if !s.Lang.IsSupported() {
+		return errors.New("lang is not supported")
+	}
+	return nil
+}
+
+// IsSupported は設定可能な言語かどうかを返す
+func (l Language) IsSupported() bool {
+	for _, supported := range SupportedLanguages {
+		if l == supported {
+			return true
+		}
+	}
+	return false
+}
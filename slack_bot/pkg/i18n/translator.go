@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed locales/*.yml
+var locales embed.FS
+
+// Translator はユーザーに返信するメッセージを言語ごとのカタログから取得する
+// カタログは locales/<言語>.yml に埋め込み、入れ子のキーは "error.queue_send" のようにドットでつないで指定する
+type Translator struct {
+	defaultLang string
+	catalogs    map[string]map[string]string
+}
+
+// NewTranslator は埋め込みのカタログを読み込む
+// defaultLang は言語が未指定の場合や、指定した言語にキーがない場合に使用する
+func NewTranslator(defaultLang string) (*Translator, error) {
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	catalogs := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := locales.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var tree map[string]any
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("メッセージのカタログ (%s) の読み込みエラー: %w", entry.Name(), err)
+		}
+		catalog := make(map[string]string)
+		flatten("", tree, catalog)
+		catalogs[strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))] = catalog
+	}
+
+	if _, ok := catalogs[defaultLang]; !ok {
+		return nil, fmt.Errorf("デフォルトの言語 %q のメッセージのカタログがありません", defaultLang)
+	}
+	return &Translator{defaultLang: defaultLang, catalogs: catalogs}, nil
+}
+
+// T は lang のメッセージを args で書式化して返す
+// lang のカタログにキーがない場合はデフォルトの言語のメッセージを、どちらにもない場合はキーをそのまま返す
+func (t *Translator) T(lang, key string, args ...any) string {
+	format, ok := t.catalogs[lang][key]
+	if !ok {
+		format, ok = t.catalogs[t.defaultLang][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// DefaultLang はデフォルトの言語を返す
+func (t *Translator) DefaultLang() string {
+	return t.defaultLang
+}
+
+// flatten は入れ子のマップをドットでつないだキーのマップにする
+func flatten(prefix string, tree map[string]any, out map[string]string) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(key, v, out)
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
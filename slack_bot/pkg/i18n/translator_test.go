@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestTranslatorT(t *testing.T) {
+	// 言語ごとのキーの差を確認するため、埋め込みのカタログの代わりにテスト用のカタログを使用する
+	translator := &Translator{
+		defaultLang: "ja",
+		catalogs: map[string]map[string]string{
+			"ja": {
+				"office_hours.closed": "受付時間外です。",
+				"rate_limited":        "%d秒後に改めてメンションしてください。",
+				"error.inquiry_id":    "問い合わせID: `%s`",
+				"only_default":        "日本語のみのメッセージ",
+			},
+			"en": {
+				"office_hours.closed": "We are closed.",
+				"rate_limited":        "Please mention me again in %d seconds.",
+				"only_en":             "English only",
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		lang string
+		key  string
+		args []any
+		want string
+	}{
+		{name: "指定した言語のメッセージ", lang: "en", key: "office_hours.closed", want: "We are closed."},
+		{name: "言語が未指定の場合はデフォルトの言語", lang: "", key: "office_hours.closed", want: "受付時間外です。"},
+		{name: "カタログのない言語はデフォルトの言語", lang: "fr", key: "office_hours.closed", want: "受付時間外です。"},
+		{name: "指定した言語にキーがない場合はデフォルトの言語", lang: "en", key: "only_default", want: "日本語のみのメッセージ"},
+		{name: "デフォルトの言語にだけないキー", lang: "en", key: "only_en", want: "English only"},
+		{name: "どの言語にもないキーはキーをそのまま返す", lang: "en", key: "error.unknown", want: "error.unknown"},
+		{name: "どの言語にもないキーは引数があってもキーをそのまま返す", lang: "ja", key: "error.unknown", args: []any{1}, want: "error.unknown"},
+		{name: "数値の引数を書式化する", lang: "en", key: "rate_limited", args: []any{30}, want: "Please mention me again in 30 seconds."},
+		{name: "フォールバックしたメッセージにも引数を書式化する", lang: "en", key: "error.inquiry_id", args: []any{"corr-001"}, want: "問い合わせID: `corr-001`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translator.T(tt.lang, tt.key, tt.args...); got != tt.want {
+				t.Errorf("T(%q, %q) = %q, want %q", tt.lang, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTranslator(t *testing.T) {
+	t.Run("入れ子のキーをドットでつないで読み込む", func(t *testing.T) {
+		translator, err := NewTranslator("en")
+		if err != nil {
+			t.Fatalf("NewTranslator() error = %v", err)
+		}
+		if got := translator.T("ja", "error.inquiry_id", "corr-001"); got != "問い合わせID: `corr-001`" {
+			t.Errorf("T() = %q, want %q", got, "問い合わせID: `corr-001`")
+		}
+		if got := translator.DefaultLang(); got != "en" {
+			t.Errorf("DefaultLang() = %q, want %q", got, "en")
+		}
+	})
+
+	t.Run("カタログのない言語をデフォルトにするとエラー", func(t *testing.T) {
+		if _, err := NewTranslator("fr"); err == nil {
+			t.Error("NewTranslator(\"fr\") error = nil, want エラー")
+		}
+	})
+
+	// どの言語で返信してもフォールバックしないよう、すべてのカタログに同じキーがあることを確認する
+	t.Run("すべての言語のカタログのキーが一致する", func(t *testing.T) {
+		translator, err := NewTranslator("ja")
+		if err != nil {
+			t.Fatalf("NewTranslator() error = %v", err)
+		}
+		want := slices.Sorted(maps.Keys(translator.catalogs["ja"]))
+		for lang, catalog := range translator.catalogs {
+			if got := slices.Sorted(maps.Keys(catalog)); !slices.Equal(got, want) {
+				t.Errorf("%s のカタログのキー = %v, want %v", lang, got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+)
+
+// anthropicVersion はMessages APIのバージョン（anthropic-version ヘッダー）
+const anthropicVersion = "2023-06-01"
+
+// AnthropicProvider はAnthropicのMessages APIで回答を生成する
+type AnthropicProvider struct {
+	client    *http.Client
+	endpoint  string
+	apiKey    string
+	model     string
+	maxTokens int
+}
+
+func NewAnthropicProvider(cfg config.AnthropicConfig) (*AnthropicProvider, error) {
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, errors.New("AnthropicのAPIキー (worker.anthropic.api_key または環境変数 ANTHROPIC_API_KEY) が設定されていません")
+	}
+	return &AnthropicProvider{
+		client:    &http.Client{Timeout: cfg.Timeout},
+		endpoint:  strings.TrimRight(cfg.BaseURL, "/") + "/messages",
+		apiKey:    apiKey,
+		model:     cfg.Model,
+		maxTokens: cfg.MaxTokens,
+	}, nil
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicRequest はMessages APIのリクエスト
+// Bedrockでは model の代わりに anthropic_version を本文に含める
+type anthropicRequest struct {
+	Model            string             `json:"model,omitempty"`
+	AnthropicVersion string             `json:"anthropic_version,omitempty"`
+	System           string             `json:"system,omitempty"`
+	Messages         []anthropicMessage `json:"messages"`
+	MaxTokens        int                `json:"max_tokens"`
+	Stream           bool               `json:"stream,omitempty"`
+}
+
+// anthropicStreamEvent はストリーミングの応答の1イベント
+// 回答のテキストは content_block_delta の text_delta で届く
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// newAnthropicRequest は prompt と会話からMessages APIのリクエストを作成する
+// 役割が system のメッセージは system にまとめ、同じ役割が続くメッセージは1つに結合する（APIは user と assistant の交互を要求する）
+func newAnthropicRequest(prompt string, history []di.ChatMessage, maxTokens int) anthropicRequest {
+	req := anthropicRequest{MaxTokens: maxTokens}
+	var system []string
+	for _, m := range slices.Concat(history, []di.ChatMessage{{Role: di.ChatRoleUser, Content: prompt}}) {
+		if m.Role == di.ChatRoleSystem {
+			system = append(system, m.Content)
+			continue
+		}
+		if n := len(req.Messages); n > 0 && req.Messages[n-1].Role == m.Role {
+			req.Messages[n-1].Content += "\n\n" + m.Content
+			continue
+		}
+		req.Messages = append(req.Messages, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	req.System = strings.Join(system, "\n\n")
+	return req
+}
+
+// decodeAnthropicResponse はMessages APIの応答からテキストの回答を取り出す
+func decodeAnthropicResponse(r io.Reader) (string, error) {
+	var res anthropicResponse
+	if err := json.NewDecoder(r).Decode(&res); err != nil {
+		return "", fmt.Errorf("Messages APIの応答のデコードエラー: %w", err)
+	}
+	var text strings.Builder
+	for _, block := range res.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", errors.New("Messages APIの応答に回答がありません")
+	}
+	return text.String(), nil
+}
+
+// Complete は会話を送信し、回答のテキストを返す
+func (p *AnthropicProvider) Complete(ctx context.Context, prompt string, history []di.ChatMessage) (string, error) {
+	res, err := p.send(ctx, prompt, history, false)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	return decodeAnthropicResponse(res.Body)
+}
+
+// Stream は会話を送信し、生成された回答を順に onDelta に渡す。戻り値は回答の全文
+func (p *AnthropicProvider) Stream(ctx context.Context, prompt string, history []di.ChatMessage, onDelta func(delta string)) (string, error) {
+	res, err := p.send(ctx, prompt, history, true)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var answer strings.Builder
+	err = readSSE(res.Body, func(data string) error {
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("Messages APIのストリームのデコードエラー: %w", err)
+		}
+		switch event.Type {
+		case "error":
+			return fmt.Errorf("Messages APIがエラーを返しました (%s): %s", event.Error.Type, event.Error.Message)
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				answer.WriteString(event.Delta.Text)
+				onDelta(event.Delta.Text)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("Messages APIのストリームの読み取りエラー: %w", err)
+	}
+	if strings.TrimSpace(answer.String()) == "" {
+		return "", errors.New("Messages APIの応答に回答がありません")
+	}
+	return answer.String(), nil
+}
+
+// リクエストを送信し、成功した場合は応答を返すメソッド（本文は呼び出し側で閉じる）
+func (p *AnthropicProvider) send(ctx context.Context, prompt string, history []di.ChatMessage, stream bool) (*http.Response, error) {
+	req := newAnthropicRequest(prompt, history, p.maxTokens)
+	req.Model = p.model
+	req.Stream = stream
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Api-Key", p.apiKey)
+	httpReq.Header.Set("Anthropic-Version", anthropicVersion)
+
+	res, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("Messages APIの呼び出しエラー: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, errorBodyLimit))
+		return nil, fmt.Errorf("Messages APIがエラーを返しました (status=%d): %s", res.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return res, nil
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/bedrockruntime"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+)
+
+// bedrockAnthropicVersion はBedrockのAnthropicのモデルに指定するMessages APIのバージョン
+const bedrockAnthropicVersion = "bedrock-2023-05-31"
+
+// BedrockProvider はAmazon BedrockのAnthropicのモデルで回答を生成する
+// リクエストと応答の形式はAnthropicのMessages APIと同じ
+type BedrockProvider struct {
+	client    *bedrockruntime.BedrockRuntime
+	modelID   string
+	maxTokens int
+	timeout   time.Duration
+}
+
+func NewBedrockProvider(cfg config.BedrockConfig) (*BedrockProvider, error) {
+	awsCfg := &aws.Config{
+		Region: aws.String(cfg.Region),
+	}
+	if cfg.AccessKey != "" {
+		awsCfg.Credentials = credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, "")
+	}
+
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &BedrockProvider{
+		client:    bedrockruntime.New(sess),
+		modelID:   cfg.ModelID,
+		maxTokens: cfg.MaxTokens,
+		timeout:   cfg.Timeout,
+	}, nil
+}
+
+// Complete は会話を送信し、回答のテキストを返す
+func (p *BedrockProvider) Complete(ctx context.Context, prompt string, history []di.ChatMessage) (string, error) {
+	req := newAnthropicRequest(prompt, history, p.maxTokens)
+	req.AnthropicVersion = bedrockAnthropicVersion
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	out, err := p.client.InvokeModelWithContext(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(p.modelID),
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+		Body:        body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("BedrockのInvokeModelの呼び出しエラー: %w", err)
+	}
+	return decodeAnthropicResponse(bytes.NewReader(out.Body))
+}
@@ -0,0 +1,158 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+)
+
+// errorBodyLimit はエラーの応答から読み取る本文の最大バイト数
+const errorBodyLimit = 4 * 1024
+
+// OpenAIProvider はOpenAI（互換）のChat Completions APIで回答を生成する
+type OpenAIProvider struct {
+	client    *http.Client
+	endpoint  string
+	apiKey    string
+	model     string
+	maxTokens int
+}
+
+func NewOpenAIProvider(cfg config.OpenAIConfig) (*OpenAIProvider, error) {
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, errors.New("OpenAIのAPIキー (worker.openai.api_key または環境変数 OPENAI_API_KEY) が設定されていません")
+	}
+	return &OpenAIProvider{
+		client:    &http.Client{Timeout: cfg.Timeout},
+		endpoint:  strings.TrimRight(cfg.BaseURL, "/") + "/chat/completions",
+		apiKey:    apiKey,
+		model:     cfg.Model,
+		maxTokens: cfg.MaxTokens,
+	}, nil
+}
+
+type chatCompletionMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionRequest struct {
+	Model     string                  `json:"model"`
+	Messages  []chatCompletionMessage `json:"messages"`
+	MaxTokens int                     `json:"max_tokens,omitempty"`
+	Stream    bool                    `json:"stream,omitempty"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message chatCompletionMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// chatCompletionChunk はストリーミングの応答の1イベント
+type chatCompletionChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// Complete は会話を送信し、最初の候補の回答を返す
+func (p *OpenAIProvider) Complete(ctx context.Context, prompt string, history []di.ChatMessage) (string, error) {
+	res, err := p.send(ctx, p.newRequest(prompt, history, false))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var completion chatCompletionResponse
+	if err := json.NewDecoder(res.Body).Decode(&completion); err != nil {
+		return "", fmt.Errorf("Chat Completions APIの応答のデコードエラー: %w", err)
+	}
+	if len(completion.Choices) == 0 || strings.TrimSpace(completion.Choices[0].Message.Content) == "" {
+		return "", errors.New("Chat Completions APIの応答に回答がありません")
+	}
+	return completion.Choices[0].Message.Content, nil
+}
+
+// Stream は会話を送信し、生成された回答を順に onDelta に渡す。戻り値は回答の全文
+func (p *OpenAIProvider) Stream(ctx context.Context, prompt string, history []di.ChatMessage, onDelta func(delta string)) (string, error) {
+	res, err := p.send(ctx, p.newRequest(prompt, history, true))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var answer strings.Builder
+	err = readSSE(res.Body, func(data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("Chat Completions APIのストリームのデコードエラー: %w", err)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+		answer.WriteString(chunk.Choices[0].Delta.Content)
+		onDelta(chunk.Choices[0].Delta.Content)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("Chat Completions APIのストリームの読み取りエラー: %w", err)
+	}
+	if strings.TrimSpace(answer.String()) == "" {
+		return "", errors.New("Chat Completions APIの応答に回答がありません")
+	}
+	return answer.String(), nil
+}
+
+func (p *OpenAIProvider) newRequest(prompt string, history []di.ChatMessage, stream bool) chatCompletionRequest {
+	req := chatCompletionRequest{Model: p.model, MaxTokens: p.maxTokens, Stream: stream}
+	for _, m := range history {
+		req.Messages = append(req.Messages, chatCompletionMessage{Role: m.Role, Content: m.Content})
+	}
+	req.Messages = append(req.Messages, chatCompletionMessage{Role: di.ChatRoleUser, Content: prompt})
+	return req
+}
+
+// リクエストを送信し、成功した場合は応答を返すメソッド（本文は呼び出し側で閉じる）
+func (p *OpenAIProvider) send(ctx context.Context, req chatCompletionRequest) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	res, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("Chat Completions APIの呼び出しエラー: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, errorBodyLimit))
+		return nil, fmt.Errorf("Chat Completions APIがエラーを返しました (status=%d): %s", res.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return res, nil
+}
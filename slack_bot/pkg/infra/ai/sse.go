@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// sseMaxLineSize はServer-Sent Eventsの1行の最大バイト数
+const sseMaxLineSize = 1024 * 1024
+
+// readSSE はServer-Sent Eventsのストリームを読み取り、イベントごとに data を fn に渡す
+// fn がエラーを返した場合は読み取りを中断してそのエラーを返す
+func readSSE(r io.Reader, fn func(data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), sseMaxLineSize)
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				if err := fn(strings.Join(data, "\n")); err != nil {
+					return err
+				}
+				data = data[:0]
+			}
+			continue
+		}
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data = append(data, strings.TrimPrefix(value, " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		return fn(strings.Join(data, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// S3PutObjectAPI はアーカイブに必要なS3クライアントのメソッド
+type S3PutObjectAPI interface {
+	PutObjectWithContext(aws.Context, *s3.PutObjectInput, ...request.Option) (*s3.PutObjectOutput, error)
+}
+
+// S3MentionArchiver はメンションをJSONLとしてS3に保存する
+type S3MentionArchiver struct {
+	client S3PutObjectAPI
+	bucket string
+	prefix string
+}
+
+func NewS3MentionArchiver(client S3PutObjectAPI, bucket, prefix string) di.MentionArchiver {
+	return &S3MentionArchiver{
+		client: client,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+func (a *S3MentionArchiver) Archive(ctx context.Context, mentions []*entity.SlackMention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, m := range mentions {
+		if err := encoder.Encode(m); err != nil {
+			return fmt.Errorf("JSONエンコードエラー: %w", err)
+		}
+	}
+
+	key := a.objectKey(mentions[0], time.Now())
+	_, err := a.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(a.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body.Bytes()),
+		ContentType: aws.String("application/x-ndjson"),
+	})
+	if err != nil {
+		return fmt.Errorf("S3へのアップロードエラー (s3://%s/%s): %w", a.bucket, key, err)
+	}
+	return nil
+}
+
+// objectKey は <prefix>/YYYY/MM/DD/<実行時刻>-<先頭のID>.jsonl 形式のキーを返す
+func (a *S3MentionArchiver) objectKey(first *entity.SlackMention, now time.Time) string {
+	now = now.UTC()
+	return path.Join(
+		a.prefix,
+		now.Format("2006/01/02"),
+		fmt.Sprintf("%s-%s.jsonl", now.Format("150405"), first.ID.String()),
+	)
+}
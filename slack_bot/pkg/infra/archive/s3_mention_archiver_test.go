@@ -0,0 +1,188 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+)
+
+// fakeS3Client はアップロードされたオブジェクトを記録する S3PutObjectAPI
+type fakeS3Client struct {
+	inputs []*s3.PutObjectInput
+	bodies [][]byte
+	err    error
+	// log はアップロードと削除の順番を記録する（fakeMentionCommand と共有する）
+	log *[]string
+}
+
+func (c *fakeS3Client) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	if c.log != nil {
+		*c.log = append(*c.log, "put")
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	body, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.inputs = append(c.inputs, input)
+	c.bodies = append(c.bodies, body)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func newTestMentions(n int) []*entity.SlackMention {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mentions := make([]*entity.SlackMention, 0, n)
+	for i := range n {
+		mentions = append(mentions, &entity.SlackMention{
+			ID:        dbtypes.ULID(ulid.Make()),
+			UserID:    "U001",
+			ChannelID: "C001",
+			Text:      "古い質問",
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	return mentions
+}
+
+func TestS3MentionArchiverArchive(t *testing.T) {
+	client := &fakeS3Client{}
+	archiver := NewS3MentionArchiver(client, "archive-bucket", "mentions")
+	mentions := newTestMentions(2)
+
+	if err := archiver.Archive(context.Background(), mentions); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if len(client.inputs) != 1 {
+		t.Fatalf("アップロード = %d回, want 1回", len(client.inputs))
+	}
+	input := client.inputs[0]
+	if got := aws.StringValue(input.Bucket); got != "archive-bucket" {
+		t.Errorf("Bucket = %q, want %q", got, "archive-bucket")
+	}
+	keyPattern := regexp.MustCompile(`^mentions/\d{4}/\d{2}/\d{2}/\d{6}-` + mentions[0].ID.String() + `\.jsonl$`)
+	if got := aws.StringValue(input.Key); !keyPattern.MatchString(got) {
+		t.Errorf("Key = %q, want %s に一致", got, keyPattern)
+	}
+	if got := aws.StringValue(input.ContentType); got != "application/x-ndjson" {
+		t.Errorf("ContentType = %q, want %q", got, "application/x-ndjson")
+	}
+
+	// 1行に1件のメンションをJSONで出力する
+	var ids []string
+	scanner := bufio.NewScanner(bytes.NewReader(client.bodies[0]))
+	for scanner.Scan() {
+		var m entity.SlackMention
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatalf("アーカイブの行の解析エラー: %v", err)
+		}
+		ids = append(ids, m.ID.String())
+	}
+	if want := []string{mentions[0].ID.String(), mentions[1].ID.String()}; !slices.Equal(ids, want) {
+		t.Errorf("アーカイブしたID = %v, want %v", ids, want)
+	}
+}
+
+func TestS3MentionArchiverArchiveEmpty(t *testing.T) {
+	client := &fakeS3Client{}
+	if err := NewS3MentionArchiver(client, "archive-bucket", "mentions").Archive(context.Background(), nil); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if len(client.inputs) != 0 {
+		t.Errorf("アップロード = %d回, want 0回", len(client.inputs))
+	}
+}
+
+// fakeMentionQuery は保持期間を過ぎたメンションを返す SlackMentionQuery
+type fakeMentionQuery struct {
+	di.SlackMentionQuery
+	command *fakeMentionCommand
+}
+
+func (q *fakeMentionQuery) FindCreatedBefore(ctx context.Context, before time.Time, limit int) ([]*entity.SlackMention, error) {
+	var found []*entity.SlackMention
+	for _, m := range q.command.mentions {
+		if m.CreatedAt.Before(before) && len(found) < limit {
+			found = append(found, m)
+		}
+	}
+	return found, nil
+}
+
+// fakeMentionCommand は削除したメンションを取り除く SlackMentionCommand
+type fakeMentionCommand struct {
+	di.SlackMentionCommand
+	mentions []*entity.SlackMention
+	log      *[]string
+}
+
+func (c *fakeMentionCommand) DeleteByIDs(ctx context.Context, ids []slack.MentionID) error {
+	*c.log = append(*c.log, "delete")
+	c.mentions = slices.DeleteFunc(c.mentions, func(m *entity.SlackMention) bool {
+		return slices.Contains(ids, slack.MentionID(m.ID))
+	})
+	return nil
+}
+
+// 保持期間を過ぎたメンションはS3にアップロードしてから削除し、アップロードに失敗した場合は削除しない
+func TestMentionRetentionArchivesBeforeDelete(t *testing.T) {
+	tests := []struct {
+		name        string
+		putErr      error
+		wantLog     []string
+		wantDeleted int
+		wantErr     bool
+	}{
+		{
+			name:        "バッチごとにアップロードしてから削除する",
+			wantLog:     []string{"put", "delete", "put", "delete"},
+			wantDeleted: 3,
+		},
+		{
+			name:    "アップロードに失敗した場合は削除しない",
+			putErr:  errors.New("access denied"),
+			wantLog: []string{"put"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			command := &fakeMentionCommand{mentions: newTestMentions(3), log: &log}
+			client := &fakeS3Client{err: tt.putErr, log: &log}
+			retention := usecase.NewMentionRetention(&fakeMentionQuery{command: command}, command, NewS3MentionArchiver(client, "archive-bucket", "mentions"), time.Hour, 2)
+
+			deleted, err := retention.Run(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if deleted != tt.wantDeleted {
+				t.Errorf("Run() = %d, want %d", deleted, tt.wantDeleted)
+			}
+			if !slices.Equal(log, tt.wantLog) {
+				t.Errorf("呼び出しの順番 = %v, want %v", log, tt.wantLog)
+			}
+			if want := 3 - tt.wantDeleted; len(command.mentions) != want {
+				t.Errorf("残ったメンション = %d件, want %d件", len(command.mentions), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer はキーごとに一定時間内の呼び出しをまとめ、最後に渡された関数だけを実行する
+// 最初の呼び出しから window 経過後に実行するため、呼び出しが続いても実行が遅れ続けることはない
+type Debouncer struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string]func()
+}
+
+func NewDebouncer(window time.Duration) *Debouncer {
+	return &Debouncer{
+		window:  window,
+		pending: make(map[string]func()),
+	}
+}
+
+// Do は window 経過後に fn を実行する
+// 実行前に同じキーで呼び出された場合は実行する関数を置き換えて false を返す
+func (d *Debouncer) Do(key string, fn func()) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.pending[key]; ok {
+		d.pending[key] = fn
+		return false
+	}
+	d.pending[key] = fn
+	time.AfterFunc(d.window, func() {
+		d.mu.Lock()
+		f := d.pending[key]
+		delete(d.pending, key)
+		d.mu.Unlock()
+
+		f()
+	})
+	return true
+}
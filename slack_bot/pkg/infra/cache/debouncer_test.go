@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDebouncerDo(t *testing.T) {
+	d := NewDebouncer(50 * time.Millisecond)
+
+	var mu sync.Mutex
+	var calls []string
+	done := make(chan struct{}, 3)
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			calls = append(calls, name)
+			mu.Unlock()
+			done <- struct{}{}
+		}
+	}
+
+	// 同じキーの2回目以降は実行する関数を置き換える
+	if !d.Do("C001:1", record("first")) {
+		t.Error("最初の Do() = false, want true")
+	}
+	if d.Do("C001:1", record("second")) {
+		t.Error("window 内の2回目の Do() = true, want false")
+	}
+	if d.Do("C001:1", record("last")) {
+		t.Error("window 内の3回目の Do() = true, want false")
+	}
+	// 別のキーは別々に実行する
+	if !d.Do("C001:2", record("other")) {
+		t.Error("別のキーの Do() = false, want true")
+	}
+
+	for range 2 {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("window の経過後に実行されませんでした")
+		}
+	}
+	// 余分に実行されないことを確認する
+	select {
+	case <-done:
+		t.Fatal("window 内の呼び出しが複数回実行されました")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 2 || !slices.Contains(calls, "last") || !slices.Contains(calls, "other") {
+		t.Errorf("実行された関数 = %v, want [last other]", calls)
+	}
+
+	// 実行後は同じキーで再び待ち始める
+	if !d.Do("C001:1", func() {}) {
+		t.Error("実行後の Do() = false, want true")
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter はキーごとに一定時間内のリクエスト数を制限する（固定ウィンドウ方式）
+// 期間が終了したキーは呼び出し時にまとめて削除されるため、利用されなくなったキーが残り続けることはない
+type RateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]*rateWindow
+	now     func() time.Time
+}
+
+type rateWindow struct {
+	resetAt time.Time
+	count   int
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*rateWindow),
+		now:     time.Now,
+	}
+}
+
+// Allow はリクエストを記録し、期間内の上限を超えている場合は false と次に受け付け可能になるまでの時間を返す
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for k, w := range l.windows {
+		if !now.Before(w.resetAt) {
+			delete(l.windows, k)
+		}
+	}
+
+	w, ok := l.windows[key]
+	if !ok {
+		w = &rateWindow{resetAt: now.Add(l.window)}
+		l.windows[key] = w
+	}
+	if w.count >= l.limit {
+		return false, w.resetAt.Sub(now)
+	}
+	w.count++
+	return true, 0
+}
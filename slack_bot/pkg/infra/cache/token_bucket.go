@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket は一定の速度で補充されるトークンを消費して呼び出しの頻度を制限する
+// burst 回までは待たずに呼び出せ、それ以降は interval ごとに1回呼び出せる
+type TokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+func NewTokenBucket(interval time.Duration, burst int) *TokenBucket {
+	return &TokenBucket{
+		interval: interval,
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// Wait はトークンを1つ消費できるまで待つ
+// 待っている間にコンテキストが終了した場合はトークンを消費せずにエラーを返す
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		wait := b.reserve()
+		if wait <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve はトークンを消費できた場合は0を、できない場合は次のトークンが補充されるまでの時間を返す
+func (b *TokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if b.tokens > float64(b.burst) {
+		b.tokens = float64(b.burst)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.interval))
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestTokenBucket(interval time.Duration, burst int) (*TokenBucket, *time.Time) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	b := NewTokenBucket(interval, burst)
+	b.now = func() time.Time { return now }
+	b.last = now
+	return b, &now
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	b, _ := newTestTokenBucket(time.Second, 3)
+
+	// burst 回までは待たずに呼び出せる
+	for i := range 3 {
+		if wait := b.reserve(); wait != 0 {
+			t.Fatalf("%d回目の reserve() = %v, want 0", i+1, wait)
+		}
+	}
+	if wait := b.reserve(); wait != time.Second {
+		t.Errorf("burst を超えた reserve() = %v, want %v", wait, time.Second)
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	b, now := newTestTokenBucket(time.Second, 2)
+	for range 2 {
+		b.reserve()
+	}
+
+	// interval の半分ではトークンが足りず、残りの時間を待つ
+	*now = now.Add(500 * time.Millisecond)
+	if wait := b.reserve(); wait != 500*time.Millisecond {
+		t.Errorf("interval の半分の経過後の reserve() = %v, want %v", wait, 500*time.Millisecond)
+	}
+	// interval ごとに1つ補充される
+	*now = now.Add(500 * time.Millisecond)
+	if wait := b.reserve(); wait != 0 {
+		t.Errorf("interval の経過後の reserve() = %v, want 0", wait)
+	}
+	if wait := b.reserve(); wait != time.Second {
+		t.Errorf("補充されたトークンの消費後の reserve() = %v, want %v", wait, time.Second)
+	}
+}
+
+func TestTokenBucketRefillCappedAtBurst(t *testing.T) {
+	b, now := newTestTokenBucket(time.Second, 2)
+	for range 2 {
+		b.reserve()
+	}
+
+	// 長時間呼び出さなくても burst を超えて補充しない
+	*now = now.Add(time.Hour)
+	for i := range 2 {
+		if wait := b.reserve(); wait != 0 {
+			t.Fatalf("%d回目の reserve() = %v, want 0", i+1, wait)
+		}
+	}
+	if wait := b.reserve(); wait != time.Second {
+		t.Errorf("burst を超えた reserve() = %v, want %v", wait, time.Second)
+	}
+}
+
+func TestTokenBucketWaitCanceled(t *testing.T) {
+	b, _ := newTestTokenBucket(time.Hour, 1)
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// TTLCache は一定時間だけキーに対応する値を保持する
+// 期限切れのキーは追加時にまとめて削除されるため、利用されなくなったキーが残り続けることはない
+// maxSize を指定した場合は、上限に達すると期限が最も近いキーから削除する
+type TTLCache[V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	items   map[string]ttlCacheItem[V]
+	now     func() time.Time
+}
+
+type ttlCacheItem[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// NewTTLCache はキャッシュを作成する
+// maxSize が 0 の場合は件数を制限しない
+func NewTTLCache[V any](ttl time.Duration, maxSize int) *TTLCache[V] {
+	return &TTLCache[V]{
+		ttl:     ttl,
+		maxSize: maxSize,
+		items:   make(map[string]ttlCacheItem[V]),
+		now:     time.Now,
+	}
+}
+
+// Get はキーに対応する値を返し、保持していないか期限切れの場合は false を返す
+func (c *TTLCache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok || !c.now().Before(item.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return item.value, true
+}
+
+// Set はキーに対応する値を保持する
+func (c *TTLCache[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, item := range c.items {
+		if !now.Before(item.expiresAt) {
+			delete(c.items, k)
+		}
+	}
+	if _, ok := c.items[key]; !ok && c.maxSize > 0 && len(c.items) >= c.maxSize {
+		c.evictOldest()
+	}
+	c.items[key] = ttlCacheItem[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Delete はキーに対応する値を削除する
+func (c *TTLCache[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
+
+// evictOldest は期限が最も近いキーを削除する
+func (c *TTLCache[V]) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	found := false
+	for k, item := range c.items {
+		if !found || item.expiresAt.Before(oldest) {
+			oldestKey, oldest, found = k, item.expiresAt, true
+		}
+	}
+	delete(c.items, oldestKey)
+}
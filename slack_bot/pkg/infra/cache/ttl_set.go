@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// TTLSet は一定時間だけキーを保持する集合
+// 期限切れのキーは追加時にまとめて削除されるため、利用されなくなったキーが残り続けることはない
+// maxSize を指定した場合は、上限に達すると期限が最も近い（最も古く追加された）キーから削除する
+type TTLSet struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	items   map[string]time.Time
+	now     func() time.Time
+}
+
+// NewTTLSet は集合を作成する
+// maxSize が 0 の場合は件数を制限しない
+func NewTTLSet(ttl time.Duration, maxSize int) *TTLSet {
+	return &TTLSet{
+		ttl:     ttl,
+		maxSize: maxSize,
+		items:   make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Add はキーを追加し、期限内に同じキーが既に追加されていた場合は false を返す
+func (s *TTLSet) Add(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, expiresAt := range s.items {
+		if !now.Before(expiresAt) {
+			delete(s.items, k)
+		}
+	}
+
+	if _, ok := s.items[key]; ok {
+		return false
+	}
+	if s.maxSize > 0 && len(s.items) >= s.maxSize {
+		s.evictOldest()
+	}
+	s.items[key] = now.Add(s.ttl)
+	return true
+}
+
+// Remove はキーを削除し、期限内でも再び追加できるようにする
+func (s *TTLSet) Remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+}
+
+// evictOldest は期限が最も近いキーを削除する
+func (s *TTLSet) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	found := false
+	for k, expiresAt := range s.items {
+		if !found || expiresAt.Before(oldest) {
+			oldestKey, oldest, found = k, expiresAt, true
+		}
+	}
+	delete(s.items, oldestKey)
+}
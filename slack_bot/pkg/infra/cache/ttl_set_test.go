@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLSetAdd(t *testing.T) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	s := NewTTLSet(time.Minute, 0)
+	s.now = func() time.Time { return now }
+
+	if !s.Add("a") {
+		t.Error("初めて追加したキーで Add() = false, want true")
+	}
+	now = now.Add(time.Minute - time.Second)
+	if s.Add("a") {
+		t.Error("期限内に同じキーで Add() = true, want false")
+	}
+	// 期限を過ぎたキーは再び追加できる
+	now = now.Add(time.Second)
+	if !s.Add("a") {
+		t.Error("期限を過ぎたキーで Add() = false, want true")
+	}
+	if len(s.items) != 1 {
+		t.Errorf("保持しているキー = %d件, want 1件", len(s.items))
+	}
+}
+
+func TestTTLSetEvictsOldest(t *testing.T) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	s := NewTTLSet(time.Hour, 2)
+	s.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		if !s.Add(key) {
+			t.Fatalf("Add(%q) = false, want true", key)
+		}
+		now = now.Add(time.Second)
+	}
+	// 上限に達したため最も古い a を削除し、b と c を保持する
+	if len(s.items) != 2 {
+		t.Errorf("保持しているキー = %d件, want 2件", len(s.items))
+	}
+	if s.Add("b") || s.Add("c") {
+		t.Error("保持しているキーで Add() = true, want false")
+	}
+	if !s.Add("a") {
+		t.Error("削除したキーで Add() = false, want true")
+	}
+}
+
+func TestTTLSetRemove(t *testing.T) {
+	s := NewTTLSet(time.Hour, 0)
+	s.Add("a")
+	s.Add("b")
+
+	// 削除したキーは期限内でも再び追加できる
+	s.Remove("a")
+	if !s.Add("a") {
+		t.Error("削除したキーで Add() = false, want true")
+	}
+	if s.Add("b") {
+		t.Error("削除していないキーで Add() = true, want false")
+	}
+	// 保持していないキーの削除は何もしない
+	s.Remove("c")
+	if len(s.items) != 2 {
+		t.Errorf("保持しているキー = %d件, want 2件", len(s.items))
+	}
+}
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/crypto"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/database"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+)
+
+// SlackMentionCommand はデータベースにメンションを書き込む
+// encryption.key が設定されている場合はテキストを暗号化して保存する
+type SlackMentionCommand struct {
+	db     *bun.DB
+	cipher *crypto.TextCipher
+}
+
+func NewSlackMentionCommand(db *bun.DB, cipher *crypto.TextCipher) di.SlackMentionCommand {
+	return &SlackMentionCommand{db: db, cipher: cipher}
+}
+
+// Create はメンションを保存する
+// 同じIDのメンションが既に保存されている場合（同じイベントの再処理）は何もしない
+func (r *SlackMentionCommand) Create(ctx context.Context, mention *entity.SlackMention) error {
+	mention, err := r.cipher.EncryptMention(mention)
+	if err != nil {
+		return err
+	}
+	if _, err := database.OnConflictDoNothing(r.db.NewInsert().Model(mention), "id").Exec(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *SlackMentionCommand) UpdateSQSMessageID(ctx context.Context, id slack.MentionID, messageID string) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.SlackMention)(nil)).
+		Set("sqs_message_id = ?", messageID).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", dbtypes.ULID(id)).
+		Exec(ctx)
+	return err
+}
+
+// UpdateStatus はメンションの状態と理由を更新する
+// 現在の状態から遷移できない場合は slack.ErrInvalidStatusTransition、メンションがない場合は sql.ErrNoRows を返す
+func (r *SlackMentionCommand) UpdateStatus(ctx context.Context, id slack.MentionID, status string, detail string) error {
+	next, err := slack.ParseMentionStatus(status)
+	if err != nil {
+		return err
+	}
+	// 遷移できる状態の場合のみ更新し、同時に更新された場合も許可されていない遷移にならないようにする
+	res, err := r.db.NewUpdate().
+		Model((*entity.SlackMention)(nil)).
+		Set("status = ?", next).
+		Set("status_detail = ?", sql.NullString{String: detail, Valid: detail != ""}).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", dbtypes.ULID(id)).
+		Where("status IN (?)", bun.In(slack.MentionStatusesBefore(next))).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+
+	var current entity.SlackMention
+	if err := r.db.NewSelect().Model(&current).Column("status").Where("id = ?", dbtypes.ULID(id)).Scan(ctx); err != nil {
+		return err
+	}
+	return slack.MentionStatus(current.Status).TransitionTo(next)
+}
+
+func (r *SlackMentionCommand) Delete(ctx context.Context, id slack.MentionID) error {
+	now := time.Now()
+	res, err := r.db.NewUpdate().
+		Model((*entity.SlackMention)(nil)).
+		Set("deleted_at = ?", now).
+		Set("updated_at = ?", now).
+		Where("id = ?", dbtypes.ULID(id)).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *SlackMentionCommand) DeleteByTS(ctx context.Context, source string, channelID string, ts string) (int, error) {
+	now := time.Now()
+	res, err := r.db.NewUpdate().
+		Model((*entity.SlackMention)(nil)).
+		Set("deleted_at = ?", now).
+		Set("updated_at = ?", now).
+		Where("type = ?", source).
+		Where("channel_id = ?", channelID).
+		Where("ts = ?", ts).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *SlackMentionCommand) DeleteByIDs(ctx context.Context, ids []slack.MentionID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := r.db.NewDelete().Model((*entity.SlackMention)(nil)).Where("id IN (?)", bun.In(dbtypes.ULIDs(ids))).Exec(ctx); err != nil {
+		return err
+	}
+	return nil
+}
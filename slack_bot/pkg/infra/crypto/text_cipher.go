@@ -0,0 +1,263 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// ErrDecryptFailed は保存されたメンションのテキストを復号できない場合のエラー
+// 鍵が設定されていない、または暗号化に使用した鍵が key / previous_keys に含まれていない場合に返す
+var ErrDecryptFailed = errors.New("メンションのテキストを復号できません")
+
+// TextCipher はメンションのテキスト・受信イベントと、テキストを含むキューのメッセージをAES-GCMで暗号化・復号する
+// 暗号化には key を使用し、復号は key と previous_keys を順に試すため鍵を切り替えても既存の行を読める
+// 鍵が設定されていない場合は nil で、暗号化せずに保存する
+type TextCipher struct {
+	primary  cipher.AEAD
+	previous []cipher.AEAD
+}
+
+func NewTextCipher(cfg *config.AppConfig) (*TextCipher, error) {
+	encCfg := cfg.Encryption
+	if encCfg.Key == "" {
+		return nil, nil
+	}
+
+	primary, err := newAEAD(encCfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("暗号化の鍵 (encryption.key) が不正です: %w", err)
+	}
+	c := &TextCipher{primary: primary}
+	for i, key := range encCfg.PreviousKeys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("以前の暗号化の鍵 (encryption.previous_keys[%d]) が不正です: %w", i, err)
+		}
+		c.previous = append(c.previous, aead)
+	}
+	return c, nil
+}
+
+// newAEAD はBase64でエンコードされた32バイトの鍵からAES-256-GCMを作成する
+func newAEAD(encodedKey string) (cipher.AEAD, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("鍵は32バイトである必要があります（%dバイト）", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptMention はテキストと受信イベント（テキストを含む）を暗号化したメンションのコピーを返す
+func (c *TextCipher) EncryptMention(m *entity.SlackMention) (*entity.SlackMention, error) {
+	if c == nil || m.TextEncrypted {
+		return m, nil
+	}
+	encrypted := *m
+	var err error
+	if encrypted.Text, err = c.seal(m.Text, m.ID[:]); err != nil {
+		return nil, err
+	}
+	if m.RawEvent != "" {
+		if encrypted.RawEvent, err = c.seal(m.RawEvent, m.ID[:]); err != nil {
+			return nil, err
+		}
+	}
+	encrypted.TextEncrypted = true
+	return &encrypted, nil
+}
+
+// DecryptMention は暗号化されたテキストと受信イベントを復号する（暗号化されていない行はそのまま）
+func (c *TextCipher) DecryptMention(m *entity.SlackMention) error {
+	if !m.TextEncrypted {
+		return nil
+	}
+	if c == nil {
+		return fmt.Errorf("%w (id=%s): 暗号化の鍵 (encryption.key) が設定されていません", ErrDecryptFailed, m.ID)
+	}
+	text, err := c.open(m.Text, m.ID[:])
+	if err != nil {
+		return fmt.Errorf("%w (id=%s): %v", ErrDecryptFailed, m.ID, err)
+	}
+	rawEvent := m.RawEvent
+	if rawEvent != "" {
+		if rawEvent, err = c.open(rawEvent, m.ID[:]); err != nil {
+			return fmt.Errorf("%w (id=%s): %v", ErrDecryptFailed, m.ID, err)
+		}
+	}
+	m.Text, m.RawEvent, m.TextEncrypted = text, rawEvent, false
+	return nil
+}
+
+// seal は plaintext を暗号化し、ノンスと暗号文を連結してBase64でエンコードする
+// 行の取り違えを検出できるよう、メンションのIDを追加データとして認証する
+func (c *TextCipher) seal(plaintext string, additionalData []byte) (string, error) {
+	nonce := make([]byte, c.primary.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("ノンスの生成エラー: %w", err)
+	}
+	sealed := c.primary.Seal(nonce, nonce, []byte(plaintext), additionalData)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open は seal で暗号化した値を key、previous_keys の順に復号を試す
+func (c *TextCipher) open(value string, additionalData []byte) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	for _, aead := range append([]cipher.AEAD{c.primary}, c.previous...) {
+		if len(sealed) < aead.NonceSize() {
+			return "", errors.New("暗号文が短すぎます")
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	return "", errors.New("設定されたいずれの鍵でも復号できません")
+}
+
+// DecryptMentions は複数のメンションのテキストを復号する
+func (c *TextCipher) DecryptMentions(mentions []*entity.SlackMention) error {
+	for _, m := range mentions {
+		if err := c.DecryptMention(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncryptConversationMessage はテキストを暗号化した会話のメッセージのコピーを返す
+func (c *TextCipher) EncryptConversationMessage(m *entity.ConversationMessage) (*entity.ConversationMessage, error) {
+	if c == nil || m.TextEncrypted {
+		return m, nil
+	}
+	encrypted := *m
+	var err error
+	if encrypted.Text, err = c.seal(m.Text, conversationMessageAD(m)); err != nil {
+		return nil, err
+	}
+	encrypted.TextEncrypted = true
+	return &encrypted, nil
+}
+
+// DecryptConversationMessages は複数の会話のメッセージのテキストを復号する（暗号化されていない行はそのまま）
+func (c *TextCipher) DecryptConversationMessages(messages []*entity.ConversationMessage) error {
+	for _, m := range messages {
+		if !m.TextEncrypted {
+			continue
+		}
+		if c == nil {
+			return fmt.Errorf("%w (conversation_message=%d): 暗号化の鍵 (encryption.key) が設定されていません", ErrDecryptFailed, m.ID)
+		}
+		text, err := c.open(m.Text, conversationMessageAD(m))
+		if err != nil {
+			return fmt.Errorf("%w (conversation_message=%d): %v", ErrDecryptFailed, m.ID, err)
+		}
+		m.Text, m.TextEncrypted = text, false
+	}
+	return nil
+}
+
+// 会話のメッセージのIDは保存するまで決まらないため、チャンネル・スレッド・タイムスタンプを追加データにする
+func conversationMessageAD(m *entity.ConversationMessage) []byte {
+	return []byte(m.ChannelID + ":" + m.ThreadTS + ":" + m.TS)
+}
+
+// EncryptOutboxMessage は送信するメッセージ（メンションのテキストを含む）を暗号化したコピーを返す
+func (c *TextCipher) EncryptOutboxMessage(m *entity.OutboxMessage) (*entity.OutboxMessage, error) {
+	if c == nil || m.PayloadEncrypted {
+		return m, nil
+	}
+	encrypted := *m
+	var err error
+	if encrypted.Payload, err = c.sealPayload(m.Payload, m.MentionID[:]); err != nil {
+		return nil, err
+	}
+	encrypted.PayloadEncrypted = true
+	return &encrypted, nil
+}
+
+// DecryptOutboxMessages は複数の送信待ちのメッセージを復号する（暗号化されていない行はそのまま）
+func (c *TextCipher) DecryptOutboxMessages(messages []*entity.OutboxMessage) error {
+	for _, m := range messages {
+		if !m.PayloadEncrypted {
+			continue
+		}
+		payload, err := c.openPayload(m.Payload, m.MentionID[:])
+		if err != nil {
+			return fmt.Errorf("%w (outbox=%d): %v", ErrDecryptFailed, m.ID, err)
+		}
+		m.Payload, m.PayloadEncrypted = payload, false
+	}
+	return nil
+}
+
+// EncryptFailedMention はデッドレターのメッセージ（メンションのテキストを含む）を暗号化したコピーを返す
+func (c *TextCipher) EncryptFailedMention(m *entity.FailedMention) (*entity.FailedMention, error) {
+	if c == nil || m.PayloadEncrypted {
+		return m, nil
+	}
+	encrypted := *m
+	var err error
+	if encrypted.Payload, err = c.sealPayload(m.Payload, m.MentionID[:]); err != nil {
+		return nil, err
+	}
+	encrypted.PayloadEncrypted = true
+	return &encrypted, nil
+}
+
+// DecryptFailedMentions は複数のデッドレターのメッセージを復号する（暗号化されていない行はそのまま）
+func (c *TextCipher) DecryptFailedMentions(mentions []*entity.FailedMention) error {
+	for _, m := range mentions {
+		if !m.PayloadEncrypted {
+			continue
+		}
+		payload, err := c.openPayload(m.Payload, m.MentionID[:])
+		if err != nil {
+			return fmt.Errorf("%w (failed_mention=%d): %v", ErrDecryptFailed, m.ID, err)
+		}
+		m.Payload, m.PayloadEncrypted = payload, false
+	}
+	return nil
+}
+
+// sealPayload はメッセージを暗号化し、JSONの列に保存できるよう暗号文をJSONの文字列にする
+func (c *TextCipher) sealPayload(payload json.RawMessage, additionalData []byte) (json.RawMessage, error) {
+	sealed, err := c.seal(string(payload), additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealed)
+}
+
+// openPayload は sealPayload で暗号化したメッセージを復号する
+func (c *TextCipher) openPayload(payload json.RawMessage, additionalData []byte) (json.RawMessage, error) {
+	if c == nil {
+		return nil, errors.New("暗号化の鍵 (encryption.key) が設定されていません")
+	}
+	var sealed string
+	if err := json.Unmarshal(payload, &sealed); err != nil {
+		return nil, err
+	}
+	plaintext, err := c.open(sealed, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(plaintext), nil
+}
@@ -0,0 +1,266 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// testKey は fill を32バイト並べた鍵をBase64でエンコードして返す
+func testKey(fill byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, 32))
+}
+
+func newTestCipher(t *testing.T, key string, previousKeys ...string) *TextCipher {
+	t.Helper()
+	c, err := NewTextCipher(&config.AppConfig{Encryption: config.EncryptionConfig{Key: key, PreviousKeys: previousKeys}})
+	if err != nil {
+		t.Fatalf("NewTextCipher() error = %v", err)
+	}
+	return c
+}
+
+func newTestMention() *entity.SlackMention {
+	return &entity.SlackMention{
+		ID:       dbtypes.ULID(ulid.MustParse("01HTGZ0000000000000000000A")),
+		Text:     "デプロイの手順を教えてください",
+		RawEvent: `{"type":"app_mention","text":"デプロイの手順を教えてください"}`,
+	}
+}
+
+func TestNewTextCipher(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.EncryptionConfig
+		wantNil bool
+		wantErr string
+	}{
+		{name: "鍵が設定されていない場合は暗号化しない", wantNil: true},
+		{name: "32バイトの鍵", cfg: config.EncryptionConfig{Key: testKey(1), PreviousKeys: []string{testKey(2)}}},
+		{name: "Base64でない鍵", cfg: config.EncryptionConfig{Key: "not base64!"}, wantErr: "encryption.key"},
+		{name: "32バイトでない鍵", cfg: config.EncryptionConfig{Key: base64.StdEncoding.EncodeToString([]byte("short"))}, wantErr: "encryption.key"},
+		{name: "不正な以前の鍵", cfg: config.EncryptionConfig{Key: testKey(1), PreviousKeys: []string{"short"}}, wantErr: "previous_keys[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewTextCipher(&config.AppConfig{Encryption: tt.cfg})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("NewTextCipher() error = %v, want %q を含むエラー", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTextCipher() error = %v", err)
+			}
+			if (c == nil) != tt.wantNil {
+				t.Errorf("NewTextCipher() = %v, want nil = %v", c, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestTextCipherMentionRoundTrip(t *testing.T) {
+	c := newTestCipher(t, testKey(1))
+	mention := newTestMention()
+
+	encrypted, err := c.EncryptMention(mention)
+	if err != nil {
+		t.Fatalf("EncryptMention() error = %v", err)
+	}
+	if !encrypted.TextEncrypted || strings.Contains(encrypted.Text, "デプロイ") || strings.Contains(encrypted.RawEvent, "デプロイ") {
+		t.Errorf("EncryptMention() = %+v, want テキストと受信イベントを暗号化したメンション", encrypted)
+	}
+	if mention.TextEncrypted || mention.Text != newTestMention().Text {
+		t.Errorf("EncryptMention() が元のメンションを変更しています: %+v", mention)
+	}
+	// 暗号化済みのメンションは二重に暗号化しない
+	if again, err := c.EncryptMention(encrypted); err != nil || again != encrypted {
+		t.Errorf("EncryptMention(暗号化済み) = %p, %v, want %p, nil", again, err, encrypted)
+	}
+
+	if err := c.DecryptMention(encrypted); err != nil {
+		t.Fatalf("DecryptMention() error = %v", err)
+	}
+	want := newTestMention()
+	if encrypted.TextEncrypted || encrypted.Text != want.Text || encrypted.RawEvent != want.RawEvent {
+		t.Errorf("DecryptMention() = %+v, want %+v", encrypted, want)
+	}
+}
+
+func TestTextCipherDecryptMentionErrors(t *testing.T) {
+	c := newTestCipher(t, testKey(1))
+	encrypted, err := c.EncryptMention(newTestMention())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cipher  *TextCipher
+		mention func() *entity.SlackMention
+	}{
+		{
+			name:   "鍵が設定されていない",
+			cipher: nil,
+			mention: func() *entity.SlackMention {
+				m := *encrypted
+				return &m
+			},
+		},
+		{
+			// メンションのIDを追加データにしているため、別の行の暗号文は復号できない
+			name:   "別のメンションの暗号文",
+			cipher: c,
+			mention: func() *entity.SlackMention {
+				m := *encrypted
+				m.ID = dbtypes.ULID(ulid.MustParse("01HTGZ0000000000000000000B"))
+				return &m
+			},
+		},
+		{
+			name:   "暗号化に使用していない鍵",
+			cipher: newTestCipher(t, testKey(2)),
+			mention: func() *entity.SlackMention {
+				m := *encrypted
+				return &m
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cipher.DecryptMention(tt.mention()); !errors.Is(err, ErrDecryptFailed) {
+				t.Errorf("DecryptMention() error = %v, want %v", err, ErrDecryptFailed)
+			}
+		})
+	}
+
+	// 暗号化されていない行は鍵がなくてもそのまま読める
+	plain := newTestMention()
+	if err := (*TextCipher)(nil).DecryptMention(plain); err != nil || plain.Text != newTestMention().Text {
+		t.Errorf("DecryptMention(暗号化されていない行) = %v, text %q", err, plain.Text)
+	}
+}
+
+// 鍵を切り替えた後も previous_keys に以前の鍵があれば、以前の鍵で保存した行を復号できる
+func TestTextCipherKeyRotation(t *testing.T) {
+	before := newTestCipher(t, testKey(1))
+	mention, err := before.EncryptMention(newTestMention())
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := before.EncryptOutboxMessage(&entity.OutboxMessage{ID: 1, MentionID: mention.ID, Payload: json.RawMessage(`{"text":"質問"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conversation, err := before.EncryptConversationMessage(&entity.ConversationMessage{ID: 1, ChannelID: "C001", ThreadTS: "1712345678.000100", TS: "1712345678.000200", Text: "回答"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestCipher(t, testKey(2), testKey(1))
+	if err := rotated.DecryptMention(mention); err != nil || mention.Text != newTestMention().Text {
+		t.Errorf("DecryptMention() = %q, %v, want %q", mention.Text, err, newTestMention().Text)
+	}
+	if err := rotated.DecryptOutboxMessages([]*entity.OutboxMessage{message}); err != nil || string(message.Payload) != `{"text":"質問"}` {
+		t.Errorf("DecryptOutboxMessages() = %s, %v", message.Payload, err)
+	}
+	if err := rotated.DecryptConversationMessages([]*entity.ConversationMessage{conversation}); err != nil || conversation.Text != "回答" {
+		t.Errorf("DecryptConversationMessages() = %q, %v", conversation.Text, err)
+	}
+
+	// 新しく保存する行は新しい鍵で暗号化するため、以前の鍵だけでは復号できない
+	reencrypted, err := rotated.EncryptMention(newTestMention())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := before.DecryptMention(reencrypted); !errors.Is(err, ErrDecryptFailed) {
+		t.Errorf("以前の鍵での DecryptMention() error = %v, want %v", err, ErrDecryptFailed)
+	}
+
+	// previous_keys から以前の鍵を外すと、以前の鍵で保存した行は復号できない
+	old, err := before.EncryptMention(newTestMention())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newTestCipher(t, testKey(2)).DecryptMention(old); !errors.Is(err, ErrDecryptFailed) {
+		t.Errorf("previous_keys なしの DecryptMention() error = %v, want %v", err, ErrDecryptFailed)
+	}
+}
+
+func TestTextCipherPayloadRoundTrip(t *testing.T) {
+	c := newTestCipher(t, testKey(1))
+	mentionID := dbtypes.ULID(ulid.MustParse("01HTGZ0000000000000000000A"))
+	payload := json.RawMessage(`{"id":"01HTGZ0000000000000000000A","text":"デプロイの手順を教えてください"}`)
+
+	t.Run("送信待ちのメッセージ", func(t *testing.T) {
+		message := &entity.OutboxMessage{ID: 1, MentionID: mentionID, Payload: payload}
+		encrypted, err := c.EncryptOutboxMessage(message)
+		if err != nil {
+			t.Fatalf("EncryptOutboxMessage() error = %v", err)
+		}
+		assertSealedPayload(t, encrypted.Payload, encrypted.PayloadEncrypted)
+		if message.PayloadEncrypted || !bytes.Equal(message.Payload, payload) {
+			t.Errorf("EncryptOutboxMessage() が元のメッセージを変更しています: %s", message.Payload)
+		}
+		if err := c.DecryptOutboxMessages([]*entity.OutboxMessage{encrypted}); err != nil {
+			t.Fatalf("DecryptOutboxMessages() error = %v", err)
+		}
+		if encrypted.PayloadEncrypted || !bytes.Equal(encrypted.Payload, payload) {
+			t.Errorf("DecryptOutboxMessages() = %s, want %s", encrypted.Payload, payload)
+		}
+	})
+
+	t.Run("デッドレター", func(t *testing.T) {
+		failed := &entity.FailedMention{ID: 1, MentionID: mentionID, Payload: payload}
+		encrypted, err := c.EncryptFailedMention(failed)
+		if err != nil {
+			t.Fatalf("EncryptFailedMention() error = %v", err)
+		}
+		assertSealedPayload(t, encrypted.Payload, encrypted.PayloadEncrypted)
+		if err := c.DecryptFailedMentions([]*entity.FailedMention{encrypted}); err != nil {
+			t.Fatalf("DecryptFailedMentions() error = %v", err)
+		}
+		if encrypted.PayloadEncrypted || !bytes.Equal(encrypted.Payload, payload) {
+			t.Errorf("DecryptFailedMentions() = %s, want %s", encrypted.Payload, payload)
+		}
+
+		// 鍵が設定されていない場合は暗号化したメッセージを復号できない
+		encrypted, err = c.EncryptFailedMention(failed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := (*TextCipher)(nil).DecryptFailedMentions([]*entity.FailedMention{encrypted}); !errors.Is(err, ErrDecryptFailed) {
+			t.Errorf("鍵なしの DecryptFailedMentions() error = %v, want %v", err, ErrDecryptFailed)
+		}
+	})
+
+	t.Run("鍵が設定されていない場合は暗号化しない", func(t *testing.T) {
+		message := &entity.OutboxMessage{MentionID: mentionID, Payload: payload}
+		if got, err := (*TextCipher)(nil).EncryptOutboxMessage(message); err != nil || got != message {
+			t.Errorf("EncryptOutboxMessage() = %p, %v, want %p, nil", got, err, message)
+		}
+	})
+}
+
+// assertSealedPayload は暗号化したメッセージがテキストを含まない、JSONの列に保存できる値であることを確認する
+func assertSealedPayload(t *testing.T, payload json.RawMessage, encrypted bool) {
+	t.Helper()
+	if !encrypted {
+		t.Error("PayloadEncrypted = false, want true")
+	}
+	if !json.Valid(payload) {
+		t.Errorf("暗号化したメッセージがJSONではありません: %s", payload)
+	}
+	if bytes.Contains(payload, []byte("デプロイ")) {
+		t.Errorf("暗号化したメッセージにテキストが含まれています: %s", payload)
+	}
+}
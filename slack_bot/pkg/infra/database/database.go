@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"go.uber.org/fx"
+)
+
+// NewDB はMySQLに接続する
+// 起動時に接続を確認し、停止時に切断する。database.enabled が false の場合は nil を返す
+func NewDB(lc fx.Lifecycle, cfg *config.AppConfig) (*bun.DB, error) {
+	return newDB(lc, cfg, false)
+}
+
+// NewMigrationDB はマイグレーション用にMySQLに接続する
+// 1つのSQLファイルに複数のSQL文を書けるよう、NewDB と異なり multiStatements を有効にする
+func NewMigrationDB(lc fx.Lifecycle, cfg *config.AppConfig) (*bun.DB, error) {
+	return newDB(lc, cfg, true)
+}
+
+func newDB(lc fx.Lifecycle, cfg *config.AppConfig, multiStatements bool) (*bun.DB, error) {
+	if !cfg.Database.Enabled {
+		return nil, nil
+	}
+	connector, err := newConnector(cfg.Database.DSN, multiStatements)
+	if err != nil {
+		return nil, err
+	}
+	sqldb := sql.OpenDB(connector)
+	sqldb.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqldb.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqldb.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	db := bun.NewDB(sqldb, mysqldialect.New())
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := db.PingContext(ctx); err != nil {
+				return fmt.Errorf("データベースに接続できません (database.dsn を確認してください): %w", err)
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return db.Close()
+		},
+	})
+
+	return db, nil
+}
+
+// newConnector は database.dsn からMySQLのコネクタを作成する
+// DATETIME のカラムを time.Time として読み込むため、DSNの指定にかかわらず parseTime を有効にする
+// multiStatements はマイグレーションの場合だけ有効にする（SQLインジェクションの影響を広げないため）
+func newConnector(dsn string, multiStatements bool) (driver.Connector, error) {
+	mysqlCfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("データベースの接続先 (database.dsn) の形式が正しくありません: %w", err)
+	}
+	mysqlCfg.ParseTime = true
+	mysqlCfg.MultiStatements = multiStatements
+	return mysql.NewConnector(mysqlCfg)
+}
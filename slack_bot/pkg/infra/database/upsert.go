@@ -0,0 +1,61 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/feature"
+)
+
+// OnConflictDoNothing は主キーまたは一意制約の conflict の列が重複する行がある場合に何もしない
+// MySQLは ON DUPLICATE KEY UPDATE、その他のデータベース（PostgreSQL, SQLite）は ON CONFLICT DO NOTHING を使用する
+// どちらも保存しなかった行は RowsAffected に含まれない
+func OnConflictDoNothing(q *bun.InsertQuery, conflict ...string) *bun.InsertQuery {
+	if q.DB().HasFeature(feature.InsertOnDuplicateKey) {
+		return q.On("DUPLICATE KEY UPDATE ? = ?", bun.Ident(conflict[0]), bun.Ident(conflict[0]))
+	}
+	return q.On("CONFLICT ("+placeholders(len(conflict))+") DO NOTHING", idents(conflict)...)
+}
+
+// OnConflictUpdate は conflict の列が重複する行がある場合に、columns の列を保存しようとした値で更新する
+func OnConflictUpdate(q *bun.InsertQuery, conflict []string, columns ...string) *bun.InsertQuery {
+	if q.DB().HasFeature(feature.InsertOnDuplicateKey) {
+		q = q.On("DUPLICATE KEY UPDATE")
+		for _, column := range columns {
+			q = q.Set("? = VALUES(?)", bun.Ident(column), bun.Ident(column))
+		}
+		return q
+	}
+	q = q.On("CONFLICT ("+placeholders(len(conflict))+") DO UPDATE", idents(conflict)...)
+	for _, column := range columns {
+		q = q.Set("? = EXCLUDED.?", bun.Ident(column), bun.Ident(column))
+	}
+	return q
+}
+
+// OnConflictUpdateIf は OnConflictUpdate と同じく更新するが、既存の行の column が value に対して op（< や <= など）を満たす場合だけ更新する
+// 更新しなかった行は RowsAffected に含まれない
+// MySQLは SET を左から順に評価するため、column は columns の最後に指定する
+func OnConflictUpdateIf(q *bun.InsertQuery, conflict, columns []string, column, op string, value any) *bun.InsertQuery {
+	if q.DB().HasFeature(feature.InsertOnDuplicateKey) {
+		q = q.On("DUPLICATE KEY UPDATE")
+		for _, c := range columns {
+			q = q.Set("? = IF(? "+op+" ?, VALUES(?), ?)", bun.Ident(c), bun.Ident(column), value, bun.Ident(c), bun.Ident(c))
+		}
+		return q
+	}
+	// 列名だけでは保存しようとした行 (EXCLUDED) と区別できないデータベースがあるため、既存の行はテーブルの別名で参照する
+	return OnConflictUpdate(q, conflict, columns...).Where("?TableAlias.? "+op+" ?", bun.Ident(column), value)
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func idents(columns []string) []any {
+	args := make([]any, 0, len(columns))
+	for _, column := range columns {
+		args = append(args, bun.Ident(column))
+	}
+	return args
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/schema"
+)
+
+type upsertTestRow struct {
+	bun.BaseModel `bun:"table:upsert_test_rows,alias:upsert_test_row"`
+
+	ID        string    `bun:"id,pk"`
+	Name      string    `bun:"name"`
+	UpdatedAt time.Time `bun:"updated_at"`
+}
+
+// 各データベースの方言で組み立てたSQLを確認する（SQLは実行しない）
+func TestUpsertQueries(t *testing.T) {
+	staleBefore := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	dialects := []struct {
+		name    string
+		dialect schema.Dialect
+	}{
+		{name: "mysql", dialect: mysqldialect.New()},
+		{name: "pg", dialect: pgdialect.New()},
+		{name: "sqlite", dialect: sqlitedialect.New()},
+	}
+	tests := []struct {
+		name  string
+		build func(q *bun.InsertQuery) *bun.InsertQuery
+		want  map[string]string
+	}{
+		{
+			name:  "OnConflictDoNothing",
+			build: func(q *bun.InsertQuery) *bun.InsertQuery { return OnConflictDoNothing(q, "id") },
+			want: map[string]string{
+				"mysql":  " ON DUPLICATE KEY UPDATE `id` = `id`",
+				"pg":     ` ON CONFLICT ("id") DO NOTHING`,
+				"sqlite": ` ON CONFLICT ("id") DO NOTHING`,
+			},
+		},
+		{
+			name: "OnConflictUpdate",
+			build: func(q *bun.InsertQuery) *bun.InsertQuery {
+				return OnConflictUpdate(q, []string{"id"}, "name", "updated_at")
+			},
+			want: map[string]string{
+				"mysql":  " ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `updated_at` = VALUES(`updated_at`)",
+				"pg":     ` ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "updated_at" = EXCLUDED."updated_at"`,
+				"sqlite": ` ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "updated_at" = EXCLUDED."updated_at"`,
+			},
+		},
+		{
+			name: "OnConflictUpdateIf",
+			build: func(q *bun.InsertQuery) *bun.InsertQuery {
+				return OnConflictUpdateIf(q, []string{"id"}, []string{"name", "updated_at"}, "updated_at", "<", staleBefore)
+			},
+			want: map[string]string{
+				"mysql":  " ON DUPLICATE KEY UPDATE `name` = IF(`updated_at` < '2024-04-05 10:00:00', VALUES(`name`), `name`), `updated_at` = IF(`updated_at` < '2024-04-05 10:00:00', VALUES(`updated_at`), `updated_at`)",
+				"pg":     ` ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "updated_at" = EXCLUDED."updated_at" WHERE ("upsert_test_row"."updated_at" < '2024-04-05 10:00:00+00:00')`,
+				"sqlite": ` ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "updated_at" = EXCLUDED."updated_at" WHERE ("upsert_test_row"."updated_at" < '2024-04-05 10:00:00+00:00')`,
+			},
+		},
+	}
+	for _, d := range dialects {
+		// SQLを組み立てるだけのため、接続はどの方言でもSQLiteのものを使用する
+		sqldb, err := sql.Open("sqlite3", ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { sqldb.Close() })
+		db := bun.NewDB(sqldb, d.dialect)
+		for _, tt := range tests {
+			t.Run(d.name+"/"+tt.name, func(t *testing.T) {
+				q := tt.build(db.NewInsert().Model(&upsertTestRow{ID: "1", Name: "a", UpdatedAt: staleBefore}))
+				got := q.String()
+				want := tt.want[d.name]
+				if len(got) < len(want) || got[len(got)-len(want):] != want {
+					t.Errorf("SQL = %s\nwant の末尾 = %s", got, want)
+				}
+			})
+		}
+	}
+}
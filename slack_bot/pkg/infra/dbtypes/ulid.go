@@ -0,0 +1,76 @@
+package dbtypes
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// ULID はデータベースのカラムにULIDを26文字の文字列（CHAR(26)）として保存する
+// ulid.ULID の driver.Valuer は16バイトのバイナリを返すため、文字列のカラムと比較できない
+// 読み込み時は文字列のほか、16バイトのバイナリで保存された値も受け付ける
+type ULID ulid.ULID
+
+// String はULIDの26文字の文字列を返す
+func (u ULID) String() string {
+	return ulid.ULID(u).String()
+}
+
+// IsZero はゼロ値かどうかを返す（bun の nullzero で NULL として保存するために使用する）
+func (u ULID) IsZero() bool {
+	return u == ULID{}
+}
+
+// Value はULIDを26文字の文字列に変換する
+func (u ULID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// Scan はデータベースの値をULIDに変換する
+// NULL の場合はゼロ値にする
+func (u *ULID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*u = ULID{}
+		return nil
+	case string:
+		return u.parse([]byte(v))
+	case []byte:
+		if len(v) == len(ulid.ULID{}) {
+			copy(u[:], v)
+			return nil
+		}
+		return u.parse(v)
+	default:
+		return fmt.Errorf("dbtypes: ULID に変換できない型です: %T", src)
+	}
+}
+
+func (u *ULID) parse(text []byte) error {
+	id, err := ulid.ParseStrict(string(text))
+	if err != nil {
+		return fmt.Errorf("dbtypes: ULID の変換エラー (%q): %w", text, err)
+	}
+	*u = ULID(id)
+	return nil
+}
+
+// MarshalText はJSONなどに26文字の文字列として出力する
+func (u ULID) MarshalText() ([]byte, error) {
+	return ulid.ULID(u).MarshalText()
+}
+
+// UnmarshalText は26文字の文字列からULIDを読み込む
+func (u *ULID) UnmarshalText(text []byte) error {
+	return (*ulid.ULID)(u).UnmarshalText(text)
+}
+
+// ULIDs はIDの一覧（ulid.ULID や slack.MentionID）を IN 句で比較できるように変換する
+func ULIDs[T ~[16]byte](ids []T) []ULID {
+	values := make([]ULID, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, ULID(id))
+	}
+	return values
+}
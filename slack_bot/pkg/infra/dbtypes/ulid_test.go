@@ -0,0 +1,67 @@
+package dbtypes
+
+import (
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestULIDValue(t *testing.T) {
+	id := ulid.MustParse("01HV7Z3Q8M5XK2N9P4R6T8W0YA")
+	tests := []struct {
+		name string
+		id   ULID
+		want string
+	}{
+		{name: "26文字の文字列に変換する", id: ULID(id), want: "01HV7Z3Q8M5XK2N9P4R6T8W0YA"},
+		{name: "ゼロ値", id: ULID{}, want: "00000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.id.Value()
+			if err != nil {
+				t.Fatalf("Value() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestULIDScan(t *testing.T) {
+	id := ulid.MustParse("01HV7Z3Q8M5XK2N9P4R6T8W0YA")
+	tests := []struct {
+		name    string
+		src     any
+		want    ULID
+		wantErr bool
+	}{
+		{name: "文字列", src: "01HV7Z3Q8M5XK2N9P4R6T8W0YA", want: ULID(id)},
+		{name: "文字列のバイト列", src: []byte("01HV7Z3Q8M5XK2N9P4R6T8W0YA"), want: ULID(id)},
+		{name: "16バイトのバイナリ", src: id[:], want: ULID(id)},
+		{name: "NULL はゼロ値にする", src: nil, want: ULID{}},
+		{name: "短い文字列", src: "01HV7Z3Q8M", wantErr: true},
+		{name: "短いバイト列", src: []byte("01HV7Z3Q8M"), wantErr: true},
+		{name: "ULIDに使用できない文字", src: "01HV7Z3Q8M5XK2N9P4R6T8W0YU", wantErr: true},
+		{name: "文字列以外の型", src: int64(1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ULID(ulid.Make())
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Scan(%v) error = nil, want error", tt.src)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan(%v) error = %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan(%v) = %s, want %s", tt.src, got, tt.want)
+			}
+		})
+	}
+}
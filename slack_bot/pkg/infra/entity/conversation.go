@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/uptrace/bun"
+)
+
+// Conversation はスレッドごとの会話
+// チャンネルとスレッドの組み合わせごとに1行で、会話が終了した後の新しい会話は同じ行を置き換える
+type Conversation struct {
+	bun.BaseModel `bun:"table:conversations"`
+
+	ID           dbtypes.ULID `bun:"id,pk,type:char(26)" json:"id"`
+	ChannelID    string       `bun:"channel_id" json:"channel_id"`
+	ThreadTS     string       `bun:"thread_ts" json:"thread_ts"`
+	StartedBy    string       `bun:"started_by" json:"started_by"`
+	LastActivity time.Time    `bun:"last_activity" json:"last_activity"`
+	MessageCount int          `bun:"message_count" json:"message_count"`
+	CreatedAt    time.Time    `bun:"created_at" json:"created_at"`
+	UpdatedAt    time.Time    `bun:"updated_at" json:"updated_at"`
+}
+
+func NewConversation(conversation *slack.Conversation) *Conversation {
+	now := time.Now()
+	return &Conversation{
+		ID:           dbtypes.ULID(conversation.ID),
+		ChannelID:    string(conversation.ChannelID),
+		ThreadTS:     string(conversation.ThreadTS),
+		StartedBy:    string(conversation.StartedBy),
+		LastActivity: conversation.LastActivity,
+		MessageCount: conversation.MessageCount,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+func (c *Conversation) ToModel() *slack.Conversation {
+	return &slack.Conversation{
+		ID:           slack.ConversationID(c.ID),
+		ChannelID:    slack.ChannelID(c.ChannelID),
+		ThreadTS:     slack.ThreadTS(c.ThreadTS),
+		StartedBy:    slack.UserID(c.StartedBy),
+		LastActivity: c.LastActivity,
+		MessageCount: c.MessageCount,
+	}
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/uptrace/bun"
+)
+
+// ConversationMessage はスレッドに投稿されたメンションとBotの回答
+// 会話が終了して新しい会話になっても、スレッドのメッセージはそのまま残す
+type ConversationMessage struct {
+	bun.BaseModel `bun:"table:conversation_messages"`
+
+	ID        int64  `bun:"id,pk,autoincrement" json:"id"`
+	ChannelID string `bun:"channel_id" json:"channel_id"`
+	ThreadTS  string `bun:"thread_ts" json:"thread_ts"`
+	TS        string `bun:"ts" json:"ts"`
+	Role      string `bun:"role" json:"role"`
+	UserID    string `bun:"user_id" json:"user_id"`
+	Text      string `bun:"text" json:"text"`
+	// TextEncrypted は Text が encryption.key で暗号化されているかどうか
+	TextEncrypted bool      `bun:"text_encrypted" json:"text_encrypted"`
+	PostedAt      time.Time `bun:"posted_at" json:"posted_at"`
+	CreatedAt     time.Time `bun:"created_at" json:"created_at"`
+}
+
+func NewConversationMessage(message *slack.ConversationMessage) *ConversationMessage {
+	return &ConversationMessage{
+		ChannelID: string(message.ChannelID),
+		ThreadTS:  string(message.ThreadTS),
+		TS:        message.TS,
+		Role:      string(message.Role),
+		UserID:    string(message.UserID),
+		Text:      string(message.Text),
+		PostedAt:  message.PostedAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (m *ConversationMessage) ToModel() slack.ConversationMessage {
+	return slack.ConversationMessage{
+		ChannelID: slack.ChannelID(m.ChannelID),
+		ThreadTS:  slack.ThreadTS(m.ThreadTS),
+		TS:        m.TS,
+		Role:      slack.ConversationRole(m.Role),
+		UserID:    slack.UserID(m.UserID),
+		Text:      slack.Text(m.Text),
+		PostedAt:  m.PostedAt,
+	}
+}
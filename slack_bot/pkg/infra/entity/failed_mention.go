@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/uptrace/bun"
+)
+
+// FailedMention はキューに送信できなかったメッセージ（デッドレター）
+// replay コマンドで再送するまで保存しておき、再送できたものは ReplayedAt を設定する
+type FailedMention struct {
+	bun.BaseModel `bun:"table:failed_mentions"`
+
+	ID            int64           `bun:"id,pk,autoincrement" json:"id"`
+	MentionID     dbtypes.ULID    `bun:"mention_id,type:char(26)" json:"mention_id"`
+	CorrelationID string          `bun:"correlation_id" json:"correlation_id"`
+	QueueKey      string          `bun:"queue_key" json:"queue_key"`
+	Payload       json.RawMessage `bun:"payload,type:json" json:"payload"`
+	// PayloadEncrypted は Payload が encryption.key で暗号化されているかどうか
+	PayloadEncrypted bool      `bun:"payload_encrypted" json:"payload_encrypted"`
+	Reason           string    `bun:"reason" json:"reason"`
+	Attempts         int       `bun:"attempts" json:"attempts"`
+	FailedAt         time.Time `bun:"failed_at" json:"failed_at"`
+	ReplayedAt       time.Time `bun:"replayed_at,nullzero" json:"replayed_at"`
+	CreatedAt        time.Time `bun:"created_at" json:"created_at"`
+	UpdatedAt        time.Time `bun:"updated_at" json:"updated_at"`
+}
+
+func NewFailedMention(mentionID ulid.ULID, correlationID string, queueKey string, payload json.RawMessage, reason string) *FailedMention {
+	now := time.Now()
+	return &FailedMention{
+		MentionID:     dbtypes.ULID(mentionID),
+		CorrelationID: correlationID,
+		QueueKey:      queueKey,
+		Payload:       payload,
+		Reason:        reason,
+		Attempts:      1,
+		FailedAt:      now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
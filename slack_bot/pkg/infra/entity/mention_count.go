@@ -0,0 +1,8 @@
+package entity
+
+// MentionCount はチャンネルやユーザーごとに集計したメンション数
+// TargetID は集計の単位によってチャンネルIDまたはユーザーIDになる
+type MentionCount struct {
+	TargetID string `bun:"target_id" json:"target_id"`
+	Count    int    `bun:"count" json:"count"`
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/uptrace/bun"
+)
+
+const (
+	// OutboxStatusPending は送信待ち（再送待ちを含む）
+	OutboxStatusPending = "pending"
+	// OutboxStatusProcessing はいずれかのインスタンスが送信中
+	OutboxStatusProcessing = "processing"
+	// OutboxStatusSent は送信済み
+	OutboxStatusSent = "sent"
+)
+
+// OutboxMessage はキューへの送信待ちのメッセージ
+type OutboxMessage struct {
+	bun.BaseModel `bun:"table:outbox"`
+
+	ID            int64        `bun:"id,pk,autoincrement" json:"id"`
+	MentionID     dbtypes.ULID `bun:"mention_id,type:char(26)" json:"mention_id"`
+	CorrelationID string       `bun:"correlation_id" json:"correlation_id"`
+	// TraceParent はメンションを受信したスパンの traceparent（トレースが無効な場合は空）
+	TraceParent string          `bun:"traceparent" json:"traceparent"`
+	QueueKey    string          `bun:"queue_key" json:"queue_key"`
+	Payload     json.RawMessage `bun:"payload,type:json" json:"payload"`
+	// PayloadEncrypted は Payload が encryption.key で暗号化されているかどうか
+	PayloadEncrypted bool      `bun:"payload_encrypted" json:"payload_encrypted"`
+	Status           string    `bun:"status" json:"status"`
+	Attempts         int       `bun:"attempts" json:"attempts"`
+	NextAttemptAt    time.Time `bun:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil      time.Time `bun:"locked_until,nullzero" json:"locked_until"`
+	LastError        string    `bun:"last_error" json:"last_error"`
+	SentAt           time.Time `bun:"sent_at,nullzero" json:"sent_at"`
+	CreatedAt        time.Time `bun:"created_at" json:"created_at"`
+	UpdatedAt        time.Time `bun:"updated_at" json:"updated_at"`
+}
+
+func NewOutboxMessage(mentionID ulid.ULID, correlationID string, queueKey string, payload json.RawMessage) *OutboxMessage {
+	now := time.Now()
+	return &OutboxMessage{
+		MentionID:     dbtypes.ULID(mentionID),
+		CorrelationID: correlationID,
+		QueueKey:      queueKey,
+		Payload:       payload,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
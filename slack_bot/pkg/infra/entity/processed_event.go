@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ProcessedEvent は処理を開始したSlackのイベント
+// Slackが再送したイベントを重複して処理しないよう、event_id などのキーを event_dedup.ttl の間保存する
+type ProcessedEvent struct {
+	bun.BaseModel `bun:"table:processed_events"`
+
+	EventKey    string    `bun:"event_key,pk" json:"event_key"`
+	ProcessedAt time.Time `bun:"processed_at" json:"processed_at"`
+}
@@ -3,45 +3,88 @@ package entity
 import (
 	"time"
 
-	"github.com/oklog/ulid/v2"
 	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
 )
 
 type SlackMention struct {
-	ID        ulid.ULID `bun:"id,pk,type:ulid"`
-	Type      string    `bun:"type"`
-	UserID    string    `bun:"user_id"`
-	ChannelID string    `bun:"channel_id"`
-	Text      string    `bun:"text"`
-	Timestamp time.Time `bun:"timestamp"`
-	EventTime time.Time `bun:"event_time"`
-	CreatedAt time.Time `bun:"created_at"`
-	UpdatedAt time.Time `bun:"updated_at"`
-	DeletedAt time.Time `bun:"deleted_at"`
+	ID            dbtypes.ULID `bun:"id,pk,type:char(26)" json:"id"`
+	Type          string       `bun:"type" json:"type"`
+	TeamID        string       `bun:"team_id" json:"team_id"`
+	Workspace     string       `bun:"workspace" json:"workspace"`
+	UserID        string       `bun:"user_id" json:"user_id"`
+	UserName      string       `bun:"user_name" json:"user_name"`
+	UserRealName  string       `bun:"user_real_name" json:"user_real_name"`
+	ChannelID     string       `bun:"channel_id" json:"channel_id"`
+	Text          string       `bun:"text" json:"text"`
+	TextTruncated bool         `bun:"text_truncated" json:"text_truncated"`
+	TextEncrypted bool         `bun:"text_encrypted" json:"text_encrypted"`
+	RawEvent      string       `bun:"raw_event,nullzero" json:"raw_event"`
+	SQSMessageID  string       `bun:"sqs_message_id,nullzero" json:"sqs_message_id"`
+	// ConversationID は会話の追跡が無効な場合は NULL
+	ConversationID dbtypes.ULID `bun:"conversation_id,type:char(26),nullzero" json:"conversation_id"`
+	// Status は処理の状態（slack.MentionStatus）、StatusDetail は送信に失敗した場合の理由
+	// Language, Persona はチャンネルに設定された回答の言語とペルソナ（設定がない場合は NULL）
+	Language     string    `bun:"language,nullzero" json:"language"`
+	Persona      string    `bun:"persona,nullzero" json:"persona"`
+	Status       string    `bun:"status" json:"status"`
+	StatusDetail string    `bun:"status_detail,nullzero" json:"status_detail"`
+	Timestamp    time.Time `bun:"timestamp" json:"timestamp"`
+	// TS, ThreadTS はSlackのタイムスタンプの文字列（この列を追加する前に保存したメンションは NULL）
+	TS        string    `bun:"ts,nullzero" json:"ts"`
+	ThreadTS  string    `bun:"thread_ts,nullzero" json:"thread_ts"`
+	EventTime time.Time `bun:"event_time" json:"event_time"`
+	CreatedAt time.Time `bun:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bun:"updated_at" json:"updated_at"`
+	DeletedAt time.Time `bun:"deleted_at,nullzero" json:"deleted_at"`
 }
 
 func NewSlackMention(mention *slack.Mention) (*SlackMention, error) {
 	return &SlackMention{
-		ID:        ulid.ULID(mention.ID),
-		Type:      "mention",
-		UserID:    string(mention.UserID),
-		ChannelID: string(mention.ChannelID),
-		Text:      string(mention.Text),
-		Timestamp: time.Time(mention.Timestamp),
-		EventTime: time.Time(mention.EventTime),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		DeletedAt: time.Time{},
+		ID:             dbtypes.ULID(mention.ID),
+		Type:           string(mention.Source),
+		TeamID:         string(mention.TeamID),
+		Workspace:      string(mention.Workspace),
+		UserID:         string(mention.UserID),
+		UserName:       string(mention.UserName),
+		UserRealName:   string(mention.UserRealName),
+		ChannelID:      string(mention.ChannelID),
+		Text:           string(mention.Text),
+		TextTruncated:  mention.TextTruncated,
+		RawEvent:       string(mention.RawEvent),
+		ConversationID: dbtypes.ULID(mention.ConversationID),
+		Language:       mention.Settings.Language,
+		Persona:        mention.Settings.Persona,
+		Status:         string(mention.Status),
+		Timestamp:      time.Time(mention.Timestamp),
+		TS:             string(mention.TS),
+		ThreadTS:       string(mention.ThreadTS),
+		EventTime:      time.Time(mention.EventTime),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		DeletedAt:      time.Time{},
 	}, nil
 }
 
 func (m *SlackMention) ToModel() *slack.Mention {
 	return &slack.Mention{
-		ID:        slack.MentionID(m.ID),
-		UserID:    slack.UserID(m.UserID),
-		ChannelID: slack.ChannelID(m.ChannelID),
-		Text:      slack.Text(m.Text),
-		Timestamp: slack.Timestamp(m.Timestamp),
-		EventTime: slack.EventTime(m.EventTime),
+		ID:             slack.MentionID(m.ID),
+		Source:         slack.MessageSource(m.Type),
+		TeamID:         slack.TeamID(m.TeamID),
+		Workspace:      slack.Workspace(m.Workspace),
+		UserID:         slack.UserID(m.UserID),
+		UserName:       slack.UserName(m.UserName),
+		UserRealName:   slack.UserName(m.UserRealName),
+		ChannelID:      slack.ChannelID(m.ChannelID),
+		Text:           slack.Text(m.Text),
+		TextTruncated:  m.TextTruncated,
+		RawEvent:       slack.RawEvent(m.RawEvent),
+		ConversationID: slack.ConversationID(m.ConversationID),
+		Settings:       slack.ChannelSettings{Language: m.Language, Persona: m.Persona},
+		Status:         slack.MentionStatus(m.Status),
+		Timestamp:      slack.Timestamp(m.Timestamp),
+		TS:             slack.SlackTS(m.TS),
+		ThreadTS:       slack.SlackTS(m.ThreadTS),
+		EventTime:      slack.EventTime(m.EventTime),
 	}
 }
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+)
+
+type UserSetting struct {
+	UserID    string    `bun:"user_id,pk" json:"user_id"`
+	Lang      string    `bun:"lang" json:"lang"`
+	CreatedAt time.Time `bun:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bun:"updated_at" json:"updated_at"`
+}
+
+func NewUserSetting(setting *slack.UserSetting) (*UserSetting, error) {
+	return &UserSetting{
+		UserID:    string(setting.UserID),
+		Lang:      string(setting.Lang),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+func (s *UserSetting) ToModel() *slack.UserSetting {
+	return &slack.UserSetting{
+		UserID: slack.UserID(s.UserID),
+		Lang:   slack.Language(s.Lang),
+	}
+}
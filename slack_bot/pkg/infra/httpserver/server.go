@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"go.uber.org/fx"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// NewServeMux は運用向けのエンドポイントを登録するServeMuxを作成する
+// 各モジュールはfx.Invokeでハンドラを登録し、サーバーは起動時にまとめて公開する
+func NewServeMux() *http.ServeMux {
+	return http.NewServeMux()
+}
+
+// Start はHTTPサーバーが有効な場合にライフサイクルに合わせて起動・停止する
+func Start(lc fx.Lifecycle, cfg *config.AppConfig, mux *http.ServeMux) {
+	if !cfg.HTTPServer.Enabled {
+		return
+	}
+
+	server := &http.Server{
+		Addr:              cfg.HTTPServer.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// ポートを確保できない場合は起動を失敗させる
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			logger.Printf(ctx, "HTTPサーバーを起動しました: %s", server.Addr)
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Errorf(context.Background(), "HTTPサーバーエラー: %v", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+			defer cancel()
+			return server.Shutdown(ctx)
+		},
+	})
+}
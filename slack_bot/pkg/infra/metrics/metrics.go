@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "slack_bot"
+
+// Metrics はBotの処理状況を表すPrometheusのメトリクス
+// イベントの処理に関するメトリクスはワークスペースの名前を workspace ラベルとして付与する
+type Metrics struct {
+	EventsReceived   *prometheus.CounterVec
+	MentionsEnqueued *prometheus.CounterVec
+	EnqueueFailures  *prometheus.CounterVec
+	AutoReplies      *prometheus.CounterVec
+	// EventsDeduplicated はSlackが再送した処理済みのイベントとして無視したイベント数
+	EventsDeduplicated *prometheus.CounterVec
+	SQSSendDuration    prometheus.Histogram
+	DBWriteDuration    prometheus.Histogram
+	// SocketModeFailures はSocket Modeの接続が連続して失敗している回数（接続が安定すると0に戻る）
+	SocketModeFailures *prometheus.GaugeVec
+	// Panics はイベントの処理中に発生して復帰したパニックの数
+	Panics *prometheus.CounterVec
+	// AccessDenied はアクセス制御により拒否したイベント数（reason は拒否した理由）
+	AccessDenied *prometheus.CounterVec
+	// OutboxPending はアウトボックスの未送信（送信中を含む）のメッセージ数で、送信処理のたびに更新する
+	OutboxPending prometheus.Gauge
+}
+
+// NewRegistry はGoランタイムとプロセスのメトリクスを登録したレジストリを作成する
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+func NewMetrics(registry *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		EventsReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_received_total",
+			Help:      "Socket Modeで受信したイベント数",
+		}, []string{"workspace", "type"}),
+		MentionsEnqueued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mentions_enqueued_total",
+			Help:      "キューに送信したメンション数",
+		}, []string{"workspace"}),
+		EnqueueFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "enqueue_failures_total",
+			Help:      "キューへの送信に失敗した数",
+		}, []string{"workspace"}),
+		AutoReplies: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auto_replies_total",
+			Help:      "キューに送信せずに自動返信したメンション数",
+		}, []string{"workspace", "rule"}),
+		EventsDeduplicated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_deduplicated_total",
+			Help:      "処理済みのため無視した再送されたイベント数",
+		}, []string{"workspace", "type"}),
+		SQSSendDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "sqs_send_duration_seconds",
+			Help:      "SQSへのメッセージ送信にかかった時間",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		DBWriteDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_write_duration_seconds",
+			Help:      "データベースへの書き込みにかかった時間",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		SocketModeFailures: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "socket_mode_consecutive_failures",
+			Help:      "Socket Modeの接続が連続して失敗している回数",
+		}, []string{"workspace"}),
+		Panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "panics_total",
+			Help:      "イベントの処理中に発生したパニックの数",
+		}, []string{"workspace", "type"}),
+		AccessDenied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "access_denied_total",
+			Help:      "アクセス制御により拒否したイベント数",
+		}, []string{"workspace", "reason"}),
+		OutboxPending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "outbox_pending",
+			Help:      "アウトボックスの未送信のメッセージ数",
+		}),
+	}
+
+	registry.MustRegister(
+		m.EventsReceived,
+		m.MentionsEnqueued,
+		m.EnqueueFailures,
+		m.AutoReplies,
+		m.EventsDeduplicated,
+		m.SQSSendDuration,
+		m.DBWriteDuration,
+		m.SocketModeFailures,
+		m.Panics,
+		m.AccessDenied,
+		m.OutboxPending,
+	)
+	return m
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"go.uber.org/fx"
+)
+
+// pushTimeout は1回の送信のタイムアウト
+const pushTimeout = 10 * time.Second
+
+// StartPusher はメトリクスを一定間隔でPushgatewayに送信する
+// スクレイプできない環境向けで、無効な場合は何もしない
+// 複数のインスタンスの値が上書きされないよう、ホスト名を instance ラベルとして付与する
+func StartPusher(lc fx.Lifecycle, cfg *config.AppConfig, registry *prometheus.Registry) {
+	pushCfg := cfg.Pushgateway
+	if !pushCfg.Enabled {
+		return
+	}
+
+	pusher := push.New(pushCfg.URL, pushCfg.Job).Gatherer(registry)
+	if hostname, err := os.Hostname(); err == nil {
+		pusher = pusher.Grouping("instance", hostname)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			ticker := time.NewTicker(pushCfg.Interval)
+			go func() {
+				defer close(done)
+				defer ticker.Stop()
+				runPusher(ctx, pusher, ticker.C)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			<-done
+			// 停止直前の値を送信しておく
+			pushMetrics(stopCtx, pusher)
+			return nil
+		},
+	})
+}
+
+// runPusher は ctx が終了するまで tick を受信するたびにメトリクスを送信する
+func runPusher(ctx context.Context, pusher *push.Pusher, tick <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			pushMetrics(ctx, pusher)
+		}
+	}
+}
+
+func pushMetrics(ctx context.Context, pusher *push.Pusher) {
+	ctx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+	if err := pusher.PushContext(ctx); err != nil {
+		logger.Errorf(ctx, "Pushgatewayへのメトリクスの送信エラー: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"go.uber.org/fx/fxtest"
+)
+
+// fakePushgateway は受信したメトリクスの送信を記録するPushgateway
+type fakePushgateway struct {
+	mu       sync.Mutex
+	paths    []string
+	bodies   [][]byte
+	received chan struct{}
+}
+
+func newFakePushgateway(t *testing.T) (*fakePushgateway, string) {
+	t.Helper()
+	gateway := &fakePushgateway{received: make(chan struct{}, 10)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gateway.mu.Lock()
+		gateway.paths = append(gateway.paths, r.Method+" "+r.URL.Path)
+		gateway.bodies = append(gateway.bodies, body)
+		gateway.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		select {
+		case gateway.received <- struct{}{}:
+		default:
+		}
+	}))
+	t.Cleanup(server.Close)
+	return gateway, server.URL
+}
+
+func (g *fakePushgateway) count() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.paths)
+}
+
+// waitPush は送信を1回受信するまで待つ
+func (g *fakePushgateway) waitPush(t *testing.T) {
+	t.Helper()
+	select {
+	case <-g.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Pushgatewayへの送信を待てませんでした")
+	}
+}
+
+func newTestRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "slackbot_test_pushed_total"})
+	counter.Inc()
+	registry.MustRegister(counter)
+	return registry
+}
+
+// tick を受信するたびにメトリクスを送信し、ctx が終了したら止める
+func TestRunPusher(t *testing.T) {
+	gateway, url := newFakePushgateway(t)
+	pusher := push.New(url, "slackbot").Gatherer(newTestRegistry())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tick := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runPusher(ctx, pusher, tick)
+	}()
+
+	for range 2 {
+		tick <- time.Now()
+		gateway.waitPush(t)
+	}
+	cancel()
+	<-done
+
+	if got := gateway.count(); got != 2 {
+		t.Fatalf("送信回数 = %d, want 2", got)
+	}
+	for i, path := range gateway.paths {
+		if path != "PUT /metrics/job/slackbot" {
+			t.Errorf("送信先[%d] = %q, want %q", i, path, "PUT /metrics/job/slackbot")
+		}
+		if !bytes.Contains(gateway.bodies[i], []byte("slackbot_test_pushed_total")) {
+			t.Errorf("送信したメトリクス[%d] に slackbot_test_pushed_total が含まれていません", i)
+		}
+	}
+}
+
+func TestStartPusher(t *testing.T) {
+	t.Run("停止時に停止直前の値を送信する", func(t *testing.T) {
+		gateway, url := newFakePushgateway(t)
+		lc := fxtest.NewLifecycle(t)
+		// 間隔による送信と区別できるよう、間隔はテスト中に経過しない長さにする
+		cfg := &config.AppConfig{Pushgateway: config.PushgatewayConfig{Enabled: true, URL: url, Job: "slackbot", Interval: time.Hour}}
+		StartPusher(lc, cfg, newTestRegistry())
+
+		lc.RequireStart()
+		if got := gateway.count(); got != 0 {
+			t.Fatalf("起動時の送信回数 = %d, want 0", got)
+		}
+		lc.RequireStop()
+
+		if got := gateway.count(); got != 1 {
+			t.Fatalf("停止時の送信回数 = %d, want 1", got)
+		}
+		if path := gateway.paths[0]; !strings.HasPrefix(path, "PUT /metrics/job/slackbot/instance/") {
+			t.Errorf("送信先 = %q, want instance ラベルを含む", path)
+		}
+	})
+
+	t.Run("設定した間隔で送信する", func(t *testing.T) {
+		gateway, url := newFakePushgateway(t)
+		lc := fxtest.NewLifecycle(t)
+		cfg := &config.AppConfig{Pushgateway: config.PushgatewayConfig{Enabled: true, URL: url, Job: "slackbot", Interval: 10 * time.Millisecond}}
+		StartPusher(lc, cfg, newTestRegistry())
+
+		lc.RequireStart()
+		gateway.waitPush(t)
+		lc.RequireStop()
+	})
+
+	t.Run("無効な場合は送信しない", func(t *testing.T) {
+		gateway, url := newFakePushgateway(t)
+		lc := fxtest.NewLifecycle(t)
+		cfg := &config.AppConfig{Pushgateway: config.PushgatewayConfig{Enabled: false, URL: url, Job: "slackbot", Interval: time.Millisecond}}
+		StartPusher(lc, cfg, newTestRegistry())
+
+		lc.RequireStart()
+		time.Sleep(20 * time.Millisecond)
+		lc.RequireStop()
+
+		if got := gateway.count(); got != 0 {
+			t.Errorf("送信回数 = %d, want 0", got)
+		}
+	})
+}
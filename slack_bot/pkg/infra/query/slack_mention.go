@@ -0,0 +1,274 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/crypto"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+)
+
+// streamPageSize は Stream で1回のクエリで取得する件数
+const streamPageSize = 500
+
+// MaxLatestByUserLimit は FindLatestByUser で取得できる件数の上限
+const MaxLatestByUserLimit = 100
+
+// SlackMentionQuery はデータベースからメンションを読み取る
+// encryption.key が設定されている場合は取得したテキストを復号する
+// メンションがない場合は sql.ErrNoRows を返す
+type SlackMentionQuery struct {
+	db     *bun.DB
+	cipher *crypto.TextCipher
+}
+
+func NewSlackMentionQuery(db *bun.DB, cipher *crypto.TextCipher) di.SlackMentionQuery {
+	return &SlackMentionQuery{db: db, cipher: cipher}
+}
+
+func (r *SlackMentionQuery) FindByID(ctx context.Context, id slack.MentionID) (*entity.SlackMention, error) {
+	var mention entity.SlackMention
+	if err := r.db.NewSelect().Model(&mention).Where("id = ?", dbtypes.ULID(id)).Scan(ctx); err != nil {
+		return &mention, err
+	}
+	return &mention, r.cipher.DecryptMention(&mention)
+}
+
+func (r *SlackMentionQuery) FindRawEventByID(ctx context.Context, id slack.MentionID, v any) error {
+	var mention entity.SlackMention
+	err := r.db.NewSelect().Model(&mention).Column("id", "text", "text_encrypted", "raw_event").Where("id = ?", dbtypes.ULID(id)).Scan(ctx)
+	if err != nil {
+		return err
+	}
+	if err := r.cipher.DecryptMention(&mention); err != nil {
+		return err
+	}
+	if mention.RawEvent == "" {
+		return fmt.Errorf("メンション %s の受信イベントは保存されていません", id)
+	}
+	if err := json.Unmarshal([]byte(mention.RawEvent), v); err != nil {
+		return fmt.Errorf("受信イベントのデコードエラー (id=%s): %w", id, err)
+	}
+	return nil
+}
+
+func (r *SlackMentionQuery) FindCreatedBefore(ctx context.Context, before time.Time, limit int) ([]*entity.SlackMention, error) {
+	var mentions []*entity.SlackMention
+	err := r.db.NewSelect().
+		Model(&mentions).
+		Where("created_at < ?", before).
+		Order("created_at ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mentions, r.cipher.DecryptMentions(mentions)
+}
+
+// ListByUser はユーザーの削除されていないメンションを新しい順（同じ日時の場合はIDの降順）に取得する
+func (r *SlackMentionQuery) ListByUser(ctx context.Context, userID string, limit int) ([]*entity.SlackMention, error) {
+	var mentions []*entity.SlackMention
+	err := r.db.NewSelect().
+		Model(&mentions).
+		Where("user_id = ?", userID).
+		Where("deleted_at IS NULL").
+		Order("event_time DESC", "id DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mentions, r.cipher.DecryptMentions(mentions)
+}
+
+// FindLatestByUser はユーザーの削除されていないメンションを新しい順（同じ日時の場合はIDの降順）に取得する
+// limit が上限を超える場合は上限の件数を取得する
+func (r *SlackMentionQuery) FindLatestByUser(ctx context.Context, userID string, limit int) ([]*entity.SlackMention, error) {
+	if limit <= 0 || limit > MaxLatestByUserLimit {
+		limit = MaxLatestByUserLimit
+	}
+
+	var mentions []*entity.SlackMention
+	err := r.db.NewSelect().
+		Model(&mentions).
+		Where("user_id = ?", userID).
+		Where("deleted_at IS NULL").
+		Order("event_time DESC", "id DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mentions, r.cipher.DecryptMentions(mentions)
+}
+
+func (r *SlackMentionQuery) CountByUser(ctx context.Context, userID string) (int, error) {
+	return r.db.NewSelect().
+		Model((*entity.SlackMention)(nil)).
+		Where("user_id = ?", userID).
+		Where("deleted_at IS NULL").
+		Count(ctx)
+}
+
+func (r *SlackMentionQuery) CountByChannel(ctx context.Context, channelID string) (int, error) {
+	return r.db.NewSelect().
+		Model((*entity.SlackMention)(nil)).
+		Where("channel_id = ?", channelID).
+		Where("deleted_at IS NULL").
+		Count(ctx)
+}
+
+func (r *SlackMentionQuery) CountSince(ctx context.Context, since time.Time) (int, error) {
+	return r.db.NewSelect().
+		Model((*entity.SlackMention)(nil)).
+		Where("event_time >= ?", since).
+		Where("deleted_at IS NULL").
+		Count(ctx)
+}
+
+// FindByEventTimeRange は期間内のメンションを event_time の昇順（同じ日時の場合はIDの昇順）に取得する
+func (r *SlackMentionQuery) FindByEventTimeRange(ctx context.Context, from, to time.Time, limit int) ([]*entity.SlackMention, error) {
+	if from.After(to) {
+		return nil, fmt.Errorf("期間の指定が不正です。開始日時 (%s) が終了日時 (%s) より後になっています", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	var mentions []*entity.SlackMention
+	err := r.db.NewSelect().
+		Model(&mentions).
+		Where("event_time >= ?", from).
+		Where("event_time <= ?", to).
+		Where("deleted_at IS NULL").
+		Order("event_time ASC", "id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mentions, r.cipher.DecryptMentions(mentions)
+}
+
+func (r *SlackMentionQuery) CountGroupedByChannelBetween(ctx context.Context, from, to time.Time) ([]*entity.MentionCount, error) {
+	return r.countGroupedBetween(ctx, "channel_id", from, to, 0)
+}
+
+func (r *SlackMentionQuery) CountGroupedByUserBetween(ctx context.Context, from, to time.Time, limit int) ([]*entity.MentionCount, error) {
+	return r.countGroupedBetween(ctx, "user_id", from, to, limit)
+}
+
+// countGroupedBetween は期間内のメンション数を column ごとに集計する
+// limit が 0 の場合はすべての集計結果を返す
+func (r *SlackMentionQuery) countGroupedBetween(ctx context.Context, column string, from, to time.Time, limit int) ([]*entity.MentionCount, error) {
+	var counts []*entity.MentionCount
+	q := r.db.NewSelect().
+		Model((*entity.SlackMention)(nil)).
+		ColumnExpr("? AS target_id", bun.Ident(column)).
+		ColumnExpr("COUNT(*) AS count").
+		Where("event_time >= ?", from).
+		Where("event_time < ?", to).
+		Where("deleted_at IS NULL").
+		GroupExpr("?", bun.Ident(column)).
+		OrderExpr("count DESC").
+		OrderExpr("? ASC", bun.Ident(column))
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	err := q.Scan(ctx, &counts)
+	return counts, err
+}
+
+// ListBetween は期間内のメンションをIDの順に取得する
+// IDはULIDで作成順に並ぶため、カーソルには最後に取得したメンションのIDを使用する
+func (r *SlackMentionQuery) ListBetween(ctx context.Context, from, to time.Time, cursor string, limit int) ([]*entity.SlackMention, string, error) {
+	q := r.db.NewSelect().
+		Model((*entity.SlackMention)(nil)).
+		Where("event_time >= ?", from).
+		Where("event_time < ?", to).
+		Where("deleted_at IS NULL")
+	if cursor != "" {
+		after, err := ulid.ParseStrict(cursor)
+		if err != nil {
+			return nil, "", fmt.Errorf("カーソルが不正です: %q", cursor)
+		}
+		q = q.Where("id > ?", dbtypes.ULID(after))
+	}
+
+	var mentions []*entity.SlackMention
+	if err := q.Order("id ASC").Limit(limit).Scan(ctx, &mentions); err != nil {
+		return nil, "", err
+	}
+	if err := r.cipher.DecryptMentions(mentions); err != nil {
+		return nil, "", err
+	}
+	return mentions, NextMentionCursor(mentions, limit), nil
+}
+
+// NextMentionCursor は limit 件取得できた場合に最後のメンションのIDを次のカーソルとして返す
+func NextMentionCursor(mentions []*entity.SlackMention, limit int) string {
+	if limit <= 0 || len(mentions) < limit {
+		return ""
+	}
+	return mentions[len(mentions)-1].ID.String()
+}
+
+func (r *SlackMentionQuery) Stream(ctx context.Context, filter di.SlackMentionFilter) (<-chan *entity.SlackMention, <-chan error) {
+	mentions := make(chan *entity.SlackMention)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(mentions)
+		defer close(errs)
+
+		// IDはULIDで作成順に並ぶため、最後に取得したIDより後ろを取得してページを進める
+		var lastID dbtypes.ULID
+		for {
+			var page []*entity.SlackMention
+			q := r.db.NewSelect().Model(&page)
+			if filter.UserID != "" {
+				q = q.Where("user_id = ?", filter.UserID)
+			}
+			if filter.ChannelID != "" {
+				q = q.Where("channel_id = ?", filter.ChannelID)
+			}
+			if !filter.CreatedAfter.IsZero() {
+				q = q.Where("created_at >= ?", filter.CreatedAfter)
+			}
+			if !filter.CreatedBefore.IsZero() {
+				q = q.Where("created_at < ?", filter.CreatedBefore)
+			}
+			if !lastID.IsZero() {
+				q = q.Where("id > ?", lastID)
+			}
+			if err := q.Order("id ASC").Limit(streamPageSize).Scan(ctx); err != nil {
+				errs <- err
+				return
+			}
+
+			for _, m := range page {
+				if err := r.cipher.DecryptMention(m); err != nil {
+					errs <- err
+					return
+				}
+				select {
+				case mentions <- m:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+			if len(page) < streamPageSize {
+				return
+			}
+			lastID = page[len(page)-1].ID
+		}
+	}()
+
+	return mentions, errs
+}
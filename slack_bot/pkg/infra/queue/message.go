@@ -0,0 +1,30 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+)
+
+// correlationIDAttribute は相関IDを付与するメッセージ属性（ヘッダー・フィールド）の名前
+const correlationIDAttribute = "correlation_id"
+
+// encodeMessage は msg をキューに送信するJSONに変換する
+// Validate() を持つメッセージは変換前に検証し、キューに送信できるサイズを超えている場合は *queuemodel.MessageTooLargeError を返す
+// AIワーカーがバックエンドに関わらず同じメッセージを扱えるよう、サイズの上限はSQSに合わせる
+func encodeMessage(msg any) ([]byte, error) {
+	if v, ok := msg.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("メッセージの検証エラー: %w", err)
+		}
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("JSONエンコードエラー: %w", err)
+	}
+	if len(body) > queuemodel.MaxMessageSize {
+		return nil, &queuemodel.MessageTooLargeError{Size: len(body)}
+	}
+	return body, nil
+}
@@ -0,0 +1,184 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"go.uber.org/fx"
+)
+
+// errNATSBacklogUnsupported はCore NATSで送信している場合に Backlog を呼び出した場合のエラー
+var errNATSBacklogUnsupported = errors.New("Core NATSはメッセージを保持しないため件数を取得できません (queue.nats.jetstream)")
+
+// NATSPublisher はNATSにメッセージを送信する
+// キュー名（elasticmq.queue_name / elasticmq.queues）に queue.nats.subject_prefix を付けたサブジェクトに送信し、
+// 相関IDはヘッダー correlation_id に付与する
+// queue.nats.jetstream の場合はサブジェクトを含むJetStreamのストリームへの保存の確認を待つ（ストリームは事前に作成しておく）
+type NATSPublisher struct {
+	conn      *nats.Conn
+	js        jetstream.JetStream
+	cfg       config.NATSQueueConfig
+	names     config.ElasticMQConfig
+	queueKeys []string
+}
+
+func NewNATSPublisher(lc fx.Lifecycle, cfg *config.AppConfig) (di.QueuePublisher, error) {
+	conn, err := nats.Connect(cfg.Queue.NATS.URL,
+		nats.Name("slack-bot"),
+		nats.Timeout(cfg.Queue.NATS.ConnectTimeout),
+		// 起動後に切断された場合は送信時にエラーを返しつつ再接続を続ける
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("NATSへの接続エラー (url=%s): %w", cfg.Queue.NATS.URL, err)
+	}
+	p := &NATSPublisher{
+		conn:      conn,
+		cfg:       cfg.Queue.NATS,
+		names:     cfg.ElasticMQ,
+		queueKeys: cfg.QueueKeys(),
+	}
+	if cfg.Queue.NATS.JetStream {
+		p.js, err = jetstream.New(conn)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("JetStreamの初期化エラー: %w", err)
+		}
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return p.Close()
+		},
+	})
+	return p, nil
+}
+
+// PublishTo は queueKey に対応するサブジェクトに msg をJSONとして送信する
+func (p *NATSPublisher) PublishTo(ctx context.Context, queueKey string, msg any) error {
+	_, err := p.PublishWithID(ctx, queueKey, msg)
+	return err
+}
+
+// PublishWithID は PublishTo と同様に送信し、JetStreamの場合は保存先のストリームとシーケンス番号（"stream:sequence" 形式）を返す
+// Core NATSはメッセージIDを発行しないため空文字列を返す
+func (p *NATSPublisher) PublishWithID(ctx context.Context, queueKey string, msg any) (string, error) {
+	body, err := encodeMessage(msg)
+	if err != nil {
+		return "", err
+	}
+	subject, err := p.subject(queueKey)
+	if err != nil {
+		return "", err
+	}
+
+	m := nats.NewMsg(subject)
+	m.Data = body
+	if correlationID := logger.CorrelationID(ctx); correlationID != "" {
+		m.Header.Set(correlationIDAttribute, correlationID)
+	}
+
+	if p.js != nil {
+		ack, err := p.js.PublishMsg(ctx, m)
+		if err != nil {
+			return "", fmt.Errorf("JetStreamへの送信エラー (subject=%s): %w", subject, err)
+		}
+		return fmt.Sprintf("%s:%d", ack.Stream, ack.Sequence), nil
+	}
+
+	if err := p.conn.PublishMsg(m); err != nil {
+		return "", fmt.Errorf("NATSへの送信エラー (subject=%s): %w", subject, err)
+	}
+	// サーバーが受け取ったことを確認してから送信済みとする
+	if err := p.flush(ctx); err != nil {
+		return "", fmt.Errorf("NATSへの送信の確認エラー (subject=%s): %w", subject, err)
+	}
+	return "", nil
+}
+
+// Check はNATSに接続できるかを確認する
+// JetStreamの場合はすべてのサブジェクトを保存するストリームが作成されているかも確認する
+func (p *NATSPublisher) Check(ctx context.Context) error {
+	if !p.conn.IsConnected() {
+		return fmt.Errorf("NATSに接続されていません (url=%s status=%s)", p.cfg.URL, p.conn.Status())
+	}
+	if err := p.flush(ctx); err != nil {
+		return fmt.Errorf("NATSへの接続エラー (url=%s): %w", p.cfg.URL, err)
+	}
+	for _, key := range p.queueKeys {
+		subject, err := p.subject(key)
+		if err != nil {
+			return err
+		}
+		if p.js == nil {
+			continue
+		}
+		if _, err := p.js.StreamNameBySubject(ctx, subject); err != nil {
+			return fmt.Errorf("サブジェクトを保存するJetStreamのストリームの取得エラー (subject=%s): %w", subject, err)
+		}
+	}
+	return nil
+}
+
+// Backlog はサブジェクトを保存するJetStreamのストリームのメッセージ数を返す
+// ストリームに他のサブジェクトが含まれる場合はその件数も含む。Core NATSの場合は取得できない
+func (p *NATSPublisher) Backlog(ctx context.Context, queueKey string) (int, error) {
+	if p.js == nil {
+		return 0, errNATSBacklogUnsupported
+	}
+	subject, err := p.subject(queueKey)
+	if err != nil {
+		return 0, err
+	}
+	name, err := p.js.StreamNameBySubject(ctx, subject)
+	if err != nil {
+		return 0, fmt.Errorf("サブジェクトを保存するJetStreamのストリームの取得エラー (subject=%s): %w", subject, err)
+	}
+	stream, err := p.js.Stream(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("JetStreamのストリームの取得エラー (stream=%s): %w", name, err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("JetStreamのストリームの情報の取得エラー (stream=%s): %w", name, err)
+	}
+	return int(info.State.Msgs), nil
+}
+
+// Close は送信済みのメッセージをサーバーに届けてから接続を閉じる
+func (p *NATSPublisher) Close() error {
+	if p.conn.IsClosed() {
+		return nil
+	}
+	err := p.conn.Flush()
+	p.conn.Close()
+	if err != nil {
+		return fmt.Errorf("NATSへの送信の確認エラー: %w", err)
+	}
+	return nil
+}
+
+// flush はサーバーが送信済みのメッセージを受け取るまで待つ
+// FlushWithContext は期限のないコンテキストではエラーになるため、その場合は queue.nats.connect_timeout まで待つ
+func (p *NATSPublisher) flush(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.ConnectTimeout)
+		defer cancel()
+	}
+	return p.conn.FlushWithContext(ctx)
+}
+
+// subject はキーに対応するサブジェクトを返す
+func (p *NATSPublisher) subject(queueKey string) (string, error) {
+	name, ok := p.names.QueueNameFor(queueKey)
+	if !ok {
+		return "", &UnknownQueueError{Key: queueKey}
+	}
+	return p.cfg.SubjectPrefix + name, nil
+}
@@ -0,0 +1,252 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"go.uber.org/fx/fxtest"
+)
+
+// contractQueueName は契約テストで送信先にするキュー（mention のキーだけを設定する）
+const contractQueueName = "mentions"
+
+// receivedMessage はキューから取り出したメッセージの本文と相関ID
+type receivedMessage struct {
+	body          string
+	correlationID string
+}
+
+// publisherBackend は契約テストの対象の実装と、送信したメッセージを取り出す方法
+type publisherBackend struct {
+	name string
+	// setup はテストごとに新しいキューと QueuePublisher を作成し、キューのメッセージを送信順に返す関数とともに返す
+	setup func(t *testing.T, cfg *config.AppConfig) (di.QueuePublisher, func() []receivedMessage)
+}
+
+func newContractConfig() *config.AppConfig {
+	return &config.AppConfig{
+		ElasticMQ: config.ElasticMQConfig{Queues: map[string]string{config.QueueKeyMention: contractQueueName}},
+		Queue: config.QueueConfig{
+			Redis: config.RedisQueueConfig{StreamPrefix: "slackbot:"},
+			NATS:  config.NATSQueueConfig{SubjectPrefix: "slackbot.", JetStream: true, ConnectTimeout: 5 * time.Second},
+		},
+	}
+}
+
+var publisherBackends = []publisherBackend{
+	{
+		name: "sqs",
+		setup: func(t *testing.T, cfg *config.AppConfig) (di.QueuePublisher, func() []receivedMessage) {
+			client := newFakeSQSClient(contractQueueName)
+			p := NewSQSPublisherWithClient(fxtest.NewLifecycle(t), client, cfg, metrics.NewMetrics(prometheus.NewRegistry()))
+			return p, func() []receivedMessage {
+				var received []receivedMessage
+				for _, m := range client.messages(contractQueueName) {
+					r := receivedMessage{body: aws.StringValue(m.Body)}
+					if v, ok := m.MessageAttributes[correlationIDAttribute]; ok {
+						r.correlationID = aws.StringValue(v.StringValue)
+					}
+					received = append(received, r)
+				}
+				return received
+			}
+		},
+	},
+	{
+		name: "redis",
+		setup: func(t *testing.T, cfg *config.AppConfig) (di.QueuePublisher, func() []receivedMessage) {
+			server := miniredis.RunT(t)
+			cfg.Queue.Redis.Addr = server.Addr()
+			p, err := NewRedisPublisher(fxtest.NewLifecycle(t), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			t.Cleanup(func() { client.Close() })
+			return p, func() []receivedMessage {
+				entries, err := client.XRange(context.Background(), "slackbot:"+contractQueueName, "-", "+").Result()
+				if err != nil {
+					t.Fatal(err)
+				}
+				var received []receivedMessage
+				for _, e := range entries {
+					r := receivedMessage{body: e.Values[redisBodyField].(string)}
+					if v, ok := e.Values[correlationIDAttribute].(string); ok {
+						r.correlationID = v
+					}
+					received = append(received, r)
+				}
+				return received
+			}
+		},
+	},
+	{
+		name: "nats",
+		setup: func(t *testing.T, cfg *config.AppConfig) (di.QueuePublisher, func() []receivedMessage) {
+			ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoLog: true, NoSigs: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			go ns.Start()
+			t.Cleanup(ns.Shutdown)
+			if !ns.ReadyForConnections(5 * time.Second) {
+				t.Fatal("NATSのサーバーが起動しませんでした")
+			}
+			cfg.Queue.NATS.URL = ns.ClientURL()
+			p, err := NewNATSPublisher(fxtest.NewLifecycle(t), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			js := p.(*NATSPublisher).js
+			stream, err := js.CreateStream(context.Background(), jetstream.StreamConfig{Name: "SLACKBOT", Subjects: []string{"slackbot.>"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return p, func() []receivedMessage {
+				info, err := stream.Info(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				var received []receivedMessage
+				for seq := info.State.FirstSeq; seq <= info.State.LastSeq && info.State.Msgs > 0; seq++ {
+					m, err := stream.GetMsg(context.Background(), seq)
+					if err != nil {
+						t.Fatal(err)
+					}
+					received = append(received, receivedMessage{body: string(m.Data), correlationID: m.Header.Get(correlationIDAttribute)})
+				}
+				return received
+			}
+		},
+	},
+}
+
+// すべてのバックエンドで、AIワーカーが同じ形式のメッセージを受け取れることを確認する
+func TestQueuePublisherContract(t *testing.T) {
+	for _, backend := range publisherBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			t.Run("メッセージのJSONと相関IDを送信順に届ける", func(t *testing.T) {
+				p, receive := backend.setup(t, newContractConfig())
+				ctx := logger.WithCorrelationID(context.Background(), "01HV7Z3Q8M5XK2N9P4R6T8W0YA")
+				messages := []map[string]string{{"text": "1件目"}, {"text": "2件目"}}
+				for _, msg := range messages {
+					if _, err := p.PublishWithID(ctx, config.QueueKeyMention, msg); err != nil {
+						t.Fatalf("PublishWithID() error = %v", err)
+					}
+				}
+
+				received := receive()
+				if len(received) != len(messages) {
+					t.Fatalf("受信したメッセージ = %d件, want %d件", len(received), len(messages))
+				}
+				for i, msg := range messages {
+					want, _ := json.Marshal(msg)
+					if received[i].body != string(want) {
+						t.Errorf("body[%d] = %s, want %s", i, received[i].body, want)
+					}
+					if received[i].correlationID != "01HV7Z3Q8M5XK2N9P4R6T8W0YA" {
+						t.Errorf("correlation_id[%d] = %q, want %q", i, received[i].correlationID, "01HV7Z3Q8M5XK2N9P4R6T8W0YA")
+					}
+				}
+
+				if err := p.Check(context.Background()); err != nil {
+					t.Errorf("Check() error = %v", err)
+				}
+				if backlog, err := p.Backlog(context.Background(), config.QueueKeyMention); err != nil || backlog != len(messages) {
+					t.Errorf("Backlog() = %d, %v, want %d, nil", backlog, err, len(messages))
+				}
+			})
+
+			t.Run("相関IDがない場合は付与しない", func(t *testing.T) {
+				p, receive := backend.setup(t, newContractConfig())
+				if err := p.PublishTo(context.Background(), config.QueueKeyMention, map[string]string{"text": "質問"}); err != nil {
+					t.Fatalf("PublishTo() error = %v", err)
+				}
+				if received := receive(); len(received) != 1 || received[0].correlationID != "" {
+					t.Errorf("受信したメッセージ = %+v, want 相関IDのない1件", received)
+				}
+			})
+
+			t.Run("設定されていないキーは UnknownQueueError を返す", func(t *testing.T) {
+				p, receive := backend.setup(t, newContractConfig())
+				_, err := p.PublishWithID(context.Background(), config.QueueKeyReaction, map[string]string{"text": "質問"})
+				var unknown *UnknownQueueError
+				if !errors.As(err, &unknown) || unknown.Key != config.QueueKeyReaction {
+					t.Errorf("PublishWithID() error = %v, want *UnknownQueueError", err)
+				}
+				if received := receive(); len(received) != 0 {
+					t.Errorf("受信したメッセージ = %d件, want 0件", len(received))
+				}
+			})
+
+			t.Run("上限を超えるメッセージは MessageTooLargeError を返す", func(t *testing.T) {
+				p, receive := backend.setup(t, newContractConfig())
+				msg := map[string]string{"text": strings.Repeat("a", queuemodel.MaxMessageSize)}
+				_, err := p.PublishWithID(context.Background(), config.QueueKeyMention, msg)
+				var tooLarge *queuemodel.MessageTooLargeError
+				if !errors.As(err, &tooLarge) {
+					t.Errorf("PublishWithID() error = %v, want *queuemodel.MessageTooLargeError", err)
+				}
+				if received := receive(); len(received) != 0 {
+					t.Errorf("受信したメッセージ = %d件, want 0件", len(received))
+				}
+			})
+		})
+	}
+}
+
+// Core NATSの場合も、期限のないコンテキストでサーバーへの到達を確認して送信する
+func TestNATSPublisherCoreWithoutDeadline(t *testing.T) {
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATSのサーバーが起動しませんでした")
+	}
+	cfg := newContractConfig()
+	cfg.Queue.NATS.URL = ns.ClientURL()
+	cfg.Queue.NATS.JetStream = false
+	p, err := NewNATSPublisher(fxtest.NewLifecycle(t), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := p.(*NATSPublisher).conn.SubscribeSync("slackbot." + contractQueueName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Check(context.Background()); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if id, err := p.PublishWithID(context.Background(), config.QueueKeyMention, map[string]string{"text": "質問"}); err != nil || id != "" {
+		t.Fatalf("PublishWithID() = %q, %v, want \"\", nil", id, err)
+	}
+	m, err := sub.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Data) != `{"text":"質問"}` {
+		t.Errorf("body = %s, want %s", m.Data, `{"text":"質問"}`)
+	}
+	if _, err := p.Backlog(context.Background(), config.QueueKeyMention); !errors.Is(err, errNATSBacklogUnsupported) {
+		t.Errorf("Backlog() error = %v, want %v", err, errNATSBacklogUnsupported)
+	}
+}
@@ -0,0 +1,125 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"go.uber.org/fx"
+)
+
+// redisBodyField はストリームのエントリでメッセージのJSONを格納するフィールド名
+const redisBodyField = "body"
+
+// RedisPublisher はRedis Streamsにメッセージを送信する
+// キュー名（elasticmq.queue_name / elasticmq.queues）に queue.redis.stream_prefix を付けたキーのストリームに XADD する
+// エントリの body フィールドにメッセージのJSON、correlation_id フィールドに相関IDを格納する
+type RedisPublisher struct {
+	client    *redis.Client
+	cfg       config.RedisQueueConfig
+	names     config.ElasticMQConfig
+	queueKeys []string
+}
+
+func NewRedisPublisher(lc fx.Lifecycle, cfg *config.AppConfig) (di.QueuePublisher, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Queue.Redis.Addr,
+		Username: cfg.Queue.Redis.Username,
+		Password: cfg.Queue.Redis.Password,
+		DB:       cfg.Queue.Redis.DB,
+	})
+	p := &RedisPublisher{
+		client:    client,
+		cfg:       cfg.Queue.Redis,
+		names:     cfg.ElasticMQ,
+		queueKeys: cfg.QueueKeys(),
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return p.Close()
+		},
+	})
+	return p, nil
+}
+
+// PublishTo は queueKey に対応するストリームに msg をJSONとして追加する
+func (p *RedisPublisher) PublishTo(ctx context.Context, queueKey string, msg any) error {
+	_, err := p.PublishWithID(ctx, queueKey, msg)
+	return err
+}
+
+// PublishWithID は PublishTo と同様に送信し、Redisが発行したエントリID（"1728000000000-0" 形式）を返す
+func (p *RedisPublisher) PublishWithID(ctx context.Context, queueKey string, msg any) (string, error) {
+	body, err := encodeMessage(msg)
+	if err != nil {
+		return "", err
+	}
+	stream, err := p.stream(queueKey)
+	if err != nil {
+		return "", err
+	}
+
+	values := map[string]any{redisBodyField: body}
+	if correlationID := logger.CorrelationID(ctx); correlationID != "" {
+		values[correlationIDAttribute] = correlationID
+	}
+	args := &redis.XAddArgs{
+		Stream: stream,
+		Values: values,
+	}
+	if p.cfg.MaxLen > 0 {
+		// 正確な長さに切り詰めると負荷が高いため、おおよその長さ（MAXLEN ~）で古いエントリを削除する
+		args.MaxLen = p.cfg.MaxLen
+		args.Approx = true
+	}
+	id, err := p.client.XAdd(ctx, args).Result()
+	if err != nil {
+		return "", fmt.Errorf("Redisのストリームへの送信エラー (stream=%s): %w", stream, err)
+	}
+	return id, nil
+}
+
+// Check はRedisに接続できるかを PING で確認する
+// ストリームは最初の XADD で作成されるため、存在は確認しない
+func (p *RedisPublisher) Check(ctx context.Context) error {
+	for _, key := range p.queueKeys {
+		if _, err := p.stream(key); err != nil {
+			return err
+		}
+	}
+	if err := p.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("Redisへの接続エラー (addr=%s): %w", p.cfg.Addr, err)
+	}
+	return nil
+}
+
+// Backlog はストリームのエントリ数（XLEN）を返す
+// ワーカーが処理済みのエントリを削除しない場合は処理待ちの件数より多くなる
+func (p *RedisPublisher) Backlog(ctx context.Context, queueKey string) (int, error) {
+	stream, err := p.stream(queueKey)
+	if err != nil {
+		return 0, err
+	}
+	count, err := p.client.XLen(ctx, stream).Result()
+	if err != nil {
+		return 0, fmt.Errorf("Redisのストリームの長さの取得エラー (stream=%s): %w", stream, err)
+	}
+	return int(count), nil
+}
+
+// Close はRedisへの接続を閉じる
+func (p *RedisPublisher) Close() error {
+	return p.client.Close()
+}
+
+// stream はキーに対応するストリームのキーを返す
+func (p *RedisPublisher) stream(queueKey string) (string, error) {
+	name, ok := p.names.QueueNameFor(queueKey)
+	if !ok {
+		return "", &UnknownQueueError{Key: queueKey}
+	}
+	return p.cfg.StreamPrefix + name, nil
+}
@@ -0,0 +1,33 @@
+package queue
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+)
+
+// NewSQSClient はElasticMQの設定からSQSクライアントを作成する
+func NewSQSClient(cfg config.ElasticMQConfig) (*sqs.SQS, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:   aws.String(cfg.Region),
+		Endpoint: aws.String(cfg.Endpoint),
+		Credentials: credentials.NewStaticCredentials(
+			cfg.AccessKey,
+			cfg.SecretKey,
+			"", // トークン
+		),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AWSセッション作成エラー: %w", err)
+	}
+	return sqs.New(sess), nil
+}
+
+// queueURL はElasticMQのキュー名からキューURLを組み立てる
+func queueURL(cfg config.ElasticMQConfig, queueName string) string {
+	return fmt.Sprintf("%s/queue/%s", cfg.Endpoint, queueName)
+}
@@ -0,0 +1,313 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+)
+
+// batchSendTimeout は1回の一括送信（失敗したエントリの再送を含む）のタイムアウト
+const batchSendTimeout = 10 * time.Second
+
+// maxBatchRetries は一部のエントリが失敗した場合に SendMessageBatch で再送する回数
+const maxBatchRetries = 2
+
+// maxBatchBytes は1回の SendMessageBatch で送信できるメッセージの合計サイズ（本文とメッセージ属性）の上限
+const maxBatchBytes = queuemodel.MaxMessageSize
+
+// errBatcherClosed は停止後に送信しようとした場合のエラー
+var errBatcherClosed = errors.New("一括送信は停止しています")
+
+// batchRequest は一括送信を待っている1件のメッセージ
+type batchRequest struct {
+	queueURL   string
+	body       string
+	attributes map[string]*sqs.MessageAttributeValue
+	// size は本文とメッセージ属性のサイズ（バイト）
+	size int
+	// result には送信結果が1回だけ送られる
+	result chan batchResult
+}
+
+// batchResult は1件のメッセージの送信結果
+type batchResult struct {
+	messageID string
+	err       error
+}
+
+// sqsBatcher は送信を待っているメッセージをキューごとに集め、SendMessageBatch でまとめて送信する
+// 件数が size に達したとき、合計サイズが maxBatchBytes を超えるとき、または最初のメッセージから flushInterval が経過したときに送信する
+// 送信結果はエントリIDでメッセージごとに対応付け、失敗したメッセージだけを再送する
+type sqsBatcher struct {
+	client        sqsiface.SQSAPI
+	metrics       *metrics.Metrics
+	size          int
+	flushInterval time.Duration
+
+	requests chan *batchRequest
+	done     chan struct{}
+	sending  sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newSQSBatcher(client sqsiface.SQSAPI, cfg config.ElasticMQBatchConfig, m *metrics.Metrics) *sqsBatcher {
+	return &sqsBatcher{
+		client:        client,
+		metrics:       m,
+		size:          cfg.Size,
+		flushInterval: cfg.FlushInterval,
+		requests:      make(chan *batchRequest, cfg.Size*10),
+		done:          make(chan struct{}),
+	}
+}
+
+// Send はメッセージを一括送信の待ちに追加し、送信結果を待つ
+// 送信できた場合はSQSが発行したメッセージIDを返す
+func (b *sqsBatcher) Send(ctx context.Context, queueURL string, body string, attributes map[string]*sqs.MessageAttributeValue) (string, error) {
+	req := &batchRequest{
+		queueURL:   queueURL,
+		body:       body,
+		attributes: attributes,
+		size:       messageSize(body, attributes),
+		result:     make(chan batchResult, 1),
+	}
+
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return "", errBatcherClosed
+	}
+	select {
+	case b.requests <- req:
+		b.mu.RUnlock()
+	case <-ctx.Done():
+		b.mu.RUnlock()
+		return "", ctx.Err()
+	}
+
+	select {
+	case res := <-req.result:
+		return res.messageID, res.err
+	case <-ctx.Done():
+		// 送信は継続されるため、呼び出し元が再送すると重複する可能性がある
+		return "", ctx.Err()
+	}
+}
+
+// Run は停止されるまでメッセージを集めて送信する
+// キューごとに最初のメッセージを受け取ってから flushInterval が経過するか、size 件に達した時点で送信する
+// 追加すると合計サイズが maxBatchBytes を超える場合は、それまでに集めたメッセージを先に送信する
+func (b *sqsBatcher) Run() {
+	defer close(b.done)
+
+	timer := time.NewTimer(b.flushInterval)
+	timer.Stop()
+	defer timer.Stop()
+
+	pending := make(map[string][]*batchRequest)
+	pendingBytes := make(map[string]int)
+	deadlines := make(map[string]time.Time)
+	flushQueue := func(url string) {
+		b.flush(url, pending[url])
+		delete(pending, url)
+		delete(pendingBytes, url)
+		delete(deadlines, url)
+	}
+	for {
+		select {
+		case req, ok := <-b.requests:
+			if !ok {
+				for url := range pending {
+					flushQueue(url)
+				}
+				return
+			}
+			url := req.queueURL
+			if len(pending[url]) > 0 && pendingBytes[url]+req.size > maxBatchBytes {
+				flushQueue(url)
+			}
+			if len(pending[url]) == 0 {
+				deadlines[url] = time.Now().Add(b.flushInterval)
+			}
+			pending[url] = append(pending[url], req)
+			pendingBytes[url] += req.size
+			if len(pending[url]) >= b.size || pendingBytes[url] >= maxBatchBytes {
+				flushQueue(url)
+			}
+		case now := <-timer.C:
+			for url, deadline := range deadlines {
+				if !deadline.After(now) {
+					flushQueue(url)
+				}
+			}
+		}
+		resetToEarliest(timer, deadlines)
+	}
+}
+
+// messageSize はSQSのサイズの上限の計算と同じく、本文とメッセージ属性の名前・型・値のバイト数を合計する
+func messageSize(body string, attributes map[string]*sqs.MessageAttributeValue) int {
+	size := len(body)
+	for name, attr := range attributes {
+		size += len(name) + len(aws.StringValue(attr.DataType)) + len(aws.StringValue(attr.StringValue)) + len(attr.BinaryValue)
+	}
+	return size
+}
+
+// resetToEarliest は最も早い送信期限に発火するようにタイマーを設定し直す
+func resetToEarliest(timer *time.Timer, deadlines map[string]time.Time) {
+	timer.Stop()
+	var earliest time.Time
+	for _, deadline := range deadlines {
+		if earliest.IsZero() || deadline.Before(earliest) {
+			earliest = deadline
+		}
+	}
+	if !earliest.IsZero() {
+		timer.Reset(time.Until(earliest))
+	}
+}
+
+// Stop は新しい送信を受け付けずに、待っているメッセージをすべて送信してから戻る
+func (b *sqsBatcher) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.requests)
+	}
+	b.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		<-b.done
+		b.sending.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("一括送信の完了待ちがタイムアウトしました: %w", ctx.Err())
+	}
+}
+
+// flush は集めたメッセージを別のゴルーチンで送信する
+func (b *sqsBatcher) flush(queueURL string, reqs []*batchRequest) {
+	b.sending.Add(1)
+	go func() {
+		defer b.sending.Done()
+		b.sendBatch(queueURL, reqs)
+	}()
+}
+
+// sendBatch は SendMessageBatch で送信し、結果をエントリIDからそれぞれのメッセージに返す
+// エントリIDには reqs のインデックスを使用し、一部のエントリが失敗した場合は失敗したエントリだけを同じIDで再送する
+// maxBatchRetries 回再送しても失敗したメッセージは個別に SendMessage で再送する
+func (b *sqsBatcher) sendBatch(queueURL string, reqs []*batchRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), batchSendTimeout)
+	defer cancel()
+
+	remaining := make([]int, len(reqs))
+	for i := range reqs {
+		remaining[i] = i
+	}
+	for attempt := 0; attempt <= maxBatchRetries && len(remaining) > 0; attempt++ {
+		retry, err := b.sendEntries(ctx, queueURL, reqs, remaining)
+		if err != nil {
+			err = sendError(err)
+			for _, i := range remaining {
+				reqs[i].result <- batchResult{err: err}
+			}
+			return
+		}
+		remaining = retry
+	}
+
+	for _, i := range remaining {
+		messageID, err := b.sendOne(ctx, queueURL, reqs[i])
+		reqs[i].result <- batchResult{messageID: messageID, err: err}
+	}
+}
+
+// sendEntries は reqs のうち indexes のメッセージを SendMessageBatch で送信し、結果が確定したメッセージに結果を返す
+// 戻り値は再送すれば成功する可能性のあるメッセージのインデックス
+func (b *sqsBatcher) sendEntries(ctx context.Context, queueURL string, reqs []*batchRequest, indexes []int) ([]int, error) {
+	entries := make([]*sqs.SendMessageBatchRequestEntry, len(indexes))
+	for n, i := range indexes {
+		entries[n] = &sqs.SendMessageBatchRequestEntry{
+			Id:                aws.String(strconv.Itoa(i)),
+			MessageBody:       aws.String(reqs[i].body),
+			MessageAttributes: reqs[i].attributes,
+		}
+	}
+
+	start := time.Now()
+	out, err := b.client.SendMessageBatchWithContext(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+		Entries:  entries,
+	})
+	b.metrics.SQSSendDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	messageIDs := make(map[string]string, len(out.Successful))
+	for _, entry := range out.Successful {
+		messageIDs[aws.StringValue(entry.Id)] = aws.StringValue(entry.MessageId)
+	}
+	failed := make(map[string]*sqs.BatchResultErrorEntry, len(out.Failed))
+	for _, entry := range out.Failed {
+		failed[aws.StringValue(entry.Id)] = entry
+	}
+
+	var retry []int
+	for _, i := range indexes {
+		id := strconv.Itoa(i)
+		if messageID, ok := messageIDs[id]; ok {
+			reqs[i].result <- batchResult{messageID: messageID}
+			continue
+		}
+		// メッセージ自体に問題がある場合は再送しても成功しないため、そのままエラーを返す
+		if entry, ok := failed[id]; ok && aws.BoolValue(entry.SenderFault) {
+			reqs[i].result <- batchResult{err: fmt.Errorf("SQS一括送信エラー (code=%s): %s", aws.StringValue(entry.Code), aws.StringValue(entry.Message))}
+			continue
+		}
+		retry = append(retry, i)
+	}
+	return retry, nil
+}
+
+// sendOne は一括送信に失敗したメッセージを SendMessage で個別に再送する
+func (b *sqsBatcher) sendOne(ctx context.Context, queueURL string, req *batchRequest) (string, error) {
+	start := time.Now()
+	out, err := b.client.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+		QueueUrl:          aws.String(queueURL),
+		MessageBody:       aws.String(req.body),
+		MessageAttributes: req.attributes,
+	})
+	b.metrics.SQSSendDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return "", sendError(err)
+	}
+	return aws.StringValue(out.MessageId), nil
+}
+
+// sendError は送信エラーを呼び出し元が種類を判別できる形にする
+func sendError(err error) error {
+	if isThrottled(err) {
+		return fmt.Errorf("SQS送信エラー: %w: %v", queuemodel.ErrThrottled, err)
+	}
+	return fmt.Errorf("SQS送信エラー: %w", err)
+}
@@ -0,0 +1,205 @@
+package queue
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+)
+
+const batcherTestQueueURL = fakeSQSEndpoint + "mentions"
+
+func newTestSQSBatcher(client *fakeSQSClient, size int, flushInterval time.Duration) *sqsBatcher {
+	return newSQSBatcher(client, config.ElasticMQBatchConfig{Enabled: true, Size: size, FlushInterval: flushInterval}, metrics.NewMetrics(prometheus.NewRegistry()))
+}
+
+// sendAll は bodies をそれぞれ別のゴルーチンで送信し、送信結果を bodies の順に返す
+// 一括送信の待ちにすべて追加されるまで待ってから戻り、結果は results で受け取る
+func sendAll(t *testing.T, b *sqsBatcher, bodies ...string) (results func() []batchResult) {
+	t.Helper()
+	got := make([]batchResult, len(bodies))
+	var wg sync.WaitGroup
+	for i, body := range bodies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			messageID, err := b.Send(ctx, batcherTestQueueURL, body, nil)
+			got[i] = batchResult{messageID: messageID, err: err}
+		}()
+		// 追加した順に送信されるよう、1件ずつ待ちに入ったことを確認する
+		waitFor(t, func() bool { return len(b.requests) == i+1 })
+	}
+	return func() []batchResult {
+		wg.Wait()
+		return got
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("条件を満たしませんでした")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func assertSent(t *testing.T, results []batchResult) {
+	t.Helper()
+	seen := make(map[string]bool)
+	for i, res := range results {
+		if res.err != nil || res.messageID == "" {
+			t.Errorf("results[%d] = %q, %v, want メッセージID, nil", i, res.messageID, res.err)
+		}
+		if seen[res.messageID] {
+			t.Errorf("results[%d] のメッセージID %q が重複しています", i, res.messageID)
+		}
+		seen[res.messageID] = true
+	}
+}
+
+// 件数に達していなくても、最初のメッセージから flushInterval が経過したら送信する
+func TestSQSBatcherFlushesAfterInterval(t *testing.T) {
+	client := newFakeSQSClient("mentions")
+	flushInterval := 100 * time.Millisecond
+	b := newTestSQSBatcher(client, 10, flushInterval)
+
+	results := sendAll(t, b, "a", "b")
+	start := time.Now()
+	go b.Run()
+	t.Cleanup(func() { b.Stop(context.Background()) })
+
+	assertSent(t, results())
+	if elapsed := time.Since(start); elapsed < flushInterval {
+		t.Errorf("%v で送信しました, want flushInterval (%v) の経過後", elapsed, flushInterval)
+	}
+	if got := client.sentBatches(); len(got) != 1 || !slices.Equal(got[0], []string{"a", "b"}) {
+		t.Errorf("batches = %v, want [[a b]]", got)
+	}
+}
+
+// size 件に達したら flushInterval を待たずに送信する
+func TestSQSBatcherFlushesAtSize(t *testing.T) {
+	client := newFakeSQSClient("mentions")
+	b := newTestSQSBatcher(client, 3, time.Hour)
+
+	results := sendAll(t, b, "a", "b", "c", "d")
+	go b.Run()
+
+	// 4件目は size に達していないため送信されない
+	waitFor(t, func() bool { return len(client.sentBatches()) == 1 })
+	if got := client.sentBatches(); !slices.Equal(got[0], []string{"a", "b", "c"}) {
+		t.Errorf("batches = %v, want [[a b c]]", got)
+	}
+
+	if err := b.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	assertSent(t, results())
+	if got := client.sentBatches(); len(got) != 2 || !slices.Equal(got[1], []string{"d"}) {
+		t.Errorf("batches = %v, want [[a b c] [d]]", got)
+	}
+}
+
+// 合計サイズが SendMessageBatch の上限を超える場合は、件数に達していなくても分けて送信する
+func TestSQSBatcherSplitsByPayloadSize(t *testing.T) {
+	client := newFakeSQSClient("mentions")
+	b := newTestSQSBatcher(client, 10, time.Hour)
+
+	large := func(c string) string { return strings.Repeat(c, 100*1024) }
+	results := sendAll(t, b, large("a"), large("b"), large("c"), "d")
+	go b.Run()
+	if err := b.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	assertSent(t, results())
+	var sizes []int
+	for _, batch := range client.sentBatches() {
+		total := 0
+		for _, body := range batch {
+			total += len(body)
+		}
+		if total > maxBatchBytes {
+			t.Errorf("一括送信の合計サイズ = %d, want %d 以下", total, maxBatchBytes)
+		}
+		sizes = append(sizes, len(batch))
+	}
+	if !slices.Equal(sizes, []int{2, 2}) {
+		t.Errorf("一括送信ごとの件数 = %v, want [2 2]", sizes)
+	}
+}
+
+// SendMessageBatch の Failed のエントリだけを再送し、再送しても失敗した場合は SendMessage で送信する
+func TestSQSBatcherRetriesFailedEntries(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int
+		wantBatches [][]string
+	}{
+		{
+			name:        "1回失敗したエントリは一括送信で再送する",
+			failures:    1,
+			wantBatches: [][]string{{"a", "b"}, {"b"}},
+		},
+		{
+			// 一括送信ですべて失敗したため、キューにある b は SendMessage で送信したもの
+			name:        "再送の回数を超えて失敗したエントリは個別に送信する",
+			failures:    maxBatchRetries + 1,
+			wantBatches: [][]string{{"a", "b"}, {"b"}, {"b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeSQSClient("mentions")
+			client.failEntries["b"] = tt.failures
+			b := newTestSQSBatcher(client, 2, time.Hour)
+
+			results := sendAll(t, b, "a", "b")
+			go b.Run()
+			t.Cleanup(func() { b.Stop(context.Background()) })
+
+			assertSent(t, results())
+			if got := client.sentBatches(); !slices.EqualFunc(got, tt.wantBatches, slices.Equal[[]string]) {
+				t.Errorf("batches = %v, want %v", got, tt.wantBatches)
+			}
+			var bodies []string
+			for _, m := range client.messages("mentions") {
+				bodies = append(bodies, *m.Body)
+			}
+			if !slices.Equal(bodies, []string{"a", "b"}) {
+				t.Errorf("キューのメッセージ = %v, want [a b]（重複なし）", bodies)
+			}
+		})
+	}
+}
+
+// 停止時は flushInterval を待たずに待っているメッセージをすべて送信し、停止後の送信は受け付けない
+func TestSQSBatcherFlushesOnStop(t *testing.T) {
+	client := newFakeSQSClient("mentions")
+	b := newTestSQSBatcher(client, 10, time.Hour)
+
+	results := sendAll(t, b, "a", "b")
+	go b.Run()
+	if err := b.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	assertSent(t, results())
+	if got := client.sentBatches(); len(got) != 1 || !slices.Equal(got[0], []string{"a", "b"}) {
+		t.Errorf("batches = %v, want [[a b]]", got)
+	}
+	if _, err := b.Send(context.Background(), batcherTestQueueURL, "c", nil); err != errBatcherClosed {
+		t.Errorf("停止後の Send() error = %v, want %v", err, errBatcherClosed)
+	}
+}
@@ -0,0 +1,206 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/tracing"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// receiveErrorBackoff は受信に失敗した場合に再試行するまでの待ち時間
+const receiveErrorBackoff = 5 * time.Second
+
+// MessageHandler は受信したメッセージを処理する
+// エラーを返した場合、メッセージは削除されずに再試行される
+type MessageHandler func(ctx context.Context, body []byte) error
+
+// DeadLetterHandler は受信回数の上限に達しても処理できなかったメッセージを受け取る
+// receiveCount はメッセージを受信した回数、cause は最後の処理のエラー
+// エラーを返した場合、メッセージは削除されずに再試行される
+type DeadLetterHandler func(ctx context.Context, body []byte, receiveCount int, cause error) error
+
+// SQSConsumer はElasticMQ（SQS互換）のキューからメッセージをロングポーリングで受信する
+type SQSConsumer struct {
+	client     sqsiface.SQSAPI
+	queueURL   string
+	retryDelay time.Duration
+	cfg        config.ResponseConsumerConfig
+	// deadLetter はデッドレターへの移動が無効な場合 nil
+	deadLetter *deadLetterPolicy
+}
+
+// deadLetterPolicy は処理できなかったメッセージをデッドレターに移す条件と移動先
+type deadLetterPolicy struct {
+	maxReceives int
+	// queueURL はデッドレターキューを使用しない場合は空
+	queueURL string
+	handler  DeadLetterHandler
+}
+
+// NewSQSResponseConsumer はAIワーカーの回答を受け取るキューのコンシューマーを作成する
+// 回答キューが設定されていない場合は nil を返す
+func NewSQSResponseConsumer(cfg *config.AppConfig) (*SQSConsumer, error) {
+	if cfg.ElasticMQ.ResponseQueueName == "" {
+		return nil, nil
+	}
+
+	return NewSQSConsumer(cfg.ElasticMQ, cfg.ElasticMQ.ResponseQueueName, cfg.ElasticMQ.ResponseRetryDelay, cfg.ElasticMQ.ResponseConsumer)
+}
+
+// NewSQSConsumer は queueName のキューのコンシューマーを作成する
+// 処理に失敗したメッセージは retryDelay 後に再度受信できるようにする
+func NewSQSConsumer(cfg config.ElasticMQConfig, queueName string, retryDelay time.Duration, consumer config.ResponseConsumerConfig) (*SQSConsumer, error) {
+	client, err := NewSQSClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SQSConsumer{
+		client:     client,
+		queueURL:   queueURL(cfg, queueName),
+		retryDelay: retryDelay,
+		cfg:        consumer,
+	}, nil
+}
+
+// SetDeadLetter は maxReceives 回受信しても処理できなかったメッセージを handler に渡し、キューから削除するようにする
+// queueName が空でない場合は、handler が保存した後にそのキュー（デッドレターキュー）にもメッセージを送信する
+func (c *SQSConsumer) SetDeadLetter(cfg config.ElasticMQConfig, maxReceives int, queueName string, handler DeadLetterHandler) {
+	policy := &deadLetterPolicy{maxReceives: maxReceives, handler: handler}
+	if queueName != "" {
+		policy.queueURL = queueURL(cfg, queueName)
+	}
+	c.deadLetter = policy
+}
+
+// Run は ctx がキャンセルされるまでメッセージを受信して handler で処理する
+// 処理に成功したメッセージは削除し、失敗したメッセージは retryDelay 後に再度受信できるようにする
+// ショートポーリングでメッセージがなかった場合は、キューへの問い合わせが続かないよう次の受信まで待つ
+func (c *SQSConsumer) Run(ctx context.Context, handler MessageHandler) {
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(c.queueURL),
+		MaxNumberOfMessages:   aws.Int64(int64(c.cfg.MaxMessages)),
+		WaitTimeSeconds:       aws.Int64(int64(c.cfg.WaitTimeSeconds)),
+		MessageAttributeNames: []*string{aws.String("All")},
+		AttributeNames:        []*string{aws.String(sqs.MessageSystemAttributeNameApproximateReceiveCount)},
+	}
+	if c.cfg.VisibilityTimeout > 0 {
+		input.VisibilityTimeout = aws.Int64(int64(c.cfg.VisibilityTimeout.Seconds()))
+	}
+
+	backingOff := false
+	for ctx.Err() == nil {
+		out, err := c.client.ReceiveMessageWithContext(ctx, input)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return
+			}
+			logger.Errorf(ctx, "キューからの受信エラー (%s): %v", c.queueURL, err)
+			if !sleepContext(ctx, receiveErrorBackoff) {
+				return
+			}
+			continue
+		}
+
+		if len(out.Messages) == 0 && c.cfg.WaitTimeSeconds == 0 {
+			// 空の受信が続く間は最初の1回のみログに出力する
+			if !backingOff {
+				logger.Printf(ctx, "キューにメッセージがないため %s ごとに受信します (%s)", c.cfg.EmptyReceiveBackoff, c.queueURL)
+				backingOff = true
+			}
+			if !sleepContext(ctx, c.cfg.EmptyReceiveBackoff) {
+				return
+			}
+			continue
+		}
+		backingOff = false
+
+		for _, m := range out.Messages {
+			c.handle(ctx, m, handler)
+		}
+	}
+}
+
+// sleepContext は d の間待つ。ctx が終了した場合は待たずに false を返す
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (c *SQSConsumer) handle(ctx context.Context, m *sqs.Message, handler MessageHandler) {
+	ctx = logger.With(ctx, "message_id", aws.StringValue(m.MessageId))
+	// 送信元のスパンがメッセージ属性にある場合は、その続きとして処理する
+	ctx = tracing.Extract(ctx, sqsAttributeCarrier(m.MessageAttributes))
+	if err := handler(ctx, []byte(aws.StringValue(m.Body))); err != nil {
+		logger.Errorf(ctx, "キューのメッセージの処理エラー: %v", err)
+		if c.moveToDeadLetter(ctx, m, err) {
+			c.delete(ctx, m)
+			return
+		}
+		_, err := c.client.ChangeMessageVisibilityWithContext(ctx, &sqs.ChangeMessageVisibilityInput{
+			QueueUrl:          aws.String(c.queueURL),
+			ReceiptHandle:     m.ReceiptHandle,
+			VisibilityTimeout: aws.Int64(int64(c.retryDelay.Seconds())),
+		})
+		if err != nil {
+			logger.Errorf(ctx, "メッセージの可視性タイムアウトの変更エラー: %v", err)
+		}
+		return
+	}
+
+	c.delete(ctx, m)
+}
+
+func (c *SQSConsumer) delete(ctx context.Context, m *sqs.Message) {
+	_, err := c.client.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(c.queueURL),
+		ReceiptHandle: m.ReceiptHandle,
+	})
+	if err != nil {
+		// 削除に失敗したメッセージは再度受信されるため、重複して処理される可能性がある
+		logger.Errorf(ctx, "キューのメッセージの削除エラー: %v", err)
+	}
+}
+
+// moveToDeadLetter は受信回数が上限に達したメッセージをデッドレターに移し、移した場合は true を返す
+// handler が保存できなかった場合は通常の失敗と同じく再試行し、次の受信時に再度移す
+// デッドレターキューへの送信は保存した後に1回だけ行い、送信に失敗しても保存済みのため再試行しない
+// （送信してから保存に失敗すると、再試行のたびにデッドレターキューに同じメッセージが送信されるため）
+func (c *SQSConsumer) moveToDeadLetter(ctx context.Context, m *sqs.Message, cause error) bool {
+	if c.deadLetter == nil {
+		return false
+	}
+	receiveCount, _ := strconv.Atoi(aws.StringValue(m.Attributes[sqs.MessageSystemAttributeNameApproximateReceiveCount]))
+	if receiveCount < c.deadLetter.maxReceives {
+		return false
+	}
+
+	if err := c.deadLetter.handler(ctx, []byte(aws.StringValue(m.Body)), receiveCount, cause); err != nil {
+		logger.Errorf(ctx, "デッドレターの保存エラー: %v", err)
+		return false
+	}
+	if c.deadLetter.queueURL != "" {
+		_, err := c.client.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+			QueueUrl:          aws.String(c.deadLetter.queueURL),
+			MessageBody:       m.Body,
+			MessageAttributes: m.MessageAttributes,
+		})
+		if err != nil {
+			logger.Errorf(ctx, "デッドレターキューへの送信エラー（デッドレターは保存済みのためキューから削除します） (%s): %v", c.deadLetter.queueURL, err)
+		}
+	}
+	logger.Warnf(ctx, "%d回受信しても処理できなかったメッセージをデッドレターに移しました", receiveCount)
+	return true
+}
@@ -0,0 +1,147 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+)
+
+// newTestSQSConsumer は fakeSQSClient の mentions キューを受信し、max_receives 回でデッドレターに移すコンシューマーを作成する
+// record はデッドレターとして保存したメッセージの受信回数を記録し、recordErr が設定されている場合は保存に失敗する
+func newTestSQSConsumer(client *fakeSQSClient, maxReceives int, deadLetterQueue string, recordErr *error) (*SQSConsumer, *[]int) {
+	cfg := config.ElasticMQConfig{Endpoint: "http://sqs.test"}
+	c := &SQSConsumer{
+		client:     client,
+		queueURL:   queueURL(cfg, "mentions"),
+		retryDelay: 30 * time.Second,
+	}
+	var recorded []int
+	c.SetDeadLetter(cfg, maxReceives, deadLetterQueue, func(ctx context.Context, body []byte, receiveCount int, cause error) error {
+		if *recordErr != nil {
+			return *recordErr
+		}
+		recorded = append(recorded, receiveCount)
+		return nil
+	})
+	return c, &recorded
+}
+
+func newReceivedMessage(receiptHandle string, receiveCount int) *sqs.Message {
+	return &sqs.Message{
+		MessageId:     aws.String("message-" + receiptHandle),
+		ReceiptHandle: aws.String(receiptHandle),
+		Body:          aws.String(`{"id":"01HTGZ0000000000000000000A"}`),
+		Attributes: map[string]*string{
+			sqs.MessageSystemAttributeNameApproximateReceiveCount: aws.String(strconv.Itoa(receiveCount)),
+		},
+	}
+}
+
+func TestSQSConsumerDeadLetterThreshold(t *testing.T) {
+	failing := func(ctx context.Context, body []byte) error { return errors.New("worker failed") }
+	tests := []struct {
+		name           string
+		receiveCount   int
+		wantDeadLetter bool
+	}{
+		{name: "上限未満は可視性タイムアウトを変更して再試行する", receiveCount: 2},
+		{name: "上限に達したらデッドレターに移して削除する", receiveCount: 3, wantDeadLetter: true},
+		{name: "上限を超えた場合もデッドレターに移す", receiveCount: 7, wantDeadLetter: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeSQSClient("mentions", "mentions-dlq")
+			var recordErr error
+			c, recorded := newTestSQSConsumer(client, 3, "mentions-dlq", &recordErr)
+
+			c.handle(context.Background(), newReceivedMessage("r1", tt.receiveCount), failing)
+
+			if tt.wantDeadLetter {
+				if len(*recorded) != 1 || (*recorded)[0] != tt.receiveCount {
+					t.Errorf("保存したデッドレターの受信回数 = %v, want [%d]", *recorded, tt.receiveCount)
+				}
+				if got := client.messages("mentions-dlq"); len(got) != 1 {
+					t.Errorf("デッドレターキューのメッセージ = %d件, want 1件", len(got))
+				}
+				if len(client.deleted) != 1 || len(client.visibility) != 0 {
+					t.Errorf("削除 = %v, 可視性の変更 = %v, want 削除のみ", client.deleted, client.visibility)
+				}
+				return
+			}
+			if len(*recorded) != 0 || len(client.messages("mentions-dlq")) != 0 {
+				t.Errorf("上限未満のメッセージをデッドレターに移しています")
+			}
+			if len(client.deleted) != 0 || client.visibility["r1"] != 30 {
+				t.Errorf("削除 = %v, 可視性の変更 = %v, want r1 を30秒", client.deleted, client.visibility)
+			}
+		})
+	}
+}
+
+// 保存に失敗した場合はデッドレターキューに送信せずに再試行し、次の受信で保存してから1回だけ送信する
+func TestSQSConsumerDeadLetterRecordFailure(t *testing.T) {
+	failing := func(ctx context.Context, body []byte) error { return errors.New("worker failed") }
+	client := newFakeSQSClient("mentions", "mentions-dlq")
+	recordErr := errors.New("database is down")
+	c, recorded := newTestSQSConsumer(client, 3, "mentions-dlq", &recordErr)
+
+	c.handle(context.Background(), newReceivedMessage("r1", 3), failing)
+	if got := client.messages("mentions-dlq"); len(got) != 0 {
+		t.Errorf("保存に失敗したのにデッドレターキューに %d件送信しています", len(got))
+	}
+	if len(client.deleted) != 0 || client.visibility["r1"] != 30 {
+		t.Errorf("削除 = %v, 可視性の変更 = %v, want 再試行", client.deleted, client.visibility)
+	}
+
+	recordErr = nil
+	c.handle(context.Background(), newReceivedMessage("r2", 4), failing)
+	if len(*recorded) != 1 {
+		t.Errorf("保存したデッドレター = %d件, want 1件", len(*recorded))
+	}
+	if got := client.messages("mentions-dlq"); len(got) != 1 {
+		t.Errorf("デッドレターキューのメッセージ = %d件, want 1件", len(got))
+	}
+	if len(client.deleted) != 1 || client.deleted[0] != "r2" {
+		t.Errorf("削除 = %v, want [r2]", client.deleted)
+	}
+}
+
+// デッドレターキューへの送信に失敗しても、保存済みのため再試行せずに削除する
+func TestSQSConsumerDeadLetterSendFailure(t *testing.T) {
+	failing := func(ctx context.Context, body []byte) error { return errors.New("worker failed") }
+	client := newFakeSQSClient("mentions", "mentions-dlq")
+	client.failSend["mentions-dlq"] = true
+	var recordErr error
+	c, recorded := newTestSQSConsumer(client, 3, "mentions-dlq", &recordErr)
+
+	c.handle(context.Background(), newReceivedMessage("r1", 3), failing)
+	if len(*recorded) != 1 {
+		t.Errorf("保存したデッドレター = %d件, want 1件", len(*recorded))
+	}
+	if len(client.deleted) != 1 || len(client.visibility) != 0 {
+		t.Errorf("削除 = %v, 可視性の変更 = %v, want 削除のみ", client.deleted, client.visibility)
+	}
+}
+
+func TestSQSConsumerDeletesHandledMessage(t *testing.T) {
+	client := newFakeSQSClient("mentions")
+	c := &SQSConsumer{client: client, queueURL: queueURL(config.ElasticMQConfig{Endpoint: "http://sqs.test"}, "mentions"), retryDelay: 30 * time.Second}
+
+	var body string
+	c.handle(context.Background(), newReceivedMessage("r1", 1), func(ctx context.Context, b []byte) error {
+		body = string(b)
+		return nil
+	})
+	if body != `{"id":"01HTGZ0000000000000000000A"}` {
+		t.Errorf("body = %s", body)
+	}
+	if len(client.deleted) != 1 || client.deleted[0] != "r1" {
+		t.Errorf("削除 = %v, want [r1]", client.deleted)
+	}
+}
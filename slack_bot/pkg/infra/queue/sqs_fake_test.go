@@ -0,0 +1,170 @@
+package queue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+)
+
+const fakeSQSEndpoint = "http://sqs.test/queue/"
+
+// fakeSQSClient は送信されたメッセージをキューごとにメモリに保持するSQSクライアント
+// queues に含まれるキューだけが存在し、それ以外のキューは AWS.SimpleQueueService.NonExistentQueue になる
+// 受信したメッセージの削除と可視性タイムアウトの変更は記録だけを行う
+type fakeSQSClient struct {
+	sqsiface.SQSAPI
+
+	mu       sync.Mutex
+	queues   map[string][]*sqs.Message
+	sequence int
+	// failSend に含まれるキューへの送信は失敗する
+	failSend map[string]bool
+	// deleted は削除したメッセージの受信ハンドル、visibility は可視性タイムアウトを変更したメッセージの受信ハンドルと秒数
+	deleted    []string
+	visibility map[string]int64
+	// batches は SendMessageBatch で送信されたエントリの本文（呼び出しの順）
+	batches [][]string
+	// failEntries は本文ごとに SendMessageBatch のエントリを一時的な失敗（SenderFault が false）にする残りの回数
+	failEntries map[string]int
+}
+
+func newFakeSQSClient(queueNames ...string) *fakeSQSClient {
+	c := &fakeSQSClient{
+		queues:      make(map[string][]*sqs.Message),
+		failSend:    make(map[string]bool),
+		visibility:  make(map[string]int64),
+		failEntries: make(map[string]int),
+	}
+	for _, name := range queueNames {
+		c.queues[name] = nil
+	}
+	return c
+}
+
+func (c *fakeSQSClient) GetQueueUrlWithContext(ctx aws.Context, in *sqs.GetQueueUrlInput, _ ...request.Option) (*sqs.GetQueueUrlOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := aws.StringValue(in.QueueName)
+	if _, ok := c.queues[name]; !ok {
+		return nil, awserr.New(sqs.ErrCodeQueueDoesNotExist, "The specified queue does not exist", nil)
+	}
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(fakeSQSEndpoint + name)}, nil
+}
+
+func (c *fakeSQSClient) SendMessageWithContext(ctx aws.Context, in *sqs.SendMessageInput, _ ...request.Option) (*sqs.SendMessageOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name, err := c.queueName(in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	if c.failSend[name] {
+		return nil, awserr.New("ServiceUnavailable", "service unavailable", nil)
+	}
+	c.sequence++
+	id := fmt.Sprintf("message-%d", c.sequence)
+	c.queues[name] = append(c.queues[name], &sqs.Message{
+		MessageId:         aws.String(id),
+		Body:              in.MessageBody,
+		MessageAttributes: in.MessageAttributes,
+	})
+	return &sqs.SendMessageOutput{MessageId: aws.String(id)}, nil
+}
+
+// SendMessageBatchWithContext はSQSと同じく合計サイズが上限を超える場合は一括送信全体を失敗させる
+func (c *fakeSQSClient) SendMessageBatchWithContext(ctx aws.Context, in *sqs.SendMessageBatchInput, _ ...request.Option) (*sqs.SendMessageBatchOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name, err := c.queueName(in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	total := 0
+	for _, entry := range in.Entries {
+		total += messageSize(aws.StringValue(entry.MessageBody), entry.MessageAttributes)
+	}
+	if total > queuemodel.MaxMessageSize {
+		return nil, awserr.New(sqs.ErrCodeBatchRequestTooLong, "Batch requests cannot be longer than 262144 bytes", nil)
+	}
+
+	bodies := make([]string, len(in.Entries))
+	out := &sqs.SendMessageBatchOutput{}
+	for i, entry := range in.Entries {
+		body := aws.StringValue(entry.MessageBody)
+		bodies[i] = body
+		if c.failEntries[body] > 0 {
+			c.failEntries[body]--
+			out.Failed = append(out.Failed, &sqs.BatchResultErrorEntry{Id: entry.Id, Code: aws.String("InternalError"), SenderFault: aws.Bool(false)})
+			continue
+		}
+		c.sequence++
+		id := fmt.Sprintf("message-%d", c.sequence)
+		c.queues[name] = append(c.queues[name], &sqs.Message{MessageId: aws.String(id), Body: entry.MessageBody, MessageAttributes: entry.MessageAttributes})
+		out.Successful = append(out.Successful, &sqs.SendMessageBatchResultEntry{Id: entry.Id, MessageId: aws.String(id)})
+	}
+	c.batches = append(c.batches, bodies)
+	return out, nil
+}
+
+// sentBatches は SendMessageBatch で送信されたエントリの本文を返す
+func (c *fakeSQSClient) sentBatches() [][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]string(nil), c.batches...)
+}
+
+func (c *fakeSQSClient) GetQueueAttributesWithContext(ctx aws.Context, in *sqs.GetQueueAttributesInput, _ ...request.Option) (*sqs.GetQueueAttributesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name, err := c.queueName(in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	return &sqs.GetQueueAttributesOutput{Attributes: map[string]*string{
+		sqs.QueueAttributeNameApproximateNumberOfMessages: aws.String(strconv.Itoa(len(c.queues[name]))),
+		sqs.QueueAttributeNameQueueArn:                    aws.String("arn:aws:sqs:elasticmq:000000000000:" + name),
+	}}, nil
+}
+
+func (c *fakeSQSClient) DeleteMessageWithContext(ctx aws.Context, in *sqs.DeleteMessageInput, _ ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.queueName(in.QueueUrl); err != nil {
+		return nil, err
+	}
+	c.deleted = append(c.deleted, aws.StringValue(in.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (c *fakeSQSClient) ChangeMessageVisibilityWithContext(ctx aws.Context, in *sqs.ChangeMessageVisibilityInput, _ ...request.Option) (*sqs.ChangeMessageVisibilityOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.queueName(in.QueueUrl); err != nil {
+		return nil, err
+	}
+	c.visibility[aws.StringValue(in.ReceiptHandle)] = aws.Int64Value(in.VisibilityTimeout)
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+// messages はキューに送信されたメッセージを返す
+func (c *fakeSQSClient) messages(queueName string) []*sqs.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*sqs.Message(nil), c.queues[queueName]...)
+}
+
+func (c *fakeSQSClient) queueName(url *string) (string, error) {
+	name, ok := strings.CutPrefix(aws.StringValue(url), fakeSQSEndpoint)
+	if _, exists := c.queues[name]; !ok || !exists {
+		return "", awserr.New(sqs.ErrCodeQueueDoesNotExist, "The specified queue does not exist", nil)
+	}
+	return name, nil
+}
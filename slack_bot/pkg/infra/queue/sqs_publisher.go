@@ -0,0 +1,210 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/tracing"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"go.uber.org/fx"
+)
+
+// UnknownQueueError は送信先のキューが設定されていないキーを指定した場合のエラー
+type UnknownQueueError struct {
+	Key string
+}
+
+func (e *UnknownQueueError) Error() string {
+	return fmt.Sprintf("キー %q に対応するキューが設定されていません (elasticmq.queues)", e.Key)
+}
+
+// SQSPublisher はElasticMQ（SQS互換）のキューにメッセージを送信する
+// 送信先のキューURLは最初の送信時に解決してキャッシュする
+// elasticmq.batch.enabled の場合は SendMessageBatch でまとめて送信する
+type SQSPublisher struct {
+	client    sqsiface.SQSAPI
+	cfg       config.ElasticMQConfig
+	queueKeys []string
+	metrics   *metrics.Metrics
+	batcher   *sqsBatcher
+
+	mu        sync.Mutex
+	queueURLs map[string]string
+}
+
+func NewSQSPublisher(lc fx.Lifecycle, cfg *config.AppConfig, m *metrics.Metrics) (di.QueuePublisher, error) {
+	client, err := NewSQSClient(cfg.ElasticMQ)
+	if err != nil {
+		return nil, err
+	}
+	return NewSQSPublisherWithClient(lc, client, cfg, m), nil
+}
+
+// NewSQSPublisherWithClient は作成済みのSQSクライアントで送信する SQSPublisher を作成する
+// 結合テストなどでクライアントの接続先や設定を差し替える場合に使用する
+func NewSQSPublisherWithClient(lc fx.Lifecycle, client sqsiface.SQSAPI, cfg *config.AppConfig, m *metrics.Metrics) *SQSPublisher {
+	p := &SQSPublisher{
+		client:    client,
+		cfg:       cfg.ElasticMQ,
+		queueKeys: cfg.QueueKeys(),
+		metrics:   m,
+		queueURLs: make(map[string]string),
+	}
+	if cfg.ElasticMQ.Batch.Enabled {
+		p.batcher = newSQSBatcher(client, cfg.ElasticMQ.Batch, m)
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go p.batcher.Run()
+				return nil
+			},
+			// 送信を待っているメッセージを送信してから停止する
+			OnStop: p.batcher.Stop,
+		})
+	}
+	return p
+}
+
+// Close は一括送信を待っているメッセージを送信してから停止する
+// SQSのクライアントは接続を保持しないため、一括送信を使用しない場合は何もしない
+func (p *SQSPublisher) Close() error {
+	if p.batcher == nil {
+		return nil
+	}
+	return p.batcher.Stop(context.Background())
+}
+
+// PublishTo は queueKey に対応するキューに msg をJSONとして送信する
+// Validate() を持つメッセージは送信前に検証し、キューに送信できるサイズを超えている場合は *queuemodel.MessageTooLargeError を返す
+// コンテキストに相関IDが設定されている場合はメッセージ属性 correlation_id として付与する
+// トレースが有効な場合はコンテキストのスパンをメッセージ属性 traceparent (tracestate) として付与する
+func (p *SQSPublisher) PublishTo(ctx context.Context, queueKey string, msg any) error {
+	_, err := p.PublishWithID(ctx, queueKey, msg)
+	return err
+}
+
+// PublishWithID は PublishTo と同様に送信し、SQSが発行したメッセージID (MessageId) を返す
+func (p *SQSPublisher) PublishWithID(ctx context.Context, queueKey string, msg any) (string, error) {
+	body, err := encodeMessage(msg)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := p.resolveQueueURL(ctx, queueKey)
+	if err != nil {
+		return "", err
+	}
+
+	attributes := sqsAttributeCarrier{}
+	if correlationID := logger.CorrelationID(ctx); correlationID != "" {
+		attributes.Set(correlationIDAttribute, correlationID)
+	}
+	// ワーカーが同じトレースの続きとして処理できるよう、イベントのスパンをメッセージ属性で引き継ぐ
+	tracing.Inject(ctx, attributes)
+	if len(attributes) == 0 {
+		attributes = nil
+	}
+
+	if p.batcher != nil {
+		return p.batcher.Send(ctx, url, string(body), attributes)
+	}
+
+	start := time.Now()
+	out, err := p.client.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+		QueueUrl:          aws.String(url),
+		MessageBody:       aws.String(string(body)),
+		MessageAttributes: attributes,
+	})
+	p.metrics.SQSSendDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return "", sendError(err)
+	}
+	return aws.StringValue(out.MessageId), nil
+}
+
+// isThrottled はSQSが送信を制限したことによるエラーかどうかを返す
+func isThrottled(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	switch aerr.Code() {
+	case "RequestThrottled", "ThrottlingException", "Throttling":
+		return true
+	}
+	return false
+}
+
+// Check は有効な機能が使用するすべてのキューについて GetQueueAttributes を呼び出し、到達できるかを確認する
+func (p *SQSPublisher) Check(ctx context.Context) error {
+	for _, key := range p.queueKeys {
+		url, err := p.resolveQueueURL(ctx, key)
+		if err != nil {
+			return err
+		}
+		_, err = p.client.GetQueueAttributesWithContext(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(url),
+			AttributeNames: []*string{aws.String("QueueArn")},
+		})
+		if err != nil {
+			return fmt.Errorf("キューの属性の取得エラー (queue=%s): %w", url, err)
+		}
+	}
+	return nil
+}
+
+// Backlog は GetQueueAttributes の ApproximateNumberOfMessages を返す
+func (p *SQSPublisher) Backlog(ctx context.Context, queueKey string) (int, error) {
+	url, err := p.resolveQueueURL(ctx, queueKey)
+	if err != nil {
+		return 0, err
+	}
+	out, err := p.client.GetQueueAttributesWithContext(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(url),
+		AttributeNames: []*string{aws.String(sqs.QueueAttributeNameApproximateNumberOfMessages)},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("キューの属性の取得エラー (queue=%s): %w", url, err)
+	}
+	count, err := strconv.Atoi(aws.StringValue(out.Attributes[sqs.QueueAttributeNameApproximateNumberOfMessages]))
+	if err != nil {
+		return 0, fmt.Errorf("キューのメッセージ数の変換エラー (queue=%s): %w", url, err)
+	}
+	return count, nil
+}
+
+// resolveQueueURL はキーに対応するキューのURLを返す
+// 未解決の場合は GetQueueUrl で取得してキャッシュする
+func (p *SQSPublisher) resolveQueueURL(ctx context.Context, queueKey string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if url, ok := p.queueURLs[queueKey]; ok {
+		return url, nil
+	}
+
+	name, ok := p.cfg.QueueNameFor(queueKey)
+	if !ok {
+		return "", &UnknownQueueError{Key: queueKey}
+	}
+	out, err := p.client.GetQueueUrlWithContext(ctx, &sqs.GetQueueUrlInput{
+		QueueName: aws.String(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("キューURLの取得エラー (queue=%s): %w", name, err)
+	}
+
+	url := aws.StringValue(out.QueueUrl)
+	p.queueURLs[queueKey] = url
+	return url, nil
+}
@@ -0,0 +1,114 @@
+//go:build integration
+
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/tracing"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/fx/fxtest"
+)
+
+// ElasticMQ に送信したメッセージを受信し、本文と相関ID・トレースのメッセージ属性を確認する
+// 実行にはDockerが必要: go test -tags integration ./pkg/infra/queue -run TestSQSPublisherElasticMQ
+func TestSQSPublisherElasticMQ(t *testing.T) {
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "softwaremill/elasticmq-native:1.6.11",
+			ExposedPorts: []string{"9324/tcp"},
+			WaitingFor:   wait.ForListeningPort("9324/tcp"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("ElasticMQのコンテナの起動エラー: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Logf("ElasticMQのコンテナの停止エラー: %v", err)
+		}
+	})
+	endpoint, err := container.PortEndpoint(ctx, "9324/tcp", "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.AppConfig{
+		ElasticMQ: config.ElasticMQConfig{
+			Endpoint:  endpoint,
+			Queues:    map[string]string{config.QueueKeyMention: "mentions"},
+			Region:    "elasticmq",
+			AccessKey: "x",
+			SecretKey: "x",
+		},
+	}
+	client, err := NewSQSClient(cfg.ElasticMQ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := client.CreateQueueWithContext(ctx, &sqs.CreateQueueInput{QueueName: aws.String("mentions")})
+	if err != nil {
+		t.Fatalf("キューの作成エラー: %v", err)
+	}
+
+	// トレースが有効な場合と同じ propagator を設定し、イベントのスパンを traceparent で表す
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	publishCtx := tracing.WithTraceParent(logger.WithCorrelationID(ctx, "01HTGZ0000000000000000000A"), traceParent)
+
+	lc := fxtest.NewLifecycle(t)
+	publisher := NewSQSPublisherWithClient(lc, client, cfg, metrics.NewMetrics(prometheus.NewRegistry()))
+	lc.RequireStart()
+	t.Cleanup(func() { lc.RequireStop() })
+
+	messageID, err := publisher.PublishWithID(publishCtx, config.QueueKeyMention, map[string]string{"text": "質問"})
+	if err != nil {
+		t.Fatalf("PublishWithID() error = %v", err)
+	}
+
+	out, err := client.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:              created.QueueUrl,
+		MessageAttributeNames: aws.StringSlice([]string{"All"}),
+		WaitTimeSeconds:       aws.Int64(5),
+	})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if len(out.Messages) != 1 {
+		t.Fatalf("受信したメッセージ数 = %d, want 1", len(out.Messages))
+	}
+	m := out.Messages[0]
+	if got := aws.StringValue(m.MessageId); got != messageID {
+		t.Errorf("MessageId = %q, want %q", got, messageID)
+	}
+	if got, want := aws.StringValue(m.Body), `{"text":"質問"}`; got != want {
+		t.Errorf("Body = %s, want %s", got, want)
+	}
+	for name, want := range map[string]string{
+		correlationIDAttribute: "01HTGZ0000000000000000000A",
+		"traceparent":          traceParent,
+	} {
+		attr, ok := m.MessageAttributes[name]
+		if !ok {
+			t.Errorf("メッセージ属性 %s がありません: %v", name, m.MessageAttributes)
+			continue
+		}
+		if got := aws.StringValue(attr.StringValue); got != want {
+			t.Errorf("メッセージ属性 %s = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+// sqsAttributeCarrier はSQSのメッセージ属性にトレースの情報（traceparent, tracestate）を読み書きする
+// 送信時にイベントのスパンを設定し、受信したワーカーが同じトレースの続きとしてスパンを記録する
+type sqsAttributeCarrier map[string]*sqs.MessageAttributeValue
+
+func (c sqsAttributeCarrier) Get(key string) string {
+	if v, ok := c[key]; ok {
+		return aws.StringValue(v.StringValue)
+	}
+	return ""
+}
+
+func (c sqsAttributeCarrier) Set(key, value string) {
+	c[key] = &sqs.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(value),
+	}
+}
+
+func (c sqsAttributeCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/database"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+)
+
+type ConversationRepository struct {
+	db *bun.DB
+}
+
+func NewConversationRepository(db *bun.DB) di.ConversationRepository {
+	return &ConversationRepository{db: db}
+}
+
+func (r *ConversationRepository) FindByChannelAndThread(ctx context.Context, channelID, threadTS string) (*entity.Conversation, error) {
+	var conversation entity.Conversation
+	err := r.db.NewSelect().
+		Model(&conversation).
+		Where("channel_id = ?", channelID).
+		Where("thread_ts = ?", threadTS).
+		Scan(ctx)
+	return &conversation, err
+}
+
+// Create は (channel_id, thread_ts) の一意制約で同じスレッドの会話が同時に作成されるのを防ぐ
+// 競合した場合は終了済みの会話だけを置き換えるため、呼び出し元は保存後に取得し直して実際の会話を確認する
+// MySQLは SET を左から順に評価するため、判定に使う last_activity は最後に更新する
+func (r *ConversationRepository) Create(ctx context.Context, conversation *entity.Conversation, staleBefore time.Time) error {
+	columns := []string{"id", "started_by", "message_count", "created_at", "updated_at", "last_activity"}
+	q := r.db.NewInsert().Model(conversation)
+	_, err := database.OnConflictUpdateIf(q, []string{"channel_id", "thread_ts"}, columns, "last_activity", "<=", staleBefore).Exec(ctx)
+	return err
+}
+
+func (r *ConversationRepository) Touch(ctx context.Context, id ulid.ULID, at time.Time) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.Conversation)(nil)).
+		Set("message_count = message_count + 1").
+		Set("last_activity = ?", at).
+		Set("updated_at = ?", at).
+		Where("id = ?", dbtypes.ULID(id)).
+		Exec(ctx)
+	return err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"slices"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/crypto"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/database"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+)
+
+type ConversationMessageRepository struct {
+	db     *bun.DB
+	cipher *crypto.TextCipher
+}
+
+func NewConversationMessageRepository(db *bun.DB, cipher *crypto.TextCipher) di.ConversationMessageRepository {
+	return &ConversationMessageRepository{db: db, cipher: cipher}
+}
+
+// Save は (channel_id, thread_ts, ts) の一意制約で、再送されたイベントや回答を重複して保存しない
+func (r *ConversationMessageRepository) Save(ctx context.Context, message *entity.ConversationMessage) error {
+	message, err := r.cipher.EncryptConversationMessage(message)
+	if err != nil {
+		return err
+	}
+	_, err = database.OnConflictDoNothing(r.db.NewInsert().Model(message), "channel_id", "thread_ts", "ts").Exec(ctx)
+	return err
+}
+
+func (r *ConversationMessageRepository) FindByThread(ctx context.Context, channelID, threadTS string, limit int) ([]*entity.ConversationMessage, error) {
+	var messages []*entity.ConversationMessage
+	err := r.db.NewSelect().
+		Model(&messages).
+		Where("channel_id = ?", channelID).
+		Where("thread_ts = ?", threadTS).
+		Order("ts DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 最新の limit 件を取得するため新しい順に取得し、古い順に並べ替える
+	slices.Reverse(messages)
+	return messages, r.cipher.DecryptConversationMessages(messages)
+}
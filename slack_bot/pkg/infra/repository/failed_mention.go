@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/crypto"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+)
+
+// FailedMentionRepository はデッドレターを保存・取得する
+// encryption.key が設定されている場合は、メンションのテキストを含むメッセージを暗号化して保存し、取得時に復号する
+type FailedMentionRepository struct {
+	db     *bun.DB
+	cipher *crypto.TextCipher
+}
+
+func NewFailedMentionRepository(db *bun.DB, cipher *crypto.TextCipher) di.FailedMentionRepository {
+	return &FailedMentionRepository{db: db, cipher: cipher}
+}
+
+func (r *FailedMentionRepository) Create(ctx context.Context, mention *entity.FailedMention) error {
+	encrypted, err := r.cipher.EncryptFailedMention(mention)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.NewInsert().Model(encrypted).Exec(ctx); err != nil {
+		return err
+	}
+	mention.ID = encrypted.ID
+	return nil
+}
+
+func (r *FailedMentionRepository) FindPending(ctx context.Context, limit int) ([]*entity.FailedMention, error) {
+	var mentions []*entity.FailedMention
+	err := r.db.NewSelect().
+		Model(&mentions).
+		Where("replayed_at IS NULL").
+		Order("id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mentions, r.cipher.DecryptFailedMentions(mentions)
+}
+
+func (r *FailedMentionRepository) MarkReplayed(ctx context.Context, id int64, replayedAt time.Time) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.FailedMention)(nil)).
+		Set("replayed_at = ?", replayedAt).
+		Set("updated_at = ?", replayedAt).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *FailedMentionRepository) MarkFailed(ctx context.Context, id int64, failedAt time.Time, reason string) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.FailedMention)(nil)).
+		Set("attempts = attempts + 1").
+		Set("reason = ?", reason).
+		Set("failed_at = ?", failedAt).
+		Set("updated_at = ?", failedAt).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
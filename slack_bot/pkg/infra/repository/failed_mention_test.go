@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/crypto"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// encryption.key が設定されている場合、デッドレターのメッセージ（メンションのテキストを含む）を暗号化して保存する
+func TestFailedMentionRepositoryEncryptsPayload(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	if _, err := db.NewCreateTable().Model((*entity.FailedMention)(nil)).Exec(ctx); err != nil {
+		t.Fatalf("テーブルの作成エラー: %v", err)
+	}
+	cipher, err := crypto.NewTextCipher(&config.AppConfig{Encryption: config.EncryptionConfig{
+		Key: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository := NewFailedMentionRepository(db, cipher)
+
+	payload := json.RawMessage(`{"id":"01HTGZ0000000000000000000A","text":"デプロイの手順を教えてください"}`)
+	failed := entity.NewFailedMention(ulid.MustParse("01HTGZ0000000000000000000A"), "01HTGZ0000000000000000000A", "mention", payload, "queue unavailable")
+	if err := repository.Create(ctx, failed); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if failed.ID == 0 {
+		t.Error("Create() が保存した行のIDを設定していません")
+	}
+
+	var stored entity.FailedMention
+	if err := db.NewSelect().Model(&stored).Where("id = ?", failed.ID).Scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !stored.PayloadEncrypted || bytes.Contains(stored.Payload, []byte("デプロイ")) {
+		t.Errorf("保存したメッセージ = %s (encrypted=%v), want 暗号化したメッセージ", stored.Payload, stored.PayloadEncrypted)
+	}
+
+	pending, err := repository.FindPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("FindPending() error = %v", err)
+	}
+	if len(pending) != 1 || !bytes.Equal(pending[0].Payload, payload) || pending[0].PayloadEncrypted {
+		t.Errorf("FindPending() = %+v, want 復号したメッセージ %s", pending, payload)
+	}
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/crypto"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/database"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+)
+
+type OutboxRepository struct {
+	db     *bun.DB
+	cipher *crypto.TextCipher
+}
+
+func NewOutboxRepository(db *bun.DB, cipher *crypto.TextCipher) di.OutboxRepository {
+	return &OutboxRepository{db: db, cipher: cipher}
+}
+
+// CreateWithMention はメンションと送信するメッセージを同じトランザクションで保存する
+// 同じIDのメンションが既に保存されている場合（同じイベントの再処理）は、送信済みまたは送信待ちのためどちらも保存しない
+// メッセージはメンションのテキストを含むため、メンションと同じ鍵で暗号化して保存する
+func (r *OutboxRepository) CreateWithMention(ctx context.Context, mention *entity.SlackMention, message *entity.OutboxMessage) error {
+	mention, err := r.cipher.EncryptMention(mention)
+	if err != nil {
+		return err
+	}
+	encrypted, err := r.cipher.EncryptOutboxMessage(message)
+	if err != nil {
+		return err
+	}
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := database.OnConflictDoNothing(tx.NewInsert().Model(mention), "id").Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return nil
+		}
+		if _, err := tx.NewInsert().Model(encrypted).Exec(ctx); err != nil {
+			return err
+		}
+		message.ID = encrypted.ID
+		return nil
+	})
+}
+
+// ClaimPending は送信待ちのメッセージと、送信中のまま lease が切れたメッセージ（送信中にプロセスが停止したもの）を取得する
+// 複数のインスタンスで同じメッセージを取得しないように、トランザクション内で FOR UPDATE SKIP LOCKED で行をロックしてから更新する
+func (r *OutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.OutboxMessage, error) {
+	var messages []*entity.OutboxMessage
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.NewSelect().
+			Model(&messages).
+			WhereGroup(" OR ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("status = ? AND next_attempt_at <= ?", entity.OutboxStatusPending, now)
+			}).
+			WhereGroup(" OR ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("status = ? AND locked_until <= ?", entity.OutboxStatusProcessing, now)
+			}).
+			Order("id ASC").
+			Limit(limit).
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx)
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(messages))
+		for i, m := range messages {
+			ids[i] = m.ID
+		}
+		lockedUntil := now.Add(lease)
+		_, err = tx.NewUpdate().
+			Model((*entity.OutboxMessage)(nil)).
+			Set("status = ?", entity.OutboxStatusProcessing).
+			Set("locked_until = ?", lockedUntil).
+			Set("updated_at = ?", now).
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		for _, m := range messages {
+			m.Status = entity.OutboxStatusProcessing
+			m.LockedUntil = lockedUntil
+			m.UpdatedAt = now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, r.cipher.DecryptOutboxMessages(messages)
+}
+
+func (r *OutboxRepository) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.OutboxMessage)(nil)).
+		Set("status = ?", entity.OutboxStatusSent).
+		Set("sent_at = ?", sentAt).
+		Set("locked_until = NULL").
+		Set("updated_at = ?", sentAt).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+// MarkFailed は送信に失敗したメッセージの試行回数を増やし、nextAttemptAt 以降に再送されるようにする
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.OutboxMessage)(nil)).
+		Set("status = ?", entity.OutboxStatusPending).
+		Set("attempts = attempts + 1").
+		Set("next_attempt_at = ?", nextAttemptAt).
+		Set("last_error = ?", lastError).
+		Set("locked_until = NULL").
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+// CountPending は未送信（送信中を含む）のメッセージ数を返す
+func (r *OutboxRepository) CountPending(ctx context.Context) (int, error) {
+	return r.db.NewSelect().
+		Model((*entity.OutboxMessage)(nil)).
+		Where("status IN (?)", bun.In([]string{entity.OutboxStatusPending, entity.OutboxStatusProcessing})).
+		Count(ctx)
+}
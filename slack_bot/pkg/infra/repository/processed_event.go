@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/database"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+)
+
+type ProcessedEventRepository struct {
+	db *bun.DB
+}
+
+func NewProcessedEventRepository(db *bun.DB) di.ProcessedEventRepository {
+	return &ProcessedEventRepository{db: db}
+}
+
+// Claim は主キーの一意制約で、複数のプロセスが同じイベントを同時に受信してもどちらか一方だけが処理するようにする
+// 処理済みの記録が staleBefore より古い場合だけ上書きする。値を変更しなかった行は RowsAffected に含まれない
+func (r *ProcessedEventRepository) Claim(ctx context.Context, eventKey string, at, staleBefore time.Time) (bool, error) {
+	q := r.db.NewInsert().Model(&entity.ProcessedEvent{EventKey: eventKey, ProcessedAt: at})
+	res, err := database.OnConflictUpdateIf(q, []string{"event_key"}, []string{"processed_at"}, "processed_at", "<", staleBefore).Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *ProcessedEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.NewDelete().
+		Model((*entity.ProcessedEvent)(nil)).
+		Where("processed_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
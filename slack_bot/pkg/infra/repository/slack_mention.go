@@ -1,31 +1,24 @@
 package repository
 
 import (
-	"context"
-
-	"github.com/oklog/ulid/v2"
 	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
-	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/command"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/crypto"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/query"
 	"github.com/uptrace/bun"
 )
 
+// SlackMentionRepository はメンションを保存・取得する
+// 読み取りは query.SlackMentionQuery、書き込みは command.SlackMentionCommand で行い、同じデータベースを使用する
+// encryption.key が設定されている場合はテキストを暗号化して保存し、取得時に復号する
 type SlackMentionRepository struct {
-	db *bun.DB
-}
-
-func NewSlackMentionRepository(db *bun.DB) di.SlackMentionRepository {
-	return &SlackMentionRepository{db: db}
-}
-
-func (r *SlackMentionRepository) FindByID(ctx context.Context, id ulid.ULID) (*entity.SlackMention, error) {
-	var mention entity.SlackMention
-	err := r.db.NewSelect().Model(&mention).Where("id = ?", id).Scan(ctx)
-	return &mention, err
+	di.SlackMentionQuery
+	di.SlackMentionCommand
 }
 
-func (r *SlackMentionRepository) Create(ctx context.Context, mention *entity.SlackMention) error {
-	if _, err := r.db.NewInsert().Model(mention).Exec(ctx); err != nil {
-		return err
+func NewSlackMentionRepository(db *bun.DB, cipher *crypto.TextCipher) di.SlackMentionRepository {
+	return &SlackMentionRepository{
+		SlackMentionQuery:   query.NewSlackMentionQuery(db, cipher),
+		SlackMentionCommand: command.NewSlackMentionCommand(db, cipher),
 	}
-	return nil
 }
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// mentionCounter は件数を返す SlackMentionRepository と InMemorySlackMentionRepository の共通のメソッド
+type mentionCounter interface {
+	CountByUser(ctx context.Context, userID string) (int, error)
+	CountByChannel(ctx context.Context, channelID string) (int, error)
+	CountSince(ctx context.Context, since time.Time) (int, error)
+}
+
+func TestSlackMentionRepositoryCountsSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	if _, err := db.NewCreateTable().Model((*entity.SlackMention)(nil)).Exec(ctx); err != nil {
+		t.Fatalf("テーブルの作成エラー: %v", err)
+	}
+	testMentionCounts(t, NewSlackMentionRepository(db, nil), func(m *entity.SlackMention) error {
+		_, err := db.NewInsert().Model(m).Exec(ctx)
+		return err
+	})
+}
+
+func TestInMemorySlackMentionRepositoryCounts(t *testing.T) {
+	r := NewInMemorySlackMentionRepository()
+	testMentionCounts(t, r, func(m *entity.SlackMention) error {
+		return r.Create(context.Background(), m)
+	})
+}
+
+// testMentionCounts は複数のチャンネル・ユーザーのメンションを保存し、削除したメンションを除いた件数を確認する
+func testMentionCounts(t *testing.T, counter mentionCounter, create func(*entity.SlackMention) error) {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	deleted := newTestMention("U002", "C002", base.Add(3*time.Hour))
+	deleted.DeletedAt = base.Add(4 * time.Hour)
+	for _, m := range []*entity.SlackMention{
+		newTestMention("U001", "C001", base),
+		newTestMention("U001", "C001", base.Add(time.Hour)),
+		newTestMention("U001", "C002", base.Add(2*time.Hour)),
+		newTestMention("U002", "C001", base.Add(25*time.Hour)),
+		deleted,
+	} {
+		if err := create(m); err != nil {
+			t.Fatalf("保存エラー: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		count func() (int, error)
+		want  int
+	}{
+		{name: "ユーザーごとの件数", count: func() (int, error) { return counter.CountByUser(ctx, "U001") }, want: 3},
+		{name: "削除したメンションは数えない", count: func() (int, error) { return counter.CountByUser(ctx, "U002") }, want: 1},
+		{name: "メンションのないユーザーは0件", count: func() (int, error) { return counter.CountByUser(ctx, "U999") }, want: 0},
+		{name: "チャンネルごとの件数", count: func() (int, error) { return counter.CountByChannel(ctx, "C001") }, want: 3},
+		{name: "チャンネルの件数にも削除したメンションを含めない", count: func() (int, error) { return counter.CountByChannel(ctx, "C002") }, want: 1},
+		{name: "メンションのないチャンネルは0件", count: func() (int, error) { return counter.CountByChannel(ctx, "C999") }, want: 0},
+		{name: "指定した日時ちょうどのメンションを含める", count: func() (int, error) { return counter.CountSince(ctx, base.Add(time.Hour)) }, want: 3},
+		{name: "直近24時間の件数", count: func() (int, error) { return counter.CountSince(ctx, base.Add(26*time.Hour).Add(-24*time.Hour)) }, want: 2},
+		{name: "以降のメンションがない場合は0件", count: func() (int, error) { return counter.CountSince(ctx, base.Add(48*time.Hour)) }, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.count()
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("count = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
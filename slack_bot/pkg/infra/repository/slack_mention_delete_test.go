@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+func TestSlackMentionRepositoryDeleteByTSSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	if _, err := db.NewCreateTable().Model((*entity.SlackMention)(nil)).Exec(ctx); err != nil {
+		t.Fatalf("テーブルの作成エラー: %v", err)
+	}
+	testMentionDeleteByTS(t, NewSlackMentionRepository(db, nil), func(m *entity.SlackMention) error {
+		_, err := db.NewInsert().Model(m).Exec(ctx)
+		return err
+	})
+}
+
+func TestInMemorySlackMentionRepositoryDeleteByTS(t *testing.T) {
+	r := NewInMemorySlackMentionRepository()
+	testMentionDeleteByTS(t, r, func(m *entity.SlackMention) error {
+		return r.Create(context.Background(), m)
+	})
+}
+
+// testMentionDeleteByTS は同じメッセージへの複数のリアクションによる依頼を、チャンネルとタイムスタンプですべて論理削除できることを確認する
+func testMentionDeleteByTS(t *testing.T, r di.SlackMentionRepository, create func(*entity.SlackMention) error) {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	newReaction := func(userID, channelID, ts string, at time.Time) *entity.SlackMention {
+		m := newTestMention(userID, channelID, at)
+		m.Type = string(slack.MessageSourceReaction)
+		m.TS = ts
+		return m
+	}
+	byAlice := newReaction("U001", "C001", "1712345678.000200", base)
+	byBob := newReaction("U002", "C001", "1712345678.000200", base.Add(time.Minute))
+	otherMessage := newReaction("U001", "C001", "1712345678.000300", base.Add(2*time.Minute))
+	otherChannel := newReaction("U001", "C002", "1712345678.000200", base.Add(3*time.Minute))
+	// 同じメッセージへのメンションは種類が異なるため削除しない
+	mention := newTestMention("U001", "C001", base.Add(4*time.Minute))
+	mention.TS = "1712345678.000200"
+	for _, m := range []*entity.SlackMention{byAlice, byBob, otherMessage, otherChannel, mention} {
+		if err := create(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := r.DeleteByTS(ctx, string(slack.MessageSourceReaction), "C001", "1712345678.000200")
+	if err != nil || deleted != 2 {
+		t.Fatalf("DeleteByTS() = %d, %v, want 2, nil", deleted, err)
+	}
+	for _, tt := range []struct {
+		name        string
+		mention     *entity.SlackMention
+		wantDeleted bool
+	}{
+		{name: "リアクションしたユーザー1", mention: byAlice, wantDeleted: true},
+		{name: "リアクションしたユーザー2", mention: byBob, wantDeleted: true},
+		{name: "別のメッセージ", mention: otherMessage},
+		{name: "別のチャンネル", mention: otherChannel},
+		{name: "同じメッセージへのメンション", mention: mention},
+	} {
+		got, err := r.FindByID(ctx, slack.MentionID(tt.mention.ID))
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		if deleted := !got.DeletedAt.IsZero(); deleted != tt.wantDeleted {
+			t.Errorf("%s: 削除 = %v, want %v", tt.name, deleted, tt.wantDeleted)
+		}
+	}
+
+	// 削除済みのメンションは件数に含めない
+	if deleted, err := r.DeleteByTS(ctx, string(slack.MessageSourceReaction), "C001", "1712345678.000200"); err != nil || deleted != 0 {
+		t.Errorf("2回目の DeleteByTS() = %d, %v, want 0, nil", deleted, err)
+	}
+}
@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/query"
+)
+
+// InMemorySlackMentionRepository はメンションをプロセスのメモリに保存する
+// データベースなしでメンションの保存を含めて動作を確認するためのもので、SlackMentionRepository と同じ結果を返す
+// 保存したメンションは再起動すると失われる。テキストは暗号化しない
+type InMemorySlackMentionRepository struct {
+	mu       sync.RWMutex
+	mentions map[slack.MentionID]*entity.SlackMention
+}
+
+func NewInMemorySlackMentionRepository() di.SlackMentionRepository {
+	return &InMemorySlackMentionRepository{
+		mentions: make(map[slack.MentionID]*entity.SlackMention),
+	}
+}
+
+func (r *InMemorySlackMentionRepository) FindByID(ctx context.Context, id slack.MentionID) (*entity.SlackMention, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mention, ok := r.mentions[id]
+	if !ok {
+		return &entity.SlackMention{}, sql.ErrNoRows
+	}
+	return cloneMention(mention), nil
+}
+
+func (r *InMemorySlackMentionRepository) FindRawEventByID(ctx context.Context, id slack.MentionID, v any) error {
+	mention, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if mention.RawEvent == "" {
+		return fmt.Errorf("メンション %s の受信イベントは保存されていません", id)
+	}
+	if err := json.Unmarshal([]byte(mention.RawEvent), v); err != nil {
+		return fmt.Errorf("受信イベントのデコードエラー (id=%s): %w", id, err)
+	}
+	return nil
+}
+
+// Create はメンションを保存する
+// 同じIDのメンションが既に保存されている場合（同じイベントの再処理）は何もしない
+func (r *InMemorySlackMentionRepository) Create(ctx context.Context, mention *entity.SlackMention) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := slack.MentionID(mention.ID)
+	if _, ok := r.mentions[id]; ok {
+		return nil
+	}
+	r.mentions[id] = cloneMention(mention)
+	return nil
+}
+
+func (r *InMemorySlackMentionRepository) UpdateSQSMessageID(ctx context.Context, id slack.MentionID, messageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if mention, ok := r.mentions[id]; ok {
+		mention.SQSMessageID = messageID
+		mention.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// UpdateStatus はメンションの状態と理由を更新する
+// 現在の状態から遷移できない場合は slack.ErrInvalidStatusTransition、メンションがない場合は sql.ErrNoRows を返す
+func (r *InMemorySlackMentionRepository) UpdateStatus(ctx context.Context, id slack.MentionID, status string, detail string) error {
+	next, err := slack.ParseMentionStatus(status)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mention, ok := r.mentions[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if err := slack.MentionStatus(mention.Status).TransitionTo(next); err != nil {
+		return err
+	}
+	mention.Status = string(next)
+	mention.StatusDetail = detail
+	mention.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *InMemorySlackMentionRepository) Delete(ctx context.Context, id slack.MentionID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mention, ok := r.mentions[id]
+	if !ok || !mention.DeletedAt.IsZero() {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	mention.DeletedAt = now
+	mention.UpdatedAt = now
+	return nil
+}
+
+func (r *InMemorySlackMentionRepository) DeleteByTS(ctx context.Context, source string, channelID string, ts string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	deleted := 0
+	for _, mention := range r.mentions {
+		if mention.Type != source || mention.ChannelID != channelID || mention.TS != ts || !mention.DeletedAt.IsZero() {
+			continue
+		}
+		mention.DeletedAt = now
+		mention.UpdatedAt = now
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (r *InMemorySlackMentionRepository) FindCreatedBefore(ctx context.Context, before time.Time, limit int) ([]*entity.SlackMention, error) {
+	mentions := r.filter(func(m *entity.SlackMention) bool {
+		return m.CreatedAt.Before(before)
+	})
+	slices.SortFunc(mentions, func(a, b *entity.SlackMention) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return limitMentions(mentions, limit), nil
+}
+
+func (r *InMemorySlackMentionRepository) DeleteByIDs(ctx context.Context, ids []slack.MentionID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		delete(r.mentions, id)
+	}
+	return nil
+}
+
+// ListByUser はユーザーの削除されていないメンションを新しい順（同じ日時の場合はIDの降順）に取得する
+func (r *InMemorySlackMentionRepository) ListByUser(ctx context.Context, userID string, limit int) ([]*entity.SlackMention, error) {
+	mentions := r.filter(func(m *entity.SlackMention) bool {
+		return m.UserID == userID && m.DeletedAt.IsZero()
+	})
+	sortByEventTimeDesc(mentions)
+	return limitMentions(mentions, limit), nil
+}
+
+// FindLatestByUser はユーザーの削除されていないメンションを新しい順（同じ日時の場合はIDの降順）に取得する
+// limit が上限を超える場合は上限の件数を取得する
+func (r *InMemorySlackMentionRepository) FindLatestByUser(ctx context.Context, userID string, limit int) ([]*entity.SlackMention, error) {
+	if limit <= 0 || limit > query.MaxLatestByUserLimit {
+		limit = query.MaxLatestByUserLimit
+	}
+	mentions := r.filter(func(m *entity.SlackMention) bool {
+		return m.UserID == userID && m.DeletedAt.IsZero()
+	})
+	sortByEventTimeDesc(mentions)
+	return limitMentions(mentions, limit), nil
+}
+
+func (r *InMemorySlackMentionRepository) CountByUser(ctx context.Context, userID string) (int, error) {
+	mentions := r.filter(func(m *entity.SlackMention) bool {
+		return m.UserID == userID && m.DeletedAt.IsZero()
+	})
+	return len(mentions), nil
+}
+
+func (r *InMemorySlackMentionRepository) CountByChannel(ctx context.Context, channelID string) (int, error) {
+	mentions := r.filter(func(m *entity.SlackMention) bool {
+		return m.ChannelID == channelID && m.DeletedAt.IsZero()
+	})
+	return len(mentions), nil
+}
+
+func (r *InMemorySlackMentionRepository) CountSince(ctx context.Context, since time.Time) (int, error) {
+	mentions := r.filter(func(m *entity.SlackMention) bool {
+		return !m.EventTime.Before(since) && m.DeletedAt.IsZero()
+	})
+	return len(mentions), nil
+}
+
+// FindByEventTimeRange は期間内のメンションを event_time の昇順（同じ日時の場合はIDの昇順）に取得する
+func (r *InMemorySlackMentionRepository) FindByEventTimeRange(ctx context.Context, from, to time.Time, limit int) ([]*entity.SlackMention, error) {
+	if from.After(to) {
+		return nil, fmt.Errorf("期間の指定が不正です。開始日時 (%s) が終了日時 (%s) より後になっています", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	mentions := r.filter(func(m *entity.SlackMention) bool {
+		return !m.EventTime.Before(from) && !m.EventTime.After(to) && m.DeletedAt.IsZero()
+	})
+	slices.SortFunc(mentions, func(a, b *entity.SlackMention) int {
+		if c := a.EventTime.Compare(b.EventTime); c != 0 {
+			return c
+		}
+		return ulid.ULID(a.ID).Compare(ulid.ULID(b.ID))
+	})
+	return limitMentions(mentions, limit), nil
+}
+
+func (r *InMemorySlackMentionRepository) CountGroupedByChannelBetween(ctx context.Context, from, to time.Time) ([]*entity.MentionCount, error) {
+	return r.countGroupedBetween(func(m *entity.SlackMention) string { return m.ChannelID }, from, to, 0), nil
+}
+
+func (r *InMemorySlackMentionRepository) CountGroupedByUserBetween(ctx context.Context, from, to time.Time, limit int) ([]*entity.MentionCount, error) {
+	return r.countGroupedBetween(func(m *entity.SlackMention) string { return m.UserID }, from, to, limit), nil
+}
+
+// countGroupedBetween は期間内のメンション数を key ごとに集計し、多い順（同数の場合は key の昇順）に返す
+// limit が 0 の場合はすべての集計結果を返す
+func (r *InMemorySlackMentionRepository) countGroupedBetween(key func(*entity.SlackMention) string, from, to time.Time, limit int) []*entity.MentionCount {
+	mentions := r.filter(func(m *entity.SlackMention) bool {
+		return !m.EventTime.Before(from) && m.EventTime.Before(to) && m.DeletedAt.IsZero()
+	})
+	counts := make(map[string]int)
+	for _, m := range mentions {
+		counts[key(m)]++
+	}
+
+	result := make([]*entity.MentionCount, 0, len(counts))
+	for targetID, count := range counts {
+		result = append(result, &entity.MentionCount{TargetID: targetID, Count: count})
+	}
+	slices.SortFunc(result, func(a, b *entity.MentionCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.TargetID, b.TargetID)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// ListBetween は期間内のメンションをIDの順に取得する
+func (r *InMemorySlackMentionRepository) ListBetween(ctx context.Context, from, to time.Time, cursor string, limit int) ([]*entity.SlackMention, string, error) {
+	var after ulid.ULID
+	if cursor != "" {
+		var err error
+		if after, err = ulid.ParseStrict(cursor); err != nil {
+			return nil, "", fmt.Errorf("カーソルが不正です: %q", cursor)
+		}
+	}
+	mentions := r.filter(func(m *entity.SlackMention) bool {
+		return !m.EventTime.Before(from) && m.EventTime.Before(to) && m.DeletedAt.IsZero() &&
+			(cursor == "" || ulid.ULID(m.ID).Compare(after) > 0)
+	})
+	slices.SortFunc(mentions, func(a, b *entity.SlackMention) int {
+		return ulid.ULID(a.ID).Compare(ulid.ULID(b.ID))
+	})
+	mentions = limitMentions(mentions, limit)
+	return mentions, query.NextMentionCursor(mentions, limit), nil
+}
+
+// Stream は条件に一致するメンションをIDの順にチャネルに送信する
+func (r *InMemorySlackMentionRepository) Stream(ctx context.Context, filter di.SlackMentionFilter) (<-chan *entity.SlackMention, <-chan error) {
+	mentions := make(chan *entity.SlackMention)
+	errs := make(chan error, 1)
+
+	page := r.filter(func(m *entity.SlackMention) bool {
+		return (filter.UserID == "" || m.UserID == filter.UserID) &&
+			(filter.ChannelID == "" || m.ChannelID == filter.ChannelID) &&
+			(filter.CreatedAfter.IsZero() || !m.CreatedAt.Before(filter.CreatedAfter)) &&
+			(filter.CreatedBefore.IsZero() || m.CreatedAt.Before(filter.CreatedBefore))
+	})
+	slices.SortFunc(page, func(a, b *entity.SlackMention) int {
+		return ulid.ULID(a.ID).Compare(ulid.ULID(b.ID))
+	})
+
+	go func() {
+		defer close(mentions)
+		defer close(errs)
+
+		for _, m := range page {
+			select {
+			case mentions <- m:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return mentions, errs
+}
+
+// filter は条件に一致するメンションの複製を返す
+func (r *InMemorySlackMentionRepository) filter(match func(*entity.SlackMention) bool) []*entity.SlackMention {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var mentions []*entity.SlackMention
+	for _, m := range r.mentions {
+		if match(m) {
+			mentions = append(mentions, cloneMention(m))
+		}
+	}
+	return mentions
+}
+
+// 呼び出し側が変更しても保存したメンションに影響しないよう複製する
+func cloneMention(m *entity.SlackMention) *entity.SlackMention {
+	c := *m
+	return &c
+}
+
+// sortByEventTimeDesc は event_time の新しい順（同じ日時の場合はIDの降順）に並べる
+func sortByEventTimeDesc(mentions []*entity.SlackMention) {
+	slices.SortFunc(mentions, func(a, b *entity.SlackMention) int {
+		if c := b.EventTime.Compare(a.EventTime); c != 0 {
+			return c
+		}
+		return ulid.ULID(b.ID).Compare(ulid.ULID(a.ID))
+	})
+}
+
+// limitMentions は先頭から最大 limit 件を返す（limit が 0 以下の場合はすべて）
+func limitMentions(mentions []*entity.SlackMention, limit int) []*entity.SlackMention {
+	if limit > 0 && len(mentions) > limit {
+		return mentions[:limit]
+	}
+	return mentions
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// newTestMention はテスト用のメンションを作成する（発生時刻の順にIDが大きくなる）
+func newTestMention(userID, channelID string, eventTime time.Time) *entity.SlackMention {
+	return &entity.SlackMention{
+		ID:        dbtypes.ULID(ulid.MustNew(ulid.Timestamp(eventTime), ulid.DefaultEntropy())),
+		Type:      string(slack.MessageSourceMention),
+		UserID:    userID,
+		ChannelID: channelID,
+		Text:      "質問",
+		Status:    string(slack.MentionStatusReceived),
+		Timestamp: eventTime,
+		EventTime: eventTime,
+		CreatedAt: eventTime,
+		UpdatedAt: eventTime,
+	}
+}
+
+func TestInMemorySlackMentionRepositoryCreateAndFindByID(t *testing.T) {
+	ctx := context.Background()
+	r := NewInMemorySlackMentionRepository()
+	mention := newTestMention("U001", "C001", time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC))
+	if err := r.Create(ctx, mention); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同じIDのメンションは上書きしない
+	duplicate := *mention
+	duplicate.Text = "再処理"
+	if err := r.Create(ctx, &duplicate); err != nil {
+		t.Fatalf("重複した Create() error = %v", err)
+	}
+	// 保存後に呼び出し側が変更しても保存したメンションに影響しない
+	mention.Text = "変更"
+
+	got, err := r.FindByID(ctx, slack.MentionID(mention.ID))
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Text != "質問" {
+		t.Errorf("Text = %q, want %q", got.Text, "質問")
+	}
+	got.Text = "取得後の変更"
+	if again, _ := r.FindByID(ctx, slack.MentionID(mention.ID)); again.Text != "質問" {
+		t.Errorf("取得したメンションの変更が保存したメンションに影響しました: %q", again.Text)
+	}
+
+	if _, err := r.FindByID(ctx, slack.MentionID(ulid.Make())); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("存在しないIDの FindByID() error = %v, want %v", err, sql.ErrNoRows)
+	}
+}
+
+func TestInMemorySlackMentionRepositoryListByUser(t *testing.T) {
+	ctx := context.Background()
+	r := NewInMemorySlackMentionRepository()
+	base := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	oldest := newTestMention("U001", "C001", base)
+	middle := newTestMention("U001", "C002", base.Add(time.Minute))
+	deleted := newTestMention("U001", "C001", base.Add(2*time.Minute))
+	newest := newTestMention("U001", "C001", base.Add(3*time.Minute))
+	other := newTestMention("U002", "C001", base.Add(4*time.Minute))
+	for _, m := range []*entity.SlackMention{oldest, middle, deleted, newest, other} {
+		if err := r.Create(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Delete(ctx, slack.MentionID(deleted.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []*entity.SlackMention
+	}{
+		{name: "削除したメンションと他のユーザーを除いて新しい順に返す", limit: 10, want: []*entity.SlackMention{newest, middle, oldest}},
+		{name: "limit 件まで返す", limit: 2, want: []*entity.SlackMention{newest, middle}},
+		{name: "limit が 0 の場合はすべて返す", limit: 0, want: []*entity.SlackMention{newest, middle, oldest}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.ListByUser(ctx, "U001", tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ListByUser() = %d件, want %d件", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].ID != tt.want[i].ID {
+					t.Errorf("ListByUser()[%d] = %s, want %s", i, got[i].ID, tt.want[i].ID)
+				}
+			}
+		})
+	}
+
+	if got, err := r.ListByUser(ctx, "U999", 10); err != nil || len(got) != 0 {
+		t.Errorf("メンションのないユーザーの ListByUser() = %d件, %v, want 0件, nil", len(got), err)
+	}
+}
+
+func TestInMemorySlackMentionRepositoryDelete(t *testing.T) {
+	ctx := context.Background()
+	r := NewInMemorySlackMentionRepository()
+	mention := newTestMention("U001", "C001", time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC))
+	if err := r.Create(ctx, mention); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(ctx, slack.MentionID(mention.ID)); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	// 削除済みと存在しないメンションは sql.ErrNoRows を返す
+	if err := r.Delete(ctx, slack.MentionID(mention.ID)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("削除済みの Delete() error = %v, want %v", err, sql.ErrNoRows)
+	}
+	if err := r.Delete(ctx, slack.MentionID(ulid.Make())); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("存在しない Delete() error = %v, want %v", err, sql.ErrNoRows)
+	}
+	// 論理削除のため FindByID では取得できる
+	if got, err := r.FindByID(ctx, slack.MentionID(mention.ID)); err != nil || got.DeletedAt.IsZero() {
+		t.Errorf("FindByID() = deleted_at %v, %v, want 削除日時あり", got.DeletedAt, err)
+	}
+}
+
+func TestInMemorySlackMentionRepositoryUpdateStatus(t *testing.T) {
+	ctx := context.Background()
+	r := NewInMemorySlackMentionRepository()
+	mention := newTestMention("U001", "C001", time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC))
+	if err := r.Create(ctx, mention); err != nil {
+		t.Fatal(err)
+	}
+	id := slack.MentionID(mention.ID)
+
+	if err := r.UpdateStatus(ctx, id, string(slack.MentionStatusFailed), "queue unavailable"); err != nil {
+		t.Fatalf("UpdateStatus(failed) error = %v", err)
+	}
+	got, _ := r.FindByID(ctx, id)
+	if got.Status != string(slack.MentionStatusFailed) || got.StatusDetail != "queue unavailable" || !got.UpdatedAt.After(mention.UpdatedAt) {
+		t.Errorf("status = %s detail = %q updated_at = %v, want failed, queue unavailable, 更新あり", got.Status, got.StatusDetail, got.UpdatedAt)
+	}
+	if err := r.UpdateStatus(ctx, id, string(slack.MentionStatusAnswered), ""); err != nil {
+		t.Fatalf("UpdateStatus(answered) error = %v", err)
+	}
+
+	if err := r.UpdateStatus(ctx, id, string(slack.MentionStatusQueued), ""); !errors.Is(err, slack.ErrInvalidStatusTransition) {
+		t.Errorf("answered -> queued の UpdateStatus() error = %v, want %v", err, slack.ErrInvalidStatusTransition)
+	}
+	if err := r.UpdateStatus(ctx, id, "unknown", ""); !errors.Is(err, slack.ErrInvalidMentionStatus) {
+		t.Errorf("定義されていない状態の UpdateStatus() error = %v, want %v", err, slack.ErrInvalidMentionStatus)
+	}
+	if err := r.UpdateStatus(ctx, slack.MentionID(ulid.Make()), string(slack.MentionStatusQueued), ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("存在しないメンションの UpdateStatus() error = %v, want %v", err, sql.ErrNoRows)
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/query"
+)
+
+// latestMentionFinder はユーザーの最近のメンションを返す SlackMentionRepository と InMemorySlackMentionRepository の共通のメソッド
+type latestMentionFinder interface {
+	FindLatestByUser(ctx context.Context, userID string, limit int) ([]*entity.SlackMention, error)
+}
+
+func TestSlackMentionRepositoryFindLatestByUserSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	if _, err := db.NewCreateTable().Model((*entity.SlackMention)(nil)).Exec(ctx); err != nil {
+		t.Fatalf("テーブルの作成エラー: %v", err)
+	}
+	testFindLatestByUser(t, NewSlackMentionRepository(db, nil), func(m *entity.SlackMention) error {
+		_, err := db.NewInsert().Model(m).Exec(ctx)
+		return err
+	})
+}
+
+func TestInMemorySlackMentionRepositoryFindLatestByUser(t *testing.T) {
+	r := NewInMemorySlackMentionRepository()
+	testFindLatestByUser(t, r, func(m *entity.SlackMention) error {
+		return r.Create(context.Background(), m)
+	})
+}
+
+// testFindLatestByUser はメンションが0件・1件・上限を超える件数のユーザーについて、
+// 新しい順の並び（同じ日時の場合はIDの降順）と件数の上限を確認する
+func testFindLatestByUser(t *testing.T, finder latestMentionFinder, create func(*entity.SlackMention) error) {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+
+	// U001: 1件（削除したメンションと他のユーザーのメンションは含めない）
+	single := newTestMention("U001", "C001", base)
+	deleted := newTestMention("U001", "C001", base.Add(time.Hour))
+	deleted.DeletedAt = base.Add(2 * time.Hour)
+
+	// U002: 上限を超える件数。最新の日時に2件あり、IDの大きい方を先に返す
+	many := make([]*entity.SlackMention, 0, query.MaxLatestByUserLimit)
+	for i := range query.MaxLatestByUserLimit {
+		many = append(many, newTestMention("U002", "C001", base.Add(time.Duration(i)*time.Minute)))
+	}
+	latest := base.Add(time.Duration(query.MaxLatestByUserLimit) * time.Minute)
+	tieSmaller := newTestMention("U002", "C002", latest)
+	tieSmaller.ID = dbtypes.ULID(ulid.MustNew(ulid.Timestamp(base), ulid.DefaultEntropy()))
+	tieLarger := newTestMention("U002", "C002", latest)
+	tieLarger.ID = dbtypes.ULID(ulid.MustNew(ulid.Timestamp(latest), ulid.DefaultEntropy()))
+
+	// 日時とIDの順番とは異なる順番で保存する
+	seed := append([]*entity.SlackMention{tieSmaller, single, deleted, tieLarger}, many...)
+	for _, m := range seed {
+		if err := create(m); err != nil {
+			t.Fatalf("保存エラー: %v", err)
+		}
+	}
+
+	t.Run("メンションがないユーザーは0件", func(t *testing.T) {
+		got, err := finder.FindLatestByUser(ctx, "U999", 10)
+		if err != nil {
+			t.Fatalf("FindLatestByUser() error = %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("FindLatestByUser() = %d件, want 0件", len(got))
+		}
+	})
+
+	t.Run("削除したメンションは含めない", func(t *testing.T) {
+		got, err := finder.FindLatestByUser(ctx, "U001", 10)
+		if err != nil {
+			t.Fatalf("FindLatestByUser() error = %v", err)
+		}
+		if len(got) != 1 || got[0].ID != single.ID {
+			t.Errorf("FindLatestByUser() = %v, want [%s]", mentionIDs(got), single.ID)
+		}
+	})
+
+	t.Run("新しい順に返し、同じ日時の場合はIDの降順", func(t *testing.T) {
+		got, err := finder.FindLatestByUser(ctx, "U002", 4)
+		if err != nil {
+			t.Fatalf("FindLatestByUser() error = %v", err)
+		}
+		n := len(many)
+		want := []dbtypes.ULID{tieLarger.ID, tieSmaller.ID, many[n-1].ID, many[n-2].ID}
+		if ids := mentionIDs(got); !slices.Equal(ids, want) {
+			t.Errorf("FindLatestByUser() = %v, want %v", ids, want)
+		}
+	})
+
+	t.Run("件数の上限", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			limit int
+			want  int
+		}{
+			{name: "指定した件数", limit: 1, want: 1},
+			{name: "上限ちょうど", limit: query.MaxLatestByUserLimit, want: query.MaxLatestByUserLimit},
+			{name: "上限を超える場合は上限の件数", limit: query.MaxLatestByUserLimit + 1, want: query.MaxLatestByUserLimit},
+			{name: "0 の場合は上限の件数", limit: 0, want: query.MaxLatestByUserLimit},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := finder.FindLatestByUser(ctx, "U002", tt.limit)
+				if err != nil {
+					t.Fatalf("FindLatestByUser() error = %v", err)
+				}
+				if len(got) != tt.want {
+					t.Errorf("FindLatestByUser(%d) = %d件, want %d件", tt.limit, len(got), tt.want)
+				}
+			})
+		}
+	})
+}
+
+// mentionIDs はメンションのIDを順番に返す
+func mentionIDs(mentions []*entity.SlackMention) []dbtypes.ULID {
+	ids := make([]dbtypes.ULID, 0, len(mentions))
+	for _, m := range mentions {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
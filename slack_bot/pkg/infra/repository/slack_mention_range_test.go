@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// mentionRangeFinder は期間内のメンションを返す SlackMentionRepository と InMemorySlackMentionRepository の共通のメソッド
+type mentionRangeFinder interface {
+	FindByEventTimeRange(ctx context.Context, from, to time.Time, limit int) ([]*entity.SlackMention, error)
+}
+
+func TestSlackMentionRepositoryFindByEventTimeRangeSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	if _, err := db.NewCreateTable().Model((*entity.SlackMention)(nil)).Exec(ctx); err != nil {
+		t.Fatalf("テーブルの作成エラー: %v", err)
+	}
+	testFindByEventTimeRange(t, NewSlackMentionRepository(db, nil), func(m *entity.SlackMention) error {
+		_, err := db.NewInsert().Model(m).Exec(ctx)
+		return err
+	})
+}
+
+func TestInMemorySlackMentionRepositoryFindByEventTimeRange(t *testing.T) {
+	r := NewInMemorySlackMentionRepository()
+	testFindByEventTimeRange(t, r, func(m *entity.SlackMention) error {
+		return r.Create(context.Background(), m)
+	})
+}
+
+// testFindByEventTimeRange は期間の境界・空の期間・古い順の並び（同じ日時の場合はIDの順）を確認する
+func testFindByEventTimeRange(t *testing.T, finder mentionRangeFinder, create func(*entity.SlackMention) error) {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+
+	// 同じ日時のメンションはIDの順に並ぶよう、保存する順番とIDの順番を逆にする
+	tieLater := newTestMention("U003", "C001", base.Add(2*time.Hour))
+	tieLater.ID = dbtypes.ULID(ulid.MustNew(ulid.Timestamp(base.Add(2*time.Hour)), ulid.DefaultEntropy()))
+	tieEarlier := newTestMention("U004", "C001", base.Add(2*time.Hour))
+	tieEarlier.ID = dbtypes.ULID(ulid.MustNew(ulid.Timestamp(base), ulid.DefaultEntropy()))
+	deleted := newTestMention("U005", "C001", base.Add(time.Hour))
+	deleted.DeletedAt = base.Add(3 * time.Hour)
+
+	mentions := map[string]*entity.SlackMention{
+		"before":      newTestMention("U001", "C001", base.Add(-time.Second)),
+		"from":        newTestMention("U001", "C001", base),
+		"middle":      newTestMention("U002", "C002", base.Add(time.Hour)),
+		"tie_later":   tieLater,
+		"tie_earlier": tieEarlier,
+		"to":          newTestMention("U001", "C001", base.Add(3*time.Hour)),
+		"after":       newTestMention("U001", "C001", base.Add(3*time.Hour+time.Second)),
+		"deleted":     deleted,
+	}
+	// 日時の順番とは異なる順番で保存する
+	for _, key := range []string{"to", "tie_later", "after", "middle", "deleted", "tie_earlier", "from", "before"} {
+		if err := create(mentions[key]); err != nil {
+			t.Fatalf("保存エラー: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		limit    int
+		want     []string
+	}{
+		{
+			name: "開始日時と終了日時ちょうどのメンションを含め、古い順に返す",
+			from: base, to: base.Add(3 * time.Hour), limit: 10,
+			want: []string{"from", "middle", "tie_earlier", "tie_later", "to"},
+		},
+		{
+			name: "範囲の外側のメンションは含めない",
+			from: base.Add(time.Second), to: base.Add(3*time.Hour - time.Second), limit: 10,
+			want: []string{"middle", "tie_earlier", "tie_later"},
+		},
+		{
+			name: "開始日時と終了日時が同じ場合はその日時のメンションだけを返す",
+			from: base.Add(2 * time.Hour), to: base.Add(2 * time.Hour), limit: 10,
+			want: []string{"tie_earlier", "tie_later"},
+		},
+		{
+			name: "期間内にメンションがない場合は空",
+			from: base.Add(4 * time.Hour), to: base.Add(5 * time.Hour), limit: 10,
+		},
+		{
+			name: "件数の上限までを古い順に返す",
+			from: base.Add(-time.Hour), to: base.Add(4 * time.Hour), limit: 3,
+			want: []string{"before", "from", "middle"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := finder.FindByEventTimeRange(ctx, tt.from, tt.to, tt.limit)
+			if err != nil {
+				t.Fatalf("FindByEventTimeRange() error = %v", err)
+			}
+			gotIDs := make([]dbtypes.ULID, 0, len(got))
+			for _, m := range got {
+				gotIDs = append(gotIDs, m.ID)
+			}
+			wantIDs := make([]dbtypes.ULID, 0, len(tt.want))
+			for _, key := range tt.want {
+				wantIDs = append(wantIDs, mentions[key].ID)
+			}
+			if !slices.Equal(gotIDs, wantIDs) {
+				t.Errorf("FindByEventTimeRange() = %v, want %v (%v)", gotIDs, wantIDs, tt.want)
+			}
+		})
+	}
+
+	t.Run("開始日時が終了日時より後の場合はエラー", func(t *testing.T) {
+		if _, err := finder.FindByEventTimeRange(ctx, base.Add(time.Hour), base, 10); err == nil {
+			t.Error("FindByEventTimeRange() error = nil, want エラー")
+		}
+	})
+}
@@ -0,0 +1,61 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mysql"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// 実行にはDockerが必要: go test -tags integration ./pkg/infra/repository -run TestSlackMentionRoundTrip
+func TestSlackMentionRoundTripPostgres(t *testing.T) {
+	ctx := context.Background()
+	container, err := postgres.Run(ctx, "postgres:16-alpine",
+		postgres.WithDatabase("slackbot"),
+		testcontainers.WithWaitStrategy(wait.ForLog("database system is ready to accept connections").WithOccurrence(2)),
+	)
+	if err != nil {
+		t.Fatalf("Postgresのコンテナの起動エラー: %v", err)
+	}
+	t.Cleanup(func() { container.Terminate(ctx) })
+	dsn, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := bun.NewDB(sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(dsn))), pgdialect.New())
+	t.Cleanup(func() { db.Close() })
+	testSlackMentionRoundTrip(t, db)
+}
+
+func TestSlackMentionRoundTripMySQL(t *testing.T) {
+	ctx := context.Background()
+	container, err := mysql.Run(ctx, "mysql:8.0", mysql.WithDatabase("slackbot"))
+	if err != nil {
+		t.Fatalf("MySQLのコンテナの起動エラー: %v", err)
+	}
+	t.Cleanup(func() { container.Terminate(ctx) })
+	dsn, err := container.ConnectionString(ctx, "parseTime=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqldb, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := bun.NewDB(sqldb, mysqldialect.New())
+	t.Cleanup(func() { db.Close() })
+	testSlackMentionRoundTrip(t, db)
+}
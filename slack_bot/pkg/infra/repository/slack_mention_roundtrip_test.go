@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+// NewMention で作成したIDのメンションを保存し、FindByID で同じIDのメンションを取得できることを確認する
+// Postgres と MySQL は slack_mention_roundtrip_integration_test.go で同じ確認を行う
+func TestSlackMentionRoundTripSQLite(t *testing.T) {
+	testSlackMentionRoundTrip(t, newSQLiteTestDB(t))
+}
+
+// newSQLiteTestDB はテストごとのメモリ上の SQLite データベースを作成する
+func newSQLiteTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqldb, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// :memory: のデータベースは接続ごとに作成されるため、接続を1つにする
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func testSlackMentionRoundTrip(t *testing.T, db *bun.DB) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := db.NewCreateTable().Model((*entity.SlackMention)(nil)).Exec(ctx); err != nil {
+		t.Fatalf("テーブルの作成エラー: %v", err)
+	}
+
+	// DATETIME は秒未満を保存しないデータベースがあるため秒単位の時刻にする
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	id, err := slack.NewMentionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conversationID := slack.ConversationID(ulid.Make())
+	tests := []struct {
+		name           string
+		id             slack.MentionID
+		conversationID slack.ConversationID
+	}{
+		{name: "会話のないメンション", id: id},
+		{name: "会話のあるメンション", id: mustMentionIDFromEvent(t, now), conversationID: conversationID},
+	}
+
+	repository := NewSlackMentionRepository(db, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mention, err := slack.NewMention(tt.id, slack.MessageSourceMention, "U001", "C001", "質問", slack.Timestamp(now), slack.EventTime(now), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mention.ConversationID = tt.conversationID
+			row, err := entity.NewSlackMention(mention)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := repository.Create(ctx, row); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			// 同じイベントの再処理で同じIDを保存しても、エラーにせず最初に保存したメンションを残す
+			duplicate := *row
+			duplicate.Text = "再処理"
+			if err := repository.Create(ctx, &duplicate); err != nil {
+				t.Fatalf("同じIDの Create() error = %v", err)
+			}
+
+			found, err := repository.FindByID(ctx, slack.MentionID(tt.id))
+			if err != nil {
+				t.Fatalf("FindByID() error = %v", err)
+			}
+			got := found.ToModel()
+			if got.ID != tt.id {
+				t.Errorf("ID = %s, want %s", got.ID, tt.id)
+			}
+			if got.ConversationID != tt.conversationID {
+				t.Errorf("ConversationID = %s, want %s", ulid.ULID(got.ConversationID), ulid.ULID(tt.conversationID))
+			}
+			if got.Text != mention.Text || !time.Time(got.Timestamp).Equal(now) {
+				t.Errorf("Text, Timestamp = %q, %v, want %q, %v", got.Text, time.Time(got.Timestamp), mention.Text, now)
+			}
+
+			// 26文字の文字列として保存し、文字列のIDでも検索できる
+			var stored string
+			if err := db.NewSelect().Model((*entity.SlackMention)(nil)).Column("id").Where("id = ?", tt.id.String()).Scan(ctx, &stored); err != nil {
+				t.Fatalf("文字列のIDでの検索エラー: %v", err)
+			}
+			if stored != tt.id.String() {
+				t.Errorf("保存したID = %q, want %q", stored, tt.id.String())
+			}
+		})
+	}
+}
+
+func mustMentionIDFromEvent(t *testing.T, at time.Time) slack.MentionID {
+	t.Helper()
+	id, err := slack.NewMentionIDFromEvent(slack.MessageSourceMention, "C001", slack.Timestamp(at))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// mentionStreamer はメンションを順に返す SlackMentionQuery と InMemorySlackMentionRepository の共通のメソッド
+type mentionStreamer interface {
+	Stream(ctx context.Context, filter di.SlackMentionFilter) (<-chan *entity.SlackMention, <-chan error)
+}
+
+func TestSlackMentionRepositoryStreamSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	if _, err := db.NewCreateTable().Model((*entity.SlackMention)(nil)).Exec(ctx); err != nil {
+		t.Fatalf("テーブルの作成エラー: %v", err)
+	}
+	testMentionStream(t, NewSlackMentionRepository(db, nil), func(m *entity.SlackMention) error {
+		_, err := db.NewInsert().Model(m).Exec(ctx)
+		return err
+	})
+}
+
+func TestInMemorySlackMentionRepositoryStream(t *testing.T) {
+	r := NewInMemorySlackMentionRepository()
+	testMentionStream(t, r, func(m *entity.SlackMention) error {
+		return r.Create(context.Background(), m)
+	})
+}
+
+// collectStream はメンションと最後のエラーをすべて受信する
+func collectStream(mentions <-chan *entity.SlackMention, errs <-chan error) ([]*entity.SlackMention, error) {
+	var got []*entity.SlackMention
+	for m := range mentions {
+		got = append(got, m)
+	}
+	return got, <-errs
+}
+
+// testMentionStream はSQLのページ（500件）をまたぐ件数のメンションを順不同に保存し、
+// IDの昇順・条件での絞り込み・途中での停止・コンテキストのキャンセルを確認する
+func testMentionStream(t *testing.T, streamer mentionStreamer, create func(*entity.SlackMention) error) {
+	t.Helper()
+	const n = 1001
+	base := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	seed := make([]*entity.SlackMention, 0, n+2)
+	for i := range n {
+		seed = append(seed, newTestMention("U001", "C001", base.Add(time.Duration(i)*time.Second)))
+	}
+	seed = append(seed,
+		newTestMention("U002", "C002", base.Add(5*time.Second)),
+		newTestMention("U002", "C001", base.Add(6*time.Second)),
+	)
+	rand.New(rand.NewPCG(1, 2)).Shuffle(len(seed), func(i, j int) { seed[i], seed[j] = seed[j], seed[i] })
+	for _, m := range seed {
+		if err := create(m); err != nil {
+			t.Fatalf("保存エラー: %v", err)
+		}
+	}
+
+	t.Run("保存した順に関係なくIDの昇順で返す", func(t *testing.T) {
+		got, err := collectStream(streamer.Stream(context.Background(), di.SlackMentionFilter{UserID: "U001"}))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		if len(got) != n {
+			t.Fatalf("Stream() = %d件, want %d件", len(got), n)
+		}
+		for i := 1; i < len(got); i++ {
+			if ulid.ULID(got[i-1].ID).Compare(ulid.ULID(got[i].ID)) >= 0 {
+				t.Fatalf("Stream()[%d] = %s が前のID %s 以下です", i, got[i].ID, got[i-1].ID)
+			}
+		}
+	})
+
+	t.Run("条件に一致するメンションだけを返す", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			filter di.SlackMentionFilter
+			want   int
+		}{
+			{name: "チャンネル", filter: di.SlackMentionFilter{ChannelID: "C002"}, want: 1},
+			{name: "ユーザーとチャンネル", filter: di.SlackMentionFilter{UserID: "U002", ChannelID: "C001"}, want: 1},
+			// 開始は含み、終了は含まない
+			{name: "作成日時の範囲", filter: di.SlackMentionFilter{UserID: "U001", CreatedAfter: base.Add(10 * time.Second), CreatedBefore: base.Add(20 * time.Second)}, want: 10},
+			{name: "一致するメンションがない", filter: di.SlackMentionFilter{UserID: "U999"}, want: 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := collectStream(streamer.Stream(context.Background(), tt.filter))
+				if err != nil {
+					t.Fatalf("Stream() error = %v", err)
+				}
+				if len(got) != tt.want {
+					t.Errorf("Stream() = %d件, want %d件", len(got), tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("受信を途中でやめてキャンセルした場合はキャンセルのエラーで終了する", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mentions, errs := streamer.Stream(ctx, di.SlackMentionFilter{})
+		for range 3 {
+			if _, ok := <-mentions; !ok {
+				t.Fatal("3件を受信する前に終了しました")
+			}
+		}
+		cancel()
+
+		select {
+		case err := <-errs:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Stream() error = %v, want %v", err, context.Canceled)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("キャンセル後に Stream() が終了しませんでした")
+		}
+		// 送信を待っていたメンションは破棄してチャネルを閉じる
+		for range mentions {
+		}
+	})
+
+	t.Run("開始前にキャンセルされた場合は何も返さない", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		mentions, errs := streamer.Stream(ctx, di.SlackMentionFilter{})
+
+		select {
+		case err := <-errs:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Stream() error = %v, want %v", err, context.Canceled)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Stream() が終了しませんでした")
+		}
+		if m, ok := <-mentions; ok {
+			t.Errorf("キャンセル後に受信したメンション = %s, want なし", m.ID)
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+)
+
+// createTestTable はテーブルと、一意制約で重複を判定するリポジトリのためのユニークインデックスを作成する
+func createTestTable(t *testing.T, db *bun.DB, model any, unique ...string) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := db.NewCreateTable().Model(model).Exec(ctx); err != nil {
+		t.Fatalf("テーブルの作成エラー: %v", err)
+	}
+	if len(unique) == 0 {
+		return
+	}
+	if _, err := db.NewCreateIndex().Model(model).Unique().Index("test_unique").Column(unique...).Exec(ctx); err != nil {
+		t.Fatalf("インデックスの作成エラー: %v", err)
+	}
+}
+
+// MySQL以外のデータベース（SQLite）でも、同じ設定の保存は既存の行を更新する
+func TestUserSettingRepositoryUpsertSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	createTestTable(t, db, (*entity.UserSetting)(nil))
+	r := NewUserSettingRepository(db)
+
+	createdAt := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	if err := r.Upsert(ctx, &entity.UserSetting{UserID: "U001", Lang: "ja", CreatedAt: createdAt, UpdatedAt: createdAt}); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	updatedAt := createdAt.Add(time.Hour)
+	if err := r.Upsert(ctx, &entity.UserSetting{UserID: "U001", Lang: "en", CreatedAt: updatedAt, UpdatedAt: updatedAt}); err != nil {
+		t.Fatalf("2回目の Upsert() error = %v", err)
+	}
+
+	got, err := r.FindByUserID(ctx, "U001")
+	if err != nil {
+		t.Fatalf("FindByUserID() error = %v", err)
+	}
+	if got.Lang != "en" || !got.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("Lang, UpdatedAt = %q, %v, want %q, %v", got.Lang, got.UpdatedAt, "en", updatedAt)
+	}
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("CreatedAt = %v, want %v（最初に保存した日時）", got.CreatedAt, createdAt)
+	}
+}
+
+func TestProcessedEventRepositoryClaimSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	createTestTable(t, db, (*entity.ProcessedEvent)(nil))
+	r := NewProcessedEventRepository(db)
+	base := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		at          time.Time
+		staleBefore time.Time
+		want        bool
+	}{
+		{name: "初めてのイベント", at: base, staleBefore: base.Add(-time.Hour), want: true},
+		{name: "処理済みのイベント", at: base.Add(time.Minute), staleBefore: base.Add(-time.Hour), want: false},
+		{name: "処理済みの記録が古いイベントは処理し直す", at: base.Add(2 * time.Hour), staleBefore: base.Add(time.Hour), want: true},
+		{name: "処理し直した記録で再び重複を判定する", at: base.Add(2*time.Hour + time.Minute), staleBefore: base.Add(time.Hour), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Claim(ctx, "Ev001", tt.at, tt.staleBefore)
+			if err != nil {
+				t.Fatalf("Claim() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Claim() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConversationRepositoryCreateSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	createTestTable(t, db, (*entity.Conversation)(nil), "channel_id", "thread_ts")
+	r := NewConversationRepository(db)
+	base := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+
+	newConversation := func(startedBy string, lastActivity time.Time) *entity.Conversation {
+		return &entity.Conversation{
+			ID:           dbtypes.ULID(ulid.Make()),
+			ChannelID:    "C001",
+			ThreadTS:     "1712311200.000100",
+			StartedBy:    startedBy,
+			LastActivity: lastActivity,
+			MessageCount: 1,
+			CreatedAt:    lastActivity,
+			UpdatedAt:    lastActivity,
+		}
+	}
+	first := newConversation("U001", base)
+	if err := r.Create(ctx, first, base.Add(-time.Hour)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// 続いている会話は置き換えない
+	if err := r.Create(ctx, newConversation("U002", base.Add(time.Minute)), base.Add(-time.Hour)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	got, err := r.FindByChannelAndThread(ctx, "C001", "1712311200.000100")
+	if err != nil {
+		t.Fatalf("FindByChannelAndThread() error = %v", err)
+	}
+	if got.ID != first.ID || got.StartedBy != "U001" {
+		t.Errorf("続いている会話 = %s (%s), want %s (U001)", got.ID, got.StartedBy, first.ID)
+	}
+
+	// 終了した会話（最後の発言が staleBefore 以前）は新しい会話に置き換える
+	second := newConversation("U003", base.Add(2*time.Hour))
+	if err := r.Create(ctx, second, base); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	got, err = r.FindByChannelAndThread(ctx, "C001", "1712311200.000100")
+	if err != nil {
+		t.Fatalf("FindByChannelAndThread() error = %v", err)
+	}
+	if got.ID != second.ID || got.StartedBy != "U003" || !got.LastActivity.Equal(second.LastActivity) {
+		t.Errorf("置き換えた会話 = %s (%s, %v), want %s (U003, %v)", got.ID, got.StartedBy, got.LastActivity, second.ID, second.LastActivity)
+	}
+}
+
+func TestConversationMessageRepositorySaveSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	createTestTable(t, db, (*entity.ConversationMessage)(nil), "channel_id", "thread_ts", "ts")
+	r := NewConversationMessageRepository(db, nil)
+
+	for _, text := range []string{"質問", "再送された質問"} {
+		if err := r.Save(ctx, &entity.ConversationMessage{ChannelID: "C001", ThreadTS: "1712311200.000100", TS: "1712311200.000100", Role: "user", UserID: "U001", Text: text}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	got, err := r.FindByThread(ctx, "C001", "1712311200.000100", 10)
+	if err != nil {
+		t.Fatalf("FindByThread() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Text != "質問" {
+		t.Errorf("FindByThread() = %d件, want 最初に保存した1件", len(got))
+	}
+}
+
+// 同じIDのメンションが保存済みの場合は、送信待ちのメッセージも保存しない
+func TestOutboxRepositoryCreateWithMentionSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	createTestTable(t, db, (*entity.SlackMention)(nil))
+	createTestTable(t, db, (*entity.OutboxMessage)(nil))
+	r := NewOutboxRepository(db, nil)
+
+	mention := newTestMention("U001", "C001", time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC))
+	for range 2 {
+		duplicate := *mention
+		message := &entity.OutboxMessage{MentionID: mention.ID, QueueKey: "mention", Payload: []byte(`{}`), Status: "pending"}
+		if err := r.CreateWithMention(ctx, &duplicate, message); err != nil {
+			t.Fatalf("CreateWithMention() error = %v", err)
+		}
+	}
+	count, err := db.NewSelect().Model((*entity.OutboxMessage)(nil)).Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("送信待ちのメッセージ = %d件, want 1件", count)
+	}
+}
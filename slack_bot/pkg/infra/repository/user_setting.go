@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/database"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/uptrace/bun"
+)
+
+type UserSettingRepository struct {
+	db *bun.DB
+}
+
+func NewUserSettingRepository(db *bun.DB) di.UserSettingRepository {
+	return &UserSettingRepository{db: db}
+}
+
+func (r *UserSettingRepository) FindByUserID(ctx context.Context, userID string) (*entity.UserSetting, error) {
+	var setting entity.UserSetting
+	err := r.db.NewSelect().Model(&setting).Where("user_id = ?", userID).Scan(ctx)
+	return &setting, err
+}
+
+// Upsert は設定を保存する。既に存在する場合は created_at を残して更新する
+func (r *UserSettingRepository) Upsert(ctx context.Context, setting *entity.UserSetting) error {
+	_, err := database.OnConflictUpdate(r.db.NewInsert().Model(setting), []string{"user_id"}, "lang", "updated_at").Exec(ctx)
+	return err
+}
@@ -0,0 +1,97 @@
+// Package secrets は設定ファイルに書かずに外部のシークレットストアで管理するトークンなどの値を取得する
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Fetcher はシークレットの名前から値を取得する
+type Fetcher interface {
+	Fetch(ctx context.Context, name string) (string, error)
+}
+
+// AWSOptions はシークレットストアに接続するAWSの設定
+// AccessKey が空の場合はAWS SDKの標準の方法（環境変数・IAMロールなど）で認証する
+type AWSOptions struct {
+	Region    string
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+}
+
+func newSession(opts AWSOptions) (*session.Session, error) {
+	awsCfg := &aws.Config{
+		Region: aws.String(opts.Region),
+	}
+	if opts.Endpoint != "" {
+		awsCfg.Endpoint = aws.String(opts.Endpoint)
+	}
+	if opts.AccessKey != "" {
+		awsCfg.Credentials = credentials.NewStaticCredentials(opts.AccessKey, opts.SecretKey, "")
+	}
+	return session.NewSession(awsCfg)
+}
+
+// SecretsManagerFetcher はAWS Secrets Managerのシークレットの文字列を取得する
+type SecretsManagerFetcher struct {
+	client secretsmanageriface.SecretsManagerAPI
+}
+
+func NewSecretsManagerFetcher(opts AWSOptions) (*SecretsManagerFetcher, error) {
+	sess, err := newSession(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretsManagerFetcher{client: secretsmanager.New(sess)}, nil
+}
+
+// Fetch は name（シークレットの名前またはARN）の現在のバージョンの値を取得する
+func (f *SecretsManagerFetcher) Fetch(ctx context.Context, name string) (string, error) {
+	out, err := f.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Secrets Managerのシークレットの取得エラー (%s): %w", name, err)
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("Secrets Managerのシークレットが文字列ではありません (%s)", name)
+	}
+	return *out.SecretString, nil
+}
+
+// SSMFetcher はAWS Systems Manager Parameter Storeのパラメータの値を取得する
+type SSMFetcher struct {
+	client ssmiface.SSMAPI
+}
+
+func NewSSMFetcher(opts AWSOptions) (*SSMFetcher, error) {
+	sess, err := newSession(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &SSMFetcher{client: ssm.New(sess)}, nil
+}
+
+// Fetch は name のパラメータの値を取得する（SecureString は復号する）
+func (f *SSMFetcher) Fetch(ctx context.Context, name string) (string, error) {
+	out, err := f.client.GetParameterWithContext(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", fmt.Errorf("SSMパラメータの取得エラー (%s): %w", name, err)
+	}
+	if out.Parameter == nil {
+		return "", fmt.Errorf("SSMパラメータがありません (%s)", name)
+	}
+	return aws.StringValue(out.Parameter.Value), nil
+}
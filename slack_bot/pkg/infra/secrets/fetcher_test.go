@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// fakeSecretsManager は GetSecretValueWithContext だけを実装し、名前ごとの出力を返す
+type fakeSecretsManager struct {
+	secretsmanageriface.SecretsManagerAPI
+	outputs map[string]*secretsmanager.GetSecretValueOutput
+}
+
+func (f *fakeSecretsManager) GetSecretValueWithContext(ctx aws.Context, in *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	out, ok := f.outputs[aws.StringValue(in.SecretId)]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+	return out, nil
+}
+
+// fakeSSM は GetParameterWithContext だけを実装し、復号を指定した場合だけ値を返す
+type fakeSSM struct {
+	ssmiface.SSMAPI
+	parameters map[string]*ssm.Parameter
+}
+
+func (f *fakeSSM) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	if !aws.BoolValue(in.WithDecryption) {
+		return nil, errors.New("WithDecryption is required")
+	}
+	p, ok := f.parameters[aws.StringValue(in.Name)]
+	if !ok {
+		return nil, errors.New("ParameterNotFound")
+	}
+	return &ssm.GetParameterOutput{Parameter: p}, nil
+}
+
+func TestSecretsManagerFetcherFetch(t *testing.T) {
+	f := &SecretsManagerFetcher{client: &fakeSecretsManager{outputs: map[string]*secretsmanager.GetSecretValueOutput{
+		"slackbot/tokens": {SecretString: aws.String(`{"bot_token":"xoxb-secret"}`)},
+		"slackbot/binary": {SecretBinary: []byte{0x01}},
+	}}}
+	tests := []struct {
+		name    string
+		secret  string
+		want    string
+		wantErr bool
+	}{
+		{name: "文字列のシークレット", secret: "slackbot/tokens", want: `{"bot_token":"xoxb-secret"}`},
+		{name: "バイナリのシークレットはエラー", secret: "slackbot/binary", wantErr: true},
+		{name: "存在しないシークレットはエラー", secret: "slackbot/missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.Fetch(context.Background(), tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fetch(%q) error = %v, wantErr %v", tt.secret, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Fetch(%q) = %q, want %q", tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSMFetcherFetch(t *testing.T) {
+	f := &SSMFetcher{client: &fakeSSM{parameters: map[string]*ssm.Parameter{
+		"/slackbot/bot_token": {Value: aws.String("xoxb-secret")},
+	}}}
+	got, err := f.Fetch(context.Background(), "/slackbot/bot_token")
+	if err != nil || got != "xoxb-secret" {
+		t.Errorf("Fetch() = %q, %v, want %q, nil", got, err, "xoxb-secret")
+	}
+	if _, err := f.Fetch(context.Background(), "/slackbot/missing"); err == nil {
+		t.Error("存在しないパラメータで Fetch() error = nil, want error")
+	}
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+)
+
+type s3PutObjectAPI interface {
+	PutObjectWithContext(aws.Context, *s3.PutObjectInput, ...request.Option) (*s3.PutObjectOutput, error)
+}
+
+// S3FileStore は添付ファイルをS3互換ストレージに保存する
+type S3FileStore struct {
+	client s3PutObjectAPI
+	bucket string
+}
+
+func NewS3FileStore(client s3PutObjectAPI, bucket string) di.FileStore {
+	return &S3FileStore{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+func (s *S3FileStore) Put(ctx context.Context, key string, contentType string, body []byte) error {
+	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("S3へのアップロードエラー (s3://%s/%s): %w", s.bucket, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// traceParentKey は W3C Trace Context のトレースの情報を格納するキー
+const traceParentKey = "traceparent"
+
+// Inject はコンテキストのスパンのトレースの情報を carrier に設定する
+// トレースが無効な場合は何も設定しない
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// Extract は carrier のトレースの情報を親スパンとして設定したコンテキストを返す
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// TraceParent はコンテキストのスパンの traceparent を返す
+// アウトボックスのようにメッセージを後から送信する場合に保存しておき、送信時に WithTraceParent で引き継ぐ
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	Inject(ctx, carrier)
+	return carrier.Get(traceParentKey)
+}
+
+// WithTraceParent は traceparent を親スパンとして設定したコンテキストを返す
+// traceparent が空の場合は ctx をそのまま返す
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return Extract(ctx, propagation.MapCarrier{traceParentKey: traceParent})
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx"
+)
+
+// NewTracerProvider はトレースの設定からTracerProviderを作成する
+// トレースが無効な場合は何も記録しないTracerProviderを返す
+// tracing.otlp.endpoint が設定されている場合は、記録したスパンをOTLP/HTTPで送信する
+func NewTracerProvider(lc fx.Lifecycle, cfg *config.AppConfig) (trace.TracerProvider, error) {
+	if !cfg.Tracing.Enabled {
+		return noop.NewTracerProvider(), nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.Tracing.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("トレースのリソースの作成エラー: %w", err)
+	}
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(NewSampler(cfg.Tracing.SampleRate)),
+		sdktrace.WithResource(res),
+	}
+	if otlp := cfg.Tracing.OTLP; otlp.Endpoint != "" {
+		exporter, err := otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpointURL(otlp.Endpoint),
+			otlptracehttp.WithHeaders(otlp.Headers),
+			otlptracehttp.WithTimeout(otlp.Timeout),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("OTLPのエクスポーターの作成エラー: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	tp := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(tp)
+	// キューのメッセージ属性でトレースを引き継ぐため、W3C Trace Context の形式で伝搬する
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			// 停止までに記録したスパンを送信してから終了する
+			return tp.Shutdown(ctx)
+		},
+	})
+
+	return tp, nil
+}
+
+// NewSampler は親スパンのサンプリング判定を引き継ぎ、
+// 親スパンがない場合は sampleRate の割合でサンプリングするSamplerを返す
+func NewSampler(sampleRate float64) sdktrace.Sampler {
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRate))
+}
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand/v2"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sampledFraction は親スパンのない n 件のトレースのうちサンプリングされた割合を返す
+func sampledFraction(sampler sdktrace.Sampler, n int) float64 {
+	r := rand.New(rand.NewPCG(1, 2))
+	sampled := 0
+	for range n {
+		var id trace.TraceID
+		binary.BigEndian.PutUint64(id[:8], r.Uint64())
+		binary.BigEndian.PutUint64(id[8:], r.Uint64())
+		res := sampler.ShouldSample(sdktrace.SamplingParameters{ParentContext: context.Background(), TraceID: id, Name: "slack.event"})
+		if res.Decision == sdktrace.RecordAndSample {
+			sampled++
+		}
+	}
+	return float64(sampled) / float64(n)
+}
+
+func TestNewSamplerFraction(t *testing.T) {
+	const n = 10000
+	tests := []struct {
+		name       string
+		sampleRate float64
+		min, max   float64
+	}{
+		{name: "0 の場合はサンプリングしない", sampleRate: 0, min: 0, max: 0},
+		{name: "1 の場合はすべてサンプリングする", sampleRate: 1, min: 1, max: 1},
+		{name: "0.25 の場合はおよそ25%をサンプリングする", sampleRate: 0.25, min: 0.23, max: 0.27},
+		// 範囲外の値は設定の読み込み時に拒否するが、Sampler としては 0 と 1 に丸められる
+		{name: "負の値は 0 と同じ", sampleRate: -0.5, min: 0, max: 0},
+		{name: "1 を超える値は 1 と同じ", sampleRate: 1.5, min: 1, max: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sampledFraction(NewSampler(tt.sampleRate), n)
+			if got < tt.min || got > tt.max {
+				t.Errorf("NewSampler(%v) のサンプリング率 = %v, want %v〜%v", tt.sampleRate, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+// 親スパンがある場合は sample_rate に関係なく親スパンの判定を引き継ぐ
+func TestNewSamplerParentBased(t *testing.T) {
+	traceID := trace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	tests := []struct {
+		name       string
+		sampleRate float64
+		flags      trace.TraceFlags
+		remote     bool
+		want       sdktrace.SamplingDecision
+	}{
+		{name: "サンプリングされた親（キューの属性で引き継いだスパン）", sampleRate: 0, flags: trace.FlagsSampled, remote: true, want: sdktrace.RecordAndSample},
+		{name: "サンプリングされなかった親（キューの属性で引き継いだスパン）", sampleRate: 1, remote: true, want: sdktrace.Drop},
+		{name: "サンプリングされた同じプロセスの親", sampleRate: 0, flags: trace.FlagsSampled, want: sdktrace.RecordAndSample},
+		{name: "サンプリングされなかった同じプロセスの親", sampleRate: 1, want: sdktrace.Drop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     trace.SpanID{1},
+				TraceFlags: tt.flags,
+				Remote:     tt.remote,
+			})
+			ctx := trace.ContextWithSpanContext(context.Background(), parent)
+			res := NewSampler(tt.sampleRate).ShouldSample(sdktrace.SamplingParameters{ParentContext: ctx, TraceID: traceID, Name: "slack.process_mention"})
+			if res.Decision != tt.want {
+				t.Errorf("ShouldSample() = %v, want %v", res.Decision, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+type correlationIDKey struct{}
+
+type attrsKey struct{}
+
+// WithCorrelationID はイベントの相関IDを設定したコンテキストを返す
+// 相関IDはイベント受信時に発行し、ログ・キューのメッセージ・Slackへの投稿に引き継ぐ
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID はコンテキストに設定された相関IDを返す
+// 設定されていない場合は空文字を返す
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// With はログに付与する項目（channel, user, ts, event_id など）を追加したコンテキストを返す
+// args は slog.Logger.With と同じくキーと値を交互に指定する。値が空文字の項目は付与しない
+func With(ctx context.Context, args ...any) context.Context {
+	added := slog.Group("", args...).Value.Group()
+	attrs := make([]slog.Attr, 0, len(contextAttrs(ctx))+len(added))
+	attrs = append(attrs, contextAttrs(ctx)...)
+	for _, attr := range added {
+		if attr.Value.Kind() == slog.KindString && attr.Value.String() == "" {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// contextAttrs はコンテキストに設定されたログの項目を返す
+func contextAttrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// New は level 以上のログを format の形式で w に出力するロガーを作成する
+// コンテキストを渡して出力したログには、相関IDと With で追加した項目を付与する
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lv slog.Level
+	if err := lv.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("ログの出力レベルが不正です: %s", level)
+	}
+	opts := &slog.HandlerOptions{Level: lv}
+
+	var handler slog.Handler
+	switch format {
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("ログの出力形式が不正です: %s", format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// contextHandler はコンテキストの相関IDとログの項目を付与してから handler に出力する
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	id, attrs := CorrelationID(ctx), contextAttrs(ctx)
+	if id != "" || len(attrs) > 0 {
+		r = r.Clone()
+		if id != "" {
+			r.AddAttrs(slog.String("correlation_id", id))
+		}
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Debugf はデバッグ用のログを出力する（log.level が debug の場合のみ出力される）
+func Debugf(ctx context.Context, format string, args ...any) {
+	logf(ctx, slog.LevelDebug, format, args...)
+}
+
+// Printf は処理の経過のログを出力する
+func Printf(ctx context.Context, format string, args ...any) {
+	logf(ctx, slog.LevelInfo, format, args...)
+}
+
+// Warnf は処理は続けられるが確認が必要なログを出力する
+func Warnf(ctx context.Context, format string, args ...any) {
+	logf(ctx, slog.LevelWarn, format, args...)
+}
+
+// Errorf はエラーのログを出力する
+func Errorf(ctx context.Context, format string, args ...any) {
+	logf(ctx, slog.LevelError, format, args...)
+}
+
+// logf は slog.Default のロガーにコンテキストの相関IDとログの項目を付けて出力する
+// 出力しないレベルの場合はメッセージを組み立てない
+func logf(ctx context.Context, level slog.Level, format string, args ...any) {
+	l := slog.Default()
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	l.Log(ctx, level, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		level   string
+		format  string
+		wantErr bool
+	}{
+		{level: "debug", format: "text"},
+		{level: "warn", format: "json"},
+		{level: "verbose", format: "text", wantErr: true},
+		{level: "info", format: "yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		if _, err := New(&bytes.Buffer{}, tt.level, tt.format); (err != nil) != tt.wantErr {
+			t.Errorf("New(%q, %q) error = %v, wantErr %v", tt.level, tt.format, err, tt.wantErr)
+		}
+	}
+}
+
+// decodeLines はJSON形式のログを1行ずつ読み込む
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("ログのJSONのデコードエラー: %v (%s)", err, line)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+// コンテキストの相関IDと With で追加した項目をログに付与する
+func TestNewAddsContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "info", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithCorrelationID(context.Background(), "01HTGZ0000000000000000000A")
+	ctx = With(ctx, "channel", "C001", "user", "U001")
+	// 値が空文字の項目は付与せず、後から追加した項目は前の項目に続けて付与する
+	ctx = With(ctx, "event_id", "Ev001", "ts", "")
+	l.InfoContext(ctx, "メンションを受信しました")
+	l.InfoContext(context.Background(), "コンテキストの項目なし")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("ログ = %d行, want 2行", len(lines))
+	}
+	want := map[string]string{
+		"correlation_id": "01HTGZ0000000000000000000A",
+		"channel":        "C001",
+		"user":           "U001",
+		"event_id":       "Ev001",
+	}
+	for k, v := range want {
+		if lines[0][k] != v {
+			t.Errorf("%s = %v, want %q", k, lines[0][k], v)
+		}
+	}
+	if _, ok := lines[0]["ts"]; ok {
+		t.Error("値が空文字の ts を付与しています")
+	}
+	if _, ok := lines[1]["correlation_id"]; ok {
+		t.Error("相関IDのないコンテキストに correlation_id を付与しています")
+	}
+}
+
+func TestWithDoesNotModifyParent(t *testing.T) {
+	parent := With(context.Background(), "channel", "C001")
+	_ = With(parent, "user", "U001")
+	if attrs := contextAttrs(parent); len(attrs) != 1 || attrs[0].Key != "channel" {
+		t.Errorf("親のコンテキストの項目 = %v, want [channel=C001]", attrs)
+	}
+}
+
+// Printf などのパッケージの関数は slog.Default に log.level 以上のログだけを出力する
+func TestLogfUsesDefaultLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "warn", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := slog.Default()
+	slog.SetDefault(l)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	ctx := With(context.Background(), "channel", "C001")
+	Debugf(ctx, "debug %d", 1)
+	Printf(ctx, "info %d", 2)
+	Warnf(ctx, "warn %d", 3)
+	Errorf(ctx, "error %d", 4)
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("ログ = %d行, want 2行 (%s)", len(lines), buf.String())
+	}
+	for i, want := range []struct{ level, msg string }{{"WARN", "warn 3"}, {"ERROR", "error 4"}} {
+		if lines[i]["level"] != want.level || lines[i]["msg"] != want.msg || lines[i]["channel"] != "C001" {
+			t.Errorf("ログ[%d] = %v, want level=%s msg=%q channel=C001", i, lines[i], want.level, want.msg)
+		}
+	}
+}
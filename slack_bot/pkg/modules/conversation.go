@@ -0,0 +1,24 @@
+package modules
+
+import (
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+var ConversationModule = fx.Options(
+	fx.Provide(newConversationTracker),
+)
+
+// newConversationTracker は会話の追跡が無効な場合 nil を返す
+// conversation.store_messages が無効な場合はスレッドのメッセージを保存しない
+func newConversationTracker(cfg *config.AppConfig, repository di.ConversationRepository, messages di.ConversationMessageRepository) *usecase.ConversationTracker {
+	if !cfg.Conversation.Enabled {
+		return nil
+	}
+	if !cfg.Conversation.StoreMessages {
+		messages = nil
+	}
+	return usecase.NewConversationTracker(repository, messages, cfg.Conversation.TTL, cfg.ThreadContext.MaxMessages)
+}
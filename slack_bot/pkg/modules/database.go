@@ -0,0 +1,15 @@
+package modules
+
+import (
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/database"
+	"go.uber.org/fx"
+)
+
+var DatabaseModule = fx.Options(
+	fx.Provide(database.NewDB),
+)
+
+// MigrateDatabaseModule はマイグレーション用のデータベース接続を提供する
+var MigrateDatabaseModule = fx.Options(
+	fx.Provide(database.NewMigrationDB),
+)
@@ -0,0 +1,10 @@
+package modules
+
+import (
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+var DigestModule = fx.Options(
+	fx.Provide(usecase.NewActivityDigest),
+)
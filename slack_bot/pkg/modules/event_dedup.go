@@ -0,0 +1,43 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+var EventDedupModule = fx.Options(
+	fx.Provide(newEventDeduplicator),
+	fx.Invoke(startEventDedupPurge),
+)
+
+// newEventDeduplicator はイベントの重複の排除が無効な場合 nil を返す
+// データベースが無効な場合（リポジトリが nil の場合）はプロセスのメモリで判定する
+func newEventDeduplicator(cfg *config.AppConfig, repository di.ProcessedEventRepository) *usecase.EventDeduplicator {
+	if !cfg.EventDedup.Enabled {
+		return nil
+	}
+	return usecase.NewEventDeduplicator(repository, cfg.EventDedup.TTL, cfg.EventDedup.CacheSize)
+}
+
+// startEventDedupPurge は期限を過ぎた処理済みのイベントを ttl ごとにデータベースから削除する
+func startEventDedupPurge(lc fx.Lifecycle, deduplicator *usecase.EventDeduplicator, repository di.ProcessedEventRepository, cfg *config.AppConfig) {
+	if deduplicator == nil || repository == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go deduplicator.RunEvery(ctx, cfg.EventDedup.TTL)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
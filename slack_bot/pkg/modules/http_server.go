@@ -0,0 +1,11 @@
+package modules
+
+import (
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/httpserver"
+	"go.uber.org/fx"
+)
+
+var HTTPServerModule = fx.Options(
+	fx.Provide(httpserver.NewServeMux),
+	fx.Invoke(httpserver.Start),
+)
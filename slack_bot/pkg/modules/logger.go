@@ -0,0 +1,30 @@
+package modules
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+)
+
+// LoggerModule は log.level / log.format のロガーを提供する
+// logger.Printf などのパッケージの関数と標準の log パッケージ、fx のログもこのロガーに出力する
+var LoggerModule = fx.Options(
+	fx.Provide(newLogger),
+	fx.Invoke(slog.SetDefault),
+	fx.WithLogger(newFxLogger),
+)
+
+func newLogger(cfg *config.AppConfig) (*slog.Logger, error) {
+	return logger.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+}
+
+// newFxLogger は依存性の注入の経過を debug レベル、失敗を error レベルで出力する fx のロガーを作成する
+func newFxLogger(l *slog.Logger) fxevent.Logger {
+	fxLogger := &fxevent.SlogLogger{Logger: l.With("component", "fx")}
+	fxLogger.UseLogLevel(slog.LevelDebug)
+	return fxLogger
+}
@@ -0,0 +1,39 @@
+package modules
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+var MaintenanceModule = fx.Options(
+	fx.Provide(usecase.NewMaintenance),
+	fx.Provide(usecase.NewConnectionHealth),
+	fx.Invoke(registerReadyzHandler),
+)
+
+// readyzResponse は readyz が返す処理の状態
+type readyzResponse struct {
+	Paused bool `json:"paused"`
+	// SocketModeFailures はSocket Modeの接続が連続して失敗しているワークスペースと回数
+	SocketModeFailures map[string]int `json:"socket_mode_failures,omitempty"`
+}
+
+// registerReadyzHandler は処理を受け付けられるかを返すエンドポイントを登録する
+// 管理コマンドで一時停止している場合や、Socket Modeの接続に失敗しているワークスペースがある場合は 503 を返す
+func registerReadyzHandler(cfg *config.AppConfig, mux *http.ServeMux, maintenance *usecase.Maintenance, health *usecase.ConnectionHealth) {
+	mux.HandleFunc(cfg.HTTPServer.ReadyzPath, func(w http.ResponseWriter, r *http.Request) {
+		res := readyzResponse{
+			Paused:             maintenance.Paused(),
+			SocketModeFailures: health.Failures(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if res.Paused || len(res.SocketModeFailures) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
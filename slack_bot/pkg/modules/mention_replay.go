@@ -0,0 +1,18 @@
+package modules
+
+import (
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+// MentionReplayModule は保存したメンションをキューに再送する処理を提供する
+// 送信先のキューと途中経過の設定は受信時と同じものを使用する
+var MentionReplayModule = fx.Options(
+	fx.Provide(newMentionReplayer),
+)
+
+func newMentionReplayer(cfg *config.AppConfig, query di.SlackMentionQuery, publisher di.QueuePublisher) *usecase.MentionReplayer {
+	return usecase.NewMentionReplayer(query, publisher, cfg.QueueKey, cfg.Progress.Enabled)
+}
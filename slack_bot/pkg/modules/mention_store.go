@@ -0,0 +1,42 @@
+package modules
+
+import (
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/command"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/crypto"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/query"
+	"github.com/uptrace/bun"
+	"go.uber.org/fx"
+)
+
+// MentionStoreModule はメンションの読み取り (di.SlackMentionQuery) と書き込み (di.SlackMentionCommand) を提供する
+// RepositoryModule と組み合わせて使用し、database.enabled が false の場合はどちらも nil になる
+// 読み取り用のレプリカなど保存先を分ける場合は、ここで渡す *bun.DB を差し替える
+var MentionStoreModule = fx.Options(
+	fx.Provide(
+		newSlackMentionQuery,
+		newSlackMentionCommand,
+	),
+)
+
+// database.in_memory の場合は読み取りと書き込みで同じメモリ上のリポジトリを使用する
+func newSlackMentionQuery(cfg *config.AppConfig, db *bun.DB, cipher *crypto.TextCipher, repository di.SlackMentionRepository) di.SlackMentionQuery {
+	if cfg.Database.InMemory {
+		return repository
+	}
+	if db == nil {
+		return nil
+	}
+	return query.NewSlackMentionQuery(db, cipher)
+}
+
+func newSlackMentionCommand(cfg *config.AppConfig, db *bun.DB, cipher *crypto.TextCipher, repository di.SlackMentionRepository) di.SlackMentionCommand {
+	if cfg.Database.InMemory {
+		return repository
+	}
+	if db == nil {
+		return nil
+	}
+	return command.NewSlackMentionCommand(db, cipher)
+}
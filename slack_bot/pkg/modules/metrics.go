@@ -0,0 +1,26 @@
+package modules
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+	"go.uber.org/fx"
+)
+
+var MetricsModule = fx.Options(
+	fx.Provide(
+		metrics.NewRegistry,
+		metrics.NewMetrics,
+	),
+	fx.Invoke(
+		registerMetricsHandler,
+		metrics.StartPusher,
+	),
+)
+
+func registerMetricsHandler(cfg *config.AppConfig, mux *http.ServeMux, registry *prometheus.Registry) {
+	mux.Handle(cfg.HTTPServer.MetricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+}
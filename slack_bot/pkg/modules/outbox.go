@@ -0,0 +1,66 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+var OutboxModule = fx.Options(
+	fx.Provide(
+		newMentionOutbox,
+		newOutboxRelay,
+	),
+	fx.Invoke(
+		startOutboxRelay,
+	),
+)
+
+// newMentionOutbox はアウトボックスが無効な場合 nil を返す
+func newMentionOutbox(cfg *config.AppConfig, repository di.OutboxRepository) *usecase.MentionOutbox {
+	if !cfg.Outbox.Enabled {
+		return nil
+	}
+	return usecase.NewMentionOutbox(repository)
+}
+
+func newOutboxRelay(
+	cfg *config.AppConfig,
+	repository di.OutboxRepository,
+	publisher di.QueuePublisher,
+	mentionCommand di.SlackMentionCommand,
+	m *metrics.Metrics,
+) *usecase.OutboxRelay {
+	return usecase.NewOutboxRelay(repository, publisher, mentionCommand, cfg.Mention.StoreSQSMessageID, cfg.Outbox.BatchSize, cfg.Outbox.Lease, cfg.Outbox.MaxBackoff, m.OutboxPending)
+}
+
+func startOutboxRelay(lc fx.Lifecycle, cfg *config.AppConfig, relay *usecase.OutboxRelay) {
+	if !cfg.Outbox.Enabled {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				relay.RunEvery(ctx, cfg.Outbox.PollInterval)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			// 送信中のメッセージの記録が終わるまで待つ
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
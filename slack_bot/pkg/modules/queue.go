@@ -0,0 +1,32 @@
+package modules
+
+import (
+	"fmt"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/metrics"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/queue"
+	"go.uber.org/fx"
+)
+
+var QueueModule = fx.Options(
+	fx.Provide(
+		newQueuePublisher,
+		queue.NewSQSResponseConsumer,
+	),
+)
+
+// queue.backend に応じた送信の実装を作成する
+func newQueuePublisher(lc fx.Lifecycle, cfg *config.AppConfig, m *metrics.Metrics) (di.QueuePublisher, error) {
+	switch cfg.Queue.Backend {
+	case config.QueueBackendSQS:
+		return queue.NewSQSPublisher(lc, cfg, m)
+	case config.QueueBackendRedis:
+		return queue.NewRedisPublisher(lc, cfg)
+	case config.QueueBackendNATS:
+		return queue.NewNATSPublisher(lc, cfg)
+	default:
+		return nil, fmt.Errorf("キューのバックエンド (queue.backend) には sqs, redis, nats のいずれかを指定してください: %s", cfg.Queue.Backend)
+	}
+}
@@ -1,10 +1,82 @@
 package modules
 
 import (
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/crypto"
 	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/repository"
+	"github.com/uptrace/bun"
 	"go.uber.org/fx"
 )
 
+// RepositoryModule はデータベースを使用するリポジトリを提供する
+// database.enabled が false の場合（*bun.DB が nil の場合）はすべてのリポジトリが nil になる
+// database.in_memory の場合はメンションのリポジトリのみメモリに保存する実装になる
 var RepositoryModule = fx.Options(
-	fx.Provide(repository.NewSlackMentionRepository),
+	fx.Provide(crypto.NewTextCipher),
+	fx.Provide(newSlackMentionRepository),
+	fx.Provide(newUserSettingRepository),
+	fx.Provide(newOutboxRepository),
+	fx.Provide(newFailedMentionRepository),
+	fx.Provide(newConversationRepository),
+	fx.Provide(newConversationMessageRepository),
+	fx.Provide(newProcessedEventRepository),
 )
+
+// InMemoryRepositoryModule はメモリに保存するメンションのリポジトリのみを提供する
+// データベースに接続せずにメンションの保存・取得を使用する処理を組み立てる場合に RepositoryModule の代わりに使用する
+var InMemoryRepositoryModule = fx.Options(
+	fx.Provide(repository.NewInMemorySlackMentionRepository),
+)
+
+func newSlackMentionRepository(cfg *config.AppConfig, db *bun.DB, cipher *crypto.TextCipher) di.SlackMentionRepository {
+	if cfg.Database.InMemory {
+		return repository.NewInMemorySlackMentionRepository()
+	}
+	if db == nil {
+		return nil
+	}
+	return repository.NewSlackMentionRepository(db, cipher)
+}
+
+func newUserSettingRepository(db *bun.DB) di.UserSettingRepository {
+	if db == nil {
+		return nil
+	}
+	return repository.NewUserSettingRepository(db)
+}
+
+func newOutboxRepository(db *bun.DB, cipher *crypto.TextCipher) di.OutboxRepository {
+	if db == nil {
+		return nil
+	}
+	return repository.NewOutboxRepository(db, cipher)
+}
+
+func newFailedMentionRepository(db *bun.DB, cipher *crypto.TextCipher) di.FailedMentionRepository {
+	if db == nil {
+		return nil
+	}
+	return repository.NewFailedMentionRepository(db, cipher)
+}
+
+func newConversationRepository(db *bun.DB) di.ConversationRepository {
+	if db == nil {
+		return nil
+	}
+	return repository.NewConversationRepository(db)
+}
+
+func newConversationMessageRepository(db *bun.DB, cipher *crypto.TextCipher) di.ConversationMessageRepository {
+	if db == nil {
+		return nil
+	}
+	return repository.NewConversationMessageRepository(db, cipher)
+}
+
+func newProcessedEventRepository(db *bun.DB) di.ProcessedEventRepository {
+	if db == nil {
+		return nil
+	}
+	return repository.NewProcessedEventRepository(db)
+}
@@ -0,0 +1,61 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/archive"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/storage"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+var RetentionModule = fx.Options(
+	fx.Provide(
+		newMentionArchiver,
+		newMentionRetention,
+	),
+	fx.Invoke(startMentionRetention),
+)
+
+// newMentionArchiver はアーカイブが無効な場合 nil を返す
+func newMentionArchiver(cfg *config.AppConfig) (di.MentionArchiver, error) {
+	archiveCfg := cfg.Retention.Archive
+	if !archiveCfg.Enabled {
+		return nil, nil
+	}
+
+	client, err := storage.NewS3Client(archiveCfg.S3Config)
+	if err != nil {
+		return nil, err
+	}
+	return archive.NewS3MentionArchiver(client, archiveCfg.Bucket, archiveCfg.Prefix), nil
+}
+
+func newMentionRetention(
+	cfg *config.AppConfig,
+	query di.SlackMentionQuery,
+	command di.SlackMentionCommand,
+	archiver di.MentionArchiver,
+) *usecase.MentionRetention {
+	return usecase.NewMentionRetention(query, command, archiver, cfg.Retention.MaxAge, cfg.Retention.BatchSize)
+}
+
+func startMentionRetention(lc fx.Lifecycle, cfg *config.AppConfig, retention *usecase.MentionRetention) {
+	if !cfg.Retention.Enabled {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go retention.RunEvery(ctx, cfg.Retention.Interval)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
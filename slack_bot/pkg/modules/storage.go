@@ -0,0 +1,26 @@
+package modules
+
+import (
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/storage"
+	"go.uber.org/fx"
+)
+
+var StorageModule = fx.Options(
+	fx.Provide(newFileStore),
+)
+
+// newFileStore は添付ファイルの転送が無効な場合 nil を返す
+func newFileStore(cfg *config.AppConfig) (di.FileStore, error) {
+	uploadCfg := cfg.Attachments.Upload
+	if !uploadCfg.Enabled {
+		return nil, nil
+	}
+
+	client, err := storage.NewS3Client(uploadCfg.S3Config)
+	if err != nil {
+		return nil, err
+	}
+	return storage.NewS3FileStore(client, uploadCfg.Bucket), nil
+}
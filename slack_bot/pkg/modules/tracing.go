@@ -0,0 +1,10 @@
+package modules
+
+import (
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/tracing"
+	"go.uber.org/fx"
+)
+
+var TracingModule = fx.Options(
+	fx.Provide(tracing.NewTracerProvider),
+)
@@ -0,0 +1,80 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/config"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/ai"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/usecase"
+	"go.uber.org/fx"
+)
+
+// WorkerModule はキューのメンションにAIで回答する処理を提供する
+var WorkerModule = fx.Options(
+	fx.Provide(
+		newAIProvider,
+		newMentionAnswerer,
+		newWorkerConsumers,
+	),
+)
+
+// WorkerConsumers は worker.queue_keys のキューごとのコンシューマー
+type WorkerConsumers []*queue.SQSConsumer
+
+// worker.provider に応じたAIプロバイダーを作成する
+func newAIProvider(cfg *config.AppConfig) (di.AIProvider, error) {
+	switch cfg.Worker.Provider {
+	case config.AIProviderOpenAI:
+		return ai.NewOpenAIProvider(cfg.Worker.OpenAI)
+	case config.AIProviderAnthropic:
+		return ai.NewAnthropicProvider(cfg.Worker.Anthropic)
+	case config.AIProviderBedrock:
+		return ai.NewBedrockProvider(cfg.Worker.Bedrock)
+	default:
+		return nil, fmt.Errorf("ワーカーのAIプロバイダー (worker.provider) には openai, anthropic, bedrock のいずれかを指定してください: %s", cfg.Worker.Provider)
+	}
+}
+
+func newMentionAnswerer(cfg *config.AppConfig, provider di.AIProvider) *usecase.MentionAnswerer {
+	return usecase.NewMentionAnswerer(provider, cfg.Worker.SystemPrompt)
+}
+
+// worker.queue_keys のキーに対応するキューのコンシューマーを作成する（同じキューは1つにまとめる）
+// dead_letter.enabled の場合は、dead_letter.max_receives 回受信しても処理できなかったメンションをデッドレターに移す
+func newWorkerConsumers(cfg *config.AppConfig, failedMentionRepository di.FailedMentionRepository, mentionCommand di.SlackMentionCommand) (WorkerConsumers, error) {
+	if cfg.Queue.Backend != config.QueueBackendSQS {
+		return nil, fmt.Errorf("worker はキューのバックエンド (queue.backend) が %s の場合のみ使用できます", config.QueueBackendSQS)
+	}
+	var recorder *usecase.DeadLetterRecorder
+	if cfg.DeadLetter.Enabled && cfg.DeadLetter.MaxReceives > 0 && failedMentionRepository != nil {
+		recorder = usecase.NewDeadLetterRecorder(failedMentionRepository, mentionCommand)
+	}
+
+	var consumers WorkerConsumers
+	seen := make(map[string]bool)
+	for _, key := range cfg.Worker.QueueKeys {
+		name, ok := cfg.ElasticMQ.QueueNameFor(key)
+		if !ok {
+			return nil, fmt.Errorf("%s のキュー (elasticmq.queues.%s または elasticmq.queue_name) が設定されていません", key, key)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		consumer, err := queue.NewSQSConsumer(cfg.ElasticMQ, name, cfg.Worker.RetryDelay, cfg.Worker.Consumer)
+		if err != nil {
+			return nil, err
+		}
+		if recorder != nil {
+			// 再送時に元のキューに送信できるよう、最初に設定されたキーで保存する
+			consumer.SetDeadLetter(cfg.ElasticMQ, cfg.DeadLetter.MaxReceives, cfg.DeadLetter.QueueName, func(ctx context.Context, body []byte, receiveCount int, cause error) error {
+				return recorder.Record(ctx, key, body, receiveCount, cause)
+			})
+		}
+		consumers = append(consumers, consumer)
+	}
+	return consumers, nil
+}
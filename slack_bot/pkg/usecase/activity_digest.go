@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+const (
+	// digestPeriod は利用状況のまとめの集計期間
+	digestPeriod = 24 * time.Hour
+	// digestTopUsers はまとめに含める利用の多いユーザーの人数
+	digestTopUsers = 5
+)
+
+// ActivityDigest はBotの利用状況のまとめを集計する
+type ActivityDigest struct {
+	query di.SlackMentionQuery
+}
+
+// DigestStats は集計期間内の利用状況
+type DigestStats struct {
+	From     time.Time
+	To       time.Time
+	Total    int
+	Channels []*entity.MentionCount
+	TopUsers []*entity.MentionCount
+	// EnqueueFailures は前回のまとめ以降にキューへの送信に失敗した数
+	EnqueueFailures int
+}
+
+func NewActivityDigest(query di.SlackMentionQuery) *ActivityDigest {
+	return &ActivityDigest{query: query}
+}
+
+// Collect は now までの24時間の利用状況を集計する
+func (d *ActivityDigest) Collect(ctx context.Context, now time.Time) (*DigestStats, error) {
+	from := now.Add(-digestPeriod)
+
+	channels, err := d.query.CountGroupedByChannelBetween(ctx, from, now)
+	if err != nil {
+		return nil, fmt.Errorf("チャンネルごとのメンション数の集計に失敗しました: %w", err)
+	}
+	users, err := d.query.CountGroupedByUserBetween(ctx, from, now, digestTopUsers)
+	if err != nil {
+		return nil, fmt.Errorf("ユーザーごとのメンション数の集計に失敗しました: %w", err)
+	}
+
+	total := 0
+	for _, c := range channels {
+		total += c.Count
+	}
+	return &DigestStats{
+		From:     from,
+		To:       now,
+		Total:    total,
+		Channels: channels,
+		TopUsers: users,
+	}, nil
+}
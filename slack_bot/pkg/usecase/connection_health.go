@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"maps"
+	"sync"
+)
+
+// ConnectionHealth はワークスペースごとのSocket Modeの接続が連続して失敗している回数
+// 再接続を監視する処理が更新し、readyz で接続できていないワークスペースを返すために使用する
+type ConnectionHealth struct {
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+func NewConnectionHealth() *ConnectionHealth {
+	return &ConnectionHealth{failures: make(map[string]int)}
+}
+
+// RecordFailure はワークスペースの接続の失敗を記録し、連続して失敗している回数を返す
+func (h *ConnectionHealth) RecordFailure(workspace string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[workspace]++
+	return h.failures[workspace]
+}
+
+// Reset は接続が安定したワークスペースの失敗回数を0に戻す
+func (h *ConnectionHealth) Reset(workspace string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.failures, workspace)
+}
+
+// Failures は接続に失敗しているワークスペースごとの連続した失敗回数を返す
+func (h *ConnectionHealth) Failures() map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return maps.Clone(h.failures)
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// ConversationTracker はメンションをスレッドごとの会話に紐付ける
+// 最後のメッセージから ttl 以上経過した会話は終了したものとして、同じスレッドで新しい会話を始める
+// messages が設定されている場合は、スレッドのメンションと回答を保存して次のメンションの会話履歴に使用する
+type ConversationTracker struct {
+	repository  di.ConversationRepository
+	messages    di.ConversationMessageRepository
+	ttl         time.Duration
+	maxMessages int
+	now         func() time.Time
+}
+
+// NewConversationTracker はスレッドのメッセージを保存しない場合 messages に nil を指定する
+func NewConversationTracker(repository di.ConversationRepository, messages di.ConversationMessageRepository, ttl time.Duration, maxMessages int) *ConversationTracker {
+	return &ConversationTracker{
+		repository:  repository,
+		messages:    messages,
+		ttl:         ttl,
+		maxMessages: maxMessages,
+		now:         time.Now,
+	}
+}
+
+// Resolve はメンションのスレッドの会話を取得し、ない場合（または終了している場合）は作成して会話のIDを返す
+// 同じスレッドの最初のメンションが同時に届いた場合も、一意制約によりどちらも同じ会話に紐付く
+func (t *ConversationTracker) Resolve(ctx context.Context, mention *slack.Mention, threadTS slack.ThreadTS) (slack.ConversationID, error) {
+	now := t.now()
+
+	found, err := t.repository.FindByChannelAndThread(ctx, string(mention.ChannelID), string(threadTS))
+	switch {
+	case err == nil && !found.ToModel().IsClosed(now, t.ttl):
+		return t.touch(ctx, found, now)
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		return slack.ConversationID{}, fmt.Errorf("会話の取得に失敗しました: %w", err)
+	}
+
+	id, err := slack.NewConversationID()
+	if err != nil {
+		return slack.ConversationID{}, err
+	}
+	conversation, err := slack.NewConversation(id, mention.ChannelID, threadTS, mention.UserID, now)
+	if err != nil {
+		return slack.ConversationID{}, err
+	}
+	if err := t.repository.Create(ctx, entity.NewConversation(conversation), now.Add(-t.ttl)); err != nil {
+		return slack.ConversationID{}, fmt.Errorf("会話の保存に失敗しました: %w", err)
+	}
+
+	// 同時に作成された別の会話が保存されている場合はそちらに紐付ける
+	found, err = t.repository.FindByChannelAndThread(ctx, string(mention.ChannelID), string(threadTS))
+	if err != nil {
+		return slack.ConversationID{}, fmt.Errorf("会話の取得に失敗しました: %w", err)
+	}
+	if ulid.ULID(found.ID) == ulid.ULID(id) {
+		return id, nil
+	}
+	return t.touch(ctx, found, now)
+}
+
+func (t *ConversationTracker) touch(ctx context.Context, conversation *entity.Conversation, now time.Time) (slack.ConversationID, error) {
+	if err := t.repository.Touch(ctx, ulid.ULID(conversation.ID), now); err != nil {
+		return slack.ConversationID{}, fmt.Errorf("会話の更新に失敗しました: %w", err)
+	}
+	return slack.ConversationID(conversation.ID), nil
+}
+
+// StoresMessages はスレッドのメッセージを保存するかどうかを返す
+func (t *ConversationTracker) StoresMessages() bool {
+	return t.messages != nil
+}
+
+// Load はスレッドの会話を最新 maxMessages 件のメッセージとともに取得する
+// 会話がない・終了している場合、またはメッセージを保存しない場合は nil を返す
+func (t *ConversationTracker) Load(ctx context.Context, channelID slack.ChannelID, threadTS slack.ThreadTS) (*slack.Conversation, error) {
+	if t.messages == nil {
+		return nil, nil
+	}
+	found, err := t.repository.FindByChannelAndThread(ctx, string(channelID), string(threadTS))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("会話の取得に失敗しました: %w", err)
+	}
+	conversation := found.ToModel()
+	if conversation.IsClosed(t.now(), t.ttl) {
+		return nil, nil
+	}
+
+	messages, err := t.messages.FindByThread(ctx, string(channelID), string(threadTS), t.maxMessages)
+	if err != nil {
+		return nil, fmt.Errorf("会話のメッセージの取得に失敗しました: %w", err)
+	}
+	for _, message := range messages {
+		if err := conversation.AddMessage(message.ToModel()); err != nil {
+			return nil, err
+		}
+	}
+	return conversation, nil
+}
+
+// Record はスレッドに投稿されたメンションまたは回答を保存する（メッセージを保存しない場合は何もしない）
+func (t *ConversationTracker) Record(ctx context.Context, message *slack.ConversationMessage) error {
+	if t.messages == nil {
+		return nil
+	}
+	if err := t.messages.Save(ctx, entity.NewConversationMessage(message)); err != nil {
+		return fmt.Errorf("会話のメッセージの保存に失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// fakeConversationRepository は ConversationRepository と同じ条件で会話を置き換えるメモリ上の実装
+type fakeConversationRepository struct {
+	mu            sync.Mutex
+	conversations map[string]*entity.Conversation
+}
+
+func newFakeConversationRepository() *fakeConversationRepository {
+	return &fakeConversationRepository{conversations: make(map[string]*entity.Conversation)}
+}
+
+func (r *fakeConversationRepository) FindByChannelAndThread(ctx context.Context, channelID, threadTS string) (*entity.Conversation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.conversations[channelID+"/"+threadTS]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (r *fakeConversationRepository) Create(ctx context.Context, conversation *entity.Conversation, staleBefore time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := conversation.ChannelID + "/" + conversation.ThreadTS
+	if existing, ok := r.conversations[key]; ok && existing.LastActivity.After(staleBefore) {
+		return nil
+	}
+	copied := *conversation
+	r.conversations[key] = &copied
+	return nil
+}
+
+func (r *fakeConversationRepository) Touch(ctx context.Context, id ulid.ULID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.conversations {
+		if ulid.ULID(c.ID) == id {
+			c.MessageCount++
+			c.LastActivity = at
+		}
+	}
+	return nil
+}
+
+// fakeConversationMessageRepository はスレッドのメッセージを ts の順に返すメモリ上の実装
+type fakeConversationMessageRepository struct {
+	mu       sync.Mutex
+	messages []*entity.ConversationMessage
+}
+
+func (r *fakeConversationMessageRepository) Save(ctx context.Context, message *entity.ConversationMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.messages {
+		if m.ChannelID == message.ChannelID && m.ThreadTS == message.ThreadTS && m.TS == message.TS && m.Role == message.Role {
+			return nil
+		}
+	}
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *fakeConversationMessageRepository) FindByThread(ctx context.Context, channelID, threadTS string, limit int) ([]*entity.ConversationMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []*entity.ConversationMessage
+	for _, m := range r.messages {
+		if m.ChannelID == channelID && m.ThreadTS == threadTS {
+			found = append(found, m)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].TS < found[j].TS })
+	if len(found) > limit {
+		found = found[len(found)-limit:]
+	}
+	return found, nil
+}
+
+func newTestThreadMention(t *testing.T, userID string) *slack.Mention {
+	t.Helper()
+	id, err := slack.NewMentionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	mention, err := slack.NewMention(id, slack.MessageSourceMention, slack.UserID(userID), "C001", "質問", slack.Timestamp(now), slack.EventTime(now), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mention
+}
+
+func TestConversationTrackerResolve(t *testing.T) {
+	const threadTS = slack.ThreadTS("1712311200.000100")
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	ttl := 30 * time.Minute
+	repository := newFakeConversationRepository()
+	tracker := NewConversationTracker(repository, nil, ttl, 10)
+	tracker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	first, err := tracker.Resolve(ctx, newTestThreadMention(t, "U001"), threadTS)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	// 会話が続いている間は同じ会話に紐付け、メッセージ数と最後のメッセージの日時を更新する
+	now = now.Add(ttl - time.Second)
+	second, err := tracker.Resolve(ctx, newTestThreadMention(t, "U002"), threadTS)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if second != first {
+		t.Errorf("会話中の Resolve() = %s, want %s", second, first)
+	}
+	stored, _ := repository.FindByChannelAndThread(ctx, "C001", string(threadTS))
+	if stored.MessageCount != 2 || !stored.LastActivity.Equal(now) || stored.StartedBy != "U001" {
+		t.Errorf("会話 = count %d last %v started_by %s, want 2 %v U001", stored.MessageCount, stored.LastActivity, stored.StartedBy, now)
+	}
+
+	// 最後のメッセージから ttl が経過した後は同じスレッドでも新しい会話を始める
+	now = now.Add(ttl)
+	third, err := tracker.Resolve(ctx, newTestThreadMention(t, "U003"), threadTS)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if third == first {
+		t.Errorf("会話の終了後の Resolve() = %s, want 新しい会話", third)
+	}
+	stored, _ = repository.FindByChannelAndThread(ctx, "C001", string(threadTS))
+	if slack.ConversationID(stored.ID) != third || stored.MessageCount != 1 || stored.StartedBy != "U003" {
+		t.Errorf("会話 = id %s count %d started_by %s, want %s 1 U003", ulid.ULID(stored.ID), stored.MessageCount, stored.StartedBy, third)
+	}
+}
+
+func TestConversationTrackerLoadAndRecord(t *testing.T) {
+	const threadTS = "1712311200.000100"
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	ttl := 30 * time.Minute
+	ctx := context.Background()
+	repository := newFakeConversationRepository()
+	messages := &fakeConversationMessageRepository{}
+	tracker := NewConversationTracker(repository, messages, ttl, 2)
+	tracker.now = func() time.Time { return now }
+
+	if conversation, err := tracker.Load(ctx, "C001", threadTS); err != nil || conversation != nil {
+		t.Fatalf("会話がない場合の Load() = %v, %v, want nil, nil", conversation, err)
+	}
+
+	if _, err := tracker.Resolve(ctx, newTestThreadMention(t, "U001"), threadTS); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []struct {
+		ts   string
+		role slack.ConversationRole
+		text slack.Text
+	}{
+		{ts: "1712311200.000100", role: slack.ConversationRoleUser, text: "最初の質問"},
+		{ts: "1712311260.000100", role: slack.ConversationRoleAssistant, text: "最初の回答"},
+		{ts: "1712311320.000100", role: slack.ConversationRoleUser, text: "次の質問"},
+	} {
+		message, err := slack.NewConversationMessage("C001", threadTS, m.ts, m.role, "U001", m.text, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tracker.Record(ctx, message); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	// 最新 maxMessages 件のメッセージを古い順に読み込む
+	conversation, err := tracker.Load(ctx, "C001", threadTS)
+	if err != nil || conversation == nil {
+		t.Fatalf("Load() = %v, %v, want conversation, nil", conversation, err)
+	}
+	var got []slack.Text
+	for _, m := range conversation.PreviousTurns("") {
+		got = append(got, m.Text)
+	}
+	if want := []slack.Text{"最初の回答", "次の質問"}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("メッセージ = %v, want %v", got, want)
+	}
+
+	// 会話が終了した後は読み込まない
+	now = now.Add(ttl)
+	if conversation, err := tracker.Load(ctx, "C001", threadTS); err != nil || conversation != nil {
+		t.Errorf("会話の終了後の Load() = %v, %v, want nil, nil", conversation, err)
+	}
+}
+
+// スレッドのメッセージを保存しない場合は Record, Load とも何もしない
+func TestConversationTrackerWithoutMessages(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewConversationTracker(newFakeConversationRepository(), nil, time.Minute, 10)
+	if tracker.StoresMessages() {
+		t.Error("StoresMessages() = true, want false")
+	}
+	message, err := slack.NewConversationMessage("C001", "1712311200.000100", "1712311200.000100", slack.ConversationRoleUser, "U001", "質問", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Record(ctx, message); err != nil {
+		t.Errorf("Record() error = %v", err)
+	}
+	if conversation, err := tracker.Load(ctx, "C001", "1712311200.000100"); err != nil || conversation != nil {
+		t.Errorf("Load() = %v, %v, want nil, nil", conversation, err)
+	}
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// DeadLetterRecorder はワーカーが繰り返し処理に失敗したメンションをデッドレターとして保存する
+// 保存したメッセージは DeadLetterReplayer（replay コマンド・管理コマンドの replay）で元のキューに再送できる
+type DeadLetterRecorder struct {
+	repository di.FailedMentionRepository
+	// mentionCommand はデータベースが無効な場合 nil
+	mentionCommand di.SlackMentionCommand
+}
+
+func NewDeadLetterRecorder(repository di.FailedMentionRepository, mentionCommand di.SlackMentionCommand) *DeadLetterRecorder {
+	return &DeadLetterRecorder{
+		repository:     repository,
+		mentionCommand: mentionCommand,
+	}
+}
+
+// Record は queueKey のキューから受信したメッセージを、処理の失敗の理由とともに保存する
+// 保存したメンションの状態は failed に更新する
+func (r *DeadLetterRecorder) Record(ctx context.Context, queueKey string, body []byte, receiveCount int, cause error) error {
+	// 形式が不正なメッセージも調査できるよう、メンションのIDが読み取れない場合もそのまま保存する
+	var msg queuemodel.MentionMessage
+	_ = json.Unmarshal(body, &msg)
+	mentionID, _ := ulid.ParseStrict(msg.ID)
+	correlationID := msg.CorrelationID
+	if correlationID == "" {
+		correlationID = msg.ID
+	}
+
+	reason := fmt.Sprintf("ワーカーが%d回受信しても処理できませんでした: %v", receiveCount, cause)
+	failed := entity.NewFailedMention(mentionID, correlationID, queueKey, body, reason)
+	if err := r.repository.Create(ctx, failed); err != nil {
+		return fmt.Errorf("デッドレターの保存に失敗しました: %w", err)
+	}
+	logger.Printf(ctx, "処理できなかったメッセージをデッドレターとして保存しました (id=%d queue_key=%s)", failed.ID, queueKey)
+
+	if r.mentionCommand == nil || mentionID == (ulid.ULID{}) {
+		return nil
+	}
+	err := r.mentionCommand.UpdateStatus(ctx, slack.MentionID(mentionID), string(slack.MentionStatusFailed), reason)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf(ctx, "メンションの状態の更新エラー (%s): %v", slack.MentionStatusFailed, err)
+	}
+	return nil
+}
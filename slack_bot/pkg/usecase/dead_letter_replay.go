@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// DeadLetterReplayer はキューに送信できずに保存したメッセージ（デッドレター）を再送する
+type DeadLetterReplayer struct {
+	repository di.FailedMentionRepository
+	publisher  di.QueuePublisher
+	now        func() time.Time
+}
+
+func NewDeadLetterReplayer(repository di.FailedMentionRepository, publisher di.QueuePublisher) *DeadLetterReplayer {
+	return &DeadLetterReplayer{
+		repository: repository,
+		publisher:  publisher,
+		now:        time.Now,
+	}
+}
+
+// Replay は再送していないメッセージを古い順に最大 limit 件再送し、再送できた件数と失敗した件数を返す
+// 再送に失敗したメッセージは失敗の理由を更新して残し、後続のメッセージの再送を続ける
+func (r *DeadLetterReplayer) Replay(ctx context.Context, limit int) (replayed int, failed int, err error) {
+	mentions, err := r.repository.FindPending(ctx, limit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("再送するメッセージの取得に失敗しました: %w", err)
+	}
+
+	for _, m := range mentions {
+		ctx := logger.WithCorrelationID(ctx, m.CorrelationID)
+		if err := r.publisher.PublishTo(ctx, m.QueueKey, m.Payload); err != nil {
+			logger.Errorf(ctx, "デッドレターの再送エラー (id=%d attempts=%d): %v", m.ID, m.Attempts+1, err)
+			if err := r.repository.MarkFailed(ctx, m.ID, r.now(), err.Error()); err != nil {
+				return replayed, failed, fmt.Errorf("再送失敗の記録に失敗しました (id=%d): %w", m.ID, err)
+			}
+			failed++
+			continue
+		}
+		if err := r.repository.MarkReplayed(ctx, m.ID, r.now()); err != nil {
+			// 再送済みの記録に失敗した場合は次回の replay で重複して送信される
+			return replayed, failed, fmt.Errorf("再送済みの記録に失敗しました (id=%d): %w", m.ID, err)
+		}
+		logger.Printf(ctx, "デッドレターを再送しました (id=%d queue_key=%s)", m.ID, m.QueueKey)
+		replayed++
+	}
+	return replayed, failed, nil
+}
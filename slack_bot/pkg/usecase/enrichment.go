@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// Enricher はキューに送信するメッセージに情報を付加する処理
+type Enricher interface {
+	Name() string
+	Enrich(ctx context.Context, msg *queue.MentionMessage) error
+}
+
+type enricherFunc struct {
+	name string
+	fn   func(ctx context.Context, msg *queue.MentionMessage) error
+}
+
+// NewEnricher は関数から名前付きの Enricher を作成する
+func NewEnricher(name string, fn func(ctx context.Context, msg *queue.MentionMessage) error) Enricher {
+	return &enricherFunc{name: name, fn: fn}
+}
+
+func (e *enricherFunc) Name() string {
+	return e.name
+}
+
+func (e *enricherFunc) Enrich(ctx context.Context, msg *queue.MentionMessage) error {
+	return e.fn(ctx, msg)
+}
+
+// EnrichmentPipeline は設定された順番で Enricher を実行する
+type EnrichmentPipeline struct {
+	enrichers []Enricher
+}
+
+// NewEnrichmentPipeline は available の中から order に指定された名前の Enricher を順番に並べる
+// 存在しない名前や重複した名前が指定された場合はエラーを返す
+func NewEnrichmentPipeline(order []string, available ...Enricher) (*EnrichmentPipeline, error) {
+	byName := make(map[string]Enricher, len(available))
+	for _, e := range available {
+		byName[e.Name()] = e
+	}
+
+	enrichers := make([]Enricher, 0, len(order))
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		e, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("不明な付加処理です: %s", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("付加処理が重複しています: %s", name)
+		}
+		seen[name] = true
+		enrichers = append(enrichers, e)
+	}
+	return &EnrichmentPipeline{enrichers: enrichers}, nil
+}
+
+// Enrich はメッセージに順番に情報を付加する
+// 付加処理に失敗した場合はログに記録して以降の処理を打ち切る（後の処理が前の処理の結果を前提にできるようにする）
+// 送信は止めず、それまでに付加した情報のままメッセージを送信する
+func (p *EnrichmentPipeline) Enrich(ctx context.Context, msg *queue.MentionMessage) {
+	for _, e := range p.enrichers {
+		if err := e.Enrich(ctx, msg); err != nil {
+			logger.Errorf(ctx, "付加処理 (%s) のエラーのため以降の付加処理を中止します: %v", e.Name(), err)
+			return
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+)
+
+// recordingEnrichers は実行した順番を calls に記録する Enricher を作成する
+func recordingEnrichers(calls *[]string) []Enricher {
+	return []Enricher{
+		NewEnricher("channel_name", func(ctx context.Context, msg *queue.MentionMessage) error {
+			*calls = append(*calls, "channel_name")
+			msg.ChannelName = "general"
+			return nil
+		}),
+		NewEnricher("permalink", func(ctx context.Context, msg *queue.MentionMessage) error {
+			*calls = append(*calls, "permalink")
+			// 前の付加処理の結果を使用できる
+			msg.Permalink = "https://example.slack.com/archives/" + msg.ChannelName + "/p" + msg.TS
+			return nil
+		}),
+		NewEnricher("locale", func(ctx context.Context, msg *queue.MentionMessage) error {
+			*calls = append(*calls, "locale")
+			msg.Locale = "ja-JP"
+			return nil
+		}),
+	}
+}
+
+func TestEnrichmentPipelineOrder(t *testing.T) {
+	tests := []struct {
+		name          string
+		order         []string
+		wantCalls     []string
+		wantPermalink string
+		wantLocale    string
+	}{
+		{
+			name:          "設定した順番で実行し、後の処理は前の処理の結果を参照できる",
+			order:         []string{"channel_name", "permalink"},
+			wantCalls:     []string{"channel_name", "permalink"},
+			wantPermalink: "https://example.slack.com/archives/general/p1712311200.000100",
+		},
+		{
+			name:          "順番を入れ替えると前の処理の結果は参照できない",
+			order:         []string{"permalink", "channel_name"},
+			wantCalls:     []string{"permalink", "channel_name"},
+			wantPermalink: "https://example.slack.com/archives//p1712311200.000100",
+		},
+		{
+			name:       "設定していない付加処理は実行しない",
+			order:      []string{"locale"},
+			wantCalls:  []string{"locale"},
+			wantLocale: "ja-JP",
+		},
+		{
+			name: "付加処理がない場合は何もしない",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			pipeline, err := NewEnrichmentPipeline(tt.order, recordingEnrichers(&calls)...)
+			if err != nil {
+				t.Fatalf("NewEnrichmentPipeline() error = %v", err)
+			}
+			msg := &queue.MentionMessage{TS: "1712311200.000100"}
+			pipeline.Enrich(context.Background(), msg)
+
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("実行した付加処理 = %v, want %v", calls, tt.wantCalls)
+			}
+			if msg.Permalink != tt.wantPermalink {
+				t.Errorf("Permalink = %q, want %q", msg.Permalink, tt.wantPermalink)
+			}
+			if msg.Locale != tt.wantLocale {
+				t.Errorf("Locale = %q, want %q", msg.Locale, tt.wantLocale)
+			}
+		})
+	}
+}
+
+// 失敗した付加処理より後の処理は実行せず、それまでに付加した情報は残す
+func TestEnrichmentPipelineStopsOnError(t *testing.T) {
+	var calls []string
+	failing := NewEnricher("failing", func(ctx context.Context, msg *queue.MentionMessage) error {
+		calls = append(calls, "failing")
+		return errors.New("取得エラー")
+	})
+	pipeline, err := NewEnrichmentPipeline([]string{"channel_name", "failing", "locale"}, append(recordingEnrichers(&calls), failing)...)
+	if err != nil {
+		t.Fatalf("NewEnrichmentPipeline() error = %v", err)
+	}
+	msg := &queue.MentionMessage{}
+	pipeline.Enrich(context.Background(), msg)
+
+	if want := []string{"channel_name", "failing"}; !slices.Equal(calls, want) {
+		t.Errorf("実行した付加処理 = %v, want %v", calls, want)
+	}
+	if msg.ChannelName != "general" {
+		t.Errorf("ChannelName = %q, want %q", msg.ChannelName, "general")
+	}
+	if msg.Locale != "" {
+		t.Errorf("Locale = %q, want 空", msg.Locale)
+	}
+}
+
+func TestNewEnrichmentPipelineInvalidOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+	}{
+		{name: "存在しない名前", order: []string{"channel_name", "unknown"}},
+		{name: "重複した名前", order: []string{"locale", "locale"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			if _, err := NewEnrichmentPipeline(tt.order, recordingEnrichers(&calls)...); err == nil {
+				t.Errorf("NewEnrichmentPipeline(%v) error = nil, want エラー", tt.order)
+			}
+		})
+	}
+}
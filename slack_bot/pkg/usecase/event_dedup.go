@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/cache"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// EventDeduplicator はSlackが再送したイベントを重複して処理しないよう、イベントのキーごとに ttl の間1回だけ処理を許可する
+// リポジトリがある場合は processed_events に保存して複数のプロセスで共有し、ない場合や保存に失敗した場合はプロセスのメモリで判定する
+type EventDeduplicator struct {
+	repository di.ProcessedEventRepository
+	memory     *cache.TTLSet
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewEventDeduplicator はデータベースを使用しない場合 repository に nil を指定する
+// メモリには最大 cacheSize 件のキーを保持し、上限に達すると古いキーから削除する
+func NewEventDeduplicator(repository di.ProcessedEventRepository, ttl time.Duration, cacheSize int) *EventDeduplicator {
+	return &EventDeduplicator{
+		repository: repository,
+		memory:     cache.NewTTLSet(ttl, cacheSize),
+		ttl:        ttl,
+		now:        time.Now,
+	}
+}
+
+// Claim はイベントを処理してよい場合に true を返し、ttl 以内に同じキーのイベントを処理している場合は false を返す
+// データベースに保存できない場合もイベントを取りこぼさないよう、メモリでの判定に切り替えて処理を継続する
+func (d *EventDeduplicator) Claim(ctx context.Context, key string) bool {
+	if d.repository == nil {
+		return d.memory.Add(key)
+	}
+	now := d.now()
+	ok, err := d.repository.Claim(ctx, key, now, now.Add(-d.ttl))
+	if err != nil {
+		logger.Errorf(ctx, "イベントの重複の確認エラー（プロセスのメモリで判定します）: %v", err)
+		return d.memory.Add(key)
+	}
+	return ok
+}
+
+// Purge は ttl を過ぎたイベントのキーをデータベースから削除し、削除した件数を返す
+func (d *EventDeduplicator) Purge(ctx context.Context) (int64, error) {
+	if d.repository == nil {
+		return 0, nil
+	}
+	return d.repository.DeleteBefore(ctx, d.now().Add(-d.ttl))
+}
+
+// RunEvery は ctx がキャンセルされるまで interval ごとに Purge を実行する
+func (d *EventDeduplicator) RunEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := d.Purge(ctx)
+		if err != nil {
+			logger.Errorf(ctx, "処理済みのイベントの削除エラー: %v", err)
+		} else if deleted > 0 {
+			logger.Printf(ctx, "期限を過ぎた処理済みのイベントを%d件削除しました", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProcessedEventRepository は ProcessedEventRepository と同じ条件でキーを保存するメモリ上の実装
+// err が設定されている場合はすべての操作を失敗させる
+type fakeProcessedEventRepository struct {
+	mu   sync.Mutex
+	keys map[string]time.Time
+	err  error
+}
+
+func (r *fakeProcessedEventRepository) Claim(ctx context.Context, eventKey string, at, staleBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return false, r.err
+	}
+	if processedAt, ok := r.keys[eventKey]; ok && !processedAt.Before(staleBefore) {
+		return false, nil
+	}
+	r.keys[eventKey] = at
+	return true, nil
+}
+
+func (r *fakeProcessedEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return 0, r.err
+	}
+	var deleted int64
+	for key, processedAt := range r.keys {
+		if processedAt.Before(before) {
+			delete(r.keys, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestEventDeduplicatorClaim(t *testing.T) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	ttl := 10 * time.Minute
+	ctx := context.Background()
+	repository := &fakeProcessedEventRepository{keys: make(map[string]time.Time)}
+	d := NewEventDeduplicator(repository, ttl, 100)
+	d.now = func() time.Time { return now }
+
+	if !d.Claim(ctx, "T001:event:Ev001") {
+		t.Error("初めてのイベントで Claim() = false, want true")
+	}
+	// Slackの再送は ttl 以内に届くため処理しない
+	now = now.Add(time.Minute)
+	if d.Claim(ctx, "T001:event:Ev001") {
+		t.Error("再送されたイベントで Claim() = true, want false")
+	}
+	if !d.Claim(ctx, "T001:event:Ev002") {
+		t.Error("別のイベントで Claim() = false, want true")
+	}
+	// ttl を過ぎた後は同じキーでも処理する
+	now = now.Add(ttl)
+	if !d.Claim(ctx, "T001:event:Ev001") {
+		t.Error("期限を過ぎたイベントで Claim() = false, want true")
+	}
+}
+
+// データベースに保存できない場合はプロセスのメモリで判定して処理を続ける
+func TestEventDeduplicatorFallsBackToMemory(t *testing.T) {
+	ctx := context.Background()
+	repository := &fakeProcessedEventRepository{keys: make(map[string]time.Time), err: errors.New("database is down")}
+	d := NewEventDeduplicator(repository, time.Minute, 100)
+
+	if !d.Claim(ctx, "T001:event:Ev001") {
+		t.Error("初めてのイベントで Claim() = false, want true")
+	}
+	if d.Claim(ctx, "T001:event:Ev001") {
+		t.Error("再送されたイベントで Claim() = true, want false")
+	}
+}
+
+func TestEventDeduplicatorWithoutRepository(t *testing.T) {
+	ctx := context.Background()
+	d := NewEventDeduplicator(nil, time.Minute, 100)
+	if !d.Claim(ctx, "T001:event:Ev001") || d.Claim(ctx, "T001:event:Ev001") {
+		t.Error("メモリでの判定で再送されたイベントを処理しています")
+	}
+	if deleted, err := d.Purge(ctx); deleted != 0 || err != nil {
+		t.Errorf("Purge() = %d, %v, want 0, nil", deleted, err)
+	}
+}
+
+func TestEventDeduplicatorPurge(t *testing.T) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	ttl := 10 * time.Minute
+	repository := &fakeProcessedEventRepository{keys: map[string]time.Time{
+		"old":    now.Add(-ttl - time.Second),
+		"recent": now.Add(-time.Minute),
+	}}
+	d := NewEventDeduplicator(repository, ttl, 100)
+	d.now = func() time.Time { return now }
+
+	deleted, err := d.Purge(context.Background())
+	if err != nil || deleted != 1 {
+		t.Fatalf("Purge() = %d, %v, want 1, nil", deleted, err)
+	}
+	if _, ok := repository.keys["recent"]; !ok {
+		t.Error("期限内のキーを削除しています")
+	}
+}
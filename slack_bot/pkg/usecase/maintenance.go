@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"sync/atomic"
+)
+
+// Maintenance はメンションの処理を一時停止するスイッチ
+// 管理コマンドで切り替え、状態はプロセス内でのみ保持する（再起動すると稼働中に戻り、他のインスタンスとは共有しない）
+type Maintenance struct {
+	paused atomic.Bool
+}
+
+func NewMaintenance() *Maintenance {
+	return &Maintenance{}
+}
+
+// Pause は処理を一時停止し、既に停止していた場合は false を返す
+func (m *Maintenance) Pause() bool {
+	return m.paused.CompareAndSwap(false, true)
+}
+
+// Resume は処理を再開し、停止していなかった場合は false を返す
+func (m *Maintenance) Resume() bool {
+	return m.paused.CompareAndSwap(true, false)
+}
+
+func (m *Maintenance) Paused() bool {
+	return m.paused.Load()
+}
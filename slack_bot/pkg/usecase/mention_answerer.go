@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+)
+
+// MentionAnswerer はキューのメンションのメッセージからAIに送信する会話を組み立て、回答を生成する
+// スレッドの会話履歴・添付ファイル名・チャンネルの設定（言語・ペルソナ）は質問と合わせて送信する
+type MentionAnswerer struct {
+	provider     di.AIProvider
+	systemPrompt string
+}
+
+func NewMentionAnswerer(provider di.AIProvider, systemPrompt string) *MentionAnswerer {
+	return &MentionAnswerer{
+		provider:     provider,
+		systemPrompt: systemPrompt,
+	}
+}
+
+// Answer はメンションへの回答を生成する
+func (a *MentionAnswerer) Answer(ctx context.Context, msg *queuemodel.MentionMessage) (string, error) {
+	answer, err := a.provider.Complete(ctx, mentionPrompt(msg), a.history(msg))
+	if err != nil {
+		return "", fmt.Errorf("回答の生成に失敗しました: %w", err)
+	}
+	return answer, nil
+}
+
+// AnswerStream はメンションへの回答を生成し、生成された差分を順に onDelta に渡す
+// プロバイダーがストリーミングに対応していない場合は、回答の全文を1回で渡す
+func (a *MentionAnswerer) AnswerStream(ctx context.Context, msg *queuemodel.MentionMessage, onDelta func(delta string)) (string, error) {
+	streaming, ok := a.provider.(di.StreamingAIProvider)
+	if !ok {
+		answer, err := a.Answer(ctx, msg)
+		if err != nil {
+			return "", err
+		}
+		onDelta(answer)
+		return answer, nil
+	}
+	answer, err := streaming.Stream(ctx, mentionPrompt(msg), a.history(msg), onDelta)
+	if err != nil {
+		return "", fmt.Errorf("回答の生成に失敗しました: %w", err)
+	}
+	return answer, nil
+}
+
+// 質問より前の会話としてAIへの指示を組み立てる
+func (a *MentionAnswerer) history(msg *queuemodel.MentionMessage) []di.ChatMessage {
+	system := []string{a.systemPrompt}
+	if msg.Settings != nil {
+		if msg.Settings.Language != "" {
+			system = append(system, fmt.Sprintf("回答は言語コード %s の言語で記述してください。", msg.Settings.Language))
+		}
+		if msg.Settings.Persona != "" {
+			system = append(system, fmt.Sprintf("ペルソナ: %s", msg.Settings.Persona))
+		}
+	} else if msg.Lang != "" {
+		system = append(system, fmt.Sprintf("回答は言語コード %s の言語で記述してください。", msg.Lang))
+	}
+	return []di.ChatMessage{{Role: di.ChatRoleSystem, Content: strings.Join(system, "\n")}}
+}
+
+// AIに送信する質問を組み立てる
+// 会話履歴は発言者を区別できないため、ユーザーの発言として質問の前にまとめて含める
+func mentionPrompt(msg *queuemodel.MentionMessage) string {
+	var user strings.Builder
+	if len(msg.History) > 0 {
+		user.WriteString("スレッドの会話:\n")
+		for _, h := range msg.History {
+			fmt.Fprintf(&user, "<@%s>: %s\n", h.User, h.Text)
+		}
+		user.WriteString("\n")
+	}
+	if len(msg.Attachments) > 0 {
+		names := make([]string, 0, len(msg.Attachments))
+		for _, file := range msg.Attachments {
+			names = append(names, file.Name)
+		}
+		fmt.Fprintf(&user, "添付ファイル: %s\n\n", strings.Join(names, ", "))
+	}
+	user.WriteString(msg.Text)
+	return user.String()
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// exportPageSize はエクスポートで1回に取得するメンションの件数
+const exportPageSize = 500
+
+// ExportFormat はメンションの履歴をエクスポートする形式
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+// ErrExportTooManyRows はエクスポートする件数が上限を超えた場合のエラー
+var ErrExportTooManyRows = errors.New("エクスポートする件数が上限を超えています")
+
+// ParseExportFormat は文字列をエクスポートの形式に変換する
+func ParseExportFormat(s string) (ExportFormat, bool) {
+	switch f := ExportFormat(s); f {
+	case ExportFormatCSV, ExportFormatJSON:
+		return f, true
+	}
+	return "", false
+}
+
+// MentionExporter は期間内のメンションの履歴をCSVまたはJSONに書き出す
+type MentionExporter struct {
+	query di.SlackMentionQuery
+	// maxRows は書き出す最大件数、maxTextLength は1件のテキストの最大文字数（0 の場合は切り詰めない）
+	maxRows       int
+	maxTextLength int
+}
+
+func NewMentionExporter(query di.SlackMentionQuery, maxRows, maxTextLength int) *MentionExporter {
+	return &MentionExporter{
+		query:         query,
+		maxRows:       maxRows,
+		maxTextLength: maxTextLength,
+	}
+}
+
+// exportRow はエクスポートする1件のメンション
+type exportRow struct {
+	ID        string `json:"id"`
+	EventTime string `json:"event_time"`
+	Workspace string `json:"workspace"`
+	Channel   string `json:"channel"`
+	User      string `json:"user"`
+	UserName  string `json:"user_name"`
+	Type      string `json:"type"`
+	Status    string `json:"status"`
+	Text      string `json:"text"`
+}
+
+// exportColumns はCSVのヘッダー（exportRow のJSONの項目名と同じ）
+var exportColumns = []string{"id", "event_time", "workspace", "channel", "user", "user_name", "type", "status", "text"}
+
+func (r exportRow) values() []string {
+	return []string{r.ID, r.EventTime, r.Workspace, r.Channel, r.User, r.UserName, r.Type, r.Status, r.Text}
+}
+
+// Export は event_time が [from, to) のメンションをIDの順に w に書き出し、書き出した件数を返す
+// 日時は loc のタイムゾーンで出力する。件数が上限を超えた場合は ErrExportTooManyRows を返す（w には途中まで書き出される）
+func (e *MentionExporter) Export(ctx context.Context, w io.Writer, format ExportFormat, from, to time.Time, loc *time.Location) (int, error) {
+	if !from.Before(to) {
+		return 0, fmt.Errorf("期間の指定が不正です。開始日時 (%s) が終了日時 (%s) より前になるように指定してください", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	writer, err := newExportWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	cursor := ""
+	for {
+		page, next, err := e.query.ListBetween(ctx, from, to, cursor, exportPageSize)
+		if err != nil {
+			return count, fmt.Errorf("メンションの取得に失敗しました: %w", err)
+		}
+		for _, m := range page {
+			if e.maxRows > 0 && count >= e.maxRows {
+				return count, fmt.Errorf("%w (上限 %d 件)", ErrExportTooManyRows, e.maxRows)
+			}
+			if err := writer.Write(e.row(m, loc)); err != nil {
+				return count, fmt.Errorf("エクスポートの書き出しエラー: %w", err)
+			}
+			count++
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if err := writer.Close(); err != nil {
+		return count, fmt.Errorf("エクスポートの書き出しエラー: %w", err)
+	}
+	return count, nil
+}
+
+func (e *MentionExporter) row(m *entity.SlackMention, loc *time.Location) exportRow {
+	return exportRow{
+		ID:        m.ID.String(),
+		EventTime: m.EventTime.In(loc).Format(time.RFC3339),
+		Workspace: m.Workspace,
+		Channel:   m.ChannelID,
+		User:      m.UserID,
+		UserName:  m.UserName,
+		Type:      m.Type,
+		Status:    m.Status,
+		Text:      redactText(m.Text, e.maxTextLength),
+	}
+}
+
+// redactText はテキストを最大 n 文字に切り詰め、切り詰めた場合は末尾に省略した文字数を付ける
+func redactText(text string, n int) string {
+	runes := []rune(text)
+	if n <= 0 || len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…(+" + strconv.Itoa(len(runes)-n) + ")"
+}
+
+// exportWriter はエクスポートの形式ごとに1件ずつ書き出す
+type exportWriter interface {
+	Write(row exportRow) error
+	// Close は書き出しを完了する（w は閉じない）
+	Close() error
+}
+
+func newExportWriter(w io.Writer, format ExportFormat) (exportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVExportWriter(w)
+	case ExportFormatJSON:
+		return &jsonExportWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("エクスポートの形式が不正です: %q", format)
+	}
+}
+
+// csvExportWriter はヘッダー付きのCSVで書き出す
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) (*csvExportWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return &csvExportWriter{w: cw}, nil
+}
+
+func (c *csvExportWriter) Write(row exportRow) error {
+	return c.w.Write(row.values())
+}
+
+func (c *csvExportWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonExportWriter はメンションの配列のJSONとして1件ずつ書き出す
+type jsonExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonExportWriter) Write(row exportRow) error {
+	b, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+	_, err = fmt.Fprintf(j.w, "%s  %s", sep, b)
+	return err
+}
+
+func (j *jsonExportWriter) Close() error {
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
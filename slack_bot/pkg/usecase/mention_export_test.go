@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/dbtypes"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// go test ./pkg/usecase -update で testdata の golden ファイルを現在の出力で更新する
+var updateGolden = flag.Bool("update", false, "testdata の golden ファイルを更新する")
+
+// assertGolden は got が testdata/name の内容と一致することを確認する
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("golden ファイルの更新エラー: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden ファイルの読み込みエラー: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s と一致しません (-update で更新できます)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// fakeMentionQuery は ListBetween だけを実装し、mentions を pageSize 件ずつ返す
+type fakeMentionQuery struct {
+	di.SlackMentionQuery
+	mentions []*entity.SlackMention
+	pageSize int
+	calls    int
+}
+
+func (q *fakeMentionQuery) ListBetween(ctx context.Context, from, to time.Time, cursor string, limit int) ([]*entity.SlackMention, string, error) {
+	q.calls++
+	var matched []*entity.SlackMention
+	for _, m := range q.mentions {
+		if !m.EventTime.Before(from) && m.EventTime.Before(to) {
+			matched = append(matched, m)
+		}
+	}
+	start := 0
+	if cursor != "" {
+		start, _ = strconv.Atoi(cursor)
+	}
+	size := limit
+	if q.pageSize > 0 && q.pageSize < size {
+		size = q.pageSize
+	}
+	end := min(start+size, len(matched))
+	next := ""
+	if end < len(matched) {
+		next = strconv.Itoa(end)
+	}
+	return matched[start:end], next, nil
+}
+
+var exportTestFrom = time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+func newExportTestMentions() []*entity.SlackMention {
+	return []*entity.SlackMention{
+		{
+			ID:        dbtypes.ULID(ulid.MustParse("01HTGZ0000000000000000000A")),
+			Workspace: "example",
+			ChannelID: "C001",
+			UserID:    "U001",
+			UserName:  "alice",
+			Type:      "app_mention",
+			Status:    "answered",
+			Text:      "デプロイの手順を教えてください",
+			EventTime: time.Date(2024, 4, 1, 15, 30, 0, 0, time.UTC),
+		},
+		{
+			// カンマ・ダブルクォート・改行はCSVでエスケープする
+			ID:        dbtypes.ULID(ulid.MustParse("01HTGZ0000000000000000000B")),
+			Workspace: "example",
+			ChannelID: "C002",
+			UserID:    "U002",
+			UserName:  "bob",
+			Type:      "message",
+			Status:    "queued",
+			Text:      "a, \"b\"\nc",
+			EventTime: time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			// 最大文字数を超えるテキストは切り詰める
+			ID:        dbtypes.ULID(ulid.MustParse("01HTGZ0000000000000000000C")),
+			Workspace: "example",
+			ChannelID: "C001",
+			UserID:    "U003",
+			UserName:  "carol",
+			Type:      "app_mention",
+			Status:    "failed",
+			Text:      "とても長い質問の本文をここに書きます",
+			EventTime: time.Date(2024, 4, 3, 23, 59, 59, 0, time.UTC),
+		},
+	}
+}
+
+func TestMentionExporterExportGolden(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	tests := []struct {
+		name   string
+		format ExportFormat
+		golden string
+	}{
+		{name: "CSV", format: ExportFormatCSV, golden: "mention_export.golden.csv"},
+		{name: "JSON", format: ExportFormatJSON, golden: "mention_export.golden.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 1ページ2件にしてカーソルをまたいだ書き出しも確認する
+			query := &fakeMentionQuery{mentions: newExportTestMentions(), pageSize: 2}
+			exporter := NewMentionExporter(query, 0, 10)
+			var buf bytes.Buffer
+			count, err := exporter.Export(context.Background(), &buf, tt.format, exportTestFrom, exportTestFrom.AddDate(0, 0, 7), loc)
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if count != 3 {
+				t.Errorf("Export() = %d, want 3", count)
+			}
+			if query.calls != 2 {
+				t.Errorf("ListBetween calls = %d, want 2", query.calls)
+			}
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestMentionExporterExportEmpty(t *testing.T) {
+	tests := []struct {
+		format ExportFormat
+		want   string
+	}{
+		{format: ExportFormatCSV, want: "id,event_time,workspace,channel,user,user_name,type,status,text\n"},
+		{format: ExportFormatJSON, want: "[]\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		exporter := NewMentionExporter(&fakeMentionQuery{}, 0, 0)
+		count, err := exporter.Export(context.Background(), &buf, tt.format, exportTestFrom, exportTestFrom.AddDate(0, 0, 1), time.UTC)
+		if err != nil || count != 0 {
+			t.Fatalf("Export(%s) = %d, %v, want 0, nil", tt.format, count, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Export(%s) output = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestMentionExporterExportTooManyRows(t *testing.T) {
+	exporter := NewMentionExporter(&fakeMentionQuery{mentions: newExportTestMentions()}, 2, 0)
+	count, err := exporter.Export(context.Background(), &bytes.Buffer{}, ExportFormatJSON, exportTestFrom, exportTestFrom.AddDate(0, 0, 7), time.UTC)
+	if !errors.Is(err, ErrExportTooManyRows) {
+		t.Errorf("Export() error = %v, want %v", err, ErrExportTooManyRows)
+	}
+	if count != 2 {
+		t.Errorf("Export() = %d, want 2", count)
+	}
+}
+
+func TestMentionExporterExportInvalid(t *testing.T) {
+	exporter := NewMentionExporter(&fakeMentionQuery{}, 0, 0)
+	if _, err := exporter.Export(context.Background(), &bytes.Buffer{}, ExportFormatCSV, exportTestFrom, exportTestFrom, time.UTC); err == nil {
+		t.Error("開始日時と終了日時が同じ場合に Export() error = nil, want error")
+	}
+	if _, err := exporter.Export(context.Background(), &bytes.Buffer{}, "xml", exportTestFrom, exportTestFrom.AddDate(0, 0, 1), time.UTC); err == nil {
+		t.Error("不正な形式で Export() error = nil, want error")
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	tests := []struct {
+		text string
+		n    int
+		want string
+	}{
+		{text: "こんにちは", n: 0, want: "こんにちは"},
+		{text: "こんにちは", n: 5, want: "こんにちは"},
+		{text: "こんにちは", n: 2, want: "こん…(+3)"},
+	}
+	for _, tt := range tests {
+		if got := redactText(tt.text, tt.n); got != tt.want {
+			t.Errorf("redactText(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.want)
+		}
+	}
+}
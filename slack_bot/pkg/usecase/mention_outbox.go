@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/tracing"
+)
+
+// MentionOutbox はメンションとキューに送信するメッセージを同じトランザクションで保存する
+// 保存したメッセージは OutboxRelay がバックグラウンドでキューに送信する
+type MentionOutbox struct {
+	repository di.OutboxRepository
+}
+
+func NewMentionOutbox(repository di.OutboxRepository) *MentionOutbox {
+	return &MentionOutbox{repository: repository}
+}
+
+// HandleMention はメンションと queueKey のキューに送信するメッセージを保存する
+// どちらかの保存に失敗した場合はどちらも保存されない
+func (o *MentionOutbox) HandleMention(ctx context.Context, mention *slack.Mention, queueKey string, payload []byte, correlationID string) error {
+	e, err := entity.NewSlackMention(mention)
+	if err != nil {
+		return err
+	}
+	message := entity.NewOutboxMessage(ulid.ULID(mention.ID), correlationID, queueKey, payload)
+	// 送信は後から OutboxRelay が行うため、受信したスパンを保存して送信時に引き継ぐ
+	message.TraceParent = tracing.TraceParent(ctx)
+	if err := o.repository.CreateWithMention(ctx, e, message); err != nil {
+		return fmt.Errorf("メンションとアウトボックスの保存に失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	queuemodel "github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/queue"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// QueueKeyResolver はワークスペースとメッセージの種類から送信先のキューのキーを返す
+type QueueKeyResolver func(workspace, msgType string) string
+
+// MentionReplayer は保存したメンションからキューのメッセージを作り直して再送する
+// AIワーカーの調査用で、受信時と同じ NewMentionMessage でメッセージを作成する
+// Slackから取得する情報（会話履歴・付加処理の結果・リアクションの絵文字名）は保存していないため含まない
+type MentionReplayer struct {
+	query         di.SlackMentionQuery
+	publisher     di.QueuePublisher
+	queueKey      QueueKeyResolver
+	statusUpdates bool
+}
+
+func NewMentionReplayer(query di.SlackMentionQuery, publisher di.QueuePublisher, queueKey QueueKeyResolver, statusUpdates bool) *MentionReplayer {
+	return &MentionReplayer{
+		query:         query,
+		publisher:     publisher,
+		queueKey:      queueKey,
+		statusUpdates: statusUpdates,
+	}
+}
+
+// replayEvent は保存した受信イベントのうち、メッセージの作成に使用する項目
+type replayEvent struct {
+	TimeStamp       string `json:"ts"`
+	ThreadTimeStamp string `json:"thread_ts"`
+}
+
+// Replay は id のメンションをキューに送信し、キューが発行したメッセージIDを返す
+// 相関IDには受信時と同じくメンションのIDを使用する
+func (r *MentionReplayer) Replay(ctx context.Context, id ulid.ULID) (string, error) {
+	stored, err := r.query.FindByID(ctx, slack.MentionID(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("メンション %s は保存されていません", id)
+	}
+	if err != nil {
+		return "", fmt.Errorf("メンションの取得に失敗しました (id=%s): %w", id, err)
+	}
+	mention := stored.ToModel()
+
+	// ts の列を追加する前に保存したメンションは、保存した受信イベントまたは時刻からSlackのタイムスタンプを復元する
+	if mention.TS == "" {
+		mention.TS = slack.SlackTS(slack.FormatSlackTimestamp(time.Time(mention.Timestamp)))
+		if mention.RawEvent != "" {
+			var evt replayEvent
+			if err := json.Unmarshal([]byte(mention.RawEvent), &evt); err != nil {
+				logger.Errorf(ctx, "受信イベントのデコードエラー（保存した項目から作成します）: %v", err)
+			} else if evt.TimeStamp != "" {
+				mention.TS, mention.ThreadTS = slack.SlackTS(evt.TimeStamp), slack.SlackTS(evt.ThreadTimeStamp)
+			}
+		}
+	}
+
+	var msg *queuemodel.MentionMessage
+	if mention.Source == slack.MessageSourceReaction {
+		msg = queuemodel.NewReactionMessage(mention, "", "")
+	} else {
+		msg = queuemodel.NewMentionMessage(mention, nil)
+	}
+	msg.CorrelationID = mention.ID.String()
+	msg.StatusUpdates = r.statusUpdates
+
+	queueKey := r.queueKey(string(mention.Workspace), msg.Type)
+	messageID, err := r.publisher.PublishWithID(logger.WithCorrelationID(ctx, msg.CorrelationID), queueKey, msg)
+	if err != nil {
+		return "", fmt.Errorf("キュー (%s) への送信に失敗しました: %w", queueKey, err)
+	}
+	return messageID, nil
+}
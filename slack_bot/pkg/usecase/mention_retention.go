@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// MentionRetention は保持期間を過ぎたメンションをアーカイブしてから削除する
+type MentionRetention struct {
+	query     di.SlackMentionQuery
+	command   di.SlackMentionCommand
+	archiver  di.MentionArchiver
+	maxAge    time.Duration
+	batchSize int
+}
+
+// NewMentionRetention はメンションの削除処理を作成する
+// archiver が nil の場合はアーカイブせずに削除する
+func NewMentionRetention(
+	query di.SlackMentionQuery,
+	command di.SlackMentionCommand,
+	archiver di.MentionArchiver,
+	maxAge time.Duration,
+	batchSize int,
+) *MentionRetention {
+	return &MentionRetention{
+		query:     query,
+		command:   command,
+		archiver:  archiver,
+		maxAge:    maxAge,
+		batchSize: batchSize,
+	}
+}
+
+// Run は保持期間を過ぎたメンションをバッチ単位でアーカイブ・削除し、削除した件数を返す
+// アーカイブに失敗したバッチは削除しない
+func (r *MentionRetention) Run(ctx context.Context) (int, error) {
+	before := time.Now().Add(-r.maxAge)
+	deleted := 0
+	for {
+		mentions, err := r.query.FindCreatedBefore(ctx, before, r.batchSize)
+		if err != nil {
+			return deleted, fmt.Errorf("保持期間を過ぎたメンションの取得に失敗しました: %w", err)
+		}
+		if len(mentions) == 0 {
+			return deleted, nil
+		}
+
+		if r.archiver != nil {
+			if err := r.archiver.Archive(ctx, mentions); err != nil {
+				return deleted, fmt.Errorf("メンションのアーカイブに失敗しました: %w", err)
+			}
+		}
+
+		ids := make([]slack.MentionID, 0, len(mentions))
+		for _, m := range mentions {
+			ids = append(ids, slack.MentionID(m.ID))
+		}
+		if err := r.command.DeleteByIDs(ctx, ids); err != nil {
+			return deleted, fmt.Errorf("メンションの削除に失敗しました: %w", err)
+		}
+		deleted += len(mentions)
+
+		if len(mentions) < r.batchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// RunEvery は ctx がキャンセルされるまで interval ごとに Run を実行する
+func (r *MentionRetention) RunEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := r.Run(ctx)
+		if err != nil {
+			logger.Errorf(ctx, "メンションの削除処理エラー: %v", err)
+		} else if deleted > 0 {
+			logger.Printf(ctx, "保持期間を過ぎたメンションを%d件削除しました", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
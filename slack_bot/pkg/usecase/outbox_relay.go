@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/di"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/domain/model/slack"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/tracing"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/logger"
+)
+
+// outboxInitialBackoff は送信に失敗したメッセージを最初に再送するまでの時間
+// 以降は失敗するたびに2倍にし、maxBackoff を上限とする
+const outboxInitialBackoff = time.Second
+
+// OutboxRelay はアウトボックスに保存された送信待ちのメッセージをキューに送信する
+type OutboxRelay struct {
+	repository di.OutboxRepository
+	publisher  di.QueuePublisher
+	// mentions はデータベースが無効な場合 nil
+	mentions di.SlackMentionCommand
+	// storeMessageID はSQSのメッセージIDをメンションに記録するかどうか
+	storeMessageID bool
+	batchSize      int
+	lease          time.Duration
+	maxBackoff     time.Duration
+	// pending は送信処理のたびに未送信のメッセージ数を記録するメトリクス（nil の場合は記録しない）
+	pending prometheus.Gauge
+	now     func() time.Time
+}
+
+// NewOutboxRelay はアウトボックスの送信処理を作成する
+// lease は取得したメッセージを他のインスタンスが取得しない時間で、送信中にプロセスが停止した場合は lease の経過後に再送される
+// mentions を指定した場合は送信後にメンションの状態を queued にし、storeMessageID の場合はSQSのメッセージIDも記録する
+// pending を指定した場合は送信処理のたびに未送信のメッセージ数を記録する
+func NewOutboxRelay(
+	repository di.OutboxRepository,
+	publisher di.QueuePublisher,
+	mentions di.SlackMentionCommand,
+	storeMessageID bool,
+	batchSize int,
+	lease time.Duration,
+	maxBackoff time.Duration,
+	pending prometheus.Gauge,
+) *OutboxRelay {
+	return &OutboxRelay{
+		repository:     repository,
+		publisher:      publisher,
+		mentions:       mentions,
+		storeMessageID: storeMessageID,
+		batchSize:      batchSize,
+		lease:          lease,
+		maxBackoff:     maxBackoff,
+		pending:        pending,
+		now:            time.Now,
+	}
+}
+
+// Run は送信待ちのメッセージを作成順に送信し、送信できた件数を返す
+// 送信に失敗したメッセージはバックオフ後に再送されるようにして、後続のメッセージの送信を続ける
+func (r *OutboxRelay) Run(ctx context.Context) (int, error) {
+	defer r.recordPending(ctx)
+
+	messages, err := r.repository.ClaimPending(ctx, r.now(), r.lease, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("送信待ちのメッセージの取得に失敗しました: %w", err)
+	}
+
+	sent := 0
+	for _, m := range messages {
+		ctx := tracing.WithTraceParent(logger.WithCorrelationID(ctx, m.CorrelationID), m.TraceParent)
+		messageID, err := r.publisher.PublishWithID(ctx, m.QueueKey, m.Payload)
+		if err != nil {
+			logger.Errorf(ctx, "アウトボックスのメッセージの送信エラー (id=%d attempts=%d): %v", m.ID, m.Attempts+1, err)
+			if err := r.repository.MarkFailed(ctx, m.ID, r.now().Add(r.backoff(m.Attempts)), err.Error()); err != nil {
+				return sent, fmt.Errorf("送信失敗の記録に失敗しました (id=%d): %w", m.ID, err)
+			}
+			continue
+		}
+		if err := r.repository.MarkSent(ctx, m.ID, r.now()); err != nil {
+			// 送信済みの記録に失敗した場合は lease の経過後に再送されるため、重複して送信される可能性がある
+			return sent, fmt.Errorf("送信済みの記録に失敗しました (id=%d): %w", m.ID, err)
+		}
+		r.markQueued(ctx, slack.MentionID(m.MentionID), messageID)
+		sent++
+	}
+	return sent, nil
+}
+
+// markQueued はメンションの状態を queued にし、設定されている場合はSQSのメッセージIDを記録する
+// 送信は完了しているため、記録に失敗してもログに出力するのみ
+func (r *OutboxRelay) markQueued(ctx context.Context, mentionID slack.MentionID, messageID string) {
+	if r.mentions == nil || mentionID == (slack.MentionID{}) {
+		return
+	}
+	if err := r.mentions.UpdateStatus(ctx, mentionID, string(slack.MentionStatusQueued), ""); err != nil {
+		logger.Errorf(ctx, "メンションの状態の更新エラー: %v", err)
+	}
+	if !r.storeMessageID || messageID == "" {
+		return
+	}
+	if err := r.mentions.UpdateSQSMessageID(ctx, mentionID, messageID); err != nil {
+		logger.Errorf(ctx, "SQSのメッセージIDの記録エラー: %v", err)
+	}
+}
+
+// recordPending は送信処理後に残っている未送信（送信中を含む）のメッセージ数をメトリクスに記録する
+func (r *OutboxRelay) recordPending(ctx context.Context) {
+	if r.pending == nil {
+		return
+	}
+	count, err := r.repository.CountPending(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "アウトボックスの件数取得エラー: %v", err)
+		return
+	}
+	r.pending.Set(float64(count))
+}
+
+// RunEvery は ctx がキャンセルされるまで interval ごとに Run を実行する
+func (r *OutboxRelay) RunEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Run(ctx); err != nil {
+			logger.Errorf(ctx, "アウトボックスの送信処理エラー: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// backoff は attempts 回失敗したメッセージを再送するまでの時間を返す
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	d := outboxInitialBackoff
+	for i := 0; i < attempts && d < r.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.maxBackoff)
+}
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/takeuchi-shogo/ai-slack-bot/slack_bot/pkg/infra/entity"
+)
+
+// fakeOutboxRepository は OutboxRepository の ClaimPending と同じ条件でメッセージを取得するメモリ上の実装
+type fakeOutboxRepository struct {
+	mu       sync.Mutex
+	messages []*entity.OutboxMessage
+}
+
+func (r *fakeOutboxRepository) CreateWithMention(ctx context.Context, mention *entity.SlackMention, message *entity.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	message.ID = int64(len(r.messages) + 1)
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *fakeOutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []*entity.OutboxMessage
+	for _, m := range r.messages {
+		if len(claimed) >= limit {
+			break
+		}
+		pending := m.Status == entity.OutboxStatusPending && !m.NextAttemptAt.After(now)
+		expired := m.Status == entity.OutboxStatusProcessing && !m.LockedUntil.After(now)
+		if !pending && !expired {
+			continue
+		}
+		m.Status = entity.OutboxStatusProcessing
+		m.LockedUntil = now.Add(lease)
+		copied := *m
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (r *fakeOutboxRepository) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.messages[id-1]
+	m.Status = entity.OutboxStatusSent
+	m.SentAt = sentAt
+	m.LockedUntil = time.Time{}
+	return nil
+}
+
+func (r *fakeOutboxRepository) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.messages[id-1]
+	m.Status = entity.OutboxStatusPending
+	m.Attempts++
+	m.NextAttemptAt = nextAttemptAt
+	m.LastError = lastError
+	m.LockedUntil = time.Time{}
+	return nil
+}
+
+func (r *fakeOutboxRepository) CountPending(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, m := range r.messages {
+		if m.Status != entity.OutboxStatusSent {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeQueuePublisher は送信したメッセージを記録し、fail に含まれるキューへの送信を失敗させる
+type fakeQueuePublisher struct {
+	mu        sync.Mutex
+	published []string
+	fail      map[string]bool
+}
+
+func (p *fakeQueuePublisher) PublishTo(ctx context.Context, queueKey string, msg any) error {
+	_, err := p.PublishWithID(ctx, queueKey, msg)
+	return err
+}
+
+func (p *fakeQueuePublisher) PublishWithID(ctx context.Context, queueKey string, msg any) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail[queueKey] {
+		return "", errors.New("queue unavailable")
+	}
+	p.published = append(p.published, string(msg.(json.RawMessage)))
+	return "message-id", nil
+}
+
+func (p *fakeQueuePublisher) Check(ctx context.Context) error { return nil }
+
+func (p *fakeQueuePublisher) Backlog(ctx context.Context, queueKey string) (int, error) {
+	return 0, nil
+}
+
+func (p *fakeQueuePublisher) Close() error { return nil }
+
+func newTestOutboxMessage(queueKey, payload string, createdAt time.Time) *entity.OutboxMessage {
+	return &entity.OutboxMessage{
+		QueueKey:      queueKey,
+		Payload:       json.RawMessage(payload),
+		Status:        entity.OutboxStatusPending,
+		NextAttemptAt: createdAt,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+	}
+}
+
+func TestOutboxRelayRun(t *testing.T) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	repository := &fakeOutboxRepository{}
+	for _, m := range []*entity.OutboxMessage{
+		newTestOutboxMessage("mention", `{"n":1}`, now),
+		newTestOutboxMessage("failing", `{"n":2}`, now),
+		newTestOutboxMessage("mention", `{"n":3}`, now),
+	} {
+		if err := repository.CreateWithMention(context.Background(), nil, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	publisher := &fakeQueuePublisher{fail: map[string]bool{"failing": true}}
+	pending := prometheus.NewGauge(prometheus.GaugeOpts{Name: "outbox_pending"})
+	relay := NewOutboxRelay(repository, publisher, nil, false, 10, time.Minute, time.Minute, pending)
+	relay.now = func() time.Time { return now }
+
+	sent, err := relay.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("sent = %d, want 2", sent)
+	}
+	// 失敗したメッセージがあっても後続のメッセージを作成順に送信する
+	if want := []string{`{"n":1}`, `{"n":3}`}; len(publisher.published) != 2 || publisher.published[0] != want[0] || publisher.published[1] != want[1] {
+		t.Errorf("published = %v, want %v", publisher.published, want)
+	}
+	failed := repository.messages[1]
+	if failed.Status != entity.OutboxStatusPending || failed.Attempts != 1 || !failed.NextAttemptAt.Equal(now.Add(outboxInitialBackoff)) {
+		t.Errorf("failed message = status %s attempts %d next %v, want pending 1 %v", failed.Status, failed.Attempts, failed.NextAttemptAt, now.Add(outboxInitialBackoff))
+	}
+	if got := testutil.ToFloat64(pending); got != 1 {
+		t.Errorf("outbox_pending = %v, want 1", got)
+	}
+
+	// バックオフの経過後に再送し、未送信のメッセージ数を 0 にする
+	delete(publisher.fail, "failing")
+	now = now.Add(outboxInitialBackoff)
+	if sent, err := relay.Run(context.Background()); err != nil || sent != 1 {
+		t.Fatalf("Run() = %d, %v, want 1, nil", sent, err)
+	}
+	if got := testutil.ToFloat64(pending); got != 0 {
+		t.Errorf("outbox_pending = %v, want 0", got)
+	}
+}
+
+// 保存後・送信前にプロセスが停止した場合も、lease の経過後に別のインスタンスが送信する
+func TestOutboxRelayRecoversAfterCrash(t *testing.T) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	lease := 30 * time.Second
+	repository := &fakeOutboxRepository{}
+	if err := repository.CreateWithMention(context.Background(), nil, newTestOutboxMessage("mention", `{"n":1}`, now)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 停止したインスタンスがメッセージを取得したまま送信していない状態にする
+	if _, err := repository.ClaimPending(context.Background(), now, lease, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	publisher := &fakeQueuePublisher{}
+	relay := NewOutboxRelay(repository, publisher, nil, false, 10, lease, time.Minute, nil)
+	relay.now = func() time.Time { return now.Add(lease / 2) }
+	if sent, err := relay.Run(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("lease 中の Run() = %d, %v, want 0, nil", sent, err)
+	}
+
+	relay.now = func() time.Time { return now.Add(lease) }
+	if sent, err := relay.Run(context.Background()); err != nil || sent != 1 {
+		t.Fatalf("lease 経過後の Run() = %d, %v, want 1, nil", sent, err)
+	}
+	if m := repository.messages[0]; m.Status != entity.OutboxStatusSent {
+		t.Errorf("status = %s, want %s", m.Status, entity.OutboxStatusSent)
+	}
+}
+
+// 送信に失敗したメッセージは再送で1回だけ送信し、送信済みになった後の送信処理では再び送信しない
+func TestOutboxRelayRetryDeliversOnce(t *testing.T) {
+	now := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	lease := 30 * time.Second
+	repository := &fakeOutboxRepository{}
+	if err := repository.CreateWithMention(context.Background(), nil, newTestOutboxMessage("mention", `{"n":1}`, now)); err != nil {
+		t.Fatal(err)
+	}
+	publisher := &fakeQueuePublisher{fail: map[string]bool{"mention": true}}
+	relay := NewOutboxRelay(repository, publisher, nil, false, 10, lease, time.Minute, nil)
+	relay.now = func() time.Time { return now }
+
+	if sent, err := relay.Run(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("送信に失敗した Run() = %d, %v, want 0, nil", sent, err)
+	}
+
+	// キューが復旧しても、バックオフの間は再送しない
+	delete(publisher.fail, "mention")
+	if sent, err := relay.Run(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("バックオフ中の Run() = %d, %v, want 0, nil", sent, err)
+	}
+
+	now = now.Add(outboxInitialBackoff)
+	if sent, err := relay.Run(context.Background()); err != nil || sent != 1 {
+		t.Fatalf("再送の Run() = %d, %v, want 1, nil", sent, err)
+	}
+
+	// 送信済みのメッセージは lease やバックオフの経過後も再び取得しない
+	for _, elapsed := range []time.Duration{0, lease, time.Hour} {
+		relay.now = func() time.Time { return now.Add(elapsed) }
+		if sent, err := relay.Run(context.Background()); err != nil || sent != 0 {
+			t.Fatalf("%v 経過後の Run() = %d, %v, want 0, nil", elapsed, sent, err)
+		}
+	}
+
+	if want := []string{`{"n":1}`}; len(publisher.published) != 1 || publisher.published[0] != want[0] {
+		t.Errorf("published = %v, want %v", publisher.published, want)
+	}
+	if m := repository.messages[0]; m.Status != entity.OutboxStatusSent || m.Attempts != 1 || !m.SentAt.Equal(now) {
+		t.Errorf("message = status %s attempts %d sent_at %v, want %s 1 %v", m.Status, m.Attempts, m.SentAt, entity.OutboxStatusSent, now)
+	}
+}
+
+func TestOutboxRelayBackoff(t *testing.T) {
+	relay := NewOutboxRelay(nil, nil, nil, false, 10, time.Minute, 10*time.Second, nil)
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Second},
+		{attempts: 1, want: 2 * time.Second},
+		{attempts: 3, want: 8 * time.Second},
+		{attempts: 4, want: 10 * time.Second},
+		{attempts: 100, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := relay.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}